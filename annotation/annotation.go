@@ -0,0 +1,233 @@
+// Package annotation declares the schema annotations entmaid reads to decorate the diagram, e.g.:
+//
+//	func (User) Annotations() []schema.Annotation {
+//		return []schema.Annotation{
+//			annotation.Styled(annotation.Style{Fill: "#f96", Stroke: "#c00"}),
+//			annotation.DisplayName("Customer"),
+//			annotation.Described("Accounts", "The customers and their cars."),
+//			annotation.Linked("https://catalog.example.com/tables/users"),
+//		}
+//	}
+//
+//	func (User) Fields() []ent.Field {
+//		return []ent.Field{
+//			field.String("email").
+//				Annotations(annotation.Classified(annotation.PII)),
+//			field.String("password_hash").
+//				Annotations(annotation.Visible(annotation.VisibilityPrivate)),
+//		}
+//	}
+//
+//	func (User) Edges() []ent.Edge {
+//		return []ent.Edge{
+//			edge.To("cars", Car.Type).
+//				Annotations(annotation.Cardinalities(annotation.ExactlyOne, annotation.OneOrMore)),
+//		}
+//	}
+//
+// The annotations only describe the diagram, they don't change the code generated by ent.
+package annotation
+
+import (
+	"entgo.io/ent/schema"
+)
+
+// Name is the name the annotations are stored under in the ent schema graph.
+const Name = "Entmaid"
+
+// Annotation holds everything entmaid reads from the annotations of a schema, several of them declared together
+// are merged into one.
+type Annotation struct {
+	Style *Style `json:"style,omitempty"`
+	// Classification is the sensitivity of the data held by a field.
+	Classification Classification `json:"classification,omitempty"`
+	// Relationship overrides how the relationship of an edge is drawn.
+	Relationship *Relationship `json:"relationship,omitempty"`
+	// Visibility is the audience of the diagrams an entity or a field is drawn in.
+	Visibility Visibility `json:"visibility,omitempty"`
+	// DisplayName is the name an entity is labeled with in the diagram.
+	DisplayName string `json:"displayName,omitempty"`
+	// Diagram describes the whole diagram, declared by any one schema type of the schema.
+	Diagram *Diagram `json:"diagram,omitempty"`
+	// Link is the URL of the external page documenting an entity, e.g. in the data catalog.
+	Link string `json:"link,omitempty"`
+}
+
+// Style highlights an entity in the diagram, e.g. the tables holding PII or taking most of the traffic. The colors
+// are CSS colors.
+type Style struct {
+	Fill   string `json:"fill,omitempty"`
+	Stroke string `json:"stroke,omitempty"`
+	// Color is the color of the text.
+	Color string `json:"color,omitempty"`
+}
+
+// Styled highlights the entity of the schema type with the style.
+func Styled(style Style) Annotation {
+	return Annotation{Style: &style}
+}
+
+// Classification is the sensitivity of the data of a field, for data governance reviews.
+type Classification string
+
+// The common classifications, any other can be used as well.
+const (
+	PII    Classification = "pii"
+	Secret Classification = "secret"
+	Public Classification = "public"
+)
+
+// Classified classifies the data of the field.
+func Classified(classification Classification) Annotation {
+	return Annotation{Classification: classification}
+}
+
+// Visibility is the audience of the diagrams an entity or a field is drawn in, the entities and fields without one
+// are public. The diagrams of a visibility draw the entities and fields of the visibilities above it, e.g. internal
+// diagrams draw the public and internal ones.
+type Visibility string
+
+const (
+	VisibilityPublic   Visibility = "public"
+	VisibilityInternal Visibility = "internal"
+	VisibilityPrivate  Visibility = "private"
+)
+
+// Visible sets the visibility of the entity of the schema type or of the field, e.g. to leave the internal tables
+// out of the diagram of the public documentation.
+func Visible(visibility Visibility) Annotation {
+	return Annotation{Visibility: visibility}
+}
+
+// DisplayName labels the entity of the schema type with a business-friendly name in the diagram, e.g. "Purchase
+// Order" for a PurchaseOrder schema, its relationships still use the name of the schema type.
+func DisplayName(name string) Annotation {
+	return Annotation{DisplayName: name}
+}
+
+// Linked links the entity of the schema type to the external page documenting it, like its data catalog entry or
+// runbook. The classDiagram binds a click to it and the Markdown diagrams list it.
+func Linked(url string) Annotation {
+	return Annotation{Link: url}
+}
+
+// Diagram is the title and description of the diagram, so it describes itself when viewed on its own.
+type Diagram struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Described titles and describes the diagram of the schema, declared by any one schema type (e.g. the root entity of
+// the domain). The --title and --description flags take precedence.
+func Described(title string, description string) Annotation {
+	return Annotation{Diagram: &Diagram{Title: title, Description: description}}
+}
+
+// Cardinality is how many entities can be on one side of a relationship.
+type Cardinality string
+
+const (
+	ZeroOrOne  Cardinality = "zero-or-one"
+	ExactlyOne Cardinality = "exactly-one"
+	ZeroOrMore Cardinality = "zero-or-more"
+	OneOrMore  Cardinality = "one-or-more"
+)
+
+// Relationship overrides how the relationship of an edge is drawn, for the constraints ent can't express (e.g. an
+// order always having at least one line) or the relationships the database doesn't enforce.
+type Relationship struct {
+	// From and To override the cardinalities of the entity declaring the edge and of the entity it points to. For M2M
+	// edges, they're how many rows of the join table the entity on the other side has.
+	From Cardinality `json:"from,omitempty"`
+	To   Cardinality `json:"to,omitempty"`
+	// Logical relationships aren't enforced by a foreign key, they're drawn dashed.
+	Logical bool `json:"logical,omitempty"`
+	// Weight is how thick the line of the relationship is drawn by the formats that can, e.g. 3 for the joins of
+	// the hot path. Lines are 1 thick by default.
+	Weight int `json:"weight,omitempty"`
+}
+
+// Cardinalities overrides the cardinalities of the relationship of the edge, an empty one keeps the cardinality
+// derived from the edge.
+func Cardinalities(from Cardinality, to Cardinality) Annotation {
+	return Annotation{Relationship: &Relationship{From: from, To: to}}
+}
+
+// Logical draws the relationship of the edge dashed, as it's only a logical one not enforced by the database.
+func Logical() Annotation {
+	return Annotation{Relationship: &Relationship{Logical: true}}
+}
+
+// Weighted draws the relationship of the edge with a line of the weight, to make the important joins stand out in
+// big diagrams.
+func Weighted(weight int) Annotation {
+	return Annotation{Relationship: &Relationship{Weight: weight}}
+}
+
+// Name implements the schema.Annotation interface.
+func (Annotation) Name() string {
+	return Name
+}
+
+// Merge implements the schema.Merger interface, the settings of the other annotation take precedence.
+func (a Annotation) Merge(other schema.Annotation) schema.Annotation {
+	var o Annotation
+	switch other := other.(type) {
+	case Annotation:
+		o = other
+	case *Annotation:
+		if other == nil {
+			return a
+		}
+		o = *other
+	default:
+		return a
+	}
+
+	if o.Style != nil {
+		a.Style = o.Style
+	}
+	if o.Classification != "" {
+		a.Classification = o.Classification
+	}
+	if o.Relationship != nil {
+		a.Relationship = a.Relationship.merge(o.Relationship)
+	}
+	if o.Visibility != "" {
+		a.Visibility = o.Visibility
+	}
+	if o.DisplayName != "" {
+		a.DisplayName = o.DisplayName
+	}
+	if o.Diagram != nil {
+		a.Diagram = o.Diagram
+	}
+	if o.Link != "" {
+		a.Link = o.Link
+	}
+
+	return a
+}
+
+// merge returns the overrides of both relationships, the other taking precedence.
+func (r *Relationship) merge(other *Relationship) *Relationship {
+	merged := *other
+	if r == nil {
+		return &merged
+	}
+
+	if merged.From == "" {
+		merged.From = r.From
+	}
+	if merged.To == "" {
+		merged.To = r.To
+	}
+	merged.Logical = merged.Logical || r.Logical
+	if merged.Weight == 0 {
+		merged.Weight = r.Weight
+	}
+
+	return &merged
+}
+
+var _ schema.Merger = Annotation{}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"strconv"
+)
+
+// WithMaxTypeLength abbreviates the attribute types longer than n characters in the Mermaid diagram, which are
+// expanded in a legend below it. Long qualified or generic types otherwise blow up the width of the entity boxes.
+func WithMaxTypeLength(n int) Option {
+	return func(o *options) {
+		o.maxTypeLength = n
+	}
+}
+
+// WithMaxNameLength abbreviates the attribute names longer than n characters in the Mermaid diagram, like
+// WithMaxTypeLength does for types.
+func WithMaxNameLength(n int) Option {
+	return func(o *options) {
+		o.maxNameLength = n
+	}
+}
+
+// Abbreviation is a type or name shortened in the diagram.
+type Abbreviation struct {
+	Short string `json:"short"`
+	Full  string `json:"full"`
+}
+
+// abbreviator shortens the types and names past their maximum length, making sure every abbreviation expands to a
+// single full type or name.
+type abbreviator struct {
+	maxTypeLength int
+	maxNameLength int
+
+	short         map[string]string
+	full          map[string]string
+	abbreviations []Abbreviation
+}
+
+func newAbbreviator(o *options) *abbreviator {
+	return &abbreviator{
+		maxTypeLength: o.maxTypeLength,
+		maxNameLength: o.maxNameLength,
+		short:         make(map[string]string),
+		full:          make(map[string]string),
+	}
+}
+
+// abbreviateModel abbreviates every attribute of the model in order, so the abbreviations are the same whether
+// they're rendered in the diagram or listed in a legend.
+func abbreviateModel(model *Model, o *options) *abbreviator {
+	a := newAbbreviator(o)
+	for _, entity := range model.Entities {
+		for _, attribute := range entity.Attributes {
+			a.typeName(attribute.Type)
+			a.attributeName(attribute.Name)
+		}
+	}
+
+	return a
+}
+
+func (a *abbreviator) typeName(s string) string {
+	return a.abbreviate(s, a.maxTypeLength)
+}
+
+func (a *abbreviator) attributeName(s string) string {
+	return a.abbreviate(s, a.maxNameLength)
+}
+
+// abbreviate truncates the string past max characters, marking it with a trailing underscore and a number when the
+// truncated string is already used for another one.
+func (a *abbreviator) abbreviate(s string, max int) string {
+	runes := []rune(s)
+	if max <= 0 || len(runes) <= max {
+		return s
+	}
+
+	if short, ok := a.short[s]; ok {
+		return short
+	}
+
+	var short string
+	for i := 1; ; i++ {
+		suffix := "_"
+		if i > 1 {
+			suffix += strconv.Itoa(i)
+		}
+
+		keep := max - len(suffix)
+		if keep < 1 {
+			keep = 1
+		}
+
+		short = string(runes[:keep]) + suffix
+		if _, taken := a.full[short]; !taken {
+			break
+		}
+	}
+
+	a.short[s] = short
+	a.full[short] = s
+	a.abbreviations = append(a.abbreviations, Abbreviation{Short: short, Full: s})
+
+	return short
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import "testing"
+
+func TestAbbreviator(t *testing.T) {
+	a := newAbbreviator(&options{maxTypeLength: 8, maxNameLength: 4})
+
+	testCases := []struct {
+		got      string
+		expected string
+	}{
+		{got: a.typeName("string"), expected: "string"},
+		{got: a.typeName("pgtype-Range"), expected: "pgtype-_"},
+		{got: a.typeName("pgtype-Range"), expected: "pgtype-_"},
+		{got: a.typeName("pgtype-Interval"), expected: "pgtype_2"},
+		{got: a.attributeName("registered_at"), expected: "reg_"},
+		{got: a.attributeName("name"), expected: "name"},
+	}
+
+	for _, tc := range testCases {
+		if tc.got != tc.expected {
+			t.Errorf("Got %q, expected %q", tc.got, tc.expected)
+		}
+	}
+
+	expected := []Abbreviation{{Short: "pgtype-_", Full: "pgtype-Range"}, {Short: "pgtype_2", Full: "pgtype-Interval"}, {Short: "reg_", Full: "registered_at"}}
+	if len(a.abbreviations) != len(expected) {
+		t.Fatalf("Got abbreviations %v, expected %v", a.abbreviations, expected)
+	}
+
+	for i := range expected {
+		if a.abbreviations[i] != expected[i] {
+			t.Errorf("Got abbreviation %v, expected %v", a.abbreviations[i], expected[i])
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"strings"
+)
+
+// WithAnalyze reports the modeling smells of the schema as warnings: entities without any relationship (orphans)
+// and foreign keys referencing each other in a cycle, which can't be inserted without deferring a constraint.
+func WithAnalyze() Option {
+	return func(o *options) {
+		o.analyze = true
+	}
+}
+
+// WithPruneOrphans omits the entities without any relationship, including views, from the diagram. Lookup and
+// config tables otherwise clutter diagrams focused on the relationships.
+func WithPruneOrphans() Option {
+	return func(o *options) {
+		o.pruneOrphans = true
+	}
+}
+
+// analyzeModel warns about the orphans and the foreign key cycles of the model.
+func analyzeModel(model *Model, o *options) {
+	related := relatedEntities(model)
+	for _, entity := range model.Entities {
+		// Views are read-only queries, they're never related to the tables.
+		if !related[entity.Name] && !entity.View {
+			o.warn(entity.Name, "", "has no relationships")
+		}
+	}
+
+	for _, cycle := range foreignKeyCycles(model) {
+		o.warn(cycle[0], "", "foreign keys form a cycle between %s", strings.Join(cycle, ", "))
+	}
+}
+
+// foreignKeyCycles returns the groups of entities whose foreign keys reference each other in a cycle, each starting
+// from its first entity in the model. Entities referencing themselves, like trees, aren't cycles.
+func foreignKeyCycles(model *Model) [][]string {
+	references := make(map[string][]string)
+	for _, rel := range model.Relationships {
+		if fk := rel.ForeignKey; fk != nil && fk.Entity != fk.RefEntity {
+			references[fk.Entity] = append(references[fk.Entity], fk.RefEntity)
+		}
+	}
+
+	// Tarjan's algorithm, every strongly connected component of more than one entity is a cycle.
+	var (
+		index    = make(map[string]int)
+		lowLink  = make(map[string]int)
+		onStack  = make(map[string]bool)
+		stack    []string
+		cycles   [][]string
+		visit    func(name string)
+		nextNode int
+	)
+
+	visit = func(name string) {
+		index[name] = nextNode
+		lowLink[name] = nextNode
+		nextNode++
+		stack = append(stack, name)
+		onStack[name] = true
+
+		for _, ref := range references[name] {
+			if _, visited := index[ref]; !visited {
+				visit(ref)
+				lowLink[name] = min(lowLink[name], lowLink[ref])
+			} else if onStack[ref] {
+				lowLink[name] = min(lowLink[name], index[ref])
+			}
+		}
+
+		if lowLink[name] != index[name] {
+			return
+		}
+
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append([]string{top}, component...)
+
+			if top == name {
+				break
+			}
+		}
+
+		if len(component) > 1 {
+			cycles = append(cycles, component)
+		}
+	}
+
+	for _, entity := range model.Entities {
+		if _, visited := index[entity.Name]; !visited {
+			visit(entity.Name)
+		}
+	}
+
+	return cycles
+}
+
+// pruneOrphanEntities removes the entities without any relationship from the model.
+func pruneOrphanEntities(model *Model, o *options) {
+	related := relatedEntities(model)
+
+	entities := model.Entities[:0]
+	for _, entity := range model.Entities {
+		if related[entity.Name] {
+			entities = append(entities, entity)
+		} else {
+			o.skipEntity(entity.Name, "has no relationships (--prune-orphans)")
+		}
+	}
+
+	model.Entities = entities
+}
+
+// relatedEntities returns the names of the entities on either end of a relationship.
+func relatedEntities(model *Model) map[string]bool {
+	related := make(map[string]bool)
+	for _, rel := range model.Relationships {
+		related[rel.From] = true
+		related[rel.To] = true
+	}
+
+	return related
+}
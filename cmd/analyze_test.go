@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzeModel(t *testing.T) {
+	reference := func(from string, to string) *Relationship {
+		return &Relationship{From: from, To: to, ForeignKey: &ForeignKey{Entity: from, Column: to + "_id", RefEntity: to, RefColumn: "id"}}
+	}
+
+	model := &Model{
+		Entities: []*Entity{{Name: "Setting"}, {Name: "User"}, {Name: "Team"}, {Name: "Node"}, {Name: "ActiveUser", View: true}},
+		Relationships: []*Relationship{
+			reference("User", "Team"),
+			reference("Team", "User"),
+			reference("Node", "Node"),
+		},
+	}
+
+	o := newOptions([]Option{WithAnalyze()})
+	analyzeModel(model, o)
+
+	expected := []Warning{
+		{Entity: "Setting", Message: "has no relationships"},
+		{Entity: "User", Message: "foreign keys form a cycle between User, Team"},
+	}
+	if !reflect.DeepEqual(o.warnings, expected) {
+		t.Errorf("Got warnings %v, expected %v", o.warnings, expected)
+	}
+}
+
+func TestPruneOrphans(t *testing.T) {
+	model := &Model{
+		Entities:      []*Entity{{Name: "Setting"}, {Name: "User"}, {Name: "Team"}, {Name: "ActiveUser", View: true}},
+		Relationships: []*Relationship{{From: "User", To: "Team"}},
+	}
+
+	pruneOrphanEntities(model, newOptions(nil))
+
+	var names []string
+	for _, entity := range model.Entities {
+		names = append(names, entity.Name)
+	}
+
+	if expected := []string{"User", "Team"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Got entities %v, expected %v", names, expected)
+	}
+}
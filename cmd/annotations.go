@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/thediveo/enumflag/v2"
+)
+
+type ErrorFormat enumflag.Flag
+
+const (
+	ErrorFormatText ErrorFormat = iota
+	ErrorFormatGitHub
+)
+
+var ErrorFormatIds = map[ErrorFormat][]string{
+	ErrorFormatText:   {"text"},
+	ErrorFormatGitHub: {"github"},
+}
+
+// writeGitHubErrors prints every error joined in err as a GitHub Actions ::error workflow command, using the file
+// and line of the errors located in a file so they're shown inline on the pull request.
+func writeGitHubErrors(w io.Writer, err error) error {
+	var buildErr *BuildError
+	if errors.As(err, &buildErr) {
+		return writeGitHubDiagnostics(w, buildErr.Diagnostics)
+	}
+
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+
+	for _, err := range errs {
+		var properties []string
+
+		var fileErr *FileError
+		if errors.As(err, &fileErr) {
+			properties = append(properties, "file="+escapeGitHubProperty(fileErr.Path))
+			if fileErr.Line > 0 {
+				properties = append(properties, fmt.Sprintf("line=%d", fileErr.Line))
+			}
+		}
+
+		if err := writeGitHubCommand(w, "error", properties, err.Error()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeGitHubDiagnostics prints the build errors of the schema package at their file, line and column.
+func writeGitHubDiagnostics(w io.Writer, diagnostics []Diagnostic) error {
+	for _, diagnostic := range diagnostics {
+		var properties []string
+		if diagnostic.Path != "" {
+			properties = append(properties, "file="+escapeGitHubProperty(diagnostic.Path))
+		}
+		if diagnostic.Line > 0 {
+			properties = append(properties, fmt.Sprintf("line=%d", diagnostic.Line))
+		}
+		if diagnostic.Column > 0 {
+			properties = append(properties, fmt.Sprintf("col=%d", diagnostic.Column))
+		}
+
+		if err := writeGitHubCommand(w, "error", properties, diagnostic.Message); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeGitHubWarnings prints the warnings as GitHub Actions ::warning workflow commands.
+func writeGitHubWarnings(w io.Writer, warnings []Warning) error {
+	for _, warning := range warnings {
+		if err := writeGitHubCommand(w, "warning", nil, warning.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeGitHubCommand(w io.Writer, command string, properties []string, message string) error {
+	if len(properties) > 0 {
+		command += " " + strings.Join(properties, ",")
+	}
+
+	_, err := fmt.Fprintf(w, "::%s::%s\n", command, escapeGitHubData(message))
+
+	return err
+}
+
+var (
+	githubDataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	githubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+func escapeGitHubData(s string) string {
+	return githubDataEscaper.Replace(s)
+}
+
+func escapeGitHubProperty(s string) string {
+	return githubPropertyEscaper.Replace(s)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteGitHubErrors(t *testing.T) {
+	err := errors.Join(
+		&FileError{Path: "docs/erd.md", Line: 12, Err: fmt.Errorf("the Mermaid %w in docs/erd.md", ErrStaleDiagram)},
+		&FileError{Path: "a,b:c.md", Err: errors.New("50% done\nthen failed")},
+		errors.New("plain"),
+	)
+
+	var builder strings.Builder
+	if err := writeGitHubErrors(&builder, err); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "::error file=docs/erd.md,line=12::the Mermaid diagram is out of date in docs/erd.md\n" +
+		"::error file=a%2Cb%3Ac.md::50%25 done%0Athen failed\n" +
+		"::error::plain\n"
+
+	if builder.String() != expected {
+		t.Errorf("Got %q, expected %q", builder.String(), expected)
+	}
+}
+
+func TestWriteGitHubWarnings(t *testing.T) {
+	var builder strings.Builder
+	if err := writeGitHubWarnings(&builder, []Warning{{Entity: "User", Element: "pets", Message: "shown as int"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "::warning::User.pets: shown as int\n"; builder.String() != expected {
+		t.Errorf("Got %q, expected %q", builder.String(), expected)
+	}
+}
+
+func TestCheckMultiLineStringStaleLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "readme.md")
+	if err := os.WriteFile(path, []byte("# Title\n\n<s>\nold\n<e>\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	line, err := checkMultiLineString(path, "new", "<s>", "<e>", &options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if line != 4 {
+		t.Errorf("Got stale line %d, expected 4", line)
+	}
+
+	line, err = checkMultiLineString(path, "old", "<s>", "<e>", &options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if line != 0 {
+		t.Errorf("Got stale line %d for an up to date file, expected 0", line)
+	}
+}
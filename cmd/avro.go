@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+
+	"entgo.io/ent/schema/field"
+)
+
+// FormatAvro is a list of Avro record schemas, one per table, like the change events streamed by CDC connectors.
+const FormatAvro Format = "avro"
+
+var (
+	// avroNameRegex matches the valid names of the Avro records, fields and enum symbols.
+	avroNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// avroInvalidNameRegex matches the characters Avro doesn't allow in names.
+	avroInvalidNameRegex = regexp.MustCompile(`[^A-Za-z0-9_]`)
+)
+
+// AvroRecord is the Avro schema of a table.
+type AvroRecord struct {
+	Type      string       `json:"type"`
+	Name      string       `json:"name"`
+	Namespace string       `json:"namespace,omitempty"`
+	Doc       string       `json:"doc,omitempty"`
+	Fields    []*AvroField `json:"fields"`
+}
+
+// AvroField is a column of the table. Type is the name of a primitive type, a logical or enum type, or the union
+// with null of the nullable columns.
+type AvroField struct {
+	Name string `json:"name"`
+	Type any    `json:"type"`
+	Doc  string `json:"doc,omitempty"`
+	// Default is only set for the nullable columns, to null.
+	Default json.RawMessage `json:"default,omitempty"`
+}
+
+type avroLogicalType struct {
+	Type        string `json:"type"`
+	LogicalType string `json:"logicalType"`
+}
+
+type avroEnum struct {
+	Type    string   `json:"type"`
+	Name    string   `json:"name"`
+	Symbols []string `json:"symbols"`
+}
+
+// avroTypes are the Avro types of the ent field types, the others are defined as logical or enum types.
+var avroTypes = map[field.Type]string{
+	field.TypeBool:    "boolean",
+	field.TypeBytes:   "bytes",
+	field.TypeString:  "string",
+	field.TypeOther:   "string",
+	field.TypeJSON:    "string",
+	field.TypeEnum:    "string",
+	field.TypeInt8:    "int",
+	field.TypeInt16:   "int",
+	field.TypeInt32:   "int",
+	field.TypeUint8:   "int",
+	field.TypeUint16:  "int",
+	field.TypeInt:     "long",
+	field.TypeInt64:   "long",
+	field.TypeUint32:  "long",
+	field.TypeUint:    "long",
+	field.TypeUint64:  "long",
+	field.TypeFloat32: "float",
+	field.TypeFloat64: "double",
+}
+
+// renderAvro writes the Avro record schemas of the tables as a JSON list, named after the entities and namespaced by
+// their database schema.
+func renderAvro(_ context.Context, w io.Writer, model *Model, _ *options) error {
+	records := make([]*AvroRecord, 0, len(model.Entities))
+
+	for _, entity := range model.Entities {
+		name := strings.TrimPrefix(entity.Name, entity.Schema+".")
+		record := &AvroRecord{Type: "record", Name: avroName(name), Namespace: avroName(entity.Schema), Doc: "Table " + entity.Table, Fields: []*AvroField{}}
+		for _, attribute := range entity.Attributes {
+			record.Fields = append(record.Fields, avroField(record.Name, attribute))
+		}
+
+		records = append(records, record)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(records)
+}
+
+// avroName returns the name, replacing the characters Avro doesn't allow.
+func avroName(name string) string {
+	if name == "" || avroNameRegex.MatchString(name) {
+		return name
+	}
+
+	name = avroInvalidNameRegex.ReplaceAllString(name, "_")
+	if !avroNameRegex.MatchString(name) {
+		name = "_" + name
+	}
+
+	return name
+}
+
+// avroField returns the field of the column, the enums are named after their record and column.
+func avroField(record string, attribute *Attribute) *AvroField {
+	avro := &AvroField{Name: avroName(attribute.Name), Doc: attribute.comment}
+
+	switch {
+	case attribute.typeInfo == nil:
+		// The columns of the join tables aren't fields of the schema.
+		avro.Type = "long"
+	case attribute.typeInfo.Type == field.TypeTime:
+		avro.Type = avroLogicalType{Type: "long", LogicalType: "timestamp-micros"}
+	case attribute.typeInfo.Type == field.TypeUUID:
+		avro.Type = avroLogicalType{Type: "string", LogicalType: "uuid"}
+	case attribute.typeInfo.Type == field.TypeEnum && avroSymbols(attribute.enums):
+		avro.Type = avroEnum{Type: "enum", Name: record + "_" + avro.Name, Symbols: attribute.enums}
+	default:
+		avro.Type = avroTypes[attribute.typeInfo.Type]
+	}
+
+	// The optional fields are nullable columns, and a null default lets the readers skip them.
+	if attribute.optional || attribute.nillable {
+		avro.Type = []any{"null", avro.Type}
+		avro.Default = json.RawMessage("null")
+	}
+
+	return avro
+}
+
+// avroSymbols reports whether the enum values are valid Avro symbols, the others are kept as strings.
+func avroSymbols(values []string) bool {
+	for _, value := range values {
+		if !avroNameRegex.MatchString(value) {
+			return false
+		}
+	}
+
+	return len(values) > 0
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"entgo.io/ent/schema/field"
+)
+
+func TestRenderAvro(t *testing.T) {
+	model := &Model{
+		Entities: []*Entity{
+			{Name: "billing.Invoice", Table: "invoices", Schema: "billing", Attributes: []*Attribute{
+				{Name: "id", Type: "uuid", typeInfo: &field.TypeInfo{Type: field.TypeUUID}},
+				{Name: "status", Type: "enum", typeInfo: &field.TypeInfo{Type: field.TypeEnum}, enums: []string{"draft", "paid"}},
+				{Name: "currency", Type: "enum", typeInfo: &field.TypeInfo{Type: field.TypeEnum}, enums: []string{"EUR", "US-D"}},
+				{Name: "paid_at", Type: "timestamp", typeInfo: &field.TypeInfo{Type: field.TypeTime}, optional: true, nillable: true, comment: "when it was paid"},
+			}},
+			{Name: "user_groups", Table: "user_groups", JoinTable: true, Attributes: []*Attribute{{Name: "user_id", Type: "int", Keys: []string{"PK", "FK"}}}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := renderAvro(context.Background(), &buf, model, newOptions(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[
+  {
+    "type": "record",
+    "name": "Invoice",
+    "namespace": "billing",
+    "doc": "Table invoices",
+    "fields": [
+      {
+        "name": "id",
+        "type": {
+          "type": "string",
+          "logicalType": "uuid"
+        }
+      },
+      {
+        "name": "status",
+        "type": {
+          "type": "enum",
+          "name": "Invoice_status",
+          "symbols": [
+            "draft",
+            "paid"
+          ]
+        }
+      },
+      {
+        "name": "currency",
+        "type": "string"
+      },
+      {
+        "name": "paid_at",
+        "type": [
+          "null",
+          {
+            "type": "long",
+            "logicalType": "timestamp-micros"
+          }
+        ],
+        "doc": "when it was paid",
+        "default": null
+      }
+    ]
+  },
+  {
+    "type": "record",
+    "name": "user_groups",
+    "doc": "Table user_groups",
+    "fields": [
+      {
+        "name": "user_id",
+        "type": "long"
+      }
+    ]
+  }
+]
+`
+	if buf.String() != expected {
+		t.Errorf("Got the Avro schemas:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestAvroName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"User":       "User",
+		"Order Item": "Order_Item",
+		"2fa":        "_2fa",
+		"a.b-c":      "a_b_c",
+		"":           "",
+	} {
+		if got := avroName(name); got != expected {
+			t.Errorf("avroName(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Place the diagram in every target listed on stdin, loading each schema once",
+	Long: `Place the diagram in every target listed on stdin, one file:start:end:profile line per target, loading each
+schema only once for all of them, so the tooling of a monorepo can update dozens of documents in one process.
+
+The start and end patterns default to the ones of the file's extension, and the profile of the configuration file
+sets the flags of the target on top of the ones given to this command. The empty fields can be left out, e.g.
+"docs/erd.md" or "docs/billing.md:::billing", and a field holding colons is written as a quoted Go string, e.g.
+docs/erd.md:"<!-- #start:erd -->":"<!-- #end:erd -->". Without a file, the target is the one of the profile. The
+empty lines and the lines starting with # are skipped.
+
+Every target is generated even when some of them fail, the errors are reported together.`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{jsonResultAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		targets, err := readBatch(cmd.InOrStdin())
+		if err != nil {
+			return err
+		}
+
+		var config *Config
+		if slices.ContainsFunc(targets, func(target batchTarget) bool { return target.profile != "" }) || configPath != "" {
+			if config, err = findConfig(); err != nil {
+				return err
+			}
+		}
+
+		return runBatch(cmd, config, targets)
+	},
+}
+
+func init() {
+	batchCmd.Flags().StringVar(&configPath, "config", "", "configuration file of the profiles of the targets, instead of the "+ConfigFileName+" of the working directory or its parents up to the repository root")
+	rootCmd.AddCommand(batchCmd)
+}
+
+// batchTarget is a line of the batch: the target, its patterns and the profile it's generated with, any of them can
+// be empty.
+type batchTarget struct {
+	line         int
+	path         string
+	startPattern string
+	endPattern   string
+	profile      string
+}
+
+// readBatch reads the file:start:end:profile lines of the batch.
+func readBatch(r io.Reader) ([]batchTarget, error) {
+	var targets []batchTarget
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields, err := batchFields(text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid batch line %d: %w", errUsage, line, err)
+		}
+		if len(fields) > 4 {
+			return nil, fmt.Errorf("%w: invalid batch line %d: %d fields instead of file:start:end:profile", errUsage, line, len(fields))
+		}
+		fields = append(fields, make([]string, 4-len(fields))...)
+
+		if fields[0] == "" && fields[3] == "" {
+			return nil, fmt.Errorf("%w: invalid batch line %d: neither a file nor a profile", errUsage, line)
+		}
+
+		targets = append(targets, batchTarget{line: line, path: fields[0], startPattern: fields[1], endPattern: fields[2], profile: fields[3]})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the batch: %w", err)
+	}
+
+	return targets, nil
+}
+
+// batchFields splits the line on the colons outside of the quoted fields.
+func batchFields(line string) ([]string, error) {
+	var fields []string
+	for {
+		var field, rest string
+		var found bool
+
+		if strings.HasPrefix(line, `"`) {
+			quoted, err := strconv.QuotedPrefix(line)
+			if err != nil {
+				return nil, fmt.Errorf("unterminated quoted field %s", line)
+			}
+
+			field, _ = strconv.Unquote(quoted)
+			after := line[len(quoted):]
+			if rest, found = strings.CutPrefix(after, ":"); !found && after != "" {
+				return nil, fmt.Errorf("the quoted field %s is followed by %q instead of a colon", quoted, after)
+			}
+		} else {
+			field, rest, found = strings.Cut(line, ":")
+		}
+
+		fields = append(fields, field)
+		if !found {
+			return fields, nil
+		}
+		line = rest
+	}
+}
+
+// runBatch generates the diagram of every target, with the flags of its profile and a single load of each schema.
+func runBatch(cmd *cobra.Command, config *Config, targets []batchTarget) error {
+	profiles := newProfileFlags(cmd.Flags())
+	specs := newSpecMemo()
+
+	// The file and patterns of a line take precedence over the flags and the profile, for that line only.
+	flagTarget, flagStart, flagEnd := targetPath, startPattern, endPattern
+
+	var errs []error
+	for _, target := range targets {
+		targetPath, startPattern, endPattern = flagTarget, flagStart, flagEnd
+
+		err := profiles.apply(config, target.profile)
+		if err == nil {
+			if target.path != "" {
+				targetPath = target.path
+			}
+			if target.startPattern != "" {
+				startPattern = target.startPattern
+			}
+			if target.endPattern != "" {
+				endPattern = target.endPattern
+			}
+
+			err = runWithOptions(cmd, func(ctx context.Context, opts []Option) error {
+				opts = append(opts, withSpecMemo(specs))
+				return GenerateDiagramContext(ctx, schemaPaths[0], targetPath, targetOutputType(cmd), startPattern, endPattern, opts...)
+			})
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d (%s): %w", target.line, targetPath, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// specMemo keeps the schemas loaded by the runs of a batch, or why they failed to load, so every schema is only
+// loaded once. The warnings of a partial load are only raised by the first run loading the schema.
+type specMemo struct {
+	mu    sync.Mutex
+	specs map[string]*cachedSpec
+	errs  map[string]error
+}
+
+func newSpecMemo() *specMemo {
+	return &specMemo{specs: make(map[string]*cachedSpec), errs: make(map[string]error)}
+}
+
+// withSpecMemo loads the schemas through the memo.
+func withSpecMemo(specs *specMemo) Option {
+	return func(o *options) {
+		o.specs = specs
+	}
+}
+
+// load returns the schema loaded by an earlier run, or loads it.
+func (m *specMemo) load(schemaPath string, o *options) (*cachedSpec, error) {
+	abs, err := filepath.Abs(schemaPath)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("%s\x00%t", abs, o.partial)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if spec, ok := m.specs[key]; ok {
+		o.logger.Info("reusing the schema loaded by the batch", "schema", schemaPath)
+		return spec, nil
+	}
+	if err, ok := m.errs[key]; ok {
+		return nil, err
+	}
+
+	spec, err := loadSchemaSpec(schemaPath, o)
+	if err != nil {
+		m.errs[key] = err
+		return nil, err
+	}
+	m.specs[key] = spec
+
+	return spec, nil
+}
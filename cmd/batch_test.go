@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadBatch(t *testing.T) {
+	input := `
+# The diagrams of the docs.
+docs/erd.md
+docs/billing.md:::billing
+docs/erd.md:"<!-- #start:erd -->":"<!-- #end:erd -->"
+:::overview
+`
+
+	targets, err := readBatch(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []batchTarget{
+		{line: 3, path: "docs/erd.md"},
+		{line: 4, path: "docs/billing.md", profile: "billing"},
+		{line: 5, path: "docs/erd.md", startPattern: "<!-- #start:erd -->", endPattern: "<!-- #end:erd -->"},
+		{line: 6, profile: "overview"},
+	}
+	if !reflect.DeepEqual(targets, expected) {
+		t.Errorf("Got the targets %+v, expected %+v", targets, expected)
+	}
+
+	for _, line := range []string{"a:b:c:d:e", `a:"<!-- #start`, `a:"x"y`, ":start:end:"} {
+		if _, err := readBatch(strings.NewReader(line)); !errors.Is(err, errUsage) {
+			t.Errorf("Expected a usage error for the line %q, got %v", line, err)
+		}
+	}
+}
+
+func TestGenerateDiagramSpecMemo(t *testing.T) {
+	dir := t.TempDir()
+	specs := newSpecMemo()
+
+	var diagrams []string
+	for _, name := range []string{"erd.mmd", "uml.mmd"} {
+		path := filepath.Join(dir, name)
+
+		opts := []Option{WithQuiet(), withSpecMemo(specs), WithOutput(FormatMermaid, path)}
+		if name == "uml.mmd" {
+			opts = append(opts, WithNotation(NotationUML))
+		}
+		if err := GenerateDiagram("../examples/start/schema", "", Plain, "", "", opts...); err != nil {
+			t.Fatalf("Failed to generate diagram: %v", err)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		diagrams = append(diagrams, string(content))
+	}
+
+	// Both diagrams are drawn from the single load of the schema.
+	if len(specs.specs) != 1 {
+		t.Errorf("Expected the schema to be loaded once, got %d schemas", len(specs.specs))
+	}
+	if !strings.HasPrefix(diagrams[0], "erDiagram") || !strings.HasPrefix(diagrams[1], "classDiagram") {
+		t.Errorf("Expected both diagrams, got:\n%s\n%s", diagrams[0], diagrams[1])
+	}
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// WithSizeBudget fits the Mermaid code of every diagram of the target, of every page of a paginated one, in the
+// number of bytes, for the renderers and APIs limiting their payload. The diagrams over the budget are rendered again
+// eliding more of them until they fit: their comments, then the labels of their relationships shortened to their
+// edge name, then their attributes but the keys. The elisions are reported as warnings, and generating fails when
+// the diagram is still over the budget.
+func WithSizeBudget(bytes int) Option {
+	return func(o *options) {
+		o.sizeBudget = bytes
+	}
+}
+
+// budgetElision is a way to shrink a diagram that's over the budget, applied on top of the previous ones.
+type budgetElision struct {
+	name string
+	// model returns the model with less drawn, the diagram returns the Mermaid code with less in it.
+	model   func(model *Model) *Model
+	diagram func(diagram string) string
+}
+
+var budgetElisions = []budgetElision{
+	{name: "comments", diagram: stripMermaidComments},
+	{name: "labels", model: shortenLabels},
+	{name: "attributes", model: collapseAttributes},
+}
+
+// renderBudgeted renders the Mermaid code of the model, eliding what's needed to fit it in the size budget.
+func renderBudgeted(ctx context.Context, model *Model, o *options) (string, error) {
+	render := func(model *Model) (string, error) {
+		var builder strings.Builder
+		err := renderMermaid(ctx, &builder, model, o)
+
+		return builder.String(), err
+	}
+
+	diagram, err := render(model)
+	if err != nil || o.sizeBudget <= 0 || len(diagram) <= o.sizeBudget {
+		return diagram, err
+	}
+
+	size := len(diagram)
+
+	var (
+		elided []string
+		strip  []func(string) string
+	)
+	for _, elision := range budgetElisions {
+		elided = append(elided, elision.name)
+		if elision.model != nil {
+			model = elision.model(model)
+		}
+		if elision.diagram != nil {
+			strip = append(strip, elision.diagram)
+		}
+
+		if diagram, err = render(model); err != nil {
+			return "", err
+		}
+		for _, strip := range strip {
+			diagram = strip(diagram)
+		}
+
+		o.logger.Debug("elided from the diagram over the size budget", "elision", elision.name, "size", len(diagram), "budget", o.sizeBudget)
+
+		if len(diagram) <= o.sizeBudget {
+			o.warn("", "", "elided the %s of the diagram%s of %d bytes to fit it in the size budget of %d bytes", joinWords(elided), pageName(model), size, o.sizeBudget)
+			return diagram, nil
+		}
+	}
+
+	return "", fmt.Errorf("the diagram%s is %d bytes even without its %s, over the size budget of %d bytes: split it with --page-size or narrow it down with --entity",
+		pageName(model), len(diagram), joinWords(elided), o.sizeBudget)
+}
+
+// pageName returns the name of the page of a paginated diagram, to follow "the diagram" in the errors.
+func pageName(model *Model) string {
+	if model.pages > 1 {
+		return fmt.Sprintf(" %d of %d", model.page, model.pages)
+	}
+
+	return ""
+}
+
+// joinWords joins the words as an English list, e.g. "a, b and c".
+func joinWords(words []string) string {
+	if len(words) < 2 {
+		return strings.Join(words, "")
+	}
+
+	return strings.Join(words[:len(words)-1], ", ") + " and " + words[len(words)-1]
+}
+
+// mermaidAttributeCommentRegex matches the comment ending the line of an attribute of an erDiagram entity, but the
+// row counting the attributes left out by WithMaxFields, which Mermaid only draws with its comment.
+var mermaidAttributeCommentRegex = regexp.MustCompile(`(?m)^(  [^_ ][^ ]* [^ ]+(?: [A-Z,]+)?) "[^"]*"$`)
+
+// stripMermaidComments removes the comment lines and the comments of the attributes from the Mermaid code, but the
+// directives, along with the blank lines left around the comment lines of the sections.
+func stripMermaidComments(diagram string) string {
+	lines := strings.SplitAfter(diagram, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "%%") && !strings.HasPrefix(trimmed, "%%{"):
+		case trimmed == "" && (len(kept) == 0 || strings.TrimSpace(kept[len(kept)-1]) == ""):
+		default:
+			kept = append(kept, line)
+		}
+	}
+
+	return mermaidAttributeCommentRegex.ReplaceAllString(strings.Join(kept, ""), "$1")
+}
+
+// shortenLabels returns the model with the labels of the relationships replaced by the name of their edge.
+func shortenLabels(model *Model) *Model {
+	shortened := *model
+	shortened.Relationships = make([]*Relationship, len(model.Relationships))
+	for i, rel := range model.Relationships {
+		short := *rel
+		if short.Edge != "" {
+			short.Label = short.Edge
+		}
+		shortened.Relationships[i] = &short
+	}
+
+	return &shortened
+}
+
+// collapseAttributes returns the model with the attributes of the entities collapsed to their first one and the keys,
+// which are always listed so the relationships can still be traced.
+func collapseAttributes(model *Model) *Model {
+	collapsed := *model
+	collapsed.Entities = make([]*Entity, len(model.Entities))
+	for i, entity := range model.Entities {
+		short := *entity
+		short.maxFields = 1
+		collapsed.Entities[i] = &short
+	}
+
+	return &collapsed
+}
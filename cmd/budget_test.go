@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRenderBudgeted(t *testing.T) {
+	model, err := loadModel(context.Background(), "../examples/start/schema", newOptions([]Option{WithQuiet()}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diagram, err := renderBudgeted(context.Background(), model, newOptions([]Option{WithQuiet(), WithMaxFields(3)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		budget  int
+		elided  string
+		missing []string
+	}{
+		{len(diagram), "", nil},
+		{len(diagram) - 1, "comments", []string{"%% entities", `"default: unknown"`}},
+		{len(diagram) - 120, "comments, labels and attributes", []string{"cars-owner", "registered_at"}},
+	} {
+		o := newOptions([]Option{WithQuiet(), WithMaxFields(3), WithSizeBudget(tc.budget)})
+		budgeted, err := renderBudgeted(context.Background(), model, o)
+		if err != nil {
+			t.Fatalf("Failed to fit the diagram in %d bytes: %v", tc.budget, err)
+		}
+
+		if len(budgeted) > tc.budget {
+			t.Errorf("The diagram of %d bytes is over the budget of %d bytes:\n%s", len(budgeted), tc.budget, budgeted)
+		}
+		for _, missing := range tc.missing {
+			if strings.Contains(budgeted, missing) {
+				t.Errorf("Expected %q to be elided from the diagram:\n%s", missing, budgeted)
+			}
+		}
+		// The keys are kept, so the relationships can still be traced.
+		if !strings.Contains(budgeted, "int user_cars FK") {
+			t.Errorf("Expected the foreign key to be kept:\n%s", budgeted)
+		}
+
+		if tc.elided == "" && len(o.warnings) > 0 || tc.elided != "" && (len(o.warnings) != 1 || !strings.Contains(o.warnings[0].Message, "elided the "+tc.elided+" of")) {
+			t.Errorf("Expected the %q elided to be reported, got %v", tc.elided, o.warnings)
+		}
+		if model.Entity("Car").maxFields != 0 {
+			t.Error("The entities of the model were collapsed")
+		}
+	}
+
+	if _, err := renderBudgeted(context.Background(), model, newOptions([]Option{WithQuiet(), WithSizeBudget(100)})); err == nil {
+		t.Error("Expected the diagram not to fit in 100 bytes")
+	}
+}
+
+func TestStripMermaidComments(t *testing.T) {
+	diagram := `%%{init: {"maxTextSize": 100000}}%%
+erDiagram
+ %% entities
+
+ User {
+  int id PK "the id"
+  string name "default: unknown"
+  __ __ "... 2 more"
+ }
+ %% User index user_name (name)
+
+ %% relationships
+
+ User ||--o{ Car : cars
+`
+
+	expected := `%%{init: {"maxTextSize": 100000}}%%
+erDiagram
+
+ User {
+  int id PK
+  string name
+  __ __ "... 2 more"
+ }
+
+ User ||--o{ Car : cars
+`
+	if stripped := stripMermaidComments(diagram); stripped != expected {
+		t.Errorf("Got:\n%s\nexpected:\n%s", stripped, expected)
+	}
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"entgo.io/ent/entc/load"
+	"golang.org/x/tools/go/packages"
+)
+
+// WithPartial draws the schemas that still build when some files of the schema package don't compile, leaving out
+// the broken files (and the ones depending on them) with a warning instead of failing.
+func WithPartial() Option {
+	return func(o *options) {
+		o.partial = true
+	}
+}
+
+// Diagnostic is a Go build error in a file of the schema package. Line and Column are 1-based and 0 when unknown.
+type Diagnostic struct {
+	Path    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// located reports whether the diagnostic points at a line of a file, like the parse and type errors do.
+func (d Diagnostic) located() bool {
+	return d.Path != "" && d.Line > 0
+}
+
+func (d Diagnostic) String() string {
+	switch {
+	case d.Path == "":
+		return d.Message
+	case d.Line == 0:
+		return fmt.Sprintf("%s: %s", d.Path, d.Message)
+	case d.Column == 0:
+		return fmt.Sprintf("%s:%d: %s", d.Path, d.Line, d.Message)
+	default:
+		return fmt.Sprintf("%s:%d:%d: %s", d.Path, d.Line, d.Column, d.Message)
+	}
+}
+
+// BuildError is returned (wrapped in ErrSchemaLoad) when the schema package doesn't compile, with every diagnostic
+// of the Go build instead of only the first one.
+type BuildError struct {
+	Diagnostics []Diagnostic
+}
+
+func (e *BuildError) Error() string {
+	return "the schema package doesn't build:" + diagnosticLines(e.Diagnostics)
+}
+
+// diagnosticLines lists the diagnostics on indented lines.
+func diagnosticLines(diagnostics []Diagnostic) string {
+	lines := make([]string, len(diagnostics))
+	for i, diagnostic := range diagnostics {
+		lines[i] = "\n\t" + diagnostic.String()
+	}
+
+	return strings.Join(lines, "")
+}
+
+// schemaDiagnostics type checks the schema package, returning the errors of the Go build.
+func schemaDiagnostics(schemaPath string) ([]Diagnostic, error) {
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo}, schemaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var diagnostics []Diagnostic
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		// Compiling for the export data repeats the parse and type errors in a single go list error.
+		typed := slices.ContainsFunc(pkg.Errors, func(perr packages.Error) bool {
+			return perr.Kind != packages.ListError
+		})
+
+		for _, perr := range pkg.Errors {
+			if !typed || perr.Kind != packages.ListError {
+				diagnostics = append(diagnostics, newDiagnostic(perr))
+			}
+		}
+	})
+
+	return diagnostics, nil
+}
+
+// newDiagnostic splits the file:line:column position of the error, relative to the working directory when it's
+// below it.
+func newDiagnostic(perr packages.Error) Diagnostic {
+	diagnostic := Diagnostic{Message: perr.Msg}
+
+	position := perr.Pos
+	var numbers []int
+	for len(numbers) < 2 {
+		i := strings.LastIndex(position, ":")
+		if i == -1 {
+			break
+		}
+
+		n, err := strconv.Atoi(position[i+1:])
+		if err != nil {
+			break
+		}
+
+		numbers = append([]int{n}, numbers...)
+		position = position[:i]
+	}
+
+	diagnostic.Path = position
+	if wd, err := os.Getwd(); err == nil && filepath.IsAbs(position) {
+		if rel, err := filepath.Rel(wd, position); err == nil && !strings.HasPrefix(rel, "..") {
+			diagnostic.Path = rel
+		}
+	}
+
+	if len(numbers) > 0 {
+		diagnostic.Line = numbers[0]
+	}
+	if len(numbers) > 1 {
+		diagnostic.Column = numbers[1]
+	}
+
+	return diagnostic
+}
+
+// loadPartialSpec loads a copy of the schema package without the files that don't build. Leaving a file out can
+// break the files referring to it, so the remaining ones are checked again until they all build. The copy is made
+// next to the schema package so its imports resolve from the same module.
+func loadPartialSpec(schemaPath string, diagnostics []Diagnostic, o *options) (*load.SchemaSpec, error) {
+	abs, err := filepath.Abs(schemaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp(filepath.Dir(abs), "entmaid-partial-")
+	if err != nil {
+		return nil, err
+	}
+	defer trackLoadDir(dir)()
+	defer os.RemoveAll(dir)
+
+	entries, err := os.ReadDir(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(abs, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, entry.Name()), content, 0o644); err != nil {
+			return nil, err
+		}
+	}
+
+	// The first diagnostics are about the schema files, the next ones about their copies.
+	from := abs
+	for len(diagnostics) > 0 {
+		skipped := make(map[string][]Diagnostic)
+		for _, diagnostic := range diagnostics {
+			if path, err := filepath.Abs(diagnostic.Path); err == nil && filepath.Dir(path) == from && strings.HasSuffix(path, ".go") {
+				name := filepath.Base(path)
+				skipped[name] = append(skipped[name], diagnostic)
+			}
+		}
+
+		if len(skipped) == 0 {
+			return nil, &BuildError{Diagnostics: diagnostics}
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(skipped)) {
+			if err := os.Remove(filepath.Join(dir, name)); err != nil {
+				return nil, &BuildError{Diagnostics: diagnostics}
+			}
+
+			o.warn(filepath.Join(schemaPath, name), "", "skipped as it doesn't build: %s", skipped[name][0].Message)
+		}
+
+		from = dir
+		if diagnostics, err = schemaDiagnostics(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	loaded, err := (&load.Config{Path: dir}).Load()
+	if err != nil {
+		return nil, err
+	}
+
+	// The positions should link to the schema files, not their copies.
+	for _, schema := range loaded.Schemas {
+		schema.Pos = strings.Replace(schema.Pos, dir, abs, 1)
+	}
+
+	return loaded, nil
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// brokenSchema copies the start example next to it with an extra file that doesn't compile, the copy has to stay
+// in the module for its imports to resolve.
+func brokenSchema(t *testing.T) string {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("../examples/start", "broken-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	files, err := filepath.Glob("../examples/start/schema/*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(file)), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	broken := "package schema\n\nvar _ = undefinedHelper()\n\nvar _ int = \"text\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "broken.go"), []byte(broken), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func TestLoadModelBuildError(t *testing.T) {
+	dir := brokenSchema(t)
+
+	_, err := loadModel(context.Background(), dir, newOptions([]Option{WithQuiet()}))
+
+	var buildErr *BuildError
+	if !errors.Is(err, ErrSchemaLoad) || !errors.As(err, &buildErr) {
+		t.Fatalf("Expected a build error, got %v", err)
+	}
+
+	if len(buildErr.Diagnostics) != 2 {
+		t.Fatalf("Expected every diagnostic of the build, got %v", buildErr.Diagnostics)
+	}
+
+	first := buildErr.Diagnostics[0]
+	if filepath.Base(first.Path) != "broken.go" || first.Line != 3 || first.Column == 0 || !strings.Contains(first.Message, "undefinedHelper") {
+		t.Errorf("Expected the diagnostic to be located in broken.go, got %+v", first)
+	}
+
+	var builder strings.Builder
+	if err := writeGitHubErrors(&builder, err); err != nil {
+		t.Fatal(err)
+	}
+
+	if lines := strings.Split(strings.TrimSpace(builder.String()), "\n"); len(lines) != 2 || !strings.Contains(lines[1], ",line=5,col=") {
+		t.Errorf("Expected a GitHub error per diagnostic, got %q", builder.String())
+	}
+}
+
+func TestLoadModelListError(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := loadModel(context.Background(), dir, newOptions([]Option{WithQuiet()}))
+
+	var buildErr *BuildError
+	if !errors.Is(err, ErrSchemaLoad) || errors.As(err, &buildErr) {
+		t.Fatalf("Expected the go list error not to be reported as a build error, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "entc/load") || !strings.Contains(err.Error(), "no Go files") {
+		t.Errorf("Expected the error of entc along with the diagnostics, got %v", err)
+	}
+}
+
+func TestLoadModelPartial(t *testing.T) {
+	dir := brokenSchema(t)
+
+	var warnings []Warning
+	o := newOptions([]Option{WithQuiet(), WithPartial(), WithWarningHandler(func(w Warning) {
+		warnings = append(warnings, w)
+	})})
+
+	model, err := loadModel(context.Background(), dir, o)
+	if err != nil {
+		t.Fatalf("Expected the schemas that build to be loaded, got %v", err)
+	}
+
+	if names := entityNames(model.Entities); len(names) < 3 {
+		t.Errorf("Expected the entities of the schema, got %v", names)
+	}
+
+	if len(warnings) != 1 || warnings[0].Entity != filepath.Join(dir, "broken.go") {
+		t.Errorf("Expected a warning about the broken file, got %v", warnings)
+	}
+
+	for _, entity := range model.Entities {
+		if entity.Source != nil && filepath.Dir(entity.Source.File) != filepath.ToSlash(dir) {
+			t.Errorf("Expected the source of %s to be in the schema, got %s", entity.Name, entity.Source.File)
+		}
+	}
+
+	if leftovers, _ := filepath.Glob("../examples/start/entmaid-partial-*"); len(leftovers) != 0 {
+		t.Errorf("Expected the partial copy to be removed, got %v", leftovers)
+	}
+}
+
+func TestNewDiagnostic(t *testing.T) {
+	tests := map[string]Diagnostic{
+		"user.go:12:3": {Path: "user.go", Line: 12, Column: 3, Message: "oops"},
+		"user.go:12":   {Path: "user.go", Line: 12, Message: "oops"},
+		"":             {Message: "oops"},
+	}
+
+	for pos, expected := range tests {
+		if got := newDiagnostic(packages.Error{Pos: pos, Msg: "oops"}); got != expected {
+			t.Errorf("Expected %+v for %q, got %+v", expected, pos, got)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"slices"
+
+	"entgo.io/ent/entc/gen"
+)
+
+// uniqueColumn reports whether the column of the node's table can't hold the same value twice, as the column of a
+// unique field or the only column of a unique index.
+func uniqueColumn(node *gen.Type, column string) bool {
+	if node == nil || column == "" {
+		return false
+	}
+
+	for _, field := range node.Fields {
+		if field.Unique && field.StorageKey() == column {
+			return true
+		}
+	}
+
+	return slices.ContainsFunc(node.Indexes, func(index *gen.Index) bool {
+		return index.Unique && len(index.Columns) == 1 && index.Columns[0] == column
+	})
+}
+
+// atMostOne narrows the "many" cardinality to at most one entity, keeping whether there must be one.
+func atMostOne(cardinality Cardinality) Cardinality {
+	switch cardinality {
+	case ZeroOrMore:
+		return ZeroOrOne
+	case OneOrMore:
+		return ExactlyOne
+	default:
+		return cardinality
+	}
+}
+
+// uniqueForeignKey draws the relationship of an O2M edge whose foreign key column is unique as the O2O it behaves
+// as: every referenced entity is then referenced by at most one row of the table holding the key.
+func uniqueForeignKey(rel *Relationship, node *gen.Type, edge *gen.Edge, o *options) {
+	if rel.ForeignKey == nil {
+		return
+	}
+
+	holder, many := edge.Type, &rel.ToCardinality
+	if edge.OwnFK() {
+		holder, many = node, &rel.FromCardinality
+	}
+
+	if uniqueColumn(holder, rel.ForeignKey.Column) && *many != atMostOne(*many) {
+		o.logger.Debug("drawing edge with a unique foreign key as O2O", "node", node.Name, "edge", edge.Name, "column", rel.ForeignKey.Column)
+		*many = atMostOne(*many)
+	}
+}
+
+// uniqueEdgeSchema draws the direct relationship of a M2M edge through an edge schema as the O2M it behaves as when
+// a column of the edge schema is unique: every entity on that side is then related to at most one on the other.
+func uniqueEdgeSchema(rel *Relationship, node *gen.Type, edge *gen.Edge, o *options) {
+	// The columns of the edge schema are ordered (owner, reference) as for the join tables.
+	if len(edge.Rel.Columns) != 2 {
+		return
+	}
+
+	for i, cardinality := range []*Cardinality{&rel.ToCardinality, &rel.FromCardinality} {
+		if uniqueColumn(edge.Through, edge.Rel.Columns[i]) {
+			o.logger.Debug("drawing M2M edge with a unique column as O2M", "node", node.Name, "edge", edge.Name, "column", edge.Rel.Columns[i])
+			*cardinality = atMostOne(*cardinality)
+		}
+	}
+}
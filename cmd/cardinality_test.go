@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"testing"
+
+	"entgo.io/ent/entc/gen"
+
+	"github.com/lespea/entmaid/annotation"
+)
+
+func TestUniqueForeignKey(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		car      *gen.Type
+		expected Cardinality
+	}{
+		{"not unique", &gen.Type{Name: "Car", Fields: []*gen.Field{{Name: "owner_id"}}}, OneOrMore},
+		{"unique field", &gen.Type{Name: "Car", Fields: []*gen.Field{{Name: "owner_id", Unique: true}}}, ExactlyOne},
+		{"unique index", &gen.Type{Name: "Car", Indexes: []*gen.Index{{Unique: true, Columns: []string{"owner_id"}}}}, ExactlyOne},
+		{"composite unique index", &gen.Type{Name: "Car", Indexes: []*gen.Index{{Unique: true, Columns: []string{"owner_id", "plate"}}}}, OneOrMore},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &gen.Type{Name: "User"}
+			cars := &gen.Edge{Name: "cars", Type: tt.car, Rel: gen.Relation{Type: gen.O2M, Columns: []string{"owner_id"}}}
+			rel := &Relationship{FromCardinality: ExactlyOne, ToCardinality: OneOrMore, ForeignKey: &ForeignKey{Column: "owner_id"}}
+
+			uniqueForeignKey(rel, user, cars, newOptions([]Option{WithQuiet()}))
+
+			if rel.FromCardinality != ExactlyOne || rel.ToCardinality != tt.expected {
+				t.Errorf("Expected an exactly-one to %s relationship, got %+v", tt.expected, rel)
+			}
+		})
+	}
+}
+
+func TestUniqueEdgeSchema(t *testing.T) {
+	membership := &gen.Type{Name: "Membership", Indexes: []*gen.Index{{Unique: true, Columns: []string{"user_id"}}}}
+	groups := &gen.Edge{Name: "groups", Type: &gen.Type{Name: "Group"}, Through: membership, Rel: gen.Relation{Type: gen.M2M, Columns: []string{"user_id", "group_id"}}}
+	rel := &Relationship{FromCardinality: ZeroOrMore, ToCardinality: ZeroOrMore}
+
+	uniqueEdgeSchema(rel, &gen.Type{Name: "User"}, groups, newOptions([]Option{WithQuiet()}))
+
+	// Every user is a member of at most one group.
+	if rel.FromCardinality != ZeroOrMore || rel.ToCardinality != ZeroOrOne {
+		t.Errorf("Expected a zero-or-more to zero-or-one relationship, got %+v", rel)
+	}
+}
+
+func TestOverrideM2MRelationship(t *testing.T) {
+	user, group := &gen.Type{Name: "User"}, &gen.Type{Name: "Group"}
+	groups := &gen.Edge{Name: "groups", Type: group, Rel: gen.Relation{Type: gen.M2M}, Annotations: gen.Annotations{annotation.Name: annotation.Cardinalities(annotation.OneOrMore, annotation.ZeroOrOne)}}
+	users := &gen.Edge{Name: "users", Type: user, Ref: groups, Inverse: "groups", Rel: gen.Relation{Type: gen.M2M}}
+	groups.Ref = users
+
+	o := newOptions([]Option{WithQuiet()})
+
+	// The relationships are to the join table, the rows of each side follow the cardinality of the other entity.
+	rel := &Relationship{FromCardinality: ZeroOrOne, ToCardinality: ZeroOrMore}
+	overrideRelationship(rel, user, groups, o)
+	if rel.FromCardinality != ZeroOrOne || rel.ToCardinality != ZeroOrOne {
+		t.Errorf("Expected the user to have zero-or-one rows of the join table, got %+v", rel)
+	}
+
+	rel = &Relationship{FromCardinality: ZeroOrOne, ToCardinality: ZeroOrMore}
+	overrideRelationship(rel, group, users, o)
+	if rel.FromCardinality != ZeroOrOne || rel.ToCardinality != OneOrMore {
+		t.Errorf("Expected the group to have one-or-more rows of the join table, got %+v", rel)
+	}
+
+	if len(o.warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", o.warnings)
+	}
+
+	groups.Ref = nil
+	overrideRelationship(&Relationship{}, user, groups, o)
+	if len(o.warnings) != 1 {
+		t.Errorf("Expected a warning about the missing back-reference, got %v", o.warnings)
+	}
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// WithChangedSince only draws the entities declared in the schema files changed since the git ref (e.g.
+// origin/main), along with the entities directly related to them, for a focused diagram of a pull request. The
+// changes are those of the working tree since the merge base of the ref, including the untracked files.
+func WithChangedSince(ref string) Option {
+	return func(o *options) {
+		o.changedSince = ref
+	}
+}
+
+// WithFollowEdges only pulls the entities related to the changed ones of WithChangedSince into the diagram through
+// the relationships of the kinds, e.g. EdgeO2M to leave out the entities on the other side of the M2M edges.
+func WithFollowEdges(kinds ...EdgeKind) Option {
+	return func(o *options) {
+		o.followEdges = append(o.followEdges, kinds...)
+	}
+}
+
+// WithoutFollowingEdges doesn't pull the entities related to the changed ones of WithChangedSince into the diagram
+// through the edges, given like for WithEdgeLabel. It keeps the hub entities related to everything, like the audit
+// logs, from crowding the diagram. The relationships are still drawn between the entities drawn anyway.
+func WithoutFollowingEdges(edges ...string) Option {
+	return func(o *options) {
+		o.noFollowEdges = append(o.noFollowEdges, edges...)
+	}
+}
+
+// followed tells whether the neighbors of the changed entities are pulled in through the relationship.
+func followed(rel *Relationship, joinTable bool, o *options) bool {
+	if len(o.followEdges) > 0 && !slices.Contains(o.followEdges, rel.kind) {
+		return false
+	}
+
+	for _, name := range edgeNames(rel, joinTable) {
+		if slices.Contains(o.noFollowEdges, name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// changedFiles returns the absolute paths of the files changed since the merge base of the ref in the git
+// repository of the working directory.
+func changedFiles(ctx context.Context, ref string) (map[string]bool, error) {
+	git := func(args ...string) ([]string, error) {
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Stderr = &stderr
+
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the files changed since %s: %w: %s", ref, err, bytes.TrimSpace(stderr.Bytes()))
+		}
+
+		return strings.Fields(string(out)), nil
+	}
+
+	root, err := git("rev-parse", "--show-toplevel")
+	if err != nil || len(root) == 0 {
+		return nil, err
+	}
+
+	changed, err := git("diff", "--name-only", "--merge-base", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	untracked, err := git("ls-files", "--others", "--exclude-standard", "--full-name")
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]bool, len(changed)+len(untracked))
+	for _, file := range append(changed, untracked...) {
+		files[filepath.Join(root[0], filepath.FromSlash(file))] = true
+	}
+
+	return files, nil
+}
+
+// changedModel returns the model of the entities declared in the changed files and their neighbors through the
+// followed relationships. The join tables of the M2M edges of the changed entities are kept, and the entities on
+// their other side are neighbors.
+func changedModel(model *Model, files map[string]bool, o *options) *Model {
+	selected := make(map[string]bool)
+	for _, entity := range model.Entities {
+		if entity.Source == nil {
+			continue
+		}
+
+		if file, err := filepath.Abs(filepath.FromSlash(entity.Source.File)); err == nil && files[file] {
+			selected[entity.Name] = true
+		}
+	}
+
+	for _, entity := range model.Entities {
+		if selected[entity.Name] && !entity.JoinTable {
+			o.changedEntities = append(o.changedEntities, entity.Name)
+		}
+	}
+
+	if len(selected) == 0 {
+		o.warn("", "", "no entity is declared in the files changed since %s", o.changedSince)
+	}
+
+	joinTables := make(map[string]bool)
+	for _, entity := range model.Entities {
+		if entity.JoinTable {
+			joinTables[entity.Name] = true
+		}
+	}
+
+	// The join tables related to a changed entity stand for the M2M edge to their other side.
+	changed := make(map[string]bool, len(selected))
+	for _, rel := range model.Relationships {
+		if joinTables[rel.To] && selected[rel.From] && followed(rel, true, o) {
+			changed[rel.To] = true
+		}
+	}
+	for name := range selected {
+		changed[name] = true
+	}
+
+	var names []string
+	neighbors := make(map[string]bool)
+	for _, rel := range model.Relationships {
+		if !followed(rel, joinTables[rel.To], o) {
+			o.logger.Debug("not following the relationship to the neighbors", "from", rel.From, "edge", rel.Edge, "to", rel.To)
+			continue
+		}
+
+		switch {
+		case changed[rel.From]:
+			neighbors[rel.To] = true
+		case changed[rel.To]:
+			neighbors[rel.From] = true
+		}
+	}
+
+	for _, entity := range model.Entities {
+		if !entity.JoinTable && (selected[entity.Name] || neighbors[entity.Name]) {
+			names = append(names, entity.Name)
+		}
+	}
+
+	filtered := filterEntities(model, names)
+	for _, entity := range model.Entities {
+		if filtered.Entity(entity.Name) == nil {
+			o.skipEntity(entity.Name, "unchanged since "+o.changedSince)
+		}
+	}
+
+	return filtered
+}
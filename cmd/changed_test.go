@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestChangedModel(t *testing.T) {
+	for _, tc := range []struct {
+		file     string
+		entities []string
+	}{
+		{"car.go", []string{"Car", "User"}},
+		// The M2M edge makes the entity of the other side of the join table a neighbor.
+		{"group.go", []string{"Group", "User", "group_users"}},
+		{"user.go", []string{"Car", "Group", "User", "group_users"}},
+		{"other.go", nil},
+	} {
+		t.Run(tc.file, func(t *testing.T) {
+			model, err := loadModel(context.Background(), "../examples/start/schema", newOptions([]Option{WithQuiet()}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			file, err := filepath.Abs(filepath.Join("../examples/start/schema", tc.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			o := newOptions([]Option{WithQuiet(), WithChangedSince("origin/main")})
+			changed := changedModel(model, map[string]bool{file: true}, o)
+			if names := entityNames(changed.Entities); !slices.Equal(names, tc.entities) {
+				t.Errorf("Expected the entities %v, got %v", tc.entities, names)
+			}
+			if len(o.skipped) != len(model.Entities)-len(tc.entities) {
+				t.Errorf("Expected the other entities to be skipped, got %v", o.skipped)
+			}
+			if warned := len(o.warnings) > 0; warned != (tc.entities == nil) {
+				t.Errorf("Expected a warning only without changed entities, got %v", o.warnings)
+			}
+		})
+	}
+}
+
+func TestChangedModelFollowedEdges(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		option   Option
+		entities []string
+	}{
+		{"kinds", WithFollowEdges(EdgeO2M), []string{"Car", "User"}},
+		{"edge", WithoutFollowingEdges("User.cars"), []string{"Group", "User", "group_users"}},
+		// The back-reference of the edge names it too.
+		{"ref", WithoutFollowingEdges("owner"), []string{"Group", "User", "group_users"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			model, err := loadModel(context.Background(), "../examples/start/schema", newOptions([]Option{WithQuiet()}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			file, err := filepath.Abs("../examples/start/schema/user.go")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			changed := changedModel(model, map[string]bool{file: true}, newOptions([]Option{WithQuiet(), WithChangedSince("origin/main"), tc.option}))
+			if names := entityNames(changed.Entities); !slices.Equal(names, tc.entities) {
+				t.Errorf("Expected the entities %v, got %v", tc.entities, names)
+			}
+		})
+	}
+}
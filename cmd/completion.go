@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// registerCompletions completes the values of the flags in the completion scripts cobra generates with
+// 'entmaid completion bash|zsh|fish|powershell', once the flags are registered.
+func registerCompletions() {
+	_ = rootCmd.MarkPersistentFlagDirname("schema")
+	_ = rootCmd.RegisterFlagCompletionFunc("entity", completeEntities)
+	_ = rootCmd.RegisterFlagCompletionFunc("output", completeOutputs)
+	_ = rootCmd.RegisterFlagCompletionFunc("feature", cobra.FixedCompletions(featureNames(), cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.RegisterFlagCompletionFunc("outputType", completeIds(OutputTypeIds))
+	_ = rootCmd.RegisterFlagCompletionFunc("mermaid-version", completeIds(MermaidVersionIds))
+	_ = rootCmd.RegisterFlagCompletionFunc("notation", completeIds(NotationIds))
+	_ = rootCmd.RegisterFlagCompletionFunc("dialect", completeIds(DialectIds))
+	_ = rootCmd.RegisterFlagCompletionFunc("sort", completeIds(EntityOrderIds))
+	_ = rootCmd.RegisterFlagCompletionFunc("field-order", completeIds(FieldOrderIds))
+	_ = rootCmd.RegisterFlagCompletionFunc("weight-by", completeIds(RelationshipWeightIds))
+	_ = rootCmd.RegisterFlagCompletionFunc("type-style", completeIds(TypeStyleIds))
+	_ = rootCmd.RegisterFlagCompletionFunc("domains", completeIds(DomainSourceIds))
+	_ = rootCmd.RegisterFlagCompletionFunc("summary", completeIds(SummaryPositionIds))
+	_ = rootCmd.RegisterFlagCompletionFunc("visibility", completeIds(VisibilityIds))
+	_ = rootCmd.RegisterFlagCompletionFunc("format-errors", completeIds(ErrorFormatIds))
+	_ = rootCmd.RegisterFlagCompletionFunc("link-template", cobra.FixedCompletions([]string{"github"}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// completeIds completes the names of the values of an enum flag.
+func completeIds[E comparable](ids map[E][]string) cobra.CompletionFunc {
+	var names []string
+	for _, values := range ids {
+		names = append(names, values...)
+	}
+
+	sort.Strings(names)
+
+	return cobra.FixedCompletions(names, cobra.ShellCompDirectiveNoFileComp)
+}
+
+// completeEntities completes the names of the entities of the schemas given on the command line, join tables
+// excepted as they're drawn along with the entities on their sides.
+func completeEntities(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(schemaPaths) == 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	// Cobra parses the flags twice when completing, repeating every --schema.
+	opts := []Option{WithQuiet()}
+	seen := map[string]bool{schemaPaths[0]: true}
+	for _, schemaPath := range schemaPaths[1:] {
+		if !seen[schemaPath] {
+			seen[schemaPath] = true
+			opts = append(opts, WithSchema(schemaPath))
+		}
+	}
+	if cache || cacheDir != "" {
+		opts = append(opts, WithCache(cacheDir))
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	model, err := loadModel(ctx, schemaPaths[0], newOptions(opts))
+	if err != nil {
+		cobra.CompErrorln(err.Error())
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return entityCompletions(model, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func entityCompletions(model *Model, toComplete string) []string {
+	var names []string
+	for _, entity := range model.Entities {
+		if !entity.JoinTable && strings.HasPrefix(entity.Name, toComplete) {
+			names = append(names, entity.Name)
+		}
+	}
+
+	return names
+}
+
+// completeOutputs completes the format of an --output, then the path after it.
+func completeOutputs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if strings.Contains(toComplete, "=") {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	var formats []string
+	for _, format := range append(Formats(), pluginFormats()...) {
+		if strings.HasPrefix(format, toComplete) {
+			formats = append(formats, format+"=")
+		}
+	}
+
+	return formats, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+}
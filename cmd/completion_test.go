@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestEntityCompletions(t *testing.T) {
+	model, err := loadModel(context.Background(), "../examples/start/schema", newOptions(nil))
+	if err != nil {
+		t.Fatalf("Failed to load the model: %v", err)
+	}
+
+	if got := entityCompletions(model, ""); !reflect.DeepEqual(got, []string{"Car", "Group", "User"}) {
+		t.Errorf("Expected the entities without the join tables, got %v", got)
+	}
+
+	if got := entityCompletions(model, "Us"); !reflect.DeepEqual(got, []string{"User"}) {
+		t.Errorf("Expected the entities starting with Us, got %v", got)
+	}
+}
+
+func TestCompleteOutputs(t *testing.T) {
+	formats, directive := completeOutputs(nil, nil, "db")
+	if !reflect.DeepEqual(formats, []string{"dbml="}) || directive&cobra.ShellCompDirectiveNoSpace == 0 {
+		t.Errorf("Expected the dbml format without a space, got %v (%d)", formats, directive)
+	}
+
+	if formats, directive := completeOutputs(nil, nil, "dbml=./"); formats != nil || directive != cobra.ShellCompDirectiveDefault {
+		t.Errorf("Expected the paths to be completed after the format, got %v (%d)", formats, directive)
+	}
+}
+
+func TestCompleteIds(t *testing.T) {
+	ids, directive := completeIds(SummaryPositionIds)(nil, nil, "")
+	if !reflect.DeepEqual(ids, []string{"above", "below", "none"}) || directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("Expected the sorted summary positions, got %v (%d)", ids, directive)
+	}
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var exportComplianceCmd = &cobra.Command{
+	Use:   "compliance <file>",
+	Short: "Write a Markdown report of the classified columns for data governance reviews",
+	Long: `Write a Markdown report listing every column classified with the entmaid annotation (pii, secret, public, ...),
+grouped by classification, along with the table holding it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWithOptions(cmd, func(ctx context.Context, opts []Option) error {
+			return exportCompliance(ctx, schemaPaths[0], args[0], newOptions(opts))
+		})
+	},
+}
+
+func init() {
+	exportCmd.AddCommand(exportComplianceCmd)
+}
+
+// exportCompliance writes the compliance report of the model into the file, or compares it to the existing file in
+// check mode.
+func exportCompliance(ctx context.Context, schemaPath string, path string, o *options) error {
+	model, err := loadModel(ctx, schemaPath, o)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFile(path, []byte(complianceReport(model)), "compliance report", o); err != nil {
+		return err
+	}
+
+	if !o.quiet {
+		if o.check {
+			fmt.Println("Compliance report is up to date.")
+		} else {
+			fmt.Println("Compliance report exported successfully.")
+		}
+	}
+
+	return nil
+}
+
+// complianceReport renders a table of the classified columns per classification, in the order the classifications
+// are first used. The tables link the entities to their documentation when any of them is linked.
+func complianceReport(model *Model) string {
+	var classifications []string
+	rows := make(map[string][][]string)
+	documented := false
+
+	for _, entity := range model.Entities {
+		for _, attribute := range entity.Attributes {
+			classification := string(attribute.Classification)
+			if classification == "" {
+				continue
+			}
+
+			if _, ok := rows[classification]; !ok {
+				classifications = append(classifications, classification)
+			}
+
+			rows[classification] = append(rows[classification], []string{entity.Name, entity.Table, attribute.Name, attribute.Type, entity.Link})
+			documented = documented || entity.Link != ""
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString("# Compliance Report\n\n")
+
+	if len(classifications) == 0 {
+		builder.WriteString("No column is classified.\n")
+		return builder.String()
+	}
+
+	for i, classification := range classifications {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+
+		builder.WriteString(fmt.Sprintf("## %s\n\n", classification))
+		if documented {
+			builder.WriteString("| Entity | Table | Column | Type | Documentation |\n")
+			builder.WriteString("| --- | --- | --- | --- | --- |\n")
+		} else {
+			builder.WriteString("| Entity | Table | Column | Type |\n")
+			builder.WriteString("| --- | --- | --- | --- |\n")
+		}
+
+		for _, row := range rows[classification] {
+			if link := row[4]; link != "" {
+				row[4] = fmt.Sprintf("[%s](%s)", complianceCell(row[0]), strings.ReplaceAll(link, ")", "%29"))
+			}
+			if !documented {
+				row = row[:4]
+			}
+
+			for i, cell := range row[:4] {
+				row[i] = complianceCell(cell)
+			}
+			builder.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		}
+	}
+
+	return builder.String()
+}
+
+// complianceCell escapes the pipes which would otherwise split the cell.
+func complianceCell(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportCompliance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "compliance.md")
+
+	if err := exportCompliance(context.Background(), "../examples/annotations/schema", path, newOptions([]Option{WithQuiet()})); err != nil {
+		t.Fatalf("Failed to export the compliance report: %v", err)
+	}
+
+	report, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"## pii\n\n| Entity | Table | Column | Type | Documentation |\n| --- | --- | --- | --- | --- |\n" +
+			"| Customer | customers | email | string | [Customer](https://catalog.example.com/tables/customers) |\n",
+		"## secret\n\n| Entity | Table | Column | Type | Documentation |\n| --- | --- | --- | --- | --- |\n" +
+			"| Customer | customers | password_hash | string | [Customer](https://catalog.example.com/tables/customers) |\n",
+	} {
+		if !strings.Contains(string(report), expected) {
+			t.Errorf("The report is missing %q:\n%s", expected, report)
+		}
+	}
+
+	if err := exportCompliance(context.Background(), "../examples/start/schema", path, newOptions([]Option{WithQuiet(), WithCheck()})); !errors.Is(err, ErrStaleDiagram) {
+		t.Errorf("Expected the report of another schema to be stale, got %v", err)
+	}
+}
+
+func TestComplianceReportUnclassified(t *testing.T) {
+	report := complianceReport(&Model{Entities: []*Entity{{Name: "User", Attributes: []*Attribute{{Name: "name"}}}}})
+	if !strings.Contains(report, "No column is classified.") {
+		t.Errorf("Expected the report to say no column is classified:\n%s", report)
+	}
+}
+
+func TestComplianceReportUndocumented(t *testing.T) {
+	report := complianceReport(&Model{Entities: []*Entity{{Name: "User", Table: "users", Attributes: []*Attribute{{Name: "email", Type: "a|b", Classification: "pii"}}}}})
+
+	expected := "| Entity | Table | Column | Type |\n| --- | --- | --- | --- |\n| User | users | email | a\\|b |\n"
+	if !strings.Contains(report, expected) {
+		t.Errorf("The report is missing %q:\n%s", expected, report)
+	}
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// FormatConfluence wraps the Mermaid diagram in a macro of the Confluence storage format.
+const FormatConfluence Format = "confluence"
+
+// defaultConfluenceMacro is the built-in code block macro, which shows the diagram's source when no Mermaid app is
+// installed in Confluence.
+const defaultConfluenceMacro = "code"
+
+// ConfluencePage is the Confluence page the diagram is pushed to, the whole body of the page is replaced by it.
+// The credentials are sent as basic auth when User is set (Confluence Cloud API tokens), or as a bearer token
+// otherwise (Data Center personal access tokens).
+type ConfluencePage struct {
+	// BaseURL is the URL Confluence is served at, e.g. https://acme.atlassian.net/wiki.
+	BaseURL string
+	ID      string
+	User    string
+	Token   string
+}
+
+// WithConfluenceMacro sets the name of the macro the Mermaid diagram is wrapped in for the confluence format, it
+// must match the Mermaid app installed in Confluence.
+func WithConfluenceMacro(name string) Option {
+	return func(o *options) {
+		o.confluenceMacro = name
+	}
+}
+
+// WithConfluencePage pushes the diagram in the confluence format to the page, or compares it to the page in check
+// mode.
+func WithConfluencePage(page ConfluencePage) Option {
+	return func(o *options) {
+		o.confluencePage = &page
+	}
+}
+
+// renderConfluence writes the Mermaid diagram inside a Confluence storage format macro.
+func renderConfluence(ctx context.Context, w io.Writer, model *Model, o *options) error {
+	var builder strings.Builder
+	if err := renderMermaid(ctx, &builder, model, o); err != nil {
+		return err
+	}
+
+	macro := o.confluenceMacro
+	if macro == "" {
+		macro = defaultConfluenceMacro
+	}
+
+	var storage strings.Builder
+	storage.WriteString(fmt.Sprintf(`<ac:structured-macro ac:name="%s" ac:schema-version="1">`, confluenceEscaper.Replace(macro)))
+	if macro == defaultConfluenceMacro {
+		storage.WriteString(`<ac:parameter ac:name="language">mermaid</ac:parameter>`)
+	}
+	// CDATA sections can't contain their terminator, so it's split across two sections.
+	storage.WriteString("<ac:plain-text-body><![CDATA[" + strings.ReplaceAll(builder.String(), "]]>", "]]]]><![CDATA[>") + "]]></ac:plain-text-body>")
+	storage.WriteString("</ac:structured-macro>\n")
+
+	_, err := io.WriteString(w, storage.String())
+
+	return err
+}
+
+var confluenceEscaper = strings.NewReplacer("&", "&amp;", `"`, "&quot;", "<", "&lt;", ">", "&gt;")
+
+// confluenceContent is the part of the Confluence content REST resource needed to update a page.
+type confluenceContent struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Title   string `json:"title"`
+	Version struct {
+		Number int `json:"number"`
+	} `json:"version"`
+	Body struct {
+		Storage struct {
+			Value          string `json:"value"`
+			Representation string `json:"representation"`
+		} `json:"storage"`
+	} `json:"body"`
+}
+
+// pushConfluence replaces the body of the Confluence page with the diagram, unless it's already up to date.
+func pushConfluence(ctx context.Context, model *Model, o *options) error {
+	page := o.confluencePage
+
+	var buf bytes.Buffer
+	if err := renderConfluence(ctx, &buf, model, o); err != nil {
+		return fmt.Errorf("%w as %s: %w", ErrRender, FormatConfluence, err)
+	}
+
+	url := fmt.Sprintf("%s/rest/api/content/%s", strings.TrimSuffix(page.BaseURL, "/"), page.ID)
+
+	var content confluenceContent
+	if err := confluenceRequest(ctx, http.MethodGet, url+"?expand=body.storage,version", page, nil, &content); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(content.Body.Storage.Value) == strings.TrimSpace(buf.String()) {
+		o.logger.Info("confluence page is unchanged", "page", page.ID)
+		return nil
+	}
+
+	if o.check {
+		return fmt.Errorf("the Mermaid %w on the Confluence page %s", ErrStaleDiagram, page.ID)
+	}
+
+	content.Version.Number++
+	content.Body.Storage.Value = buf.String()
+	content.Body.Storage.Representation = "storage"
+
+	if err := confluenceRequest(ctx, http.MethodPut, url, page, &content, nil); err != nil {
+		return err
+	}
+
+	o.logger.Info("pushed diagram to confluence", "page", page.ID, "version", content.Version.Number)
+
+	return nil
+}
+
+func confluenceRequest(ctx context.Context, method string, url string, page *ConfluencePage, body any, result any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrConfluence, err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrConfluence, err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if page.User != "" {
+		req.SetBasicAuth(page.User, page.Token)
+	} else if page.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+page.Token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrConfluence, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%w: %s %s returned %s: %s", ErrConfluence, method, url, resp.Status, bytes.TrimSpace(message))
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("%w: %w", ErrConfluence, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRenderConfluence(t *testing.T) {
+	model := &Model{Entities: []*Entity{{Name: "User", Attributes: []*Attribute{{Name: "id", Type: "int", Keys: []string{"PK"}}}}}}
+
+	var builder strings.Builder
+	if err := renderConfluence(context.Background(), &builder, model, newOptions(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<ac:structured-macro ac:name="code" ac:schema-version="1"><ac:parameter ac:name="language">mermaid</ac:parameter>` +
+		"<ac:plain-text-body><![CDATA[erDiagram\n %% entities\n\n User {\n  int id PK\n }\n\n]]></ac:plain-text-body></ac:structured-macro>\n"
+	if builder.String() != expected {
+		t.Errorf("Got %q, expected %q", builder.String(), expected)
+	}
+
+	builder.Reset()
+	if err := renderConfluence(context.Background(), &builder, model, newOptions([]Option{WithConfluenceMacro("mermaid-cloud")})); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(builder.String(), `<ac:structured-macro ac:name="mermaid-cloud" ac:schema-version="1"><ac:plain-text-body>`) {
+		t.Errorf("Expected the app macro without the language parameter, got %q", builder.String())
+	}
+}
+
+func TestPushConfluence(t *testing.T) {
+	var pushed *confluenceContent
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, token, _ := r.BasicAuth(); user != "me" || token != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			content := confluenceContent{ID: "42", Type: "page", Title: "ERD"}
+			content.Version.Number = 3
+			if pushed != nil {
+				content.Body.Storage.Value = pushed.Body.Storage.Value
+			}
+			_ = json.NewEncoder(w).Encode(content)
+		case http.MethodPut:
+			pushed = &confluenceContent{}
+			_ = json.NewDecoder(r.Body).Decode(pushed)
+		}
+	}))
+	defer server.Close()
+
+	page := WithConfluencePage(ConfluencePage{BaseURL: server.URL + "/", ID: "42", User: "me", Token: "secret"})
+
+	err := GenerateDiagram("../examples/start/schema", "", Plain, "", "", page, WithCheck())
+	if !errors.Is(err, ErrStaleDiagram) {
+		t.Errorf("Expected the empty page to be stale, got %v", err)
+	}
+
+	if err := GenerateDiagram("../examples/start/schema", "", Plain, "", "", page); err != nil {
+		t.Fatalf("Failed to push the diagram: %v", err)
+	}
+
+	if pushed == nil || pushed.Version.Number != 4 || pushed.Body.Storage.Representation != "storage" || !strings.Contains(pushed.Body.Storage.Value, "User |o--o{ Car") {
+		t.Errorf("Unexpected content pushed: %+v", pushed)
+	}
+
+	if err := GenerateDiagram("../examples/start/schema", "", Plain, "", "", page, WithCheck()); err != nil {
+		t.Errorf("Expected the pushed page to be up to date, got %v", err)
+	}
+
+	err = GenerateDiagram("../examples/start/schema", "", Plain, "", "", WithConfluencePage(ConfluencePage{BaseURL: server.URL, ID: "42"}))
+	if !errors.Is(err, ErrConfluence) || ExitCode(err) != ExitTarget {
+		t.Errorf("Expected unauthorized requests to fail with ErrConfluence, got %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGenerateDiagramContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := GenerateDiagramContext(ctx, "../examples/start/schema", "../examples/start/readme-expected.md", Markdown, defaultStartPattern, defaultEndPattern, WithCheck())
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a canceled error, got %v", err)
+	}
+
+	if code := ExitCode(err); code != ExitTimeout {
+		t.Errorf("Expected exit code %d, got %d", ExitTimeout, code)
+	}
+}
+
+func TestGenerateDiagramContextTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+
+	err := GenerateDiagramContext(ctx, "../examples/start/schema", "../examples/start/readme-expected.md", Markdown, defaultStartPattern, defaultEndPattern, WithCheck())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline exceeded error, got %v", err)
+	}
+}
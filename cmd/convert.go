@@ -0,0 +1,388 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lespea/entmaid/annotation"
+)
+
+var (
+	convertFromMarkdown string
+	convertFromJSON     string
+	convertTo           string
+)
+
+var convertCmd = &cobra.Command{
+	Use:   "convert [file]",
+	Short: "Render a generated diagram or JSON model to another format, without the schema",
+	Long: `Render the diagram generated in a target, or the model of a JSON output, to another format written to the file
+or printed to stdout, so the secondary exports can be refreshed where the schema isn't available:
+
+  entmaid convert --from-markdown README.md --to dbml docs/schema.dbml
+
+The erDiagrams are read between the start and end patterns of the target, or from the whole file when it has none.
+A diagram only holds what it draws: the tables of the entities, the descriptions of the fields and whatever the
+diagram was generated without are left out, the JSON model of a --output json=<file> keeps everything else.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := StdioTarget
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		return runWithOptions(cmd, func(ctx context.Context, opts []Option) error {
+			return convertDiagram(ctx, convertFromMarkdown, convertFromJSON, Format(convertTo), path, newOptions(opts))
+		})
+	},
+}
+
+func init() {
+	convertCmd.Flags().StringVar(&convertFromMarkdown, "from-markdown", "", "target file holding the generated diagram to convert, - for stdin")
+	convertCmd.Flags().StringVar(&convertFromJSON, "from-json", "", "JSON model written by an output of the json format to convert, - for stdin")
+	convertCmd.Flags().StringVar(&convertTo, "to", "", "format to convert to, derived from the extension of the file when not given")
+	convertCmd.MarkFlagsOneRequired("from-markdown", "from-json")
+	convertCmd.MarkFlagsMutuallyExclusive("from-markdown", "from-json")
+	_ = convertCmd.RegisterFlagCompletionFunc("to", cobra.FixedCompletions(Formats(), cobra.ShellCompDirectiveNoFileComp))
+
+	rootCmd.AddCommand(convertCmd)
+}
+
+// convertDiagram renders the model of the diagram of the target, or of the JSON model, in the format to the file at
+// path or to stdout.
+func convertDiagram(ctx context.Context, markdownPath string, jsonPath string, format Format, path string, o *options) error {
+	if format == "" {
+		var ok bool
+		if format, ok = PathFormat(path); !ok {
+			return fmt.Errorf("%w: the format to convert to is required", errUsage)
+		}
+	}
+
+	var model *Model
+	if jsonPath != "" {
+		content, err := readTargetFile(jsonPath, o)
+		if err != nil {
+			return inFile(jsonPath, fmt.Errorf("%w: %w", ErrTarget, err))
+		}
+
+		model = &Model{}
+		if err := json.Unmarshal(content, model); err != nil {
+			return inFile(jsonPath, fmt.Errorf("%w: invalid JSON model: %w", ErrTarget, err))
+		}
+	} else {
+		diagram, err := readDiagram(markdownPath, o)
+		if err != nil {
+			return err
+		}
+
+		if model = parseMermaidModel(diagram); len(model.Entities) == 0 {
+			return inFile(markdownPath, fmt.Errorf("%w: no erDiagram entity to convert", ErrInvalidDiagram))
+		}
+	}
+
+	o.logger.Debug("converting diagram", "entities", len(model.Entities), "relationships", len(model.Relationships), "format", format)
+
+	if path != StdioTarget {
+		if err := writeOutput(ctx, model, output{format: format, path: path}, o); err != nil {
+			return err
+		}
+
+		if !o.quiet {
+			if o.check {
+				fmt.Printf("The %s is up to date.\n", format)
+			} else {
+				fmt.Printf("Diagram converted to %s successfully.\n", format)
+			}
+		}
+
+		return nil
+	}
+
+	content, err := renderFormat(ctx, model, format, o)
+	if err != nil {
+		return err
+	}
+
+	if _, err := o.stdout.Write(content); err != nil {
+		return fmt.Errorf("%w: failed to write the %s: %w", ErrRender, format, err)
+	}
+
+	return nil
+}
+
+var (
+	// convertAttributeRegex splits an attribute of an erDiagram entity into its type, name, keys and comment.
+	convertAttributeRegex = regexp.MustCompile(`^(` + mermaidWordPattern + `)\s+(` + mermaidWordPattern + `)(?:\s+(` +
+		mermaidKeyPattern + `(?:\s*,\s*` + mermaidKeyPattern + `)*))?(?:\s+"([^"]*)")?$`)
+	// convertIndexRegex and convertCheckRegex match the comments listing the indexes and check constraints below
+	// their entity.
+	convertIndexRegex = regexp.MustCompile(`^%% (\S+) (unique )?index (\S+) \((.*)\)$`)
+	convertCheckRegex = regexp.MustCompile(`^%% (\S+) check (?:(\S+) )?\((.*)\)$`)
+	// convertLabelRegex splits the label of an entity into its display name and its tags.
+	convertLabelRegex = regexp.MustCompile(`^\S+\["(.*?)((?: \([^()]*\))*)"\]`)
+
+	// mermaidEntityCodeUnescaper decodes the entity codes written by mermaidComment.
+	mermaidEntityCodeUnescaper = strings.NewReplacer("#quot;", `"`, "#96;", "`", "#35;", "#")
+)
+
+// parseMermaidModel returns the model drawn by the erDiagrams of the content, as far as they draw it. The stubs of
+// paginated diagrams repeat the entities drawn on other pages, only the entity with the most attributes is kept.
+func parseMermaidModel(content string) *Model {
+	model := &Model{}
+
+	var entity *Entity
+	joinTables := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if entity != nil {
+			if trimmed == "}" {
+				if existing := model.Entity(entity.Name); existing == nil {
+					model.Entities = append(model.Entities, entity)
+				} else if len(entity.Attributes) > len(existing.Attributes) {
+					*existing = *entity
+				}
+				entity = nil
+			} else if attribute := parseMermaidAttribute(trimmed); attribute != nil {
+				entity.Attributes = append(entity.Attributes, attribute)
+			}
+			continue
+		}
+
+		switch {
+		case trimmed == "%% join tables":
+			joinTables = true
+		case trimmed == "%% entities" || trimmed == "%% relationships":
+			joinTables = false
+		case mermaidEntityLineRegex.MatchString(trimmed):
+			entity = parseMermaidEntity(trimmed)
+			entity.JoinTable = joinTables
+		case mermaidRelationshipRegex.MatchString(trimmed):
+			match := diffRelationshipRegex.FindStringSubmatch(trimmed)
+			ends := match[2]
+			rel := &Relationship{
+				From: mermaidName(match[1]), To: mermaidName(match[3]), Label: mermaidName(match[4]),
+				FromCardinality: mermaidCardinality(mermaidLeftEnds, ends[:2]), ToCardinality: mermaidCardinality(mermaidRightEnds, ends[4:]),
+				Logical: ends[2:4] == "..",
+			}
+			if !slices.ContainsFunc(model.Relationships, func(other *Relationship) bool {
+				return other.From == rel.From && other.To == rel.To && other.Label == rel.Label
+			}) {
+				model.Relationships = append(model.Relationships, rel)
+			}
+		case convertIndexRegex.MatchString(trimmed):
+			match := convertIndexRegex.FindStringSubmatch(trimmed)
+			if indexed := model.Entity(match[1]); indexed != nil && !slices.ContainsFunc(indexed.Indexes, func(index *Index) bool { return index.Name == match[3] }) {
+				indexed.Indexes = append(indexed.Indexes, &Index{Name: match[3], Columns: strings.Split(match[4], ", "), Unique: match[2] != ""})
+			}
+		case mermaidStyleRegex.MatchString(trimmed):
+			fields := strings.Fields(trimmed)
+			if styled := model.Entity(mermaidName(fields[1])); styled != nil {
+				styled.Style = parseMermaidStyle(fields[2])
+			}
+		case convertCheckRegex.MatchString(trimmed):
+			match := convertCheckRegex.FindStringSubmatch(trimmed)
+			check := &Check{Name: match[2], Expression: match[3]}
+			if checked := model.Entity(match[1]); checked != nil && !slices.ContainsFunc(checked.Checks, func(other *Check) bool { return *other == *check }) {
+				checked.Checks = append(checked.Checks, check)
+			}
+		}
+	}
+
+	inferForeignKeys(model)
+
+	return model
+}
+
+// inferForeignKeys realizes the relationships of the parsed model with the FK columns of their entities, as the
+// diagram doesn't draw which column references which: the FK column of the entity on the many side (either side of
+// O2O relationships) named after the other entity, or else its only FK column left.
+func inferForeignKeys(model *Model) {
+	used := make(map[*Attribute]bool)
+
+	for _, byName := range []bool{true, false} {
+		for _, rel := range model.Relationships {
+			if rel.ForeignKey != nil {
+				continue
+			}
+
+			var sides [][2]string
+			switch {
+			case !isSingle(rel.FromCardinality) && !isSingle(rel.ToCardinality):
+				continue
+			case !isSingle(rel.ToCardinality):
+				sides = [][2]string{{rel.To, rel.From}}
+			case !isSingle(rel.FromCardinality):
+				sides = [][2]string{{rel.From, rel.To}}
+			default:
+				sides = [][2]string{{rel.To, rel.From}, {rel.From, rel.To}}
+			}
+
+			for _, side := range sides {
+				holder, ref := model.Entity(side[0]), model.Entity(side[1])
+				if holder == nil || ref == nil {
+					continue
+				}
+
+				refColumn := primaryKey(ref)
+				if refColumn == nil {
+					continue
+				}
+
+				if column := foreignKeyColumn(holder, ref, used, byName); column != nil {
+					used[column] = true
+					rel.ForeignKey = &ForeignKey{Entity: holder.Name, Column: column.Name, RefEntity: ref.Name, RefColumn: refColumn.Name}
+					break
+				}
+			}
+		}
+	}
+}
+
+// primaryKey returns the single PK column of the entity, or nil when it has none or a composite one.
+func primaryKey(entity *Entity) *Attribute {
+	var pk *Attribute
+	for _, attribute := range entity.Attributes {
+		if slices.Contains(attribute.Keys, "PK") {
+			if pk != nil {
+				return nil
+			}
+			pk = attribute
+		}
+	}
+
+	return pk
+}
+
+// foreignKeyColumn returns the FK column of the holder not used yet named after the referenced entity, or the only
+// one left when not by name.
+func foreignKeyColumn(holder *Entity, ref *Entity, used map[*Attribute]bool, byName bool) *Attribute {
+	normalize := func(name string) string {
+		return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+	}
+	refName := normalize(ref.Name[strings.LastIndexByte(ref.Name, '.')+1:])
+
+	var candidates []*Attribute
+	for _, attribute := range holder.Attributes {
+		if !used[attribute] && slices.Contains(attribute.Keys, "FK") {
+			if byName && strings.Contains(normalize(attribute.Name), refName) {
+				return attribute
+			}
+			candidates = append(candidates, attribute)
+		}
+	}
+
+	if !byName && len(candidates) == 1 {
+		return candidates[0]
+	}
+
+	return nil
+}
+
+// parseMermaidEntity returns the entity of the line opening it, its label telling its display name, whether it's a
+// view and its guards.
+func parseMermaidEntity(line string) *Entity {
+	entity := &Entity{Name: mermaidName(mermaidEntityLineRegex.FindStringSubmatch(line)[1])}
+	// The entities stored in a database schema are qualified by it.
+	if schema, _, ok := strings.Cut(entity.Name, "."); ok {
+		entity.Schema = schema
+	}
+
+	match := convertLabelRegex.FindStringSubmatch(line)
+	if match == nil {
+		return entity
+	}
+
+	if label := mermaidEntityCodeUnescaper.Replace(match[1]); label != entity.Name {
+		entity.DisplayName = label
+	}
+
+	for _, tag := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(match[2], " ("), ")"), ") (") {
+		switch {
+		case tag == "view":
+			entity.View = true
+		case tag != "" && !strings.HasPrefix(tag, "see diagram "):
+			entity.Guards = strings.Split(tag, ",")
+		}
+	}
+
+	return entity
+}
+
+// parseMermaidAttribute returns the attribute of the line, restoring the type and name that had to be changed into
+// words and the details of its comment it can tell apart, or nil when the line isn't one.
+func parseMermaidAttribute(line string) *Attribute {
+	match := convertAttributeRegex.FindStringSubmatch(line)
+	// The row counting the attributes left out isn't one.
+	if match == nil || match[1] == "__" {
+		return nil
+	}
+
+	attribute := &Attribute{Type: match[1], Name: match[2]}
+	if match[3] != "" {
+		attribute.Keys = strings.Split(strings.ReplaceAll(match[3], " ", ""), ",")
+	}
+
+	for _, detail := range strings.Split(mermaidEntityCodeUnescaper.Replace(match[4]), ", ") {
+		switch classification := annotation.Classification(detail); {
+		case strings.HasPrefix(detail, "type: "):
+			attribute.Type = strings.TrimPrefix(detail, "type: ")
+		case strings.HasPrefix(detail, "column: "):
+			attribute.Name = strings.TrimPrefix(detail, "column: ")
+		case strings.HasPrefix(detail, "default: "):
+			attribute.Default = strings.TrimPrefix(detail, "default: ")
+		case detail == "immutable":
+			attribute.Immutable = true
+		case classification == annotation.PII || classification == annotation.Secret || classification == annotation.Public:
+			attribute.Classification = classification
+		}
+	}
+
+	return attribute
+}
+
+// parseMermaidStyle returns the style of the CSS properties of a style statement written by mermaidStyle.
+func parseMermaidStyle(properties string) *annotation.Style {
+	style := &annotation.Style{}
+	// The commas of the values are escaped, unlike the ones separating the properties.
+	for _, property := range strings.Split(strings.ReplaceAll(properties, `\,`, "\x00"), ",") {
+		name, value, _ := strings.Cut(strings.ReplaceAll(property, "\x00", ","), ":")
+
+		switch name {
+		case "fill":
+			style.Fill = value
+		case "stroke":
+			style.Stroke = value
+		case "color":
+			style.Color = value
+		}
+	}
+
+	return style
+}
+
+// mermaidName returns the entity name or label, unquoted.
+func mermaidName(name string) string {
+	if unquoted, ok := strings.CutPrefix(name, `"`); ok {
+		return mermaidEntityCodeUnescaper.Replace(strings.TrimSuffix(unquoted, `"`))
+	}
+
+	return name
+}
+
+// mermaidCardinality returns the cardinality drawn by the end of a relationship.
+func mermaidCardinality(ends map[Cardinality]string, end string) Cardinality {
+	for cardinality, drawn := range ends {
+		if drawn == end {
+			return cardinality
+		}
+	}
+
+	return ""
+}
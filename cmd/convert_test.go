@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConvertDiagram(t *testing.T) {
+	expected, err := os.ReadFile("testdata/fixtures/start/dbml.dbml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The readme holds the diagram of the start schema, the DBML of which has the foreign keys inferred from it.
+	path := filepath.Join(t.TempDir(), "schema.dbml")
+	if err := convertDiagram(context.Background(), "../examples/start/readme.md", "", "", path, newOptions([]Option{WithQuiet()})); err != nil {
+		t.Fatalf("Failed to convert the diagram: %v", err)
+	}
+
+	dbml, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(dbml) != string(expected) {
+		t.Errorf("The converted DBML differs from the one of the schema:\n%s\nexpected:\n%s", dbml, expected)
+	}
+}
+
+func TestConvertDiagramJSON(t *testing.T) {
+	var stdout bytes.Buffer
+	if err := convertDiagram(context.Background(), "", "testdata/fixtures/multischema/json.json", FormatDBML, StdioTarget, newOptions([]Option{WithStdio(nil, &stdout)})); err != nil {
+		t.Fatalf("Failed to convert the JSON model: %v", err)
+	}
+
+	expected, err := os.ReadFile("testdata/fixtures/multischema/dbml.dbml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stdout.String() != string(expected) {
+		t.Errorf("The converted DBML differs from the one of the schema:\n%s\nexpected:\n%s", stdout.String(), expected)
+	}
+}
+
+func TestConvertDiagramWithoutEntities(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "readme.md")
+	if err := os.WriteFile(path, []byte("classDiagram\n class User {\n }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := convertDiagram(context.Background(), path, "", FormatDBML, StdioTarget, newOptions(nil)); err == nil {
+		t.Error("Expected a class diagram to have no entity to convert")
+	}
+}
+
+func TestParseMermaidModel(t *testing.T) {
+	model := parseMermaidModel(`erDiagram
+ %% entities
+
+ Order["Purchase #quot;Order#quot; (view) (policy,hooks)"] {
+  int id PK
+  numeric_10_2_ total "type: numeric(10,2), default: 0, immutable, pii"
+  int user_orders FK
+ }
+ %% Order unique index order_total (total)
+ %% Order check positive (total >= 0)
+
+ User {
+  int id PK
+ }
+
+ %% relationships
+
+ User ||--o{ Order : orders
+ Order |o..o| Order : "replacement-of"
+
+ style Order fill:rgb(1\,2\,3),stroke:#c00
+`)
+
+	order := model.Entity("Order")
+	if order == nil || model.Entity("User") == nil {
+		t.Fatalf("Expected the Order and User entities, got %+v", model.Entities)
+	}
+
+	if order.DisplayName != `Purchase "Order"` || !order.View || strings.Join(order.Guards, ",") != "policy,hooks" {
+		t.Errorf("Unexpected label of the order: %q, view %v, guards %v", order.DisplayName, order.View, order.Guards)
+	}
+
+	total := order.Attribute("total")
+	if total == nil || total.Type != "numeric(10,2)" || total.Default != "0" || !total.Immutable || total.Classification != "pii" {
+		t.Errorf("Unexpected total attribute: %+v", total)
+	}
+
+	if len(order.Indexes) != 1 || !order.Indexes[0].Unique || order.Indexes[0].Columns[0] != "total" {
+		t.Errorf("Unexpected indexes: %+v", order.Indexes)
+	}
+	if len(order.Checks) != 1 || order.Checks[0].Name != "positive" || order.Checks[0].Expression != "total >= 0" {
+		t.Errorf("Unexpected checks: %+v", order.Checks)
+	}
+	if order.Style == nil || order.Style.Fill != "rgb(1,2,3)" || order.Style.Stroke != "#c00" {
+		t.Errorf("Unexpected style: %+v", order.Style)
+	}
+
+	if len(model.Relationships) != 2 {
+		t.Fatalf("Expected 2 relationships, got %+v", model.Relationships)
+	}
+
+	orders, replacement := model.Relationships[0], model.Relationships[1]
+	if orders.FromCardinality != ExactlyOne || orders.ToCardinality != ZeroOrMore || orders.ForeignKey == nil || orders.ForeignKey.Column != "user_orders" {
+		t.Errorf("Unexpected orders relationship: %+v", orders)
+	}
+	// The only FK column of the order is already used by the orders of the user.
+	if replacement.Label != "replacement-of" || !replacement.Logical || replacement.ForeignKey != nil {
+		t.Errorf("Unexpected replacement relationship: %+v", replacement)
+	}
+}
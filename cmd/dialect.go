@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"strings"
+
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/entc/gen"
+	"entgo.io/ent/schema/field"
+	"github.com/thediveo/enumflag/v2"
+)
+
+// Dialect is the database the diagram shows the column types of.
+type Dialect enumflag.Flag
+
+const (
+	// DialectAny is the default, showing the readable types of any database.
+	DialectAny Dialect = iota
+	DialectPostgres
+	DialectMySQL
+	DialectSQLite
+)
+
+var DialectIds = map[Dialect][]string{
+	DialectAny:      {"any"},
+	DialectPostgres: {dialect.Postgres},
+	DialectMySQL:    {dialect.MySQL},
+	DialectSQLite:   {dialect.SQLite},
+}
+
+// WithDialect shows the column types of the database: its SchemaType is preferred over the other dialects', slices
+// stored as JSON are shown as the JSON column of the database and Postgres shows the array types of lib/pq as its
+// real array columns.
+func WithDialect(d Dialect) Option {
+	return func(o *options) {
+		o.dialect = d
+	}
+}
+
+// postgresArrays are the Postgres columns of the array types of lib/pq.
+var postgresArrays = map[string]string{
+	"pq.stringarray":  "text[]",
+	"pq.int64array":   "bigint[]",
+	"pq.int32array":   "integer[]",
+	"pq.float64array": "float8[]",
+	"pq.float32array": "float4[]",
+	"pq.boolarray":    "boolean[]",
+	"pq.byteaarray":   "bytea[]",
+}
+
+// dialectType returns the column type of the field in the database of the dialect, when it differs from the
+// readable type.
+func dialectType(f *gen.Field, d Dialect) (string, bool) {
+	goType := strings.ToLower(strings.TrimLeft(f.Type.String(), "*"))
+
+	switch {
+	case d == DialectPostgres && postgresArrays[goType] != "":
+		return postgresArrays[goType], true
+	case f.Type.Type == field.TypeJSON && arrayRegex.MatchString(goType):
+		// ent stores the slices of JSON fields (e.g. field.Strings) as a JSON document, not an array column.
+		if d == DialectPostgres {
+			return "jsonb", true
+		}
+		return "json", true
+	}
+
+	return "", false
+}
@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <old> <new>",
+	Short: "Draw the changes between two diagrams as one diagram highlighting the added, changed and removed entities",
+	Long: `Draw the entities and relationships of two diagrams as one erDiagram, printed to stdout: the added entities
+are green, the entities whose attributes changed are yellow and the removed ones are red with a dashed border. The
+added relationships are labeled with a +, the removed ones with a - and drawn dashed.
+
+The diagrams are read between the start and end patterns of the files, or from the whole files when they have none,
+and - reads one of them from stdin, e.g. to compare the diagram of a pull request to the one of the main branch:
+
+  git show main:README.md | entmaid diff - README.md`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWithOptions(cmd, func(ctx context.Context, opts []Option) error {
+			return diffDiagrams(ctx, args[0], args[1], outputType, newOptions(opts))
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+}
+
+// diffStyles are the styles of the entities of the diff by their change, after the colors of the diffs of GitHub.
+var diffStyles = map[string]string{
+	"added":   "fill:#e6ffec,stroke:#1a7f37",
+	"changed": "fill:#fff8c5,stroke:#9a6700",
+	"removed": "fill:#ffebe9,stroke:#cf222e,stroke-dasharray:5",
+}
+
+// diffRelationshipRegex splits a relationship of the diagram into its entities, cardinalities and label.
+var diffRelationshipRegex = regexp.MustCompile(`^(` + mermaidNamePattern + `)\s+(\S+)\s+(` + mermaidNamePattern + `)\s*:\s*(.*)$`)
+
+// diagramPart is an entity or a relationship of a diagram along with the lines drawing it, its key identifies it in
+// the other diagram.
+type diagramPart struct {
+	key   string
+	lines []string
+}
+
+// diffDiagrams writes the diff of the diagrams of the files to the standard output.
+func diffDiagrams(ctx context.Context, previousPath string, currentPath string, outputType OutputType, o *options) error {
+	previous, err := readDiagram(previousPath, o)
+	if err != nil {
+		return err
+	}
+
+	current, err := readDiagram(currentPath, o)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	diagram, err := fenceMermaid(diffDiagram(previous, current, o), outputType, o)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(o.stdout, diagram+"\n"); err != nil {
+		return fmt.Errorf("%w: failed to write string: %w", ErrRender, err)
+	}
+
+	return nil
+}
+
+// readDiagram returns the diagrams between the patterns of the file, or the whole file when it has no patterns.
+func readDiagram(path string, o *options) (string, error) {
+	content, err := readTargetFile(path, o)
+	if err != nil {
+		return "", inFile(path, fmt.Errorf("%w: %w", ErrTarget, err))
+	}
+
+	start, end := startPattern, endPattern
+	if start == "" || end == "" {
+		start, end = DefaultPatterns(path)
+	}
+
+	regions, err := findMarkerRegions(string(content), start, end)
+	if errors.Is(err, ErrMarkerNotFound) && !strings.Contains(string(content), start) {
+		return string(content), nil
+	}
+	if err != nil {
+		return "", inFile(path, err)
+	}
+
+	var diagrams strings.Builder
+	for _, region := range regions {
+		diagrams.WriteString(string(content[region.startIndex+len(start):region.endIndex]) + "\n")
+	}
+
+	return diagrams.String(), nil
+}
+
+// parseDiagram returns the entities and relationships of the erDiagrams in the content in the order they're drawn.
+// The stubs of paginated diagrams repeat the entities drawn on other pages, only the entity with the most lines is
+// kept.
+func parseDiagram(content string) (entities []diagramPart, relationships []diagramPart) {
+	var entity *diagramPart
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		switch match := mermaidEntityLineRegex.FindStringSubmatch(trimmed); {
+		case entity != nil:
+			entity.lines = append(entity.lines, trimmed)
+			if trimmed != "}" {
+				continue
+			}
+
+			if i := slices.IndexFunc(entities, func(other diagramPart) bool { return other.key == entity.key }); i == -1 {
+				entities = append(entities, *entity)
+			} else if len(entity.lines) > len(entities[i].lines) {
+				entities[i] = *entity
+			}
+			entity = nil
+		case match != nil:
+			entity = &diagramPart{key: strings.Trim(match[1], `"`), lines: []string{trimmed}}
+		case mermaidRelationshipRegex.MatchString(trimmed):
+			match := diffRelationshipRegex.FindStringSubmatch(trimmed)
+			key := fmt.Sprintf("%s to %s (%s)", strings.Trim(match[1], `"`), strings.Trim(match[3], `"`), strings.Trim(match[4], `"`))
+			if !slices.ContainsFunc(relationships, func(other diagramPart) bool { return other.key == key }) {
+				relationships = append(relationships, diagramPart{key: key, lines: []string{trimmed}})
+			}
+		}
+	}
+
+	return entities, relationships
+}
+
+// diffDiagram draws the entities and relationships of the current diagram along with the removed ones of the
+// previous diagram, styled by their change.
+func diffDiagram(previous string, current string, o *options) string {
+	previousEntities, previousRelationships := parseDiagram(previous)
+	currentEntities, currentRelationships := parseDiagram(current)
+
+	find := func(parts []diagramPart, key string) *diagramPart {
+		if i := slices.IndexFunc(parts, func(part diagramPart) bool { return part.key == key }); i != -1 {
+			return &parts[i]
+		}
+		return nil
+	}
+
+	changes := make(map[string][]string)
+	var entities []string
+	for _, entity := range currentEntities {
+		switch other := find(previousEntities, entity.key); {
+		case other == nil:
+			changes["added"] = append(changes["added"], entity.key)
+		case !slices.Equal(other.lines, entity.lines):
+			changes["changed"] = append(changes["changed"], entity.key)
+		}
+		entities = append(entities, entity.lines...)
+	}
+	for _, entity := range previousEntities {
+		if find(currentEntities, entity.key) == nil {
+			changes["removed"] = append(changes["removed"], entity.key)
+			entities = append(entities, entity.lines...)
+		}
+	}
+
+	var relationships []string
+	for _, rel := range currentRelationships {
+		if find(previousRelationships, rel.key) == nil {
+			relationships = append(relationships, diffRelationship(rel.lines[0], "+", false))
+		} else {
+			relationships = append(relationships, rel.lines[0])
+		}
+	}
+	for _, rel := range previousRelationships {
+		if find(currentRelationships, rel.key) == nil {
+			relationships = append(relationships, diffRelationship(rel.lines[0], "-", true))
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString("erDiagram\n")
+	for _, change := range []string{"added", "changed", "removed"} {
+		if len(changes[change]) > 0 {
+			builder.WriteString(mermaidLine(fmt.Sprintf(" %%%% %s: %s", change, strings.Join(changes[change], ", "))) + "\n")
+		}
+	}
+
+	for _, line := range entities {
+		switch {
+		case line == "}":
+			builder.WriteString(" }\n\n")
+		case strings.HasSuffix(line, "{"):
+			builder.WriteString(" " + line + "\n")
+		default:
+			builder.WriteString("  " + line + "\n")
+		}
+	}
+
+	for _, line := range relationships {
+		builder.WriteString(" " + line + "\n")
+	}
+
+	// Mermaid 10 can't style the entities of ER diagrams, the comments above are the only marks of the changes.
+	if o.mermaidVersion != Mermaid10 && len(changes) > 0 {
+		if len(relationships) > 0 {
+			builder.WriteString("\n")
+		}
+		for _, change := range []string{"added", "changed", "removed"} {
+			for _, name := range changes[change] {
+				builder.WriteString(fmt.Sprintf(" style %s %s\n", mermaidEntityName(name), diffStyles[change]))
+			}
+		}
+	}
+
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+// diffRelationship prefixes the label of the relationship with the mark of its change, drawing it dashed when it's
+// removed.
+func diffRelationship(line string, mark string, dashed bool) string {
+	match := diffRelationshipRegex.FindStringSubmatch(line)
+	cardinalities := match[2]
+	if dashed {
+		cardinalities = strings.Replace(cardinalities, "--", "..", 1)
+	}
+
+	return match[1] + " " + cardinalities + " " + match[3] + " : " + mermaidLabel(mark+" "+strings.Trim(match[4], `"`))
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffDiagram(t *testing.T) {
+	previous := "erDiagram\n Car {\n  int id PK\n }\n\n Group {\n  int id PK\n }\n\n User {\n  int id PK\n }\n\n" +
+		" User |o--o{ Car : cars-owner\n Group |o--o{ User : users\n"
+	current := "erDiagram\n Car {\n  int id PK\n }\n\n Pet {\n  int id PK\n }\n\n User {\n  int id PK\n  string name\n }\n\n" +
+		" User |o--o{ Car : cars-owner\n User |o--o{ Pet : pets\n"
+
+	diagram := diffDiagram(previous, current, newOptions(nil))
+	if err := validateMermaid(diagram); err != nil {
+		t.Fatalf("Invalid diff: %v\n%s", err, diagram)
+	}
+
+	for _, expected := range []string{
+		" %% added: Pet\n %% changed: User\n %% removed: Group\n",
+		" Group {\n  int id PK\n }\n",
+		" User |o--o{ Car : cars-owner\n",
+		` User |o--o{ Pet : "+ pets"` + "\n",
+		` Group |o..o{ User : "- users"` + "\n",
+		" style Pet " + diffStyles["added"] + "\n",
+		" style User " + diffStyles["changed"] + "\n",
+		" style Group " + diffStyles["removed"],
+	} {
+		if !strings.Contains(diagram, expected) {
+			t.Errorf("The diff is missing %q:\n%s", expected, diagram)
+		}
+	}
+	if strings.Contains(diagram, "style Car") {
+		t.Errorf("The unchanged entity is styled:\n%s", diagram)
+	}
+
+	if diagram := diffDiagram(previous, current, newOptions([]Option{WithMermaidVersion(Mermaid10)})); strings.Contains(diagram, "style") {
+		t.Errorf("Mermaid 10 can't style the entities:\n%s", diagram)
+	}
+}
+
+func TestReadDiagram(t *testing.T) {
+	dir := t.TempDir()
+	readme := filepath.Join(dir, "README.md")
+	if err := os.WriteFile(readme, []byte("# Schema\n\n<!-- #start:entmaid -->\nerDiagram\n<!-- #end:entmaid -->\n\nCar {\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	plain := filepath.Join(dir, "erd.mmd")
+	if err := os.WriteFile(plain, []byte("erDiagram\n Car {\n }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	o := newOptions(nil)
+	if diagram, err := readDiagram(readme, o); err != nil || diagram != "\nerDiagram\n\n" {
+		t.Errorf("Expected the diagram between the patterns, got %q (%v)", diagram, err)
+	}
+	if diagram, err := readDiagram(plain, o); err != nil || diagram != "erDiagram\n Car {\n }\n" {
+		t.Errorf("Expected the whole file, got %q (%v)", diagram, err)
+	}
+	if _, err := readDiagram(filepath.Join(dir, "missing.md"), o); err == nil {
+		t.Error("Expected a missing file to fail")
+	}
+}
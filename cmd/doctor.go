@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+)
+
+// doctorCheck is the outcome of a check of the doctor command, with the fix to apply when it failed.
+type doctorCheck struct {
+	name string
+	info string
+	err  error
+	fix  string
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the setup of entmaid and suggest fixes for the misconfigurations",
+	Long: `Check the setup of entmaid: that the configuration file parses, the schema loads, the target exists with its
+markers and the diagram renders under the Mermaid size limit. The checks are run with the flags of every profile of the
+configuration file when there's one, and with the flags given to this command otherwise.
+
+Every failed check is reported along with its fix, and the command fails when any check did.`,
+	Args: cobra.NoArgs,
+	// The failed checks are already reported with their fix.
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var checks []doctorCheck
+		run := func(profile string) error {
+			return runWithOptions(cmd, func(ctx context.Context, opts []Option) error {
+				for _, check := range diagnose(ctx, schemaPaths[0], targetPath, outputType, startPattern, endPattern, newOptions(opts)) {
+					if profile != "" {
+						check.name = "profile " + profile + ": " + check.name
+					}
+					checks = append(checks, check)
+				}
+
+				return nil
+			})
+		}
+
+		config, check := doctorConfig(configPath)
+		checks = append(checks, check)
+
+		var err error
+		if config != nil {
+			err = runProfiles(cmd.Flags(), config, slices.Sorted(maps.Keys(config.Profiles)), run)
+		} else {
+			err = run("")
+		}
+		if err != nil {
+			checks = append(checks, doctorCheck{name: "flags", err: err, fix: "fix the flags given to entmaid, its ENTMAID_* environment variables or " + ConfigFileName})
+		}
+
+		return writeChecks(os.Stdout, checks)
+	},
+}
+
+func init() {
+	doctorCmd.Flags().StringVar(&configPath, "config", "", "configuration file of the profiles, instead of the "+ConfigFileName+" of the working directory or its parents up to the repository root")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// doctorConfig parses the configuration file, if there's one.
+func doctorConfig(path string) (*Config, doctorCheck) {
+	check := doctorCheck{name: "config"}
+
+	if path == "" {
+		var err error
+		if path, err = findRepoFile(ConfigFileName); err != nil {
+			check.err = err
+			return nil, check
+		}
+		if path == "" {
+			check.info = "no " + ConfigFileName + ", using the flags (run 'entmaid init' to create one)"
+			return nil, check
+		}
+	}
+
+	config, err := readConfig(path)
+	if err != nil {
+		check.err = err
+		check.fix = "fix the JSON of " + path + `, e.g. {"profiles": {"default": {"target": "README.md"}}}`
+		return nil, check
+	}
+
+	check.info = fmt.Sprintf("%s with %s", path, pluralize(len(config.Profiles), "profile", "profiles"))
+
+	return config, check
+}
+
+// diagnose loads the schema, looks for the markers in the target and measures the rendered diagram.
+func diagnose(ctx context.Context, schemaPath string, targetPath string, outputType OutputType, startPattern string, endPattern string, o *options) []doctorCheck {
+	schema := doctorCheck{name: "schema"}
+	model, err := loadModel(ctx, schemaPath, o)
+	if err != nil {
+		schema.err = err
+		schema.fix = "pass the directory of the ent schemas with --schema (e.g. ./ent/schema) and make sure they build with 'go build'"
+		return []doctorCheck{schema}
+	}
+	schema.info = fmt.Sprintf("%s: %s", schemaPath, pluralize(len(model.Entities), "entity", "entities"))
+
+	return []doctorCheck{
+		schema,
+		diagnoseTarget(targetPath, startPattern, endPattern, o),
+		diagnoseSize(ctx, model, outputType, o),
+	}
+}
+
+// diagnoseTarget checks that the target exists and holds the markers of the diagram.
+func diagnoseTarget(targetPath string, startPattern string, endPattern string, o *options) doctorCheck {
+	check := doctorCheck{name: "target"}
+
+	switch targetPath {
+	case "":
+		check.info = "no target, only writing the --output files"
+		return check
+	case StdioTarget:
+		check.info = "reading the target from stdin"
+		return check
+	}
+
+	defaultStart, defaultEnd := DefaultPatterns(targetPath)
+	if startPattern == "" {
+		startPattern = defaultStart
+	}
+	if endPattern == "" {
+		endPattern = defaultEnd
+	}
+
+	content, err := os.ReadFile(targetPath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && o.createMarkers:
+		check.info = targetPath + " will be created by --create-markers"
+		return check
+	case errors.Is(err, fs.ErrNotExist):
+		check.err = inFile(targetPath, fmt.Errorf("%w: %w", ErrTarget, err))
+		check.fix = "create " + targetPath + " with 'entmaid init', pass --create-markers or point --target to the document holding the diagram"
+		return check
+	case err != nil:
+		check.err = inFile(targetPath, fmt.Errorf("%w: %w", ErrTarget, err))
+		return check
+	}
+
+	fileContent := string(content)
+	if o.createMarkers && !strings.Contains(fileContent, startPattern) && !strings.Contains(fileContent, endPattern) {
+		check.info = targetPath + " will get its markers from --create-markers"
+		return check
+	}
+
+	if _, err := spliceMultiLineString(fileContent, "", startPattern, endPattern, o.allMarkers); err != nil {
+		check.err = inFile(targetPath, err)
+		switch {
+		case errors.Is(err, ErrDuplicateMarkers):
+			check.fix = "remove the extra markers from " + targetPath + " or pass --all-markers to fill every pair"
+		default:
+			check.fix = fmt.Sprintf("place the lines %s and %s, in this order, where the diagram belongs in %s", startPattern, endPattern, targetPath)
+		}
+		return check
+	}
+
+	check.info = targetPath + " has the markers " + startPattern + " and " + endPattern
+
+	return check
+}
+
+// diagnoseSize renders the diagram, every page of it when it's paginated, and checks its size against the default
+// Mermaid maxTextSize.
+func diagnoseSize(ctx context.Context, model *Model, outputType OutputType, o *options) doctorCheck {
+	check := doctorCheck{name: "render"}
+
+	pages := []*Model{model}
+	if o.pageSize > 0 && len(model.Entities) > o.pageSize {
+		pages = paginateModel(model, o.pageSize)
+	}
+
+	largest := 0
+	for _, page := range pages {
+		var builder strings.Builder
+		if err := renderMermaid(ctx, &builder, page, o); err != nil {
+			check.err = err
+			return check
+		}
+
+		diagram, err := fenceMermaid(builder.String(), outputType, o)
+		if err != nil {
+			check.err = err
+			return check
+		}
+
+		largest = max(largest, utf8.RuneCountInString(diagram))
+	}
+
+	if largest > mermaidMaxTextSize {
+		check.err = fmt.Errorf("the diagram is %d characters, larger than the default Mermaid maxTextSize of %d, and won't render", largest, mermaidMaxTextSize)
+		check.fix = "split it into several diagrams with --page-size, or narrow it down with --entity or " + IgnoreFileName
+		return check
+	}
+
+	check.info = fmt.Sprintf("%d characters, under the Mermaid maxTextSize of %d", largest, mermaidMaxTextSize)
+
+	return check
+}
+
+// writeChecks prints the outcome of the checks, returning an error when any of them failed.
+func writeChecks(w io.Writer, checks []doctorCheck) error {
+	failed := 0
+	for _, check := range checks {
+		if check.err == nil {
+			if _, err := fmt.Fprintf(w, "ok    %s: %s\n", check.name, check.info); err != nil {
+				return err
+			}
+			continue
+		}
+
+		failed++
+		if _, err := fmt.Fprintf(w, "FAIL  %s: %v\n", check.name, check.err); err != nil {
+			return err
+		}
+		if check.fix != "" {
+			if _, err := fmt.Fprintf(w, "      fix: %s\n", check.fix); err != nil {
+				return err
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiagnoseTarget(t *testing.T) {
+	dir := t.TempDir()
+
+	for name, content := range map[string]string{
+		"ok.md":        "# Project\n<!-- #start:entmaid -->\n<!-- #end:entmaid -->\n",
+		"missing.md":   "# Project\n",
+		"reversed.md":  "<!-- #end:entmaid -->\n<!-- #start:entmaid -->\n",
+		"duplicate.md": strings.Repeat("<!-- #start:entmaid -->\n<!-- #end:entmaid -->\n", 2),
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, expected := range map[string]error{
+		"ok.md":        nil,
+		"missing.md":   ErrMarkerNotFound,
+		"reversed.md":  ErrMarkerOrder,
+		"duplicate.md": ErrDuplicateMarkers,
+		"absent.md":    ErrTarget,
+	} {
+		check := diagnoseTarget(filepath.Join(dir, name), "", "", newOptions(nil))
+		if !errors.Is(check.err, expected) {
+			t.Errorf("Checking %s failed with %v, expected %v", name, check.err, expected)
+		}
+		if check.err != nil && check.fix == "" {
+			t.Errorf("Checking %s failed without a fix", name)
+		}
+	}
+
+	if check := diagnoseTarget(filepath.Join(dir, "missing.md"), "", "", newOptions([]Option{WithCreateMarkers()})); check.err != nil {
+		t.Errorf("Expected --create-markers to add the missing markers, got %v", check.err)
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	checks := diagnose(context.Background(), "../examples/start/schema", "../examples/start/readme.md", Markdown, "", "", newOptions(nil))
+	if len(checks) != 3 {
+		t.Fatalf("Expected the schema, target and render checks, got %+v", checks)
+	}
+	for _, check := range checks {
+		if check.err != nil {
+			t.Errorf("The %s check failed: %v", check.name, check.err)
+		}
+	}
+
+	checks = diagnose(context.Background(), "../examples/missing", "", Markdown, "", "", newOptions(nil))
+	if len(checks) != 1 || !errors.Is(checks[0].err, ErrSchemaLoad) {
+		t.Errorf("Expected only the failed schema check, got %+v", checks)
+	}
+}
+
+func TestWriteChecks(t *testing.T) {
+	var builder strings.Builder
+	err := writeChecks(&builder, []doctorCheck{
+		{name: "config", info: "no .entmaid.json"},
+		{name: "target", err: errors.New("marker not found"), fix: "add the markers"},
+	})
+	if err == nil {
+		t.Error("Expected an error for the failed check")
+	}
+
+	expected := "ok    config: no .entmaid.json\nFAIL  target: marker not found\n      fix: add the markers\n"
+	if builder.String() != expected {
+		t.Errorf("Got the report %q, expected %q", builder.String(), expected)
+	}
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/thediveo/enumflag/v2"
+)
+
+// DomainSource is where the domains of the entities are inferred from, which they're grouped by like by their database
+// schema (see WithGroupBySchema and the site export) without annotating every schema.
+type DomainSource enumflag.Flag
+
+const (
+	// DomainNone infers no domain, the default.
+	DomainNone DomainSource = iota
+	// DomainPrefix puts the entities whose names start with the same word in its domain, e.g. BillingInvoice and
+	// BillingPayment in billing, when at least two of them share it.
+	DomainPrefix
+	// DomainPackage puts the entities in the domain named after the directory of their schema package, to merge the
+	// schemas of several packages (e.g. ./ent/schema/billing) with WithSchema.
+	DomainPackage
+)
+
+var DomainSourceIds = map[DomainSource][]string{
+	DomainNone:    {"none"},
+	DomainPrefix:  {"prefix"},
+	DomainPackage: {"package"},
+}
+
+// domain is an override of the inferred domains, for the entities matched by the pattern.
+type domain struct {
+	pattern string
+	name    string
+}
+
+// WithDomains infers the domains of the entities from the source.
+func WithDomains(source DomainSource) Option {
+	return func(o *options) {
+		o.domainSource = source
+	}
+}
+
+// WithDomain puts the entities matched by the pattern (as by path.Match, e.g. Billing*) in the domain, overriding the
+// inferred domain. The last matching pattern wins.
+func WithDomain(pattern string, name string) Option {
+	return func(o *options) {
+		o.domains = append(o.domains, domain{pattern: pattern, name: name})
+	}
+}
+
+// group returns the domain of the entity, or otherwise the database schema it's stored in, which the entities are
+// grouped by.
+func (e *Entity) group() string {
+	if e.Domain != "" {
+		return e.Domain
+	}
+
+	return e.Schema
+}
+
+// packageDomains puts the entities of the model loaded from the schema directory in the domain of its package.
+func packageDomains(model *Model, dir string) {
+	name := strings.ToLower(filepath.Base(filepath.Clean(dir)))
+	for _, entity := range model.Entities {
+		if !entity.JoinTable {
+			entity.Domain = name
+		}
+	}
+}
+
+// domainPrefixRegex matches the first word of a CamelCase type name.
+var domainPrefixRegex = regexp.MustCompile(`^[A-Z][a-z0-9]+`)
+
+// assignDomains infers the domains of the entities from their prefix, applies the overrides and puts the join tables
+// in the domain of the entity declaring their edge.
+func assignDomains(model *Model, o *options) {
+	if o.domainSource == DomainPrefix {
+		prefixes := make(map[string]string, len(model.Entities))
+		counts := make(map[string]int)
+		for _, entity := range model.Entities {
+			name := entity.typeName
+			if entity.JoinTable || name == "" {
+				continue
+			}
+
+			if prefix := domainPrefixRegex.FindString(name); prefix != "" {
+				prefixes[entity.Name] = prefix
+				counts[prefix]++
+			}
+		}
+
+		for _, entity := range model.Entities {
+			if prefix, ok := prefixes[entity.Name]; ok && counts[prefix] > 1 {
+				entity.Domain = strings.ToLower(prefix)
+			}
+		}
+	}
+
+	for _, entity := range model.Entities {
+		for _, override := range o.domains {
+			if matched, _ := path.Match(override.pattern, entity.Name); matched && !entity.JoinTable {
+				entity.Domain = override.name
+			}
+		}
+	}
+
+	for _, rel := range model.Relationships {
+		to, from := model.Entity(rel.To), model.Entity(rel.From)
+		if to != nil && from != nil && to.JoinTable && to.Domain == "" {
+			to.Domain = from.Domain
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestAssignDomains(t *testing.T) {
+	newModel := func() *Model {
+		return &Model{
+			Entities: []*Entity{
+				{Name: "BillingInvoice", typeName: "BillingInvoice"},
+				{Name: "BillingPayment", typeName: "BillingPayment"},
+				{Name: "User", typeName: "User"},
+				{Name: "UserProfile", typeName: "UserProfile"},
+				{Name: "Group", typeName: "Group"},
+				{Name: "HTTPLog", typeName: "HTTPLog"},
+				{Name: "invoice_payments", JoinTable: true},
+			},
+			Relationships: []*Relationship{{From: "BillingInvoice", To: "invoice_payments"}},
+		}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		opts     []Option
+		expected map[string]string
+	}{
+		{
+			name:     "prefix",
+			opts:     []Option{WithDomains(DomainPrefix)},
+			expected: map[string]string{"BillingInvoice": "billing", "BillingPayment": "billing", "User": "user", "UserProfile": "user", "invoice_payments": "billing"},
+		},
+		{
+			name:     "overrides",
+			opts:     []Option{WithDomains(DomainPrefix), WithDomain("User*", "accounts"), WithDomain("Group", "accounts"), WithDomain("*Profile", "profiles")},
+			expected: map[string]string{"BillingInvoice": "billing", "BillingPayment": "billing", "User": "accounts", "UserProfile": "profiles", "Group": "accounts", "invoice_payments": "billing"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			model := newModel()
+			assignDomains(model, newOptions(tc.opts))
+
+			for _, entity := range model.Entities {
+				if entity.Domain != tc.expected[entity.Name] {
+					t.Errorf("Expected %s to be in the %q domain, got %q", entity.Name, tc.expected[entity.Name], entity.Domain)
+				}
+			}
+		})
+	}
+}
+
+func TestPackageDomains(t *testing.T) {
+	model := &Model{Entities: []*Entity{{Name: "Invoice"}, {Name: "invoice_lines", JoinTable: true}}}
+	packageDomains(model, "./ent/schema/Billing/")
+
+	if model.Entities[0].Domain != "billing" || model.Entities[1].Domain != "" {
+		t.Errorf("Expected only the Invoice to be in the billing domain, got %+v", model.Entities)
+	}
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// FormatDrawIO is the XML of diagrams.net (draw.io), with the tables laid out on a grid so the diagram can be
+// polished by hand.
+const FormatDrawIO Format = "drawio"
+
+const (
+	drawIOWidth    = 160
+	drawIOCharSize = 7
+	drawIORowSize  = 26
+	drawIOSpacing  = 80
+)
+
+// drawIOTableStyle and drawIORowStyle are the styles of the "Table" shape of the entity relation palette of
+// diagrams.net.
+const (
+	drawIOTableStyle = "swimlane;fontStyle=1;childLayout=stackLayout;horizontal=1;startSize=26;horizontalStack=0;resizeParent=1;resizeParentMax=0;resizeLast=0;collapsible=1;marginBottom=0;"
+	drawIORowStyle   = "text;strokeColor=none;fillColor=none;align=left;verticalAlign=middle;spacingLeft=4;spacingRight=4;overflow=hidden;rotatable=0;points=[[0,0.5],[1,0.5]];portConstraint=eastwest;"
+	drawIOEdgeStyle  = "edgeStyle=entityRelationEdgeStyle;fontSize=12;endFill=0;startFill=0;"
+)
+
+// drawIOArrows are the entity relation arrows of diagrams.net drawing the cardinalities.
+var drawIOArrows = map[Cardinality]string{
+	ZeroOrOne:  "ERzeroToOne",
+	ExactlyOne: "ERmandOne",
+	ZeroOrMore: "ERzeroToMany",
+	OneOrMore:  "ERoneToMany",
+}
+
+type drawIOFile struct {
+	XMLName xml.Name      `xml:"mxfile"`
+	Host    string        `xml:"host,attr"`
+	Diagram drawIODiagram `xml:"diagram"`
+}
+
+type drawIODiagram struct {
+	ID    string      `xml:"id,attr"`
+	Name  string      `xml:"name,attr"`
+	Model drawIOModel `xml:"mxGraphModel"`
+}
+
+type drawIOModel struct {
+	Grid  int          `xml:"grid,attr"`
+	Cells []drawIOCell `xml:"root>mxCell"`
+}
+
+type drawIOCell struct {
+	ID       string          `xml:"id,attr"`
+	Value    string          `xml:"value,attr,omitempty"`
+	Style    string          `xml:"style,attr,omitempty"`
+	Parent   string          `xml:"parent,attr,omitempty"`
+	Source   string          `xml:"source,attr,omitempty"`
+	Target   string          `xml:"target,attr,omitempty"`
+	Vertex   string          `xml:"vertex,attr,omitempty"`
+	Edge     string          `xml:"edge,attr,omitempty"`
+	Geometry *drawIOGeometry `xml:"mxGeometry"`
+}
+
+type drawIOGeometry struct {
+	X        int    `xml:"x,attr,omitempty"`
+	Y        int    `xml:"y,attr,omitempty"`
+	Width    int    `xml:"width,attr,omitempty"`
+	Height   int    `xml:"height,attr,omitempty"`
+	Relative string `xml:"relative,attr,omitempty"`
+	As       string `xml:"as,attr"`
+}
+
+// renderDrawIO writes the model as a diagrams.net file: every entity is a table shape with a row per attribute, laid
+// out on a grid in the order of the entities, and the relationships are connectors with the crow's foot arrows.
+func renderDrawIO(ctx context.Context, w io.Writer, model *Model, o *options) error {
+	cells := []drawIOCell{{ID: "0"}, {ID: "1", Parent: "0"}}
+
+	columns := int(math.Ceil(math.Sqrt(float64(len(model.Entities)))))
+	ids := make(map[string]string, len(model.Entities))
+
+	x, y, rowHeight := 0, 0, 0
+	for i, entity := range model.Entities {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if i > 0 && i%columns == 0 {
+			x, y, rowHeight = 0, y+rowHeight+drawIOSpacing, 0
+		}
+
+		rows := make([]string, len(entity.Attributes))
+		for j, attribute := range entity.Attributes {
+			rows[j] = drawIOAttribute(attribute)
+		}
+
+		width := drawIOWidth
+		for _, text := range append(rows, entity.Name) {
+			width = max(width, (len(text)+2)*drawIOCharSize)
+		}
+		height := drawIORowSize * (len(rows) + 1)
+
+		id := fmt.Sprintf("entity-%d", i)
+		ids[entity.Name] = id
+
+		cells = append(cells, drawIOCell{
+			ID:       id,
+			Value:    entity.Name,
+			Style:    drawIOTableStyle + drawIOEntityStyle(entity),
+			Parent:   "1",
+			Vertex:   "1",
+			Geometry: &drawIOGeometry{X: x, Y: y, Width: width, Height: height, As: "geometry"},
+		})
+
+		for j, text := range rows {
+			cells = append(cells, drawIOCell{
+				ID:       fmt.Sprintf("%s-%d", id, j),
+				Value:    text,
+				Style:    drawIORowStyle,
+				Parent:   id,
+				Vertex:   "1",
+				Geometry: &drawIOGeometry{Y: drawIORowSize * (j + 1), Width: width, Height: drawIORowSize, As: "geometry"},
+			})
+		}
+
+		x += width + drawIOSpacing
+		rowHeight = max(rowHeight, height)
+	}
+
+	for i, rel := range model.Relationships {
+		style := drawIOEdgeStyle + "startArrow=" + drawIOArrows[rel.FromCardinality] + ";endArrow=" + drawIOArrows[rel.ToCardinality] + ";"
+		if rel.Logical {
+			style += "dashed=1;"
+		}
+		if rel.Weight > 0 {
+			style += fmt.Sprintf("strokeWidth=%d;", rel.Weight)
+		}
+
+		cells = append(cells, drawIOCell{
+			ID:       fmt.Sprintf("relationship-%d", i),
+			Value:    relationshipLabel(rel, o),
+			Style:    style,
+			Parent:   "1",
+			Source:   ids[rel.From],
+			Target:   ids[rel.To],
+			Edge:     "1",
+			Geometry: &drawIOGeometry{Relative: "1", As: "geometry"},
+		})
+	}
+
+	file := drawIOFile{
+		Host: "entmaid",
+		Diagram: drawIODiagram{
+			ID:    "entmaid",
+			Name:  "ERD",
+			Model: drawIOModel{Grid: 1, Cells: cells},
+		},
+	}
+
+	content, err := xml.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", content)
+
+	return err
+}
+
+// drawIOAttribute returns the row of the attribute, e.g. "PK id: int".
+func drawIOAttribute(attribute *Attribute) string {
+	text := attribute.Name + ": " + attribute.Type
+	if len(attribute.Keys) > 0 {
+		text = strings.Join(attribute.Keys, ",") + " " + text
+	}
+
+	return text
+}
+
+// drawIOEntityStyle returns the style highlighting the entity, the views are dashed.
+func drawIOEntityStyle(entity *Entity) string {
+	var style strings.Builder
+	if entity.View {
+		style.WriteString("dashed=1;")
+	}
+
+	if entity.Style != nil {
+		for _, color := range [][2]string{{"fillColor", entity.Style.Fill}, {"strokeColor", entity.Style.Stroke}, {"fontColor", entity.Style.Color}} {
+			if color[1] != "" {
+				style.WriteString(color[0] + "=" + color[1] + ";")
+			}
+		}
+	}
+
+	return style.String()
+}
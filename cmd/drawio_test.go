@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/lespea/entmaid/annotation"
+)
+
+func TestRenderDrawIO(t *testing.T) {
+	model := &Model{
+		Entities: []*Entity{
+			{Name: "User", Attributes: []*Attribute{{Name: "id", Type: "int", Keys: []string{"PK"}}, {Name: "tags", Type: "map<string,int>"}}},
+			{Name: "Car", Attributes: []*Attribute{{Name: "owner_id", Type: "int", Keys: []string{"FK"}}}},
+			{Name: "ActiveUser", View: true, Style: &annotation.Style{Fill: "#ffd"}},
+		},
+		Relationships: []*Relationship{
+			{From: "User", To: "Car", FromCardinality: ZeroOrOne, ToCardinality: ZeroOrMore, Label: "cars-owner"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := renderDrawIO(context.Background(), &buf, model, newOptions(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	var file drawIOFile
+	if err := xml.Unmarshal(buf.Bytes(), &file); err != nil {
+		t.Fatalf("The draw.io file isn't valid XML: %v\n%s", err, buf.String())
+	}
+
+	cells := make(map[string]drawIOCell)
+	for _, cell := range file.Diagram.Model.Cells {
+		cells[cell.ID] = cell
+	}
+
+	if cell := cells["entity-0-1"]; cell.Value != "tags: map<string,int>" || cell.Parent != "entity-0" {
+		t.Errorf("Expected the tags row in the User table, got %+v", cell)
+	}
+
+	// The two tables of the first row are side by side, the view starts the second row below the taller one.
+	if user, car := cells["entity-0"].Geometry, cells["entity-1"].Geometry; car.X != user.X+user.Width+drawIOSpacing || car.Y != 0 {
+		t.Errorf("Expected the Car table next to the User table, got %+v and %+v", user, car)
+	}
+	if view := cells["entity-2"]; view.Geometry.X != 0 || view.Geometry.Y != 3*drawIORowSize+drawIOSpacing || !strings.Contains(view.Style, "dashed=1;fillColor=#ffd;") {
+		t.Errorf("Expected the dashed and filled view on the second row, got %+v", view)
+	}
+
+	edge := cells["relationship-0"]
+	if edge.Source != "entity-0" || edge.Target != "entity-1" || !strings.Contains(edge.Style, "startArrow=ERzeroToOne;endArrow=ERzeroToMany;") {
+		t.Errorf("Expected the crow's foot connector from User to Car, got %+v", edge)
+	}
+}
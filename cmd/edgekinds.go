@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"slices"
+
+	"entgo.io/ent/entc/gen"
+)
+
+// EdgeKind is the kind of relationship an edge draws, to only draw some of them.
+type EdgeKind string
+
+const (
+	EdgeO2O EdgeKind = "o2o"
+	// EdgeO2M is also the kind of the M2O edges, as they're the back-references of O2M relationships.
+	EdgeO2M EdgeKind = "o2m"
+	// EdgeM2M is the kind of the relationships to the join tables and to the entities of the edge schemas.
+	EdgeM2M EdgeKind = "m2m"
+)
+
+// ParseEdgeKind returns the kind of relationship named as by ent (o2o, o2m, m2o or m2m).
+func ParseEdgeKind(name string) (EdgeKind, bool) {
+	switch kind := EdgeKind(name); kind {
+	case EdgeO2O, EdgeO2M, EdgeM2M:
+		return kind, true
+	case "m2o":
+		return EdgeO2M, true
+	default:
+		return "", false
+	}
+}
+
+// WithOnlyEdges only draws the relationships of the kinds, e.g. EdgeM2M for a diagram of the many to many structure
+// of the schema. The entities are all drawn, see WithPruneOrphans to leave out the ones without relationships left.
+func WithOnlyEdges(kinds ...EdgeKind) Option {
+	return func(o *options) {
+		o.onlyEdges = append(o.onlyEdges, kinds...)
+	}
+}
+
+// WithoutEdges leaves the relationships of the kinds out of the diagram, along with the join tables of the M2M edges.
+func WithoutEdges(kinds ...EdgeKind) Option {
+	return func(o *options) {
+		o.noEdges = append(o.noEdges, kinds...)
+	}
+}
+
+// edgeKind returns the kind of relationship the edge draws.
+func edgeKind(node *gen.Type, edge *gen.Edge) EdgeKind {
+	switch {
+	case edge.M2M() || node.IsEdgeSchema() || edge.Type.IsEdgeSchema():
+		return EdgeM2M
+	case edge.Rel.Type == gen.O2O:
+		return EdgeO2O
+	default:
+		return EdgeO2M
+	}
+}
+
+// filterEdgeKinds removes the relationships of the kinds not drawn from the model, and the join tables left without
+// relationships.
+func filterEdgeKinds(model *Model, o *options) {
+	relationships := model.Relationships[:0]
+	for _, rel := range model.Relationships {
+		if (len(o.onlyEdges) == 0 || slices.Contains(o.onlyEdges, rel.kind)) && !slices.Contains(o.noEdges, rel.kind) {
+			relationships = append(relationships, rel)
+		}
+	}
+	model.Relationships = relationships
+
+	related := relatedEntities(model)
+	entities := model.Entities[:0]
+	for _, entity := range model.Entities {
+		if entity.JoinTable && !related[entity.Name] {
+			o.skipEntity(entity.Name, "its relationships aren't drawn")
+			continue
+		}
+		entities = append(entities, entity)
+	}
+	model.Entities = entities
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestFilterEdgeKinds(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		schema        string
+		opts          []Option
+		relationships []string
+		entities      []string
+	}{
+		{
+			name:          "only m2m",
+			schema:        "../examples/start/schema",
+			opts:          []Option{WithOnlyEdges(EdgeM2M)},
+			relationships: []string{"users-groups", "groups-users"},
+			entities:      []string{"Car", "Group", "User", "group_users"},
+		},
+		{
+			name:          "no m2m",
+			schema:        "../examples/start/schema",
+			opts:          []Option{WithoutEdges(EdgeM2M)},
+			relationships: []string{"cars-owner"},
+			entities:      []string{"Car", "Group", "User"},
+		},
+		{
+			// The relationships to the entity of an edge schema realize its M2M edges.
+			name:     "edge schema",
+			schema:   "../examples/edgeschema/schema",
+			opts:     []Option{WithoutEdges(EdgeM2M)},
+			entities: []string{"Group", "Membership", "User"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			model, err := loadModel(context.Background(), tc.schema, newOptions(append(tc.opts, WithQuiet())))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var relationships []string
+			for _, rel := range model.Relationships {
+				relationships = append(relationships, rel.Label)
+			}
+			if !slices.Equal(relationships, tc.relationships) {
+				t.Errorf("Expected the relationships %v, got %v", tc.relationships, relationships)
+			}
+
+			if entities := entityNames(model.Entities); !slices.Equal(entities, tc.entities) {
+				t.Errorf("Expected the entities %v, got %v", tc.entities, entities)
+			}
+		})
+	}
+}
+
+func TestParseEdgeKind(t *testing.T) {
+	for name, expected := range map[string]EdgeKind{"o2o": EdgeO2O, "o2m": EdgeO2M, "m2o": EdgeO2M, "m2m": EdgeM2M, "many": ""} {
+		if kind, ok := ParseEdgeKind(name); kind != expected || ok != (expected != "") {
+			t.Errorf("Expected %s to be the %q kind, got %q", name, expected, kind)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"strings"
+
+	"entgo.io/ent/entc/gen"
+)
+
+// WithCompactEdgeSchemas draws the M2M edges through an edge schema as a direct relationship between their two
+// entities, labeled with the fields the edge schema carries (e.g. 'groups-users (role, joined_at)'), instead of
+// drawing the edge schema as an entity of its own between them.
+func WithCompactEdgeSchemas() Option {
+	return func(o *options) {
+		o.compactEdges = true
+	}
+}
+
+// newEdgeSchemaRelationship returns the direct relationship of the M2M edge through an edge schema.
+func newEdgeSchemaRelationship(node *gen.Type, edge *gen.Edge) *Relationship {
+	label := edge.Name + getEdgeRefName(edge.Ref)
+	if fields := edgeSchemaFields(edge.Through); len(fields) > 0 {
+		label += " (" + strings.Join(fields, ", ") + ")"
+	}
+
+	rel := &Relationship{
+		From:            entityName(node),
+		To:              entityName(edge.Type),
+		FromCardinality: ZeroOrMore,
+		ToCardinality:   ZeroOrMore,
+		Label:           label,
+		Edge:            edge.Name,
+		kind:            EdgeM2M,
+	}
+
+	if edge.Ref != nil {
+		rel.Ref = edge.Ref.Name
+	}
+
+	return rel
+}
+
+// edgeSchemaFields returns the names of the fields the edge schema carries besides its edge fields and its ID.
+func edgeSchemaFields(through *gen.Type) []string {
+	var fields []string
+	for _, field := range through.Fields {
+		if !field.IsEdgeField() {
+			fields = append(fields, field.Name)
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestEdgeSchemas(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		opts     []Option
+		drawn    []string
+		notDrawn []string
+	}{
+		{
+			name:     "between",
+			drawn:    []string{" Membership {\n  string role", " Membership }o--|| User : user\n", " Membership }o--|| Group : group\n"},
+			notDrawn: []string{" memberships {", "o--o{"},
+		},
+		{
+			name:     "compact",
+			opts:     []Option{WithCompactEdgeSchemas()},
+			drawn:    []string{" User }o--o{ Group : \"groups-users (role)\"\n"},
+			notDrawn: []string{"Membership", "memberships"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			o := newOptions(tc.opts)
+
+			model, err := loadModel(context.Background(), "../examples/edgeschema/schema", o)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var builder strings.Builder
+			if err := renderMermaid(context.Background(), &builder, model, o); err != nil {
+				t.Fatal(err)
+			}
+
+			diagram := builder.String()
+			for _, expected := range tc.drawn {
+				if !strings.Contains(diagram, expected) {
+					t.Errorf("The diagram is missing %q:\n%s", expected, diagram)
+				}
+			}
+			for _, unexpected := range tc.notDrawn {
+				if strings.Contains(diagram, unexpected) {
+					t.Errorf("The diagram shouldn't contain %q:\n%s", unexpected, diagram)
+				}
+			}
+		})
+	}
+}
@@ -1,190 +1,441 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
+	"path/filepath"
 	"strings"
-
-	"entgo.io/ent/entc"
-	"entgo.io/ent/entc/gen"
+	"sync"
 )
 
-func GenerateDiagram(schemaPath string, targetPath string, outputType OutputType, startPattern string, endPattern string) error {
-	graph, err := entc.LoadGraph(schemaPath, &gen.Config{})
-	if err != nil {
-		return fmt.Errorf("failed to load schema graph from the path %s: %v", schemaPath, err)
+func GenerateDiagram(schemaPath string, targetPath string, outputType OutputType, startPattern string, endPattern string, opts ...Option) error {
+	return GenerateDiagramContext(context.Background(), schemaPath, targetPath, outputType, startPattern, endPattern, opts...)
+}
+
+// GenerateDiagramContext is GenerateDiagram but stops loading, rendering or writing once the context is done.
+func GenerateDiagramContext(ctx context.Context, schemaPath string, targetPath string, outputType OutputType, startPattern string, endPattern string, opts ...Option) (err error) {
+	o := newOptions(opts)
+	if o.rawOutput && targetPath != "" && targetPath != StdioTarget {
+		o.outputs = append(o.outputs, output{format: FormatMermaid, path: rawPath(targetPath)})
+	}
+
+	var model *Model
+	if o.resultJSON != nil {
+		defer func() {
+			err = errors.Join(err, writeResultJSON(o.resultJSON, newRunReport(model, err, o)))
+		}()
+	}
+
+	// Nothing is loaded when nothing changed since the last run.
+	var state *runState
+	if o.skipUnchanged {
+		state = newRunState(append([]string{schemaPath}, o.schemaPaths...), targetPath, o)
+		if state != nil && state.unchanged(o) {
+			o.logger.Info("skipping the run, nothing changed since the last one", "state", state.path)
+			printOutcome(OutcomeUnchanged, true, o)
+
+			return nil
+		}
+	}
+
+	if o.reportPath != "" {
+		defer func() {
+			err = errors.Join(err, writeReport(o.reportPath, newRunReport(model, err, o)))
+		}()
 	}
-	// Generate the Mermaid code for the ERD diagram
-	mermaidCode, err := generateMermaidCode(graph)
+
+	defaultStart, defaultEnd := DefaultPatterns(targetPath)
+	if startPattern == "" {
+		startPattern = defaultStart
+	}
+	if endPattern == "" {
+		endPattern = defaultEnd
+	}
+
+	model, err = loadModel(ctx, schemaPath, o)
 	if err != nil {
 		return err
 	}
 
-	mermaidCode = addMermaidToType(mermaidCode, outputType)
+	// The Mermaid outputs are written from the render of the target, so they're identical to its diagram.
+	render := sync.OnceValues(func() (*targetRender, error) {
+		return renderTarget(ctx, model, o)
+	})
 
-	err = insertMultiLineString(targetPath, mermaidCode, startPattern, endPattern)
-	if err != nil {
-		return fmt.Errorf("failed to insert Mermaid code into the file: %v", err)
+	jobs := make([]func(context.Context) error, 0, len(o.outputs)+4)
+	if targetPath != "" {
+		jobs = append(jobs, func(ctx context.Context) error {
+			render, err := render()
+			if err != nil {
+				return err
+			}
+
+			return placeTarget(ctx, model, render, targetPath, outputType, startPattern, endPattern, o)
+		})
+	}
+
+	for _, out := range o.outputs {
+		jobs = append(jobs, func(ctx context.Context) error {
+			if out.format != FormatMermaid || targetPath == "" {
+				return writeOutput(ctx, model, out, o)
+			}
+
+			return writeRawTarget(ctx, model, render, out, o)
+		})
 	}
 
-	fmt.Println("Mermaid file generated successfully.")
+	if o.confluencePage != nil {
+		jobs = append(jobs, func(ctx context.Context) error {
+			return pushConfluence(ctx, model, o)
+		})
+	}
+
+	if o.notionPage != nil {
+		jobs = append(jobs, func(ctx context.Context) error {
+			return pushNotion(ctx, model, o)
+		})
+	}
+
+	if o.prCommentPath != "" {
+		jobs = append(jobs, func(ctx context.Context) error {
+			return writePRComment(ctx, model, targetPath, startPattern, endPattern, o)
+		})
+	}
+
+	if o.goldenPath != "" {
+		jobs = append(jobs, func(ctx context.Context) error {
+			return compareGolden(ctx, model, o)
+		})
+	}
+
+	if err := runConcurrently(ctx, jobs); err != nil {
+		return err
+	}
+
+	if o.gitAdd && len(o.modified) > 0 {
+		if err := gitAdd(ctx, o.modified); err != nil {
+			return err
+		}
+
+		o.logger.Info("staged modified files", "files", o.modified)
+	}
+
+	if state != nil {
+		state.record(o)
+	}
+
+	// The document written to stdout can't be mixed with the success message.
+	if o.check {
+		printOutcome(OutcomeUpToDate, true, o)
+	} else {
+		printOutcome(OutcomeGenerated, targetPath != StdioTarget, o)
+	}
 
 	return nil
 }
 
-// generateMermaidCode generates the Mermaid code for the ERD diagram based on the schema graph.
-func generateMermaidCode(graph *gen.Graph) (string, error) {
-	var builder strings.Builder
-
-	builder.WriteString("erDiagram\n")
+// loadModel loads the schema graphs, or the snapshot of WithGraphSnapshot or WithEntSnapshot, and builds the model of the diagram from them.
+func loadModel(ctx context.Context, schemaPath string, o *options) (*Model, error) {
+	schemaPaths := append([]string{schemaPath}, o.schemaPaths...)
+
+	// A snapshot of the model stands for the schemas, along with the header of the schemas it was written from.
+	var models []*Model
+	var snapshotHeader string
+	if o.graphSnapshot != "" {
+		snapshot, err := readGraphSnapshot(o.graphSnapshot, o)
+		if err != nil {
+			return nil, err
+		}
+		schemaPaths, models, snapshotHeader = []string{o.graphSnapshot}, []*Model{snapshot}, snapshot.Header
+	} else if o.entSnapshot != "" {
+		model, err := loadEntSnapshot(ctx, o.entSnapshot, o)
+		if err != nil {
+			return nil, err
+		}
+		schemaPaths, models = []string{o.entSnapshot}, []*Model{model}
+	} else {
+		var err error
+		if models, err = loadSchemaModels(ctx, schemaPaths, o); err != nil {
+			return nil, err
+		}
+	}
 
-	for _, node := range graph.Nodes {
-		builder.WriteString(fmt.Sprintf(" %s {\n", node.Name))
+	model := mergeModels(schemaPaths, models, o)
+	describeDiagram(model, o)
 
-		if node.HasOneFieldID() {
-			builder.WriteString(fmt.Sprintf("  %s %s PK\n", formatType(node.ID.Type.String()), node.ID.Name))
+	if o.ignoreFile != "" {
+		patterns, err := readIgnoreFile(o.ignoreFile)
+		if err != nil {
+			return nil, err
 		}
 
-		for _, field := range node.Fields {
-			builder.WriteString(fmt.Sprintf("  %s %s\n", formatType(field.Type.String()), field.Name))
+		model = ignoreModel(model, patterns, o)
+	}
+
+	if o.visibility != VisibilityPrivate {
+		model = visibleModel(model, o)
+	}
+
+	if len(o.entities) > 0 {
+		for _, name := range o.entities {
+			if model.Entity(name) == nil {
+				o.warn(name, "", "is not an entity of the schema")
+			}
 		}
 
-		for _, foreignKey := range node.ForeignKeys {
-			// For now we don't support user defined foreign keys as need to test them out more.
-			// Will add support for them in the future and focus on the ent generated ones.
-			if foreignKey.UserDefined {
-				continue
+		filtered := filterEntities(model, o.entities)
+		for _, entity := range model.Entities {
+			if filtered.Entity(entity.Name) == nil {
+				o.skipEntity(entity.Name, "not drawn with --entity")
 			}
+		}
+		model = filtered
+	}
 
-			builder.WriteString(fmt.Sprintf("  %s %s FK\n", formatType(foreignKey.Field.Type.String()), foreignKey.Field.Name))
+	if o.changedSince != "" {
+		files, err := changedFiles(ctx, o.changedSince)
+		if err != nil {
+			return nil, err
 		}
+		model = changedModel(model, files, o)
+	}
 
-		builder.WriteString(" }\n\n")
+	if len(o.onlyEdges) > 0 || len(o.noEdges) > 0 {
+		filterEdgeKinds(model, o)
+	}
 
-		for _, edge := range node.Edges {
-			// Ent handles M2M relationships in a way that we can't easily generate an accurate ERD with it.
-			// SO we attempt to extract out the actual M2M table to properly display it.
-			if edge.M2M() {
-				// We need to map the relationship between both base tables, but only create the table once.
-				if !edge.IsInverse() {
-					rel := edge.Rel
-					builder.WriteString(fmt.Sprintf(" %s {\n", rel.Table))
+	if len(o.joinTableNames) > 0 || o.shortJoinTables {
+		renameJoinTables(model, o)
+	}
 
-					for _, column := range rel.Columns {
-						builder.WriteString(fmt.Sprintf("  %s %s PK,FK\n", "int", column))
-					}
+	if o.header && o.graphSnapshot != "" {
+		model.Header = snapshotHeader
+	} else if o.header {
+		var err error
+		model.Header, err = generateHeader(schemaPaths, o.headerTimestamp, o.reproducible)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-					builder.WriteString(" }\n\n")
-				}
-			}
+	if o.linkTemplate != "" {
+		if err := addLinks(ctx, model, o); err != nil {
+			return nil, err
 		}
 	}
 
-	for _, node := range graph.Nodes {
-		for _, edge := range node.Edges {
-			// Need to handle M2M relationships a bit more special.
-			if edge.M2M() {
-				builder.WriteString(fmt.Sprintf(" %s %s %s : %s%s\n", node.Name, "|o--o{", edge.Rel.Table, edge.Name, getEdgeRefName(edge.Ref)))
-				continue
-			}
+	if o.weightBy != WeightNone {
+		weighRelationships(model, o.weightBy)
+	}
 
-			if edge.IsInverse() {
-				continue
-			}
+	if len(o.edgeLabels) > 0 || o.verbLabels || o.forwardLabels {
+		labelRelationships(model, o)
+	}
+	if len(o.displayNames) > 0 {
+		displayEntities(model, o)
+	}
+	if len(o.entityLinks) > 0 {
+		linkEntities(model, o)
+	}
+	if o.locale != nil {
+		localizeModel(model, o.locale, o)
+	}
 
-			_, err := builder.WriteString(fmt.Sprintf(" %s %s %s : %s%s\n", node.Name, getEdgeRelationship(edge), edge.Type.Name, edge.Name, getEdgeRefName(edge.Ref)))
-			if err != nil {
-				return "", fmt.Errorf("failed to write string: %v", err)
-			}
+	if len(o.modelHooks) > 0 {
+		if err := runModelHooks(model, o); err != nil {
+			return nil, err
 		}
 	}
 
-	return builder.String(), nil
-}
+	if o.redaction != nil {
+		model = redactModel(model, o.redaction, o)
+	}
 
-func addMermaidToType(mermaidCode string, outputType OutputType) string {
-	switch outputType {
-	case Markdown:
-		return fmt.Sprintf("```mermaid\n%s\n```", mermaidCode)
-	case Plain:
-		return mermaidCode
-	default:
-		return mermaidCode
+	if o.analyze {
+		analyzeModel(model, o)
+	}
+	if len(o.lintRules) > 0 {
+		lintModel(model, o)
+	}
+	if o.pruneOrphans {
+		pruneOrphanEntities(model, o)
 	}
+	if o.stubHubs > 0 {
+		stubHubEntities(model, o)
+	}
+	if o.warnEntities > 0 || o.failEntities > 0 {
+		if err := checkEntityLimits(model, o); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.strict && len(o.warnings) > 0 {
+		return nil, fmt.Errorf("%w: %d warnings while generating the diagram", ErrWarnings, len(o.warnings))
+	}
+
+	return model, nil
 }
 
-func formatType(s string) string {
-	ls := strings.ToLower(s)
-	switch ls {
-	case "time.time":
-		return "timestamp"
+// loadSchemaModels loads the model of each schema, resolving the import paths given for them in place.
+func loadSchemaModels(ctx context.Context, schemaPaths []string, o *options) ([]*Model, error) {
+	models := make([]*Model, 0, len(schemaPaths))
+	for i, schemaPath := range schemaPaths {
+		dir, err := resolveSchemaPath(schemaPath)
+		if err != nil {
+			return nil, fmt.Errorf("%w from the path %s: %w", ErrSchemaLoad, schemaPath, err)
+		}
+
+		if dir != schemaPath {
+			o.logger.Info("resolved schema import path", "schema", schemaPath, "dir", dir)
+			schemaPaths[i] = dir
+		}
+
+		o.logger.Info("loading schema graph", "schema", dir)
 
-	case "time.duration":
-		return "duration"
+		graph, err := loadGraph(ctx, dir, o)
+		if err != nil {
+			return nil, fmt.Errorf("%w from the path %s: %w", ErrSchemaLoad, schemaPath, err)
+		}
 
-	case "map[string]interface {}", "map[string]interface{}", "map[string]any":
-		return "jsonb"
+		o.logger.Info("loaded schema graph", "nodes", len(graph.Nodes))
 
-	default:
-		if strings.Contains(ls, "inet") {
-			return "inet"
-		} else {
-			return strings.ReplaceAll(s, ".", "-")
+		model, err := buildModel(ctx, graph, o)
+		if err != nil {
+			return nil, err
+		}
+
+		if o.domainSource == DomainPackage {
+			packageDomains(model, dir)
+		}
+
+		if o.mixins {
+			if err := resolveMixins(model, dir); err != nil {
+				return nil, fmt.Errorf("%w from the path %s: %w", ErrSchemaLoad, schemaPath, err)
+			}
+		}
+
+		if o.expandJSON {
+			if err := expandJSONFields(model, dir, o); err != nil {
+				return nil, fmt.Errorf("%w from the path %s: %w", ErrSchemaLoad, schemaPath, err)
+			}
 		}
+
+		models = append(models, model)
 	}
+
+	return models, nil
 }
 
-func getEdgeRelationship(edge *gen.Edge) string {
-	if edge.O2M() {
-		return "|o--o{"
-	}
+// targetRender is the Mermaid code of the diagrams of the target, one per page of a paginated diagram.
+type targetRender struct {
+	pages    []*Model
+	diagrams []string
+}
 
-	if edge.M2O() {
-		return "}o--o|"
+// renderTarget renders the model as the Mermaid diagrams of the target, split in pages when it's too large for
+// renderers.
+func renderTarget(ctx context.Context, model *Model, o *options) (*targetRender, error) {
+	pages := []*Model{model}
+	if o.pageSize > 0 && len(model.Entities) > o.pageSize {
+		pages = paginateModel(model, o.pageSize)
 	}
 
-	if edge.M2M() {
-		return "}o--o{"
+	render := &targetRender{pages: pages, diagrams: make([]string, len(pages))}
+	for i, page := range pages {
+		diagram, err := renderBudgeted(ctx, page, o)
+		if err != nil {
+			return nil, err
+		}
+		render.diagrams[i] = diagram
 	}
 
-	return "|o--o|"
+	return render, nil
 }
 
-func getEdgeRefName(ref *gen.Edge) string {
-	if ref == nil {
-		return ""
+// writeTarget renders the model as Mermaid and places it between the patterns in the target file, or compares it
+// to what's already there in check mode.
+func writeTarget(ctx context.Context, model *Model, targetPath string, outputType OutputType, startPattern string, endPattern string, o *options) error {
+	render, err := renderTarget(ctx, model, o)
+	if err != nil {
+		return err
 	}
 
-	return fmt.Sprintf("-%s", ref.Name)
+	return placeTarget(ctx, model, render, targetPath, outputType, startPattern, endPattern, o)
 }
 
-func insertMultiLineString(filePath string, multiLineString string, startPattern string, endPattern string) error {
-	// Read the content of the file
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return err
+// placeTarget places the rendered diagrams of the model between the patterns in the target file, or compares them
+// to what's already there in check mode.
+func placeTarget(ctx context.Context, model *Model, render *targetRender, targetPath string, outputType OutputType, startPattern string, endPattern string, o *options) error {
+	// Anything but a doc comment placed between the comment markers of a Go file breaks its build.
+	if strings.EqualFold(filepath.Ext(targetPath), ".go") && outputType != GoDoc {
+		return inFile(targetPath, fmt.Errorf("%w: a Go target only holds the diagram as a doc comment, use the godoc output type instead of %s",
+			ErrTarget, OutputTypeIds[outputType][0]))
 	}
 
-	// Convert the content to a string
-	fileContent := string(content)
+	pages := render.pages
+	diagrams := make([]string, len(pages))
+	for i, page := range pages {
+		diagram, err := fenceMermaid(render.diagrams[i], outputType, o)
+		if err != nil {
+			return err
+		}
 
-	// Find the starting and ending strings
-	startIndex := strings.Index(fileContent, startPattern)
-	endIndex := strings.Index(fileContent, endPattern)
+		if len(pages) > 1 && outputType.isMarkdown() {
+			diagram = fmt.Sprintf("**Diagram %d of %d**\n\n%s", page.page, page.pages, diagram)
+		}
+		if o.index {
+			diagram = fmt.Sprintf("<a id=\"%s\"></a>\n\n%s", diagramAnchor(i+1), diagram)
+		}
+		diagrams[i] = diagram
+	}
+
+	mermaidCode := addSummary(strings.Join(diagrams, "\n\n"), model, outputType, o)
 
-	// Check if the starting and ending strings are found
-	if startIndex == -1 || endIndex == -1 {
-		return fmt.Errorf("starting (%s) or ending (%s) string not found in the file", startPattern, endPattern)
+	if o.legend {
+		mermaidCode += renderLegend(model, outputType, o)
+	}
+	if outputType.isMarkdown() {
+		mermaidCode += markdownLinks(model)
+	}
+	if outputType == GoDoc {
+		mermaidCode = goDocComment(mermaidCode)
 	}
 
-	// Construct the updated content with the generated multi-line string
-	updatedContent := fileContent[:startIndex+len(startPattern)+1] + multiLineString + "\n" + fileContent[endIndex:]
+	// The index is placed first, so the markers created for it are above the diagram.
+	if o.index {
+		if err := writeIndex(ctx, pages, targetPath, o); err != nil {
+			return err
+		}
+	}
 
-	// Write the updated content back to the file
-	err = os.WriteFile(filePath, []byte(updatedContent), 0o644)
-	if err != nil {
+	if err := ctx.Err(); err != nil {
 		return err
 	}
 
+	if o.check {
+		line, err := checkMultiLineString(targetPath, mermaidCode, startPattern, endPattern, o)
+		if err != nil {
+			return fmt.Errorf("failed to check Mermaid code in the file: %w", err)
+		}
+
+		if line > 0 {
+			err := &FileError{Path: targetPath, Line: line, Err: fmt.Errorf("the Mermaid %w in %s", ErrStaleDiagram, targetPath)}
+			if o.webhookURL != "" {
+				return errors.Join(err, notifyShapeChanges(ctx, targetPath, mermaidCode, startPattern, endPattern, o))
+			}
+
+			return err
+		}
+
+		return nil
+	}
+
+	if err := insertMultiLineString(targetPath, mermaidCode, startPattern, endPattern, o); err != nil {
+		return fmt.Errorf("failed to insert Mermaid code into the file: %w", err)
+	}
+
+	o.logger.Info("wrote diagram", "target", targetPath, "bytes", len(mermaidCode))
+
 	return nil
 }
@@ -35,6 +35,69 @@ func TestGenerateDiagram(t *testing.T) {
 			startPattern:   defaultStartPattern,
 			endPattern:     defaultEndPattern,
 		},
+		{
+			schemaPath:     "../examples/edgefield/schema",
+			targetPath:     "../examples/edgefield/readme.md",
+			expectedOutput: "../examples/edgefield/readme-expected.md",
+			startPattern:   defaultStartPattern,
+			endPattern:     defaultEndPattern,
+		},
+		{
+			schemaPath:     "../examples/edgeschema/schema",
+			targetPath:     "../examples/edgeschema/readme.md",
+			expectedOutput: "../examples/edgeschema/readme-expected.md",
+			startPattern:   defaultStartPattern,
+			endPattern:     defaultEndPattern,
+		},
+		{
+			schemaPath:     "../examples/customtypes/schema",
+			targetPath:     "../examples/customtypes/readme.md",
+			expectedOutput: "../examples/customtypes/readme-expected.md",
+			startPattern:   defaultStartPattern,
+			endPattern:     defaultEndPattern,
+		},
+		{
+			schemaPath:     "../examples/multischema/schema",
+			targetPath:     "../examples/multischema/readme.md",
+			expectedOutput: "../examples/multischema/readme-expected.md",
+			startPattern:   defaultStartPattern,
+			endPattern:     defaultEndPattern,
+		},
+		{
+			schemaPath:     "../examples/views/schema",
+			targetPath:     "../examples/views/readme.md",
+			expectedOutput: "../examples/views/readme-expected.md",
+			startPattern:   defaultStartPattern,
+			endPattern:     defaultEndPattern,
+		},
+		{
+			schemaPath:     "../examples/storagekey/schema",
+			targetPath:     "../examples/storagekey/readme.md",
+			expectedOutput: "../examples/storagekey/readme-expected.md",
+			startPattern:   defaultStartPattern,
+			endPattern:     defaultEndPattern,
+		},
+		{
+			schemaPath:     "../examples/ids/schema",
+			targetPath:     "../examples/ids/readme.md",
+			expectedOutput: "../examples/ids/readme-expected.md",
+			startPattern:   defaultStartPattern,
+			endPattern:     defaultEndPattern,
+		},
+		{
+			schemaPath:     "../examples/annotations/schema",
+			targetPath:     "../examples/annotations/readme.md",
+			expectedOutput: "../examples/annotations/readme-expected.md",
+			startPattern:   defaultStartPattern,
+			endPattern:     defaultEndPattern,
+		},
+		{
+			schemaPath:     "../examples/multiedge/schema",
+			targetPath:     "../examples/multiedge/readme.md",
+			expectedOutput: "../examples/multiedge/readme-expected.md",
+			startPattern:   defaultStartPattern,
+			endPattern:     defaultEndPattern,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"entgo.io/ent/entc/gen"
+)
+
+// WithEntSnapshot draws the schemas of the snapshot written by ent's schema/snapshot feature at path instead of
+// loading the schema package, so the docs builds of the repos vendoring the snapshot but not the schema draw it.
+// The path is either the generated Go file (e.g. ent/internal/schema.go) or the JSON it holds. The options reading
+// the schema files (WithMixins, WithExpandJSON and the package domains) have nothing to read and are skipped.
+func WithEntSnapshot(path string) Option {
+	return func(o *options) {
+		o.entSnapshot = path
+	}
+}
+
+// loadEntSnapshot builds the model of the schemas of the ent snapshot, with the features it was generated with.
+func loadEntSnapshot(ctx context.Context, snapshotPath string, o *options) (*Model, error) {
+	snapshot, err := readEntSnapshot(snapshotPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w from the ent snapshot %s: %w", ErrSchemaLoad, snapshotPath, err)
+	}
+
+	if o.mixins || o.expandJSON || o.domainSource == DomainPackage {
+		o.warn("", "", "the ent snapshot %s doesn't hold the schema files, the mixins, JSON fields and package domains aren't drawn", snapshotPath)
+	}
+
+	cfg := &gen.Config{}
+	if o.genConfig != nil {
+		copied := *o.genConfig
+		cfg = &copied
+	}
+
+	for _, name := range snapshot.Features {
+		for _, feature := range gen.AllFeatures {
+			if feature.Name == name && !slices.ContainsFunc(cfg.Features, func(f gen.Feature) bool { return f.Name == name }) {
+				cfg.Features = append(cfg.Features, feature)
+			}
+		}
+	}
+
+	for _, opt := range o.entcOptions {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg.Schema = snapshot.Schema
+	if cfg.Package == "" {
+		cfg.Package = snapshot.Package
+		if cfg.Package == "" {
+			cfg.Package = path.Dir(snapshot.Schema)
+		}
+	}
+	if cfg.Target == "" {
+		// The snapshot is generated in the internal package of the target.
+		abs, err := filepath.Abs(snapshotPath)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Target = filepath.Dir(filepath.Dir(abs))
+	}
+
+	graph, err := gen.NewGraph(cfg, snapshot.Schemas...)
+	if err != nil {
+		return nil, fmt.Errorf("%w from the ent snapshot %s: %w", ErrSchemaLoad, snapshotPath, err)
+	}
+
+	o.logger.Info("loaded ent snapshot", "snapshot", snapshotPath, "nodes", len(graph.Nodes))
+
+	return buildModel(ctx, graph, o)
+}
+
+// readEntSnapshot reads the ent snapshot, from the Schema constant of a Go file or from the JSON file.
+func readEntSnapshot(snapshotPath string) (*gen.Snapshot, error) {
+	content, err := os.ReadFile(snapshotPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(filepath.Ext(snapshotPath), ".go") {
+		if content, err = snapshotConstant(snapshotPath, content); err != nil {
+			return nil, err
+		}
+	}
+
+	snapshot := &gen.Snapshot{}
+	if err := json.Unmarshal(content, snapshot); err != nil {
+		return nil, err
+	}
+
+	if len(snapshot.Schemas) == 0 {
+		return nil, errors.New("the snapshot holds no schema")
+	}
+
+	return snapshot, nil
+}
+
+// snapshotConstant returns the value of the Schema string constant the snapshot is generated as.
+func snapshotConstant(snapshotPath string, src []byte) ([]byte, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), snapshotPath, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, decl := range file.Decls {
+		decl, ok := decl.(*ast.GenDecl)
+		if !ok || decl.Tok != token.CONST {
+			continue
+		}
+
+		for _, spec := range decl.Specs {
+			value := spec.(*ast.ValueSpec)
+			for i, name := range value.Names {
+				if name.Name != "Schema" || i >= len(value.Values) {
+					continue
+				}
+
+				lit, ok := value.Values[i].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					return nil, errors.New("the Schema constant isn't a string literal")
+				}
+
+				unquoted, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					return nil, err
+				}
+
+				return []byte(unquoted), nil
+			}
+		}
+	}
+
+	return nil, errors.New("no Schema constant, expected the internal/schema.go of the schema/snapshot feature")
+}
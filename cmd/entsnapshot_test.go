@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"entgo.io/ent/entc"
+	"entgo.io/ent/entc/gen"
+)
+
+func TestGenerateDiagramEntSnapshot(t *testing.T) {
+	graph, err := entc.LoadGraph("../examples/start/schema", &gen.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	quoted, err := graph.SchemaSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := strconv.Unquote(quoted)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	goPath, jsonPath := filepath.Join(dir, "internal", "schema.go"), filepath.Join(dir, "snapshot.json")
+	if err := os.MkdirAll(filepath.Dir(goPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(goPath, []byte("// Code generated by ent, DO NOT EDIT.\n\n//go:build tools\n\npackage internal\n\nconst Schema = "+quoted+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(jsonPath, []byte(snapshot), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mermaidPath := filepath.Join(dir, "erd.mmd")
+	if err := GenerateDiagram("../examples/start/schema", "", Plain, "", "", WithQuiet(), WithOutput(FormatMermaid, mermaidPath)); err != nil {
+		t.Fatalf("Failed to draw the schema: %v", err)
+	}
+	expected, err := os.ReadFile(mermaidPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, snapshotPath := range []string{goPath, jsonPath} {
+		// The schema isn't loaded at all, the missing path proves it.
+		snapshotMermaidPath := filepath.Join(dir, "snapshot.mmd")
+		err = GenerateDiagram("../examples/missing/schema", "", Plain, "", "", WithQuiet(), WithEntSnapshot(snapshotPath), WithOutput(FormatMermaid, snapshotMermaidPath))
+		if err != nil {
+			t.Fatalf("Failed to draw the snapshot %s: %v", snapshotPath, err)
+		}
+
+		drawn, err := os.ReadFile(snapshotMermaidPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(drawn) != string(expected) {
+			t.Errorf("Expected the snapshot %s to be drawn as its schema:\n%s\nexpected:\n%s", snapshotPath, drawn, expected)
+		}
+	}
+
+	notSnapshot := filepath.Join(dir, "doc.go")
+	if err := os.WriteFile(notSnapshot, []byte("package internal\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, snapshotPath := range []string{notSnapshot, filepath.Join(dir, "missing.json")} {
+		err = GenerateDiagram("../examples/missing/schema", "", Plain, "", "", WithQuiet(), WithEntSnapshot(snapshotPath))
+		if !errors.Is(err, ErrSchemaLoad) {
+			t.Errorf("Expected the snapshot %s to fail loading, got %v", snapshotPath, err)
+		}
+	}
+}
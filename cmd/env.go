@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// EnvPrefix prefixes the environment variables setting the flags, e.g. ENTMAID_OUTPUT_TYPE for --outputType and
+// ENTMAID_PAGE_SIZE for --page-size.
+const EnvPrefix = "ENTMAID_"
+
+// envName returns the environment variable of the flag.
+func envName(flag string) string {
+	var builder strings.Builder
+	builder.WriteString(EnvPrefix)
+
+	for i, r := range flag {
+		switch {
+		case r == '-':
+			builder.WriteRune('_')
+		case r >= 'A' && r <= 'Z' && i > 0:
+			builder.WriteRune('_')
+			builder.WriteRune(r)
+		default:
+			builder.WriteString(strings.ToUpper(string(r)))
+		}
+	}
+
+	return builder.String()
+}
+
+// applyEnv sets the flags that weren't given on the command line from their environment variable, so the flags take
+// precedence over the environment. The values of the flags that can be repeated are given one per line, the
+// comma-separated ones (e.g. --schema) as they would be on the command line.
+func applyEnv(flags *pflag.FlagSet, lookup func(string) (string, bool)) error {
+	var err error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || flag.Name == "help" {
+			return
+		}
+
+		name := envName(flag.Name)
+		value, ok := lookup(name)
+		if !ok {
+			return
+		}
+
+		values := []string{value}
+		if flag.Value.Type() == "stringArray" {
+			values = strings.FieldsFunc(value, func(r rune) bool { return r == '\n' || r == '\r' })
+		}
+
+		for _, value := range values {
+			if setErr := flags.Set(flag.Name, value); setErr != nil {
+				err = fmt.Errorf("%w: invalid value %q of $%s: %w", errUsage, value, name, setErr)
+				return
+			}
+		}
+	})
+
+	return err
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestEnvName(t *testing.T) {
+	for flag, expected := range map[string]string{
+		"page-size":  "ENTMAID_PAGE_SIZE",
+		"outputType": "ENTMAID_OUTPUT_TYPE",
+		"schema":     "ENTMAID_SCHEMA",
+	} {
+		if got := envName(flag); got != expected {
+			t.Errorf("envName(%q) = %q, expected %q", flag, got, expected)
+		}
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	flags := pflag.NewFlagSet("entmaid", pflag.ContinueOnError)
+	pageSize := flags.Int("page-size", 0, "")
+	title := flags.String("title", "", "")
+	schemas := flags.StringSlice("schema", nil, "")
+	outputs := flags.StringArray("output", nil, "")
+
+	if err := flags.Parse([]string{"--title", "from the flag"}); err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{
+		"ENTMAID_PAGE_SIZE": "40",
+		"ENTMAID_TITLE":     "from the environment",
+		"ENTMAID_SCHEMA":    "./ent/schema,./other/schema",
+		"ENTMAID_OUTPUT":    "dbml=docs/erd.dbml\njson=docs/erd.json\n",
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	if err := applyEnv(flags, lookup); err != nil {
+		t.Fatal(err)
+	}
+
+	if *pageSize != 40 {
+		t.Errorf("Expected the page size from the environment, got %d", *pageSize)
+	}
+	if *title != "from the flag" {
+		t.Errorf("Expected the flag to take precedence over the environment, got %q", *title)
+	}
+	if expected := []string{"./ent/schema", "./other/schema"}; !reflect.DeepEqual(*schemas, expected) {
+		t.Errorf("Got the schemas %q, expected %q", *schemas, expected)
+	}
+	if expected := []string{"dbml=docs/erd.dbml", "json=docs/erd.json"}; !reflect.DeepEqual(*outputs, expected) {
+		t.Errorf("Got the outputs %q, expected %q", *outputs, expected)
+	}
+
+	env["ENTMAID_PAGE_SIZE"] = "many"
+	flags.Lookup("page-size").Changed = false
+	if err := applyEnv(flags, lookup); !errors.Is(err, errUsage) {
+		t.Errorf("Expected a usage error for an invalid value, got %v", err)
+	}
+}
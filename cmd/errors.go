@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Sentinel errors returned (wrapped) by GenerateDiagram, use errors.Is to branch on the failure mode.
+var (
+	// ErrSchemaLoad is returned when the ent schema graph could not be loaded.
+	ErrSchemaLoad = errors.New("failed to load schema graph")
+	// ErrSchemaHash is returned when the schema files could not be hashed for the header.
+	ErrSchemaHash = errors.New("failed to hash the schema")
+	// ErrRender is returned when the diagram could not be rendered.
+	ErrRender = errors.New("failed to render the diagram")
+	// ErrUnknownFormat is returned when an output is requested in a format that isn't supported.
+	ErrUnknownFormat = errors.New("unknown format")
+	// ErrUnsupportedEdge is returned when an edge lacks the information needed to draw it.
+	ErrUnsupportedEdge = errors.New("unsupported edge")
+	// ErrTarget is returned when the target file could not be read or written.
+	ErrTarget = errors.New("failed to access the target file")
+	// ErrConfluence is returned when the diagram could not be pushed to or compared with the Confluence page.
+	ErrConfluence = errors.New("failed to access the Confluence page")
+	// ErrNotion is returned when the diagram could not be pushed to or compared with the Notion page.
+	ErrNotion = errors.New("failed to access the Notion page")
+	// ErrWebhook is returned when the changes of the diagram could not be posted to the webhook.
+	ErrWebhook = errors.New("failed to notify the webhook")
+	// ErrSelfUpdate is returned when the latest release could not be downloaded, verified or installed.
+	ErrSelfUpdate = errors.New("failed to update entmaid")
+	// ErrMarkerNotFound is returned when the start or end pattern is missing from the target.
+	ErrMarkerNotFound = errors.New("marker not found")
+	// ErrMarkerOrder is returned when the start and end patterns are reversed or nested.
+	ErrMarkerOrder = errors.New("markers out of order")
+	// ErrDuplicateMarkers is returned when the target holds several pairs of patterns but only one was expected.
+	ErrDuplicateMarkers = errors.New("duplicate markers")
+	// ErrMarkerInContent is returned when the generated content holds the start or end pattern, which would corrupt
+	// the target on the next run.
+	ErrMarkerInContent = errors.New("marker found in the generated content")
+	// ErrTooManyEntities is returned when the diagram draws more entities than the limit of WithEntityLimits.
+	ErrTooManyEntities = errors.New("too many entities")
+	// ErrWarnings is returned in strict mode when warnings were raised while generating the diagram.
+	ErrWarnings = errors.New("warnings were raised")
+	// ErrInvalidDiagram is returned when the rendered Mermaid diagram doesn't pass validation.
+	ErrInvalidDiagram = errors.New("invalid Mermaid diagram")
+	// ErrModelHook is returned when a hook of WithModelHook fails.
+	ErrModelHook = errors.New("failed to run the model hook")
+	// ErrStaleDiagram is returned in check mode when the diagram in the target differs from the generated one.
+	ErrStaleDiagram = errors.New("diagram is out of date")
+)
+
+// FileError is an error located in a file, such as a missing marker or a stale diagram in the target.
+// Line is 1-based and 0 when the error isn't tied to a specific line.
+type FileError struct {
+	Path string
+	Line int
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// atLine returns the error located at the line of the offset in the content.
+func atLine(content string, offset int, err error) error {
+	return &FileError{Line: strings.Count(content[:offset], "\n") + 1, Err: err}
+}
+
+// inFile sets the path of the error's location, wrapping it when it isn't located yet.
+func inFile(path string, err error) error {
+	var fileErr *FileError
+	if errors.As(err, &fileErr) {
+		if fileErr.Path == "" {
+			fileErr.Path = path
+		}
+
+		return err
+	}
+
+	return &FileError{Path: path, Err: err}
+}
+
+// Exit codes used by the CLI so scripts can tell the failure modes apart.
+const (
+	ExitOK         = 0
+	ExitError      = 1
+	ExitUsage      = 2
+	ExitStale      = 3
+	ExitSchemaLoad = 4
+	ExitMarker     = 5
+	ExitTarget     = 6
+	ExitRender     = 7
+	ExitWarnings   = 8
+	ExitTimeout    = 9
+	ExitEntities   = 10
+)
+
+// errUsage wraps errors caused by invalid flags or arguments.
+var errUsage = errors.New("invalid usage")
+
+// ExitCode maps an error returned by GenerateDiagram (or the CLI) to the process exit code.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, errUsage), errors.Is(err, ErrUnknownFormat):
+		return ExitUsage
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return ExitTimeout
+	case errors.Is(err, ErrStaleDiagram):
+		return ExitStale
+	case errors.Is(err, ErrSchemaLoad), errors.Is(err, ErrSchemaHash):
+		return ExitSchemaLoad
+	case errors.Is(err, ErrMarkerNotFound), errors.Is(err, ErrMarkerOrder), errors.Is(err, ErrDuplicateMarkers), errors.Is(err, ErrMarkerInContent):
+		return ExitMarker
+	case errors.Is(err, ErrTarget), errors.Is(err, ErrConfluence), errors.Is(err, ErrNotion), errors.Is(err, ErrWebhook):
+		return ExitTarget
+	case errors.Is(err, ErrRender), errors.Is(err, ErrUnsupportedEdge), errors.Is(err, ErrInvalidDiagram):
+		return ExitRender
+	case errors.Is(err, ErrWarnings):
+		return ExitWarnings
+	case errors.Is(err, ErrTooManyEntities):
+		return ExitEntities
+	default:
+		return ExitError
+	}
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected int
+	}{
+		{err: nil, expected: ExitOK},
+		{err: errors.New("boom"), expected: ExitError},
+		{err: fmt.Errorf("%w: unknown flag", errUsage), expected: ExitUsage},
+		{err: fmt.Errorf("check: %w", ErrStaleDiagram), expected: ExitStale},
+		{err: fmt.Errorf("%w: no such dir", ErrSchemaLoad), expected: ExitSchemaLoad},
+		{err: fmt.Errorf("insert: %w", ErrMarkerOrder), expected: ExitMarker},
+		{err: fmt.Errorf("%w: permission denied", ErrTarget), expected: ExitTarget},
+		{err: fmt.Errorf("%w: edge", ErrUnsupportedEdge), expected: ExitRender},
+		{err: fmt.Errorf("%w: 40 entities", ErrTooManyEntities), expected: ExitEntities},
+	}
+
+	for _, tc := range testCases {
+		if code := ExitCode(tc.err); code != tc.expected {
+			t.Errorf("ExitCode(%v) = %d, expected %d", tc.err, code, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// WithTitle titles the diagram, in the front matter of the Mermaid diagram and for the fence templates.
+func WithTitle(title string) Option {
+	return func(o *options) {
+		o.title = title
+	}
+}
+
+// WithDescription describes the diagram in a comment block at its top, e.g. with its scope and audience, so it
+// describes itself when viewed on its own.
+func WithDescription(description string) Option {
+	return func(o *options) {
+		o.description = description
+	}
+}
+
+// describeDiagram titles and describes the diagram as annotated in the schema, unless WithTitle and WithDescription
+// do. The model gets the title and description of the options, for the JSON output.
+func describeDiagram(model *Model, o *options) {
+	if o.title != "" {
+		model.Title = o.title
+	} else {
+		o.title = model.Title
+	}
+
+	if o.description != "" {
+		model.Description = o.description
+	} else {
+		o.description = model.Description
+	}
+}
+
+// WithFence fences the diagram in the target with the prefix and suffix lines instead of the fence of the output
+// type, e.g. ":::mermaid" and ":::" for Azure DevOps wikis. Both are text/template templates which can use
+// {{.Type}} (the name of the output type) and {{.Title}}.
+func WithFence(prefix string, suffix string) Option {
+	return func(o *options) {
+		o.fencePrefix = prefix
+		o.fenceSuffix = suffix
+	}
+}
+
+// fenceData is what the fence templates can use.
+type fenceData struct {
+	Type  string
+	Title string
+}
+
+// fenceMermaid fences the Mermaid code with the templates of WithFence, or as the output type does by default.
+func fenceMermaid(mermaidCode string, outputType OutputType, o *options) (string, error) {
+	if o.fencePrefix == "" && o.fenceSuffix == "" {
+		return addMermaidToType(mermaidCode, outputType), nil
+	}
+
+	data := fenceData{Title: o.title}
+	if ids := OutputTypeIds[outputType]; len(ids) > 0 {
+		data.Type = ids[0]
+	}
+
+	prefix, err := executeFence("prefix", o.fencePrefix, data)
+	if err != nil {
+		return "", err
+	}
+
+	suffix, err := executeFence("suffix", o.fenceSuffix, data)
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + "\n" + mermaidCode + "\n" + suffix, nil
+}
+
+func executeFence(name string, text string, data fenceData) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid fence %s: %w", ErrRender, name, err)
+	}
+
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, data); err != nil {
+		return "", fmt.Errorf("%w: invalid fence %s: %w", ErrRender, name, err)
+	}
+
+	return builder.String(), nil
+}
+
+// mermaidFrontMatter returns the front matter titling the diagram.
+func mermaidFrontMatter(title string) string {
+	// A JSON string is a valid YAML scalar, whatever characters the title has.
+	quoted, _ := json.Marshal(title)
+
+	return fmt.Sprintf("---\ntitle: %s\n---\n", quoted)
+}
+
+// goDocComment turns the diagram into a code block of a Go doc comment, separated from the comment lines of the
+// patterns by empty lines and indented by a tab as gofmt formats them, so formatting the file doesn't change it.
+func goDocComment(diagram string) string {
+	var builder strings.Builder
+	builder.WriteString("//\n")
+	for _, line := range strings.Split(strings.TrimRight(diagram, "\n"), "\n") {
+		if line = strings.TrimRight(line, " \t"); line == "" {
+			builder.WriteString("//\n")
+		} else {
+			builder.WriteString("//\t" + line + "\n")
+		}
+	}
+	builder.WriteString("//")
+
+	return builder.String()
+}
@@ -0,0 +1,74 @@
+package cmd
+
+// WithEntities only draws the named entities and the relationships between them. The join tables of M2M edges are
+// drawn when the entities on both of their sides are.
+func WithEntities(names ...string) Option {
+	return func(o *options) {
+		o.entities = append(o.entities, names...)
+	}
+}
+
+// filterEntities returns the model with only the named entities, their join tables and the relationships between
+// them.
+func filterEntities(model *Model, names []string) *Model {
+	selected := make(map[string]bool, len(names))
+	for _, name := range names {
+		selected[name] = true
+	}
+
+	// A join table is only drawn when everything it's related to is.
+	joinTables := make(map[string]bool)
+	for _, entity := range model.Entities {
+		if entity.JoinTable {
+			joinTables[entity.Name] = true
+		}
+	}
+
+	for _, rel := range model.Relationships {
+		switch {
+		case joinTables[rel.To] && !selected[rel.From]:
+			joinTables[rel.To] = false
+		case joinTables[rel.From] && !selected[rel.To]:
+			joinTables[rel.From] = false
+		}
+	}
+
+	filtered := &Model{Header: model.Header}
+	for _, entity := range model.Entities {
+		if selected[entity.Name] || joinTables[entity.Name] {
+			filtered.Entities = append(filtered.Entities, entity)
+		}
+	}
+
+	kept := func(name string) bool {
+		return selected[name] || joinTables[name]
+	}
+
+	for _, rel := range model.Relationships {
+		if kept(rel.From) && kept(rel.To) {
+			filtered.Relationships = append(filtered.Relationships, rel)
+		}
+	}
+
+	return filtered
+}
+
+// renameRelationships points the relationships of the model and their foreign keys to the new names of the renamed
+// entities, given by their old name.
+func renameRelationships(model *Model, names map[string]string) {
+	for _, rel := range model.Relationships {
+		for _, entity := range []*string{&rel.From, &rel.To} {
+			if name, ok := names[*entity]; ok {
+				*entity = name
+			}
+		}
+
+		if rel.ForeignKey != nil {
+			for _, entity := range []*string{&rel.ForeignKey.Entity, &rel.ForeignKey.RefEntity} {
+				if name, ok := names[*entity]; ok {
+					*entity = name
+				}
+			}
+		}
+	}
+}
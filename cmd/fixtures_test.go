@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// fixtureUpdateEnv regenerates the golden outputs of the fixtures instead of comparing to them, like the
+// assertions of the entmaidtest package.
+const fixtureUpdateEnv = "ENTMAID_UPDATE"
+
+// fixtures are the example schemas covering the constructs the renderers have to agree on, by the name of their
+// golden outputs in testdata/fixtures.
+var fixtures = map[string]string{
+	// M2M edges through a join table.
+	"start": "../examples/start/schema",
+	// M2M edges through an edge schema.
+	"edgeschema": "../examples/edgeschema/schema",
+	// Foreign keys declared as fields.
+	"edgefield": "../examples/edgefield/schema",
+	// UUID and string IDs.
+	"ids": "../examples/ids/schema",
+	// Entities in several database schemas.
+	"multischema": "../examples/multischema/schema",
+	"mixins":      "../examples/mixins/schema",
+	// Self-references and logical relationships.
+	"annotations": "../examples/annotations/schema",
+}
+
+// TestFixtures renders every fixture in every format and compares it to its golden output, so a change of the
+// rendering shows up as a diff of the golden files. Run the tests with ENTMAID_UPDATE=1 to regenerate them.
+func TestFixtures(t *testing.T) {
+	update := os.Getenv(fixtureUpdateEnv) != ""
+
+	// SVG needs the Mermaid CLI and is rendered from the Mermaid output anyway.
+	formats := slices.DeleteFunc(Formats(), func(format string) bool { return format == string(FormatSVG) })
+
+	for name, schemaPath := range fixtures {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			opts := []Option{WithQuiet(), WithReproducible()}
+			for _, format := range formats {
+				opts = append(opts, WithOutput(Format(format), filepath.Join(dir, format+extensions[Format(format)])))
+			}
+
+			if err := GenerateDiagram(schemaPath, "", Plain, "", "", opts...); err != nil {
+				t.Fatalf("Failed to render the fixture: %v", err)
+			}
+
+			for _, format := range formats {
+				file := format + extensions[Format(format)]
+				golden := filepath.Join("testdata", "fixtures", name, file)
+
+				content, err := os.ReadFile(filepath.Join(dir, file))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if update {
+					if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+						t.Fatal(err)
+					}
+					if err := os.WriteFile(golden, content, 0o644); err != nil {
+						t.Fatal(err)
+					}
+					continue
+				}
+
+				expected, err := os.ReadFile(golden)
+				if err != nil {
+					t.Errorf("Missing the golden %s output, run the tests with %s=1 to create it: %v", format, fixtureUpdateEnv, err)
+					continue
+				}
+
+				if line := firstDifferentLine(string(expected), string(content)); line != 0 {
+					t.Errorf("The %s output changed at line %d (%s), run the tests with %s=1 to update %s",
+						format, line, lineChange(string(expected), string(content), line), fixtureUpdateEnv, golden)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,492 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Format is a diagram language the model can be rendered as, when writing additional outputs.
+type Format string
+
+const (
+	FormatMermaid Format = "mermaid"
+	FormatDBML    Format = "dbml"
+	FormatJSON    Format = "json"
+	FormatSVG     Format = "svg"
+	// FormatSourceMap isn't a diagram but a manifest of the schema files the entities and fields are declared in.
+	FormatSourceMap Format = "sourcemap"
+)
+
+// renderer writes the model in a specific format.
+type renderer func(ctx context.Context, w io.Writer, model *Model, o *options) error
+
+var renderers = map[Format]renderer{
+	FormatMermaid: renderMermaid,
+	FormatDBML:    renderDBML,
+	FormatJSON:    renderJSON,
+	FormatSVG:     renderSVG,
+
+	FormatConfluence: renderConfluence,
+	FormatDrawIO:     renderDrawIO,
+	FormatOpenAPI:    renderOpenAPI,
+	FormatAvro:       renderAvro,
+	FormatGraphML:    renderGraphML,
+
+	FormatOpenLineage: renderOpenLineage,
+
+	FormatMatrix:    renderMatrix,
+	FormatMatrixCSV: renderMatrixCSV,
+
+	FormatSourceMap: renderSourceMap,
+}
+
+// extensions are the file extensions of the formats, added to the output paths without one. The formats of the
+// extensions they alone use are also picked for the output paths given without a format.
+var extensions = map[Format]string{
+	FormatMermaid: ".mmd",
+	FormatDBML:    ".dbml",
+	FormatJSON:    ".json",
+	FormatSVG:     ".svg",
+
+	FormatConfluence: ".xml",
+	FormatDrawIO:     ".drawio",
+	FormatOpenAPI:    ".json",
+	FormatAvro:       ".avsc",
+	FormatGraphML:    ".graphml",
+
+	FormatOpenLineage: ".json",
+
+	FormatMatrix:    ".md",
+	FormatMatrixCSV: ".csv",
+
+	FormatSourceMap: ".json",
+}
+
+// Formats returns the names of every format outputs can be written as.
+func Formats() []string {
+	names := make([]string, 0, len(renderers))
+	for format := range renderers {
+		names = append(names, string(format))
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// output is an additional file the whole rendered diagram is written to.
+type output struct {
+	format Format
+	path   string
+}
+
+// WithOutput additionally writes the whole diagram rendered in the format to the file at path, with the extension of
+// the format when the path has none (e.g. docs/erd.mmd for docs/erd as mermaid, which is written without fences).
+// All outputs are rendered concurrently from the same loaded schema graph.
+func WithOutput(format Format, path string) Option {
+	return func(o *options) {
+		if extension, ok := extensions[format]; ok && filepath.Ext(path) == "" {
+			path += extension
+		}
+
+		o.outputs = append(o.outputs, output{format: format, path: path})
+	}
+}
+
+// WithRawOutput additionally writes the raw Mermaid code of the diagram next to the target, e.g. to README.mmd for
+// README.md, for the tools reading .mmd files. Like the other Mermaid outputs of WithOutput, it's written from the
+// render of the target so the two are identical, but for a paginated diagram which is written whole. There's no
+// raw output for the targets written to stdout.
+func WithRawOutput() Option {
+	return func(o *options) {
+		o.rawOutput = true
+	}
+}
+
+// rawPath returns the path of the raw Mermaid code of the diagram of the target.
+func rawPath(targetPath string) string {
+	return strings.TrimSuffix(targetPath, filepath.Ext(targetPath)) + extensions[FormatMermaid]
+}
+
+// writeRawTarget writes the Mermaid output from the render of the target, unless it's paginated in several
+// diagrams a single .mmd file can't hold.
+func writeRawTarget(ctx context.Context, model *Model, render func() (*targetRender, error), out output, o *options) error {
+	target, err := render()
+	if err != nil {
+		return err
+	}
+
+	if len(target.diagrams) != 1 {
+		o.logger.Debug("rendering the paginated diagram whole for the output", "path", out.path)
+		return writeOutput(ctx, model, out, o)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return writeFile(out.path, []byte(target.diagrams[0]), string(out.format), o)
+}
+
+// PathFormat returns the format of the output path from its extension, the .json files being the JSON model. It
+// returns false when no format has this extension.
+func PathFormat(path string) (Format, bool) {
+	extension := strings.ToLower(filepath.Ext(path))
+	switch extension {
+	case "":
+		return "", false
+	case ".mermaid":
+		return FormatMermaid, true
+	case extensions[FormatJSON]:
+		return FormatJSON, true
+	}
+
+	for _, format := range Formats() {
+		if extensions[Format(format)] == extension {
+			return Format(format), true
+		}
+	}
+
+	return "", false
+}
+
+// WithMermaidCLI sets the path of the mermaid-cli (mmdc) executable used to render SVG outputs.
+func WithMermaidCLI(path string) Option {
+	return func(o *options) {
+		o.mmdcPath = path
+	}
+}
+
+// runConcurrently runs every job in its own goroutine and returns all of their errors joined together.
+func runConcurrently(ctx context.Context, jobs []func(context.Context) error) error {
+	errs := make([]error, len(jobs))
+
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = job(ctx)
+		}()
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// writeOutput renders the model in the output's format and writes it to its file, or compares it to the file in
+// check mode.
+func writeOutput(ctx context.Context, model *Model, out output, o *options) error {
+	if o.check && out.format == FormatSVG {
+		o.logger.Info("skipping check of rendered output", "path", out.path)
+		return nil
+	}
+
+	content, err := renderFormat(ctx, model, out.format, o)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return writeFile(out.path, content, string(out.format), o)
+}
+
+// renderFormat renders the model in the format, built in or provided by a plugin.
+func renderFormat(ctx context.Context, model *Model, format Format, o *options) ([]byte, error) {
+	render, ok := renderers[format]
+	if !ok {
+		render, ok = pluginRenderer(format)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w %q, expected one of %s or an %s%s executable in the PATH", ErrUnknownFormat, format, strings.Join(Formats(), ", "), pluginPrefix, format)
+	}
+
+	var buf bytes.Buffer
+	if err := render(ctx, &buf, model, o); err != nil {
+		return nil, fmt.Errorf("%w as %s: %w", ErrRender, format, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeFile writes the content rendered in the format to the file at path when it changed, or compares it to the
+// file in check mode.
+func writeFile(path string, content []byte, format string, o *options) error {
+	if o.check {
+		existing, err := os.ReadFile(path)
+		if err != nil {
+			return inFile(path, fmt.Errorf("%w: %w", ErrTarget, err))
+		}
+
+		if line := firstDifferentLine(string(existing), string(content)); line > 0 {
+			return &FileError{Path: path, Line: line, Err: fmt.Errorf("the %s %w in %s", format, ErrStaleDiagram, path)}
+		}
+
+		return nil
+	}
+
+	target, err := lockTarget(path, true)
+	if err != nil {
+		return inFile(path, fmt.Errorf("%w: %w", ErrTarget, err))
+	}
+	defer target.unlock()
+
+	if len(target.content) > 0 && bytes.Equal(target.content, content) {
+		o.logger.Info("output is unchanged", "format", format, "path", path)
+		return nil
+	}
+
+	if err := target.write(content); err != nil {
+		return inFile(path, fmt.Errorf("%w: %w", ErrTarget, err))
+	}
+
+	o.recordModified(path, len(content))
+	o.logger.Info("wrote output", "format", format, "path", path, "bytes", len(content))
+
+	return nil
+}
+
+// renderJSON writes the model itself, so other tools can consume it.
+func renderJSON(_ context.Context, w io.Writer, model *Model, _ *options) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(model)
+}
+
+// dbmlIdentifierRegex matches the names that can be used in DBML without quoting them.
+var dbmlIdentifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func dbmlName(name string) string {
+	if dbmlIdentifierRegex.MatchString(name) {
+		return name
+	}
+
+	return `"` + strings.ReplaceAll(name, `"`, `\"`) + `"`
+}
+
+// dbmlEntityName returns the name of the entity, using the DBML schema.table notation for entities qualified by their
+// database schema.
+func dbmlEntityName(model *Model, name string) string {
+	if entity := model.Entity(name); entity != nil && entity.Schema != "" {
+		return dbmlName(entity.Schema) + "." + dbmlName(strings.TrimPrefix(name, entity.Schema+"."))
+	}
+
+	return dbmlName(name)
+}
+
+// renderDBML writes the model as DBML (https://dbml.dbdiagram.io), using the foreign keys as references.
+func renderDBML(ctx context.Context, w io.Writer, model *Model, _ *options) error {
+	var builder strings.Builder
+
+	if model.Header != "" {
+		builder.WriteString(fmt.Sprintf("// %s\n\n", model.Header))
+	}
+
+	for _, entity := range model.Entities {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// DBML can only color the header of the tables, with hex colors.
+		if entity.Style != nil && strings.HasPrefix(entity.Style.Fill, "#") {
+			builder.WriteString(fmt.Sprintf("Table %s [headercolor: %s] {\n", dbmlEntityName(model, entity.Name), entity.Style.Fill))
+		} else {
+			builder.WriteString(fmt.Sprintf("Table %s {\n", dbmlEntityName(model, entity.Name)))
+		}
+
+		var pks []string
+		for _, attribute := range entity.Attributes {
+			for _, key := range attribute.Keys {
+				if key == "PK" {
+					pks = append(pks, dbmlName(attribute.Name))
+				}
+			}
+		}
+
+		for _, attribute := range entity.Attributes {
+			builder.WriteString(fmt.Sprintf("  %s %s", dbmlName(attribute.Name), dbmlName(attribute.Type)))
+
+			var settings []string
+			if len(pks) == 1 && pks[0] == dbmlName(attribute.Name) {
+				settings = append(settings, "pk")
+			}
+			if attribute.Default != "" {
+				settings = append(settings, "default: "+dbmlDefault(attribute))
+			}
+			var notes []string
+			if attribute.Classification != "" {
+				notes = append(notes, strings.ReplaceAll(string(attribute.Classification), "'", "\\'"))
+			}
+			if attribute.Immutable {
+				notes = append(notes, "immutable")
+			}
+			if len(attribute.Fields) > 0 {
+				notes = append(notes, strings.ReplaceAll(jsonFieldsComment(attribute.Fields), "'", "\\'"))
+			}
+			if len(notes) > 0 {
+				settings = append(settings, "note: '"+strings.Join(notes, ", ")+"'")
+			}
+
+			if len(settings) > 0 {
+				builder.WriteString(" [" + strings.Join(settings, ", ") + "]")
+			}
+			builder.WriteString("\n")
+		}
+
+		var notes []string
+		switch {
+		case entity.View && entity.URL != "":
+			notes = append(notes, "View defined in "+strings.ReplaceAll(entity.URL, "'", "\\'"))
+		case entity.View:
+			notes = append(notes, "View")
+		case entity.URL != "":
+			notes = append(notes, "Defined in "+strings.ReplaceAll(entity.URL, "'", "\\'"))
+		}
+		if len(entity.Guards) > 0 {
+			notes = append(notes, "Guarded by "+strings.Join(entity.Guards, ", "))
+		}
+		for _, check := range entity.Checks {
+			notes = append(notes, "Check "+strings.ReplaceAll(check.describe(), "'", "\\'"))
+		}
+		if entity.Link != "" {
+			notes = append(notes, "Documented at "+strings.ReplaceAll(entity.Link, "'", "\\'"))
+		}
+		if len(notes) > 0 {
+			builder.WriteString(fmt.Sprintf("\n  Note: '%s'\n", strings.Join(notes, ". ")))
+		}
+
+		// DBML only supports composite primary keys through an index.
+		var indexes []string
+		if len(pks) > 1 {
+			indexes = append(indexes, fmt.Sprintf("(%s) [pk]", strings.Join(pks, ", ")))
+		}
+
+		for _, index := range entity.Indexes {
+			columns := make([]string, len(index.Columns))
+			for i, column := range index.Columns {
+				columns[i] = dbmlName(column)
+			}
+
+			settings := fmt.Sprintf("name: '%s'", index.Name)
+			if index.Unique {
+				settings = "unique, " + settings
+			}
+
+			indexes = append(indexes, fmt.Sprintf("(%s) [%s]", strings.Join(columns, ", "), settings))
+		}
+
+		if len(indexes) > 0 {
+			builder.WriteString("\n  indexes {\n")
+			for _, index := range indexes {
+				builder.WriteString("    " + index + "\n")
+			}
+			builder.WriteString("  }\n")
+		}
+
+		builder.WriteString("}\n\n")
+	}
+
+	for _, rel := range model.Relationships {
+		fk := rel.ForeignKey
+		if fk == nil {
+			continue
+		}
+
+		operator := ">"
+		if isSingle(rel.FromCardinality) && isSingle(rel.ToCardinality) {
+			operator = "-"
+		}
+
+		builder.WriteString(fmt.Sprintf("Ref: %s.%s %s %s.%s", dbmlEntityName(model, fk.Entity), dbmlName(fk.Column), operator, dbmlEntityName(model, fk.RefEntity), dbmlName(fk.RefColumn)))
+		if rel.Logical {
+			builder.WriteString(" // logical, not enforced by the database")
+		}
+		builder.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, builder.String())
+
+	return err
+}
+
+// dbmlDefault returns the default of the attribute as a DBML value, defaults computed by a function are
+// written as an expression.
+func dbmlDefault(attribute *Attribute) string {
+	if attribute.Default == "auto" {
+		return "`auto`"
+	}
+
+	if attribute.GoType == "bool" || strings.HasPrefix(attribute.GoType, "int") || strings.HasPrefix(attribute.GoType, "uint") || strings.HasPrefix(attribute.GoType, "float") {
+		return attribute.Default
+	}
+
+	return "'" + strings.ReplaceAll(attribute.Default, "'", "\\'") + "'"
+}
+
+func isSingle(c Cardinality) bool {
+	return c == ZeroOrOne || c == ExactlyOne
+}
+
+// renderSVG renders the Mermaid diagram to an SVG image using the mermaid-cli (mmdc).
+func renderSVG(ctx context.Context, w io.Writer, model *Model, o *options) error {
+	dir, err := os.MkdirTemp("", "entmaid")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	input, err := os.Create(filepath.Join(dir, "diagram.mmd"))
+	if err != nil {
+		return err
+	}
+
+	if err := renderMermaid(ctx, input, model, o); err != nil {
+		input.Close()
+		return err
+	}
+
+	if err := input.Close(); err != nil {
+		return err
+	}
+
+	outputPath := filepath.Join(dir, "diagram.svg")
+
+	mmdc := o.mmdcPath
+	if mmdc == "" {
+		mmdc = "mmdc"
+	}
+
+	cmd := exec.CommandContext(ctx, mmdc, "--quiet", "--input", input.Name(), "--output", outputPath)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if out = bytes.TrimSpace(out); len(out) > 0 {
+			return fmt.Errorf("failed to run %s: %w: %s", mmdc, err, out)
+		}
+
+		return fmt.Errorf("failed to run %s: %w", mmdc, err)
+	}
+
+	svg, err := os.ReadFile(outputPath)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(svg)
+
+	return err
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateDiagramOutputs(t *testing.T) {
+	dir := t.TempDir()
+	dbmlPath := filepath.Join(dir, "schema.dbml")
+	jsonPath := filepath.Join(dir, "schema.json")
+	mermaidPath := filepath.Join(dir, "schema.mmd")
+
+	err := GenerateDiagram("../examples/start/schema", "", Markdown, "", "",
+		WithOutput(FormatDBML, dbmlPath), WithOutput(FormatJSON, jsonPath), WithOutput(FormatMermaid, mermaidPath))
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	dbml, err := os.ReadFile(dbmlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"Table Car {\n  id int [pk]\n", "(group_id, user_id) [pk]", "Ref: Car.user_cars > User.id\n", "Ref: group_users.user_id > User.id\n", "  name string [default: 'unknown']\n"} {
+		if !strings.Contains(string(dbml), expected) {
+			t.Errorf("DBML output is missing %q:\n%s", expected, dbml)
+		}
+	}
+
+	content, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var model Model
+	if err := json.Unmarshal(content, &model); err != nil {
+		t.Fatalf("Failed to decode the JSON output: %v", err)
+	}
+
+	if len(model.Entities) != 4 || len(model.Relationships) != 3 {
+		t.Errorf("Expected 4 entities and 3 relationships, got %d and %d", len(model.Entities), len(model.Relationships))
+	}
+
+	mermaid, err := os.ReadFile(mermaidPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := os.ReadFile("../examples/start/readme-expected.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(expected), string(mermaid)) {
+		t.Errorf("Mermaid output doesn't match the diagram in the example:\n%s", mermaid)
+	}
+
+	err = GenerateDiagram("../examples/start/schema", "", Markdown, "", "", WithCheck(),
+		WithOutput(FormatDBML, dbmlPath), WithOutput(FormatJSON, jsonPath), WithOutput(FormatMermaid, mermaidPath))
+	if err != nil {
+		t.Errorf("Expected the outputs to be up to date: %v", err)
+	}
+}
+
+func TestGenerateDiagramRawOutput(t *testing.T) {
+	targetPath := filepath.Join(t.TempDir(), "README.md")
+	if err := os.WriteFile(targetPath, []byte("<!-- #start:entmaid -->\n<!-- #end:entmaid -->\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The raw output shares the render of the target, the elisions of the budget included.
+	var warnings []Warning
+	err := GenerateDiagram("../examples/start/schema", targetPath, Markdown, "", "", WithRawOutput(), WithSizeBudget(400),
+		WithWarningHandler(func(w Warning) { warnings = append(warnings, w) }))
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	target, err := os.ReadFile(targetPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(filepath.Join(filepath.Dir(targetPath), "README.mmd"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(target), "```mermaid\n"+string(raw)) {
+		t.Errorf("The raw output differs from the diagram of the target:\n%s\ntarget:\n%s", raw, target)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("Expected the diagram to be budgeted once, got %v", warnings)
+	}
+}
+
+func TestWriteOutputUnknownFormat(t *testing.T) {
+	err := writeOutput(context.Background(), &Model{}, output{format: "png", path: "ignored"}, newOptions(nil))
+	if ExitCode(err) != ExitUsage {
+		t.Errorf("Expected an unknown format to be a usage error, got %v", err)
+	}
+}
+
+func TestOutputExtensions(t *testing.T) {
+	for _, format := range Formats() {
+		if _, ok := extensions[Format(format)]; !ok {
+			t.Errorf("The %s format has no extension", format)
+		}
+	}
+
+	o := newOptions([]Option{WithOutput(FormatMermaid, "docs/erd"), WithOutput(FormatDBML, "docs/erd.txt")})
+	if o.outputs[0].path != "docs/erd.mmd" || o.outputs[1].path != "docs/erd.txt" {
+		t.Errorf("Expected only the path without an extension to get the one of its format, got %+v", o.outputs)
+	}
+
+	for path, expected := range map[string]Format{
+		"erd.mmd":         FormatMermaid,
+		"erd.mermaid":     FormatMermaid,
+		"docs/erd.DBML":   FormatDBML,
+		"erd.json":        FormatJSON,
+		"events.avsc":     FormatAvro,
+		"erd.drawio":      FormatDrawIO,
+		"erd.txt":         "",
+		"docs/erd":        "",
+		"docs.d/erd.yaml": "",
+	} {
+		if format, ok := PathFormat(path); format != expected || ok != (expected != "") {
+			t.Errorf("Expected the format of %s to be %q, got %q", path, expected, format)
+		}
+	}
+}
+
+func TestGenerateDiagramDBMLIndexes(t *testing.T) {
+	dbmlPath := filepath.Join(t.TempDir(), "schema.dbml")
+
+	if err := GenerateDiagram("../examples/edgefield/schema", "", Markdown, "", "", WithOutput(FormatDBML, dbmlPath)); err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	dbml, err := os.ReadFile(dbmlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "  indexes {\n    (author_id, title) [unique, name: 'post_author_id_title']\n  }\n"
+	if !strings.Contains(string(dbml), expected) {
+		t.Errorf("DBML output is missing the index of the Post:\n%s", dbml)
+	}
+}
+
+func TestGenerateDiagramDBMLChecks(t *testing.T) {
+	dbmlPath := filepath.Join(t.TempDir(), "schema.dbml")
+
+	if err := GenerateDiagram("../examples/customtypes/schema", "", Markdown, "", "", WithOutput(FormatDBML, dbmlPath)); err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	dbml, err := os.ReadFile(dbmlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "  Note: 'Check (price >= 0). Check paid_total (NOT paid OR total > 0)'\n"
+	if !strings.Contains(string(dbml), expected) {
+		t.Errorf("DBML output is missing the checks of the Booking:\n%s", dbml)
+	}
+}
+
+func TestRenderUnicode(t *testing.T) {
+	model := &Model{
+		Entities: []*Entity{
+			{Name: "ユーザー", Table: "ユーザー", Attributes: []*Attribute{
+				{Name: "id", Type: "int", Keys: []string{"PK"}},
+				{Name: "名前", Type: "string", Default: "名無し"},
+				{Name: "café", Type: "string"},
+			}},
+			{Name: "Straße", Table: "straßen", Attributes: []*Attribute{
+				{Name: "id", Type: "int", Keys: []string{"PK"}},
+				{Name: "ユーザー_id", Type: "int", Keys: []string{"FK"}},
+			}},
+		},
+		Relationships: []*Relationship{{
+			From: "ユーザー", To: "Straße", FromCardinality: ZeroOrOne, ToCardinality: ZeroOrMore, Label: "住所",
+			ForeignKey: &ForeignKey{Entity: "Straße", Column: "ユーザー_id", RefEntity: "ユーザー", RefColumn: "id"},
+		}},
+	}
+
+	expected := map[Format][]string{
+		FormatMermaid: {
+			` "ユーザー" {` + "\n",
+			`  string __ "column: 名前, default: 名無し"` + "\n",
+			`  string caf_ "column: café"` + "\n",
+			` "ユーザー" |o--o{ "Straße" : "住所"` + "\n",
+		},
+		FormatDBML: {
+			`Table "ユーザー" {` + "\n",
+			`  "名前" string [default: '名無し']` + "\n",
+			`Ref: "Straße"."ユーザー_id" > "ユーザー".id` + "\n",
+		},
+		FormatJSON: {
+			`"name": "ユーザー"`,
+			`"name": "名前"`,
+			`"default": "名無し"`,
+		},
+	}
+
+	o := newOptions([]Option{WithValidate()})
+	for format, snippets := range expected {
+		var builder strings.Builder
+		if err := renderers[format](context.Background(), &builder, model, o); err != nil {
+			t.Fatalf("Failed to render %s: %v", format, err)
+		}
+
+		for _, snippet := range snippets {
+			if !strings.Contains(builder.String(), snippet) {
+				t.Errorf("The %s output is missing %q:\n%s", format, snippet, builder.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+)
+
+// WithGitAdd stages the files modified by GenerateDiagram in git, for use in hooks and go generate.
+func WithGitAdd() Option {
+	return func(o *options) {
+		o.gitAdd = true
+	}
+}
+
+// gitAdd stages the files, each in the git repository containing it.
+func gitAdd(ctx context.Context, files []string) error {
+	for _, file := range files {
+		cmd := exec.CommandContext(ctx, "git", "-C", filepath.Dir(file), "add", "--", filepath.Base(file))
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to stage %s: %w: %s", file, err, bytes.TrimSpace(out))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateDiagramGitAdd(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", repo).CombinedOutput(); err != nil {
+		t.Fatalf("Failed to create the repository: %v: %s", err, out)
+	}
+
+	readme := filepath.Join(repo, "README.md")
+	if err := os.WriteFile(readme, []byte("# Schema\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := GenerateDiagram("../examples/start/schema", readme, Markdown, "", "", WithCreateMarkers(), WithOutput(FormatDBML, filepath.Join(repo, "schema.dbml")), WithGitAdd())
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	out, err := exec.Command("git", "-C", repo, "diff", "--cached", "--name-only").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if staged := strings.Fields(string(out)); len(staged) != 2 || staged[0] != "README.md" || staged[1] != "schema.dbml" {
+		t.Errorf("Expected README.md and schema.dbml to be staged, got %v", staged)
+	}
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+
+	"entgo.io/ent/entc"
+	"entgo.io/ent/entc/gen"
+)
+
+// globalIDRange is the size of the range of IDs ent allocates to every type with the sql/globalid feature.
+const globalIDRange = 1 << 32
+
+// WithGlobalIDs loads the schema with the sql/globalid feature and notes the range of IDs allocated to every entity
+// in the global ID space in the comment of its primary key. The ranges already allocated by the project's codegen are
+// read from its internal/globalid.go (see WithGenConfig for its Target), the new types get the next free ones.
+func WithGlobalIDs() Option {
+	return func(o *options) {
+		o.globalIDs = true
+		o.entcOptions = append(o.entcOptions, entc.FeatureNames(gen.FeatureGlobalID.Name))
+	}
+}
+
+// globalIDStart returns the first ID of the range of the node in the global ID space, or nil when it hasn't any.
+func globalIDStart(node *gen.Type) *int {
+	if annotation := node.EntSQL(); annotation != nil && annotation.IncrementStart != nil {
+		start := *annotation.IncrementStart
+		return &start
+	}
+
+	return nil
+}
+
+// globalIDComment describes the range of the IDs starting at start, as a multiple of the size of the ranges.
+func globalIDComment(start int) string {
+	if start > 0 && start%globalIDRange == 0 {
+		return fmt.Sprintf("global ids from %d<<32", start/globalIDRange)
+	}
+
+	return fmt.Sprintf("global ids from %d", start)
+}
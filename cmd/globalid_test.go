@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGlobalIDs(t *testing.T) {
+	o := newOptions([]Option{WithQuiet(), WithGlobalIDs()})
+
+	model, err := loadModel(context.Background(), "../examples/start/schema", o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	starts := make(map[int]string)
+	for _, entity := range model.Entities {
+		if entity.JoinTable {
+			continue
+		}
+
+		id := entity.Attribute("id")
+		if id == nil || id.GlobalID == nil {
+			t.Fatalf("Expected %s to have a range of global IDs", entity.Name)
+		}
+		if other, ok := starts[*id.GlobalID]; ok || *id.GlobalID%globalIDRange != 0 {
+			t.Errorf("Unexpected range %d of %s, other: %s", *id.GlobalID, entity.Name, other)
+		}
+		starts[*id.GlobalID] = entity.Name
+	}
+
+	var builder strings.Builder
+	if err := renderMermaid(context.Background(), &builder, model, o); err != nil {
+		t.Fatal(err)
+	}
+	if expected := ` int id PK "global ids from 1<<32"`; !strings.Contains(builder.String(), expected) {
+		t.Errorf("The diagram is missing %q:\n%s", expected, builder.String())
+	}
+}
+
+func TestGlobalIDComment(t *testing.T) {
+	for start, expected := range map[int]string{0: "global ids from 0", 1 << 32: "global ids from 1<<32", 3 << 32: "global ids from 3<<32", 10: "global ids from 10"} {
+		if got := globalIDComment(start); got != expected {
+			t.Errorf("globalIDComment(%d) = %q, expected %q", start, got, expected)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// WithGolden compares the whole Mermaid diagram to the golden snapshot at path, failing with ErrStaleDiagram on any
+// change to the rendering, or rewrites the snapshot when update is set. Unlike the target, the snapshot has no
+// markers and is compared whether or not WithCheck is given.
+func WithGolden(path string, update bool) Option {
+	return func(o *options) {
+		o.goldenPath = path
+		o.goldenUpdate = update
+	}
+}
+
+// compareGolden renders the Mermaid diagram and compares it to the golden snapshot, or writes it in update mode.
+func compareGolden(ctx context.Context, model *Model, o *options) error {
+	var buf bytes.Buffer
+	if err := renderMermaid(ctx, &buf, model, o); err != nil {
+		return fmt.Errorf("%w as golden snapshot: %w", ErrRender, err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	path := o.goldenPath
+	if o.goldenUpdate {
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			return inFile(path, fmt.Errorf("%w: %w", ErrTarget, err))
+		}
+
+		o.recordModified(path, buf.Len())
+		o.logger.Info("updated golden snapshot", "path", path)
+
+		return nil
+	}
+
+	existing, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return inFile(path, fmt.Errorf("%w: the golden snapshot %s doesn't exist, run with --update to create it", ErrTarget, path))
+	}
+	if err != nil {
+		return inFile(path, fmt.Errorf("%w: %w", ErrTarget, err))
+	}
+
+	line := firstDifferentLine(string(existing), buf.String())
+	if line == 0 {
+		return nil
+	}
+
+	return &FileError{Path: path, Line: line, Err: fmt.Errorf("the golden snapshot %w in %s at line %d (%s), run with --update to accept the change",
+		ErrStaleDiagram, path, line, lineChange(string(existing), buf.String(), line))}
+}
+
+// lineChange describes how the line of the snapshot changed.
+func lineChange(existing string, generated string, line int) string {
+	lineAt := func(content string) (string, bool) {
+		lines := strings.Split(stripHeaderTimestamp(content), "\n")
+		if line > len(lines) {
+			return "", false
+		}
+		return strings.TrimSpace(lines[line-1]), true
+	}
+
+	was, hadLine := lineAt(existing)
+	now, hasLine := lineAt(generated)
+
+	switch {
+	case !hadLine:
+		return fmt.Sprintf("added %q", now)
+	case !hasLine:
+		return fmt.Sprintf("removed %q", was)
+	default:
+		return fmt.Sprintf("%q is now %q", was, now)
+	}
+}
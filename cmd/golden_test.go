@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGolden(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "start.mmd")
+
+	err := GenerateDiagram("../examples/start/schema", "", Plain, "", "", WithQuiet(), WithGolden(golden, false))
+	if !errors.Is(err, ErrTarget) || !strings.Contains(err.Error(), "--update") {
+		t.Fatalf("Expected the missing snapshot to fail, got %v", err)
+	}
+
+	if err := GenerateDiagram("../examples/start/schema", "", Plain, "", "", WithQuiet(), WithGolden(golden, true)); err != nil {
+		t.Fatalf("Failed to create the golden snapshot: %v", err)
+	}
+
+	content, err := os.ReadFile(golden)
+	if err != nil || !strings.HasPrefix(string(content), "erDiagram\n") {
+		t.Fatalf("Expected the snapshot to hold the Mermaid diagram, got %q (%v)", content, err)
+	}
+
+	if err := GenerateDiagram("../examples/start/schema", "", Plain, "", "", WithQuiet(), WithGolden(golden, false)); err != nil {
+		t.Errorf("Expected the diagram to match its snapshot, got %v", err)
+	}
+
+	stale := strings.Replace(string(content), "string model", "string name", 1)
+	if err := os.WriteFile(golden, []byte(stale), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err = GenerateDiagram("../examples/start/schema", "", Plain, "", "", WithQuiet(), WithGolden(golden, false))
+
+	var fileErr *FileError
+	if !errors.Is(err, ErrStaleDiagram) || !errors.As(err, &fileErr) || fileErr.Line == 0 {
+		t.Fatalf("Expected the changed rendering to fail at its line, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), `"string name" is now "string model"`) {
+		t.Errorf("Expected the error to describe the change, got %v", err)
+	}
+}
+
+func TestLineChange(t *testing.T) {
+	if got := lineChange("a\nb", "a\nb\nc", 3); got != `added "c"` {
+		t.Errorf("Expected an added line, got %s", got)
+	}
+
+	if got := lineChange("a\nb\nc", "a\nb", 3); got != `removed "c"` {
+		t.Errorf("Expected a removed line, got %s", got)
+	}
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// FormatGraphML is the entity graph as GraphML, with the entities as nodes and the relationships as typed edges
+// but no attributes, for graph tools like Gephi, yEd or networkx.
+const FormatGraphML Format = "graphml"
+
+type graphMLFile struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// graphMLKeys declare the data of the nodes and edges, the label keys are the ones Gephi shows.
+var graphMLKeys = []graphMLKey{
+	{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+	{ID: "table", For: "node", AttrName: "table", AttrType: "string"},
+	{ID: "schema", For: "node", AttrName: "schema", AttrType: "string"},
+	{ID: "kind", For: "node", AttrName: "kind", AttrType: "string"},
+	{ID: "edgeLabel", For: "edge", AttrName: "label", AttrType: "string"},
+	{ID: "edge", For: "edge", AttrName: "edge", AttrType: "string"},
+	{ID: "fromCardinality", For: "edge", AttrName: "fromCardinality", AttrType: "string"},
+	{ID: "toCardinality", For: "edge", AttrName: "toCardinality", AttrType: "string"},
+	{ID: "logical", For: "edge", AttrName: "logical", AttrType: "boolean"},
+	{ID: "weight", For: "edge", AttrName: "weight", AttrType: "int"},
+}
+
+// renderGraphML writes the entity graph as GraphML: a node per entity typed by its kind (entity, join table or view)
+// and a directed edge per relationship, from the entity of the ent edge to the entity it points to.
+func renderGraphML(ctx context.Context, w io.Writer, model *Model, o *options) error {
+	graph := graphMLGraph{ID: "entmaid", EdgeDefault: "directed"}
+
+	for _, entity := range model.Entities {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		kind := "entity"
+		switch {
+		case entity.JoinTable:
+			kind = "join-table"
+		case entity.View:
+			kind = "view"
+		}
+
+		node := graphMLNode{ID: entity.Name, Data: []graphMLData{{Key: "label", Value: entity.Name}, {Key: "table", Value: entity.Table}}}
+		if entity.Schema != "" {
+			node.Data = append(node.Data, graphMLData{Key: "schema", Value: entity.Schema})
+		}
+		node.Data = append(node.Data, graphMLData{Key: "kind", Value: kind})
+
+		graph.Nodes = append(graph.Nodes, node)
+	}
+
+	for i, rel := range model.Relationships {
+		edge := graphMLEdge{
+			ID:     fmt.Sprintf("e%d", i),
+			Source: rel.From,
+			Target: rel.To,
+			Data: []graphMLData{
+				{Key: "edgeLabel", Value: relationshipLabel(rel, o)},
+				{Key: "edge", Value: rel.Edge},
+				{Key: "fromCardinality", Value: string(rel.FromCardinality)},
+				{Key: "toCardinality", Value: string(rel.ToCardinality)},
+				{Key: "logical", Value: strconv.FormatBool(rel.Logical)},
+			},
+		}
+		if rel.Weight > 0 {
+			edge.Data = append(edge.Data, graphMLData{Key: "weight", Value: strconv.Itoa(rel.Weight)})
+		}
+
+		graph.Edges = append(graph.Edges, edge)
+	}
+
+	content, err := xml.MarshalIndent(graphMLFile{XMLNS: "http://graphml.graphdrawing.org/xmlns", Keys: graphMLKeys, Graph: graph}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, content)
+
+	return err
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"testing"
+)
+
+func TestRenderGraphML(t *testing.T) {
+	model := &Model{
+		Entities: []*Entity{
+			{Name: "User", Table: "users", Attributes: []*Attribute{{Name: "id", Type: "int", Keys: []string{"PK"}}}},
+			{Name: "billing.Invoice", Table: "invoices", Schema: "billing"},
+			{Name: "ActiveUser", Table: "active_users", View: true},
+			{Name: "user_groups", Table: "user_groups", JoinTable: true},
+		},
+		Relationships: []*Relationship{
+			{From: "User", To: "billing.Invoice", FromCardinality: ExactlyOne, ToCardinality: ZeroOrMore, Label: "invoices-owner", Edge: "invoices"},
+			{From: "User", To: "ActiveUser", FromCardinality: ZeroOrOne, ToCardinality: ZeroOrOne, Label: "active", Edge: "active", Logical: true},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := renderGraphML(context.Background(), &buf, model, newOptions(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	var file graphMLFile
+	if err := xml.Unmarshal(buf.Bytes(), &file); err != nil {
+		t.Fatalf("The GraphML isn't valid XML: %v\n%s", err, buf.String())
+	}
+
+	kinds := make(map[string]string)
+	for _, node := range file.Graph.Nodes {
+		for _, data := range node.Data {
+			if data.Key == "kind" {
+				kinds[node.ID] = data.Value
+			}
+		}
+	}
+	for name, expected := range map[string]string{"User": "entity", "billing.Invoice": "entity", "ActiveUser": "view", "user_groups": "join-table"} {
+		if kinds[name] != expected {
+			t.Errorf("Got the kind %q of %s, expected %q", kinds[name], name, expected)
+		}
+	}
+
+	if len(file.Graph.Edges) != 2 {
+		t.Fatalf("Expected an edge per relationship, got %+v", file.Graph.Edges)
+	}
+	edge := file.Graph.Edges[1]
+	if edge.Source != "User" || edge.Target != "ActiveUser" || edge.Data[0].Value != "active" || edge.Data[4].Value != "true" {
+		t.Errorf("Unexpected logical edge %+v", edge)
+	}
+}
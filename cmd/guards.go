@@ -0,0 +1,27 @@
+package cmd
+
+import "entgo.io/ent/entc/gen"
+
+// WithGuards marks the entities guarded at runtime by a privacy policy, hooks or interceptors, e.g. with a
+// "(policy,hooks)" label in Mermaid diagrams, to audit which tables are protected from the diagram.
+func WithGuards() Option {
+	return func(o *options) {
+		o.guards = true
+	}
+}
+
+// entityGuards returns the kinds of runtime guards the schema type declares, including through its mixins.
+func entityGuards(node *gen.Type) []string {
+	var guards []string
+	if node.NumPolicy() > 0 {
+		guards = append(guards, "policy")
+	}
+	if node.NumHooks() > 0 {
+		guards = append(guards, "hooks")
+	}
+	if node.NumInterceptors() > 0 {
+		guards = append(guards, "interceptors")
+	}
+
+	return guards
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateDiagramGuards(t *testing.T) {
+	err := GenerateDiagram("../examples/guards/schema", "../examples/guards/readme.md", Markdown, defaultStartPattern, defaultEndPattern, WithGuards())
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	if !compareFiles("../examples/guards/readme.md", "../examples/guards/readme-expected.md") {
+		t.Errorf("Generated file does not match the expected output")
+	}
+}
+
+func TestGenerateDiagramGuardsMermaid10(t *testing.T) {
+	dir := t.TempDir()
+	mermaidPath := filepath.Join(dir, "erd.mmd")
+	dbmlPath := filepath.Join(dir, "erd.dbml")
+
+	err := GenerateDiagram("../examples/guards/schema", "", Plain, "", "", WithGuards(), WithMermaidVersion(Mermaid10),
+		WithOutput(FormatMermaid, mermaidPath), WithOutput(FormatDBML, dbmlPath))
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	mermaid, err := os.ReadFile(mermaidPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(mermaid), " %% Account is guarded by policy,hooks\n Account {\n") {
+		t.Errorf("Expected a comment marking the guarded entity:\n%s", mermaid)
+	}
+
+	dbml, err := os.ReadFile(dbmlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(dbml), "Note: 'Guarded by interceptors'") {
+		t.Errorf("Expected a note listing the guards in the DBML output:\n%s", dbml)
+	}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const headerPrefix = "generated by entmaid"
+
+// headerTimestampRegex matches the volatile timestamp part of the header so it can be ignored when checking.
+var headerTimestampRegex = regexp.MustCompile(`(` + regexp.QuoteMeta(headerPrefix) + `[^\n]*?) at \S+`)
+
+// hashSchema returns a hash of all the Go source files making up the schema package, or of the snapshot file the
+// schema is read from.
+func hashSchema(schemaPath string) (string, error) {
+	files, err := filepath.Glob(filepath.Join(schemaPath, "*.go"))
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(schemaPath); err == nil && !info.IsDir() {
+		files = []string{schemaPath}
+	}
+
+	sort.Strings(files)
+
+	hash := sha256.New()
+
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+
+		// Include the file name so renaming or moving code between files changes the hash.
+		fmt.Fprintf(hash, "%s\x00%d\x00", filepath.Base(file), len(content))
+		hash.Write(content)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// generateHeader builds the provenance comment placed at the top of the diagram, each format adds its own comment
+// syntax. The hashes of several schemas are combined into one. Reproducible headers only hold the hash.
+func generateHeader(schemaPaths []string, timestamp bool, reproducible bool) (string, error) {
+	hashes := make([]string, len(schemaPaths))
+	for i, schemaPath := range schemaPaths {
+		var err error
+		hashes[i], err = hashSchema(schemaPath)
+		if err != nil {
+			return "", fmt.Errorf("%w at the path %s: %w", ErrSchemaHash, schemaPath, err)
+		}
+	}
+
+	schemaHash := hashes[0]
+	if len(hashes) > 1 {
+		combined := sha256.Sum256([]byte(strings.Join(hashes, "\x00")))
+		schemaHash = hex.EncodeToString(combined[:])
+	}
+
+	if reproducible {
+		return fmt.Sprintf("%s from schema sha256:%s", headerPrefix, schemaHash), nil
+	}
+
+	header := fmt.Sprintf("%s %s from schema sha256:%s", headerPrefix, version(), schemaHash)
+	if timestamp {
+		header += " at " + time.Now().UTC().Format(time.RFC3339)
+	}
+
+	return header, nil
+}
+
+// stripHeaderTimestamp removes the generation time from any header so two renders can be compared.
+func stripHeaderTimestamp(s string) string {
+	return headerTimestampRegex.ReplaceAllString(s, "$1")
+}
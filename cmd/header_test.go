@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerateHeader(t *testing.T) {
+	first, err := generateHeader([]string{"../examples/start/schema"}, false, false)
+	if err != nil {
+		t.Fatalf("Failed to generate header: %v", err)
+	}
+
+	second, err := generateHeader([]string{"../examples/start/schema"}, true, false)
+	if err != nil {
+		t.Fatalf("Failed to generate header: %v", err)
+	}
+
+	if !strings.HasPrefix(second, first+" at ") {
+		t.Errorf("Timestamped header %q does not extend %q", second, first)
+	}
+
+	if stripHeaderTimestamp(second) != first {
+		t.Errorf("Stripping the timestamp from %q did not result in %q", second, first)
+	}
+
+	other, err := generateHeader([]string{"../examples/m2m2types/schema"}, false, false)
+	if err != nil {
+		t.Fatalf("Failed to generate header: %v", err)
+	}
+
+	if other == first {
+		t.Errorf("Different schemas produced the same header %q", first)
+	}
+
+	reproducible, err := generateHeader([]string{"../examples/start/schema"}, true, true)
+	if err != nil {
+		t.Fatalf("Failed to generate header: %v", err)
+	}
+
+	if expected := strings.Replace(first, headerPrefix+" "+version()+" ", headerPrefix+" ", 1); reproducible != expected {
+		t.Errorf("Expected the reproducible header %q, got %q", expected, reproducible)
+	}
+}
+
+func TestGenerateDiagramCheck(t *testing.T) {
+	err := GenerateDiagram("../examples/start/schema", "../examples/start/readme-expected.md", Markdown, defaultStartPattern, defaultEndPattern, WithCheck())
+	if err != nil {
+		t.Errorf("Expected the diagram to be up to date: %v", err)
+	}
+
+	err = GenerateDiagram("../examples/m2m2types/schema", "../examples/start/readme-expected.md", Markdown, defaultStartPattern, defaultEndPattern, WithCheck())
+	if !errors.Is(err, ErrStaleDiagram) {
+		t.Errorf("Expected the diagram of a different schema to be out of date, got %v", err)
+	}
+}
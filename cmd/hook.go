@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const hookMarker = "# Installed by entmaid hook install."
+
+var (
+	hookMode    string
+	hookCommand string
+	hookForce   bool
+)
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Manage the git hook keeping the diagram up to date",
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install a git pre-commit hook running entmaid with the given flags",
+	Long: `Install a git pre-commit hook running entmaid with the schema, target and other flags given to this command.
+
+In check mode (the default) the commit fails when the diagram is out of date, in regenerate mode the diagram is
+regenerated and the modified files are staged (--git-add) as part of the commit.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if hookMode != "check" && hookMode != "regenerate" {
+			return fmt.Errorf("%w: unknown hook mode %q, expected check or regenerate", errUsage, hookMode)
+		}
+
+		hooksDir, err := gitHooksDir()
+		if err != nil {
+			return err
+		}
+
+		hookPath := filepath.Join(hooksDir, "pre-commit")
+
+		existing, err := os.ReadFile(hookPath)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		if err == nil && !hookForce && !bytes.Contains(existing, []byte(hookMarker)) {
+			return fmt.Errorf("%w: %s already exists and wasn't installed by entmaid, pass --force to replace it", errUsage, hookPath)
+		}
+
+		if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+			return err
+		}
+
+		script := preCommitScript(hookCommand, hookMode == "regenerate", hookArgs(cmd))
+		if err := os.WriteFile(hookPath, []byte(script), 0o755); err != nil {
+			return err
+		}
+
+		if !quiet {
+			fmt.Printf("Installed the pre-commit hook in %s.\n", hookPath)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	hookInstallCmd.Flags().StringVar(&hookMode, "mode", "check", "what the hook does: 'check' fails the commit on a stale diagram, 'regenerate' updates and stages it")
+	hookInstallCmd.Flags().StringVar(&hookCommand, "command", "entmaid", "command the hook runs entmaid with, e.g. \"go run github.com/lespea/entmaid@latest\"")
+	hookInstallCmd.Flags().BoolVar(&hookForce, "force", false, "replace an existing pre-commit hook not installed by entmaid")
+
+	hookCmd.AddCommand(hookInstallCmd)
+	rootCmd.AddCommand(hookCmd)
+}
+
+// gitHooksDir returns the hooks directory of the current repository, respecting core.hooksPath.
+func gitHooksDir() (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the git hooks directory, is this a git repository? %w", err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// hookArgs returns the entmaid flags that were set on the command line, so the hook runs with the same ones.
+func hookArgs(cmd *cobra.Command) []string {
+	var args []string
+
+	local := cmd.LocalNonPersistentFlags()
+
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		// The mode of the hook decides whether to check or stage the files.
+		if flag.Name == "check" || flag.Name == "git-add" || flag.Name == "skip-unchanged" || local.Lookup(flag.Name) != nil {
+			return
+		}
+
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			for _, value := range slice.GetSlice() {
+				args = append(args, "--"+flag.Name+"="+value)
+			}
+
+			return
+		}
+
+		args = append(args, "--"+flag.Name+"="+flag.Value.String())
+	})
+
+	return args
+}
+
+func preCommitScript(command string, regenerate bool, args []string) string {
+	var builder strings.Builder
+
+	builder.WriteString("#!/bin/sh\n")
+	builder.WriteString(hookMarker + "\n\n")
+
+	var quoted string
+	for _, arg := range args {
+		quoted += " " + shellQuote(arg)
+	}
+
+	if !regenerate {
+		builder.WriteString(fmt.Sprintf("exec %s --check --skip-unchanged%s\n", command, quoted))
+		return builder.String()
+	}
+
+	builder.WriteString(fmt.Sprintf("exec %s --git-add --skip-unchanged%s\n", command, quoted))
+
+	return builder.String()
+}
+
+// shellQuote quotes the argument for a POSIX shell.
+func shellQuote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import "testing"
+
+func TestPreCommitScript(t *testing.T) {
+	args := []string{"--schema=./ent/schema", "--startPattern=<!-- it's here -->"}
+
+	check := preCommitScript("entmaid", false, args)
+	expectedCheck := "#!/bin/sh\n" + hookMarker + "\n\nexec entmaid --check --skip-unchanged '--schema=./ent/schema' '--startPattern=<!-- it'\\''s here -->'\n"
+	if check != expectedCheck {
+		t.Errorf("Got check script %q, expected %q", check, expectedCheck)
+	}
+
+	regenerate := preCommitScript("go run github.com/lespea/entmaid@latest", true, args[:1])
+	expectedRegenerate := "#!/bin/sh\n" + hookMarker + "\n\nexec go run github.com/lespea/entmaid@latest --git-add --skip-unchanged '--schema=./ent/schema'\n"
+	if regenerate != expectedRegenerate {
+		t.Errorf("Got regenerate script %q, expected %q", regenerate, expectedRegenerate)
+	}
+}
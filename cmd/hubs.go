@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"github.com/lespea/entmaid/annotation"
+)
+
+// WithStubHubs draws the entities with more relationships than the limit, like a User every other entity refers to,
+// as dimmed stubs without their attributes, indexes and check constraints. The hubs otherwise dominate the overview
+// diagrams and tangle their layout. The entities styled by their annotation keep their style.
+func WithStubHubs(relationships int) Option {
+	return func(o *options) {
+		o.stubHubs = relationships
+	}
+}
+
+// hubStyle dims the stubs of the hubs in the Mermaid diagram, after the muted colors of GitHub.
+var hubStyle = annotation.Style{Fill: "#f6f8fa", Stroke: "#d0d7de", Color: "#8c959f"}
+
+// stubHubEntities replaces the entities of the model with more relationships than the limit by their stubs, the
+// join tables excepted.
+func stubHubEntities(model *Model, o *options) {
+	relationships := make(map[string]int)
+	for _, rel := range model.Relationships {
+		relationships[rel.From]++
+		// The relationships of an entity to itself, like the parent of a tree, are counted once.
+		if rel.To != rel.From {
+			relationships[rel.To]++
+		}
+	}
+
+	for i, entity := range model.Entities {
+		// The join tables always have the two relationships of their M2M edge.
+		if entity.JoinTable || relationships[entity.Name] <= o.stubHubs {
+			continue
+		}
+
+		o.logger.Debug("drawing the hub as a stub", "entity", entity.Name, "relationships", relationships[entity.Name])
+
+		// The entity is copied as it's shared with the cached models.
+		stub := *entity
+		stub.Attributes, stub.Indexes, stub.Checks = nil, nil, nil
+		if stub.Style == nil {
+			style := hubStyle
+			stub.Style = &style
+		}
+		model.Entities[i] = &stub
+	}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lespea/entmaid/annotation"
+)
+
+func TestStubHubEntities(t *testing.T) {
+	user := &Entity{Name: "User", Attributes: []*Attribute{{Name: "id", Keys: []string{"PK"}}}, Indexes: []*Index{{Name: "user_name"}}}
+	styled := &Entity{Name: "Node", Attributes: []*Attribute{{Name: "id"}}, Style: &annotation.Style{Fill: "#fff"}}
+	model := &Model{
+		Entities: []*Entity{user, {Name: "Car"}, {Name: "Post"}, styled, {Name: "user_groups", JoinTable: true}},
+		Relationships: []*Relationship{
+			{From: "User", To: "Car"}, {From: "User", To: "Post"}, {From: "User", To: "user_groups"}, {From: "Car", To: "user_groups"},
+			// The relationships of the tree count once.
+			{From: "Node", To: "Node"}, {From: "Node", To: "Node"}, {From: "Node", To: "Post"},
+		},
+	}
+
+	stubHubEntities(model, newOptions([]Option{WithStubHubs(2)}))
+
+	stub := model.Entity("User")
+	if stub == user || stub.Attributes != nil || stub.Indexes != nil || !reflect.DeepEqual(stub.Style, &hubStyle) {
+		t.Errorf("Expected User to be a dimmed stub, got %+v", stub)
+	}
+	if len(user.Attributes) != 1 {
+		t.Errorf("The entity of the model was modified: %+v", user)
+	}
+
+	if node := model.Entity("Node"); node.Attributes != nil || node.Style.Fill != "#fff" {
+		t.Errorf("Expected Node to be a stub keeping its style, got %+v", node)
+	}
+
+	for _, name := range []string{"Car", "Post", "user_groups"} {
+		if entity := model.Entity(name); entity.Style != nil {
+			t.Errorf("Expected %s not to be stubbed, got %+v", name, entity)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package cmd
+
+import "slices"
+
+// WithHideIDs leaves the primary key columns out of the Mermaid diagram, keeping the ones that are also foreign keys
+// (e.g. the columns of join tables). The other formats keep them as their references point to them.
+func WithHideIDs() Option {
+	return func(o *options) {
+		o.hideIDs = true
+	}
+}
+
+// hiddenAttribute reports whether the attribute is a primary key left out by WithHideIDs.
+func hiddenAttribute(attribute *Attribute, o *options) bool {
+	return o.hideIDs && slices.Contains(attribute.Keys, "PK") && !slices.Contains(attribute.Keys, "FK")
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFileName is the file listing the entities and fields never drawn in the diagrams of a repository, looked up
+// from the working directory up to the root of the repository by the CLI.
+const IgnoreFileName = ".entmaidignore"
+
+// WithIgnoreFile leaves the entities and fields matched by the patterns of the ignore file out of every output.
+//
+// Each line is an Entity or Entity.field pattern matched as by path.Match (e.g. Audit* or *.password_hash), blank
+// lines and lines starting with # are skipped and a pattern starting with ! draws again what an earlier one ignored.
+func WithIgnoreFile(path string) Option {
+	return func(o *options) {
+		o.ignoreFile = path
+	}
+}
+
+// ignorePattern is a line of the ignore file.
+type ignorePattern struct {
+	pattern string
+	negate  bool
+}
+
+// findRepoFile returns the file of the working directory or of its closest parent, stopping at the root of the git
+// repository. It returns "" when there's none.
+func findRepoFile(name string) (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		candidate := filepath.Join(dir, name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "", nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// readIgnoreFile parses the patterns of the ignore file.
+func readIgnoreFile(ignorePath string) ([]ignorePattern, error) {
+	content, err := os.ReadFile(ignorePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the ignore file: %w", err)
+	}
+
+	var patterns []ignorePattern
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		var pattern ignorePattern
+		if strings.HasPrefix(text, "!") {
+			pattern.negate = true
+			text = strings.TrimSpace(text[1:])
+		}
+
+		pattern.pattern = text
+		if _, err := path.Match(text, ""); err != nil || text == "" {
+			return nil, &FileError{Path: ignorePath, Line: line, Err: fmt.Errorf("%w: invalid pattern %q in %s at line %d", errUsage, text, ignorePath, line)}
+		}
+
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, scanner.Err()
+}
+
+// ignored reports whether the entity, or its field when it isn't empty, is ignored by the patterns. The last matching
+// pattern wins. The fields are matched by the part of the pattern after its last dot, as the entities can be qualified
+// by their database schema (e.g. billing.Invoice.total).
+func ignored(patterns []ignorePattern, entity string, field string) bool {
+	result := false
+	for _, pattern := range patterns {
+		matched, _ := path.Match(pattern.pattern, entity)
+		if field != "" {
+			i := strings.LastIndex(pattern.pattern, ".")
+			if i == -1 {
+				continue
+			}
+
+			entityMatched, _ := path.Match(pattern.pattern[:i], entity)
+			fieldMatched, _ := path.Match(pattern.pattern[i+1:], field)
+			matched = entityMatched && fieldMatched
+		}
+
+		if matched {
+			result = !pattern.negate
+		}
+	}
+
+	return result
+}
+
+// ignoreModel returns the model without the ignored entities along with their relationships and join tables, and
+// without the ignored fields.
+func ignoreModel(model *Model, patterns []ignorePattern, o *options) *Model {
+	var names []string
+	for _, entity := range model.Entities {
+		switch {
+		case ignored(patterns, entity.Name, ""):
+			if !entity.JoinTable {
+				o.skipEntity(entity.Name, "ignored by "+IgnoreFileName)
+			}
+		case !entity.JoinTable:
+			names = append(names, entity.Name)
+		}
+	}
+
+	filtered := filterEntities(model, names)
+
+	// Join tables can be ignored on their own, even though both of their sides are drawn.
+	kept := filtered.Entities[:0]
+	for _, entity := range filtered.Entities {
+		if entity.JoinTable && ignored(patterns, entity.Name, "") {
+			o.skipEntity(entity.Name, "ignored by "+IgnoreFileName)
+			continue
+		}
+		kept = append(kept, entity)
+	}
+	filtered.Entities = kept
+
+	relationships := filtered.Relationships[:0]
+	for _, rel := range filtered.Relationships {
+		if filtered.Entity(rel.From) != nil && filtered.Entity(rel.To) != nil {
+			relationships = append(relationships, rel)
+		}
+	}
+	filtered.Relationships = relationships
+
+	for _, entity := range filtered.Entities {
+		attributes := make([]*Attribute, 0, len(entity.Attributes))
+		for _, attribute := range entity.Attributes {
+			if !ignored(patterns, entity.Name, attribute.Name) {
+				attributes = append(attributes, attribute)
+			}
+		}
+		entity.Attributes = attributes
+	}
+
+	return filtered
+}
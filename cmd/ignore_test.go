@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIgnored(t *testing.T) {
+	patterns := []ignorePattern{{pattern: "Audit*"}, {pattern: "AuditSummary", negate: true}, {pattern: "*.password_hash"}, {pattern: "billing.Invoice.total"}}
+
+	testCases := []struct {
+		entity   string
+		field    string
+		expected bool
+	}{
+		{"AuditLog", "", true},
+		{"AuditSummary", "", false},
+		{"User", "", false},
+		{"User", "password_hash", true},
+		{"User", "name", false},
+		{"billing.Invoice", "total", true},
+		{"billing.Invoice", "", false},
+	}
+
+	for _, testCase := range testCases {
+		if got := ignored(patterns, testCase.entity, testCase.field); got != testCase.expected {
+			t.Errorf("ignored(%q, %q) = %t, expected %t", testCase.entity, testCase.field, got, testCase.expected)
+		}
+	}
+}
+
+func TestReadIgnoreFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), IgnoreFileName)
+	if err := os.WriteFile(path, []byte("# internal tables\nAudit*\n\n!AuditSummary\nUser.[\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := readIgnoreFile(path)
+
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) || fileErr.Line != 5 || !errors.Is(err, errUsage) {
+		t.Fatalf("Expected a usage error at line 5, got %v", err)
+	}
+}
+
+func TestGenerateDiagramIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	ignorePath, mermaidPath := filepath.Join(dir, IgnoreFileName), filepath.Join(dir, "erd.mmd")
+	if err := os.WriteFile(ignorePath, []byte("Group\nUser.age\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := GenerateDiagram("../examples/start/schema", "", Plain, "", "", WithIgnoreFile(ignorePath), WithOutput(FormatMermaid, mermaidPath)); err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	content, err := os.ReadFile(mermaidPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, unexpected := range []string{" Group {", " group_users {", "int age"} {
+		if strings.Contains(string(content), unexpected) {
+			t.Errorf("Expected %q to be ignored:\n%s", unexpected, content)
+		}
+	}
+	if !strings.Contains(string(content), " User |o--o{ Car : cars-owner\n") {
+		t.Errorf("Expected the relationships of the drawn entities to be kept:\n%s", content)
+	}
+}
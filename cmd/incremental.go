@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// WithIncremental skips rendering and writing the diagrams of the site export whose part of the model didn't change
+// since the last export into the same directory, as recorded in a state file of the cache directory (see WithCache).
+// The settings identify the other options the diagrams are rendered with, changing them regenerates every diagram.
+// A diagram whose file was removed is always written again, one edited by hand is only overwritten once its part of
+// the model changes.
+func WithIncremental(settings string) Option {
+	return func(o *options) {
+		o.incremental = true
+		o.incrementalKey = settings
+	}
+}
+
+// siteState records the diagrams of the last site export into a directory by file name.
+type siteState struct {
+	Diagrams map[string]siteDiagram `json:"diagrams"`
+
+	path string
+	dir  string
+}
+
+// siteDiagram is a diagram of the site export along with the hash of the part of the model it draws, the diagram is
+// kept as the index page embeds it.
+type siteDiagram struct {
+	Hash    string `json:"hash"`
+	Diagram string `json:"diagram"`
+}
+
+// readSiteState returns the state of the last export of the site into the directory, an empty one when there's none,
+// or nil when the export isn't incremental.
+func readSiteState(dir string, o *options) *siteState {
+	if !o.incremental || o.check {
+		return nil
+	}
+
+	cacheDir, err := cacheDirectory(o.cacheDir)
+	if err != nil {
+		o.logger.Warn("exporting the whole site, the cache directory is unknown", "error", err)
+		return nil
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		o.logger.Warn("exporting the whole site, the directory is unknown", "error", err)
+		return nil
+	}
+
+	key := sha256.Sum256([]byte(abs))
+	state := &siteState{path: filepath.Join(cacheDir, "site-"+hex.EncodeToString(key[:])+".json"), dir: dir}
+
+	content, err := os.ReadFile(state.path)
+	if err == nil {
+		err = json.Unmarshal(content, state)
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		o.logger.Warn("ignoring unreadable site state", "state", state.path, "error", err)
+	}
+
+	if state.Diagrams == nil || err != nil {
+		state.Diagrams = make(map[string]siteDiagram)
+	}
+
+	return state
+}
+
+// unchanged returns the diagram of the file from the last export when the hash of its part of the model is the same
+// and the files written for it are still there.
+func (s *siteState) unchanged(file string, hash string, files ...string) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+
+	previous, ok := s.Diagrams[file]
+	if !ok || hash == "" || previous.Hash != hash {
+		return "", false
+	}
+
+	for _, file := range files {
+		if _, err := os.Stat(filepath.Join(s.dir, file)); err != nil {
+			return "", false
+		}
+	}
+
+	return previous.Diagram, true
+}
+
+// record sets the diagram of the file for the next export.
+func (s *siteState) record(file string, hash string, diagram string) {
+	if s != nil {
+		s.Diagrams[file] = siteDiagram{Hash: hash, Diagram: diagram}
+	}
+}
+
+// write saves the state once the site is exported, forgetting the diagrams that weren't exported this time.
+func (s *siteState) write(exported map[string]bool, o *options) {
+	if s == nil {
+		return
+	}
+
+	for file := range s.Diagrams {
+		if !exported[file] {
+			delete(s.Diagrams, file)
+		}
+	}
+
+	if err := writeCacheFile(s.path, s); err != nil {
+		o.logger.Warn("failed to save the site state", "state", s.path, "error", err)
+	}
+}
+
+// modelHash hashes what the diagrams draw of the model: the model itself along with the details of its entities and
+// attributes that aren't part of its JSON, the settings of the export and the entmaid version.
+func modelHash(model *Model, o *options) string {
+	hash := sha256.New()
+	hash.Write([]byte(version() + "\x00" + o.incrementalKey + "\x00" + strconv.FormatBool(o.overview) + "\x00"))
+
+	content, err := json.Marshal(model)
+	if err != nil {
+		// An empty hash never matches the state, the diagram is exported again.
+		return ""
+	}
+	hash.Write(content)
+
+	for _, entity := range model.Entities {
+		details, _ := json.Marshal([]any{entity.drawnOn, entity.typeName, entity.mixins, entity.abstract})
+		hash.Write(details)
+
+		for _, attribute := range entity.Attributes {
+			hash.Write([]byte(strconv.FormatBool(attribute.optional)))
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
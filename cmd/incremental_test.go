@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestExportSiteIncremental(t *testing.T) {
+	dir, cacheDir := t.TempDir(), t.TempDir()
+	billing := filepath.Join(dir, "billing.mmd")
+
+	export := func(settings string) []string {
+		t.Helper()
+
+		o := newOptions([]Option{WithQuiet(), WithCache(cacheDir), WithIncremental(settings)})
+		if err := exportSite(context.Background(), "../examples/multischema/schema", dir, o); err != nil {
+			t.Fatalf("Failed to export the site: %v", err)
+		}
+
+		return o.modified
+	}
+
+	if modified := export(""); len(modified) != 3 {
+		t.Fatalf("Expected the diagrams and the index to be written, got %v", modified)
+	}
+
+	// The diagram edited by hand is left as it is while its entities are unchanged.
+	if err := os.WriteFile(billing, []byte("edited"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "auth.mmd")); err != nil {
+		t.Fatal(err)
+	}
+
+	if modified := export(""); !slices.Equal(modified, []string{filepath.Join(dir, "auth.mmd")}) {
+		t.Errorf("Expected only the removed diagram to be written again, got %v", modified)
+	}
+	if content, _ := os.ReadFile(billing); string(content) != "edited" {
+		t.Errorf("Expected the unchanged diagram to be skipped, got %q", content)
+	}
+
+	if modified := export("--title=ERD"); !slices.Contains(modified, billing) {
+		t.Errorf("Expected other settings to write every diagram again, got %v", modified)
+	}
+}
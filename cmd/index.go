@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// WithIndex maintains an index of the diagrams of the Markdown target between the patterns of IndexPatterns, linking
+// to every diagram of a paginated target (see WithPageSize) with the entities drawn in it.
+func WithIndex() Option {
+	return func(o *options) {
+		o.index = true
+	}
+}
+
+// diagramAnchor is the id of the anchor placed before the diagram of the page, linked to by the index.
+func diagramAnchor(page int) string {
+	return fmt.Sprintf("entmaid-diagram-%d", page)
+}
+
+// renderIndex lists the diagrams of the pages with a link to their anchor and the entities they draw, leaving out
+// the join tables and the stubs of the entities drawn in another diagram.
+func renderIndex(pages []*Model) string {
+	var builder strings.Builder
+	for i, page := range pages {
+		var names []string
+		for _, entity := range page.Entities {
+			if !entity.JoinTable && entity.drawnOn == 0 {
+				names = append(names, entity.Name)
+			}
+		}
+
+		name := "Diagram"
+		if len(pages) > 1 {
+			name = fmt.Sprintf("Diagram %d of %d", i+1, len(pages))
+		}
+
+		builder.WriteString(fmt.Sprintf("- [%s](#%s): %s\n", name, diagramAnchor(i+1), strings.Join(names, ", ")))
+	}
+
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+// writeIndex places the index of the diagrams between the index patterns of the target, or compares it to what's
+// already there in check mode.
+func writeIndex(ctx context.Context, pages []*Model, targetPath string, o *options) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	index := renderIndex(pages)
+	startPattern, endPattern := IndexPatterns(targetPath)
+
+	if o.check {
+		line, err := checkMultiLineString(targetPath, index, startPattern, endPattern, o)
+		if err != nil {
+			return fmt.Errorf("failed to check the index of the diagrams in the file: %w", err)
+		}
+
+		if line > 0 {
+			return &FileError{Path: targetPath, Line: line, Err: fmt.Errorf("the index of the %w in %s", ErrStaleDiagram, targetPath)}
+		}
+
+		return nil
+	}
+
+	if err := insertMultiLineString(targetPath, index, startPattern, endPattern, o); err != nil {
+		return fmt.Errorf("failed to insert the index of the diagrams into the file: %w", err)
+	}
+
+	o.logger.Info("wrote index of the diagrams", "target", targetPath, "diagrams", len(pages))
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateDiagramIndex(t *testing.T) {
+	readme := filepath.Join(t.TempDir(), "README.md")
+	content := "# Schema\n\n<!-- #start-index:entmaid -->\n<!-- #end-index:entmaid -->\n\n<!-- #start:entmaid -->\n<!-- #end:entmaid -->\n"
+	if err := os.WriteFile(readme, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	o := []Option{WithQuiet(), WithIndex(), WithPageSize(2)}
+	if err := GenerateDiagram("../examples/start/schema", readme, Markdown, "", "", o...); err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := os.ReadFile(readme)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"<!-- #start-index:entmaid -->\n- [Diagram 1 of 2](#entmaid-diagram-1): Car, Group\n- [Diagram 2 of 2](#entmaid-diagram-2): User\n<!-- #end-index:entmaid -->\n",
+		"<a id=\"entmaid-diagram-1\"></a>\n\n**Diagram 1 of 2**\n",
+		"<a id=\"entmaid-diagram-2\"></a>\n\n**Diagram 2 of 2**\n",
+	} {
+		if !strings.Contains(string(written), expected) {
+			t.Errorf("The target is missing %q:\n%s", expected, written)
+		}
+	}
+
+	if err := GenerateDiagram("../examples/start/schema", readme, Markdown, "", "", append(o, WithCheck())...); err != nil {
+		t.Errorf("Expected the index to be up to date: %v", err)
+	}
+
+	stale := strings.Replace(string(written), "Car, Group", "Car", 1)
+	if err := os.WriteFile(readme, []byte(stale), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := GenerateDiagram("../examples/start/schema", readme, Markdown, "", "", append(o, WithCheck())...); ExitCode(err) != ExitStale {
+		t.Errorf("Expected a stale index to fail the check, got %v", err)
+	}
+}
+
+func TestIndexPatterns(t *testing.T) {
+	for _, target := range []string{"README.md", "doc.go", "erd.mmd"} {
+		start, end := IndexPatterns(target)
+		defaultStart, defaultEnd := DefaultPatterns(target)
+
+		for _, pattern := range []string{start, end} {
+			for _, other := range []string{defaultStart, defaultEnd} {
+				if strings.Contains(pattern, other) || strings.Contains(other, pattern) {
+					t.Errorf("The index pattern %q of %s overlaps with %q", pattern, target, other)
+				}
+			}
+		}
+	}
+}
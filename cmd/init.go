@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// InitTarget is the document the init command places the markers in, unless a --target is given.
+const InitTarget = "README.md"
+
+var initForce bool
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Scaffold the configuration file and the markers of the diagram",
+	Long: `Scaffold the setup of entmaid in the current directory: detect the directory of the ent schemas (unless a
+--schema is given), write a starter .entmaid.json configuration file generating the diagram into the target, and place
+the start and end markers of the diagram at the end of the target (README.md unless a --target is given).
+
+The diagram is then generated between the markers by 'entmaid generate'.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schema := schemaPaths[0]
+		if !cmd.Flags().Changed("schema") {
+			detected, err := detectSchemaDir(".")
+			if err != nil {
+				return err
+			}
+			if detected == "" {
+				return fmt.Errorf("%w: no ent schema found in the current directory, pass --schema", errUsage)
+			}
+			schema = detected
+		}
+
+		target := InitTarget
+		if cmd.Flags().Changed("target") {
+			target = targetPath
+		}
+		if target == "" || target == StdioTarget {
+			return fmt.Errorf("%w: init needs a target file to place the markers in", errUsage)
+		}
+
+		if err := writeStarterConfig(ConfigFileName, schema, target, initForce); err != nil {
+			return err
+		}
+
+		start, end := DefaultPatterns(target)
+		if startPattern != "" {
+			start = startPattern
+		}
+		if endPattern != "" {
+			end = endPattern
+		}
+
+		added, err := addMarkers(target, start, end)
+		if err != nil {
+			return err
+		}
+
+		if !quiet {
+			fmt.Printf("Wrote %s for the schema %s.\n", ConfigFileName, schema)
+			if added {
+				fmt.Printf("Placed the markers of the diagram at the end of %s, move them where the diagram belongs.\n", target)
+			} else {
+				fmt.Printf("%s already has the markers of the diagram.\n", target)
+			}
+			fmt.Println("Run 'entmaid generate' to generate the diagram.")
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	initCmd.Flags().BoolVar(&initForce, "force", false, "replace an existing "+ConfigFileName+" configuration file")
+	rootCmd.AddCommand(initCmd)
+}
+
+// detectSchemaDir returns the shallowest directory under root holding ent schemas, i.e. Go files embedding
+// ent.Schema, as a relative path like ./ent/schema. It returns "" when there's none. The hidden, vendor and
+// testdata directories are skipped.
+func detectSchemaDir(root string) (string, error) {
+	var dirs []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			name := entry.Name()
+			if path != root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		dir := filepath.Dir(path)
+		if slices.Contains(dirs, dir) {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.Contains(content, []byte(`"entgo.io/ent"`)) && bytes.Contains(content, []byte("ent.Schema")) {
+			dirs = append(dirs, dir)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(dirs) == 0 {
+		return "", nil
+	}
+
+	// Copies of the schemas, e.g. in examples, are usually nested deeper than the schemas of the project.
+	slices.SortStableFunc(dirs, func(a, b string) int {
+		return strings.Count(a, string(filepath.Separator)) - strings.Count(b, string(filepath.Separator))
+	})
+
+	rel, err := filepath.Rel(root, dirs[0])
+	if err != nil {
+		return "", err
+	}
+
+	if rel == "." {
+		return rel, nil
+	}
+
+	return "./" + filepath.ToSlash(rel), nil
+}
+
+// writeStarterConfig writes a configuration file with a single profile generating the diagram of the schema into
+// the target. An existing file is only replaced when forced.
+func writeStarterConfig(path string, schema string, target string, force bool) error {
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf("%w: %s already exists, pass --force to replace it", errUsage, path)
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	profile := map[string]any{"target": target}
+	if startPattern != "" {
+		profile["startPattern"] = startPattern
+	}
+	if endPattern != "" {
+		profile["endPattern"] = endPattern
+	}
+
+	config := Config{
+		Flags:    map[string]any{"schema": schema},
+		Profiles: map[string]map[string]any{"default": profile},
+	}
+
+	content, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(content, '\n'), 0o644)
+}
+
+// addMarkers appends the start and end patterns to the target file, creating it if needed, unless it already has
+// them. It reports whether they were added.
+func addMarkers(path string, startPattern string, endPattern string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, inFile(path, fmt.Errorf("%w: %w", ErrTarget, err))
+	}
+
+	fileContent := string(content)
+	if strings.Contains(fileContent, startPattern) || strings.Contains(fileContent, endPattern) {
+		return false, nil
+	}
+
+	updated := appendMultiLineString(fileContent, "", startPattern, endPattern)
+	if err := os.WriteFile(path, []byte(updated), 0o644); err != nil {
+		return false, inFile(path, fmt.Errorf("%w: %w", ErrTarget, err))
+	}
+
+	return true, nil
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectSchemaDir(t *testing.T) {
+	root := t.TempDir()
+
+	schema := "package schema\n\nimport \"entgo.io/ent\"\n\ntype User struct {\n\tent.Schema\n}\n"
+	for path, content := range map[string]string{
+		"main.go":                          "package main\n",
+		"internal/ent/schema/user.go":      schema,
+		"internal/ent/schema/user_test.go": schema,
+		"examples/blog/ent/schema/post.go": schema,
+		".cache/ent/schema/user.go":        schema,
+		"vendor/ent/schema/user.go":        schema,
+	} {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir, err := detectSchemaDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir != "./internal/ent/schema" {
+		t.Errorf("Detected the schema %q, expected ./internal/ent/schema", dir)
+	}
+
+	if dir, err := detectSchemaDir(filepath.Join(root, "examples", "blog", "ent", "schema")); err != nil || dir != "." {
+		t.Errorf("Detected the schema %q (%v) in the schema directory itself", dir, err)
+	}
+}
+
+func TestWriteStarterConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+
+	if err := writeStarterConfig(path, "./ent/schema", "README.md", false); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := readConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Flags["schema"] != "./ent/schema" || config.Profiles["default"]["target"] != "README.md" {
+		t.Errorf("Got the config %+v", config)
+	}
+
+	if err := writeStarterConfig(path, "./ent/schema", "README.md", false); !errors.Is(err, errUsage) {
+		t.Errorf("Expected a usage error for an existing config, got %v", err)
+	}
+	if err := writeStarterConfig(path, "./schema", "README.md", true); err != nil {
+		t.Errorf("Expected --force to replace the config, got %v", err)
+	}
+}
+
+func TestAddMarkers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "README.md")
+	if err := os.WriteFile(path, []byte("# Project"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	start, end := DefaultPatterns(path)
+	for i, expected := range []bool{true, false} {
+		added, err := addMarkers(path, start, end)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if added != expected {
+			t.Errorf("Run %d added the markers: %v, expected %v", i, added, expected)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "# Project\n\n<!-- #start:entmaid -->\n\n<!-- #end:entmaid -->\n"; string(content) != expected {
+		t.Errorf("Got the README %q, expected %q", content, expected)
+	}
+}
@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"context"
+)
+
+// Inspect loads the schema and returns the model the diagram would be drawn from, without rendering or writing
+// anything, for the Go tools that want the entities, columns and relationships of the schema (e.g. custom linters
+// or impact analysis) without depending on entc. The options shaping the model apply (e.g. WithEntities,
+// WithIgnoreFile or WithGenConfig), the warnings are reported to the WithWarningHandler.
+func Inspect(schemaPath string, opts ...Option) (*Model, error) {
+	return InspectContext(context.Background(), schemaPath, opts...)
+}
+
+// InspectContext is Inspect but stops loading once the context is done.
+func InspectContext(ctx context.Context, schemaPath string, opts ...Option) (*Model, error) {
+	return loadModel(ctx, schemaPath, newOptions(opts))
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	var warnings []Warning
+	model, err := Inspect("../examples/start/schema", WithEntities("User", "Car", "Plane"), WithWarningHandler(func(warning Warning) {
+		warnings = append(warnings, warning)
+	}))
+	if err != nil {
+		t.Fatalf("Failed to inspect the schema: %v", err)
+	}
+
+	var names []string
+	for _, entity := range model.Entities {
+		names = append(names, entity.Name)
+	}
+	if !slices.Equal(names, []string{"Car", "User"}) {
+		t.Errorf("Expected the Car and User entities, got %v", names)
+	}
+
+	if len(model.Relationships) != 1 || model.Relationships[0].ForeignKey == nil || model.Relationships[0].ForeignKey.Column != "user_cars" {
+		t.Errorf("Expected the cars relationship realized by user_cars, got %+v", model.Relationships)
+	}
+
+	if len(warnings) != 1 || warnings[0].Entity != "Plane" {
+		t.Errorf("Expected a warning about the unknown Plane entity, got %+v", warnings)
+	}
+}
+
+func TestInspectContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := InspectContext(ctx, "../examples/start/schema"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the canceled inspection to fail with context.Canceled, got %v", err)
+	}
+}
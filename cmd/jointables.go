@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"maps"
+	"slices"
+)
+
+// WithJoinTableName draws the join table of a M2M edge (e.g. "group_users") under another name in the diagrams, the
+// real name of the table is kept as the table of the entity (e.g. in the JSON output). It takes precedence over
+// WithShortJoinTables.
+func WithJoinTableName(table string, name string) Option {
+	return func(o *options) {
+		if o.joinTableNames == nil {
+			o.joinTableNames = make(map[string]string)
+		}
+		o.joinTableNames[table] = name
+	}
+}
+
+// WithShortJoinTables draws the join tables named by ent after their edge (e.g. "group_users") without the prefix of
+// the entity owning the edge, as "users". The join tables named through StorageKey are drawn as they are.
+func WithShortJoinTables() Option {
+	return func(o *options) {
+		o.shortJoinTables = true
+	}
+}
+
+// renameJoinTables renames the join tables of the model along with the relationships to them. A join table is kept
+// as it is when its new name is already taken by another entity.
+func renameJoinTables(model *Model, o *options) {
+	used := make(map[string]bool, len(o.joinTableNames))
+	names := make(map[string]string)
+
+	for _, entity := range model.Entities {
+		if !entity.JoinTable {
+			continue
+		}
+
+		name := entity.Table
+		if o.shortJoinTables && entity.edge != "" {
+			name = entity.edge
+		}
+		if renamed, ok := o.joinTableNames[entity.Table]; ok {
+			name, used[entity.Table] = renamed, true
+		}
+
+		name = qualifiedName(entity.Schema, name)
+		if name == entity.Name {
+			continue
+		}
+		if model.Entity(name) != nil {
+			o.warn(entity.Name, "", "the join table can't be renamed %s, an entity already has this name", name)
+			continue
+		}
+
+		o.logger.Debug("renaming join table", "table", entity.Name, "name", name)
+		names[entity.Name] = name
+		entity.Name = name
+	}
+
+	for _, table := range slices.Sorted(maps.Keys(o.joinTableNames)) {
+		if !used[table] {
+			o.warn(table, "", "is not a join table of the schema")
+		}
+	}
+
+	renameRelationships(model, names)
+}
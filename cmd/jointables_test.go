@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRenameJoinTables(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		schema   string
+		opts     []Option
+		drawn    []string
+		notDrawn []string
+		warnings int
+	}{
+		{
+			name:     "short",
+			schema:   "../examples/m2m2types/schema",
+			opts:     []Option{WithShortJoinTables()},
+			drawn:    []string{" users {\n", " Group |o--o{ users : users-groups\n", " User |o--o{ users : groups-users\n"},
+			notDrawn: []string{"group_users"},
+		},
+		{
+			name:     "renamed",
+			schema:   "../examples/m2m2types/schema",
+			opts:     []Option{WithShortJoinTables(), WithJoinTableName("group_users", "memberships")},
+			drawn:    []string{" memberships {\n", " User |o--o{ memberships : groups-users\n"},
+			notDrawn: []string{"group_users", " users {"},
+		},
+		{
+			name:     "taken",
+			schema:   "../examples/m2m2types/schema",
+			opts:     []Option{WithJoinTableName("group_users", "User"), WithJoinTableName("users_groups", "memberships")},
+			drawn:    []string{" group_users {\n"},
+			warnings: 2,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			o := newOptions(append(tc.opts, WithQuiet()))
+
+			model, err := loadModel(context.Background(), tc.schema, o)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, entity := range model.Entities {
+				if entity.JoinTable && entity.Table != "group_users" {
+					t.Errorf("Expected the join table %s to keep its table, got %s", entity.Name, entity.Table)
+				}
+			}
+
+			var builder strings.Builder
+			if err := renderMermaid(context.Background(), &builder, model, o); err != nil {
+				t.Fatal(err)
+			}
+
+			diagram := builder.String()
+			for _, expected := range tc.drawn {
+				if !strings.Contains(diagram, expected) {
+					t.Errorf("The diagram is missing %q:\n%s", expected, diagram)
+				}
+			}
+			for _, unexpected := range tc.notDrawn {
+				if strings.Contains(diagram, unexpected) {
+					t.Errorf("The diagram shouldn't contain %q:\n%s", unexpected, diagram)
+				}
+			}
+
+			if len(o.warnings) != tc.warnings {
+				t.Errorf("Expected %d warnings, got %v", tc.warnings, o.warnings)
+			}
+		})
+	}
+}
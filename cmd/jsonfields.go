@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"entgo.io/ent/schema/field"
+	"golang.org/x/tools/go/packages"
+)
+
+// WithExpandJSON lists the exported fields of the Go struct held by field.JSON columns (one level deep, by their
+// JSON names) in the comment of the column, instead of only showing it as a JSON type.
+func WithExpandJSON() Option {
+	return func(o *options) {
+		o.expandJSON = true
+	}
+}
+
+// JSONField is a field of the Go struct stored in a JSON column.
+type JSONField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// expandJSONFields sets the fields of the JSON columns holding a Go struct, type checking the packages declaring
+// them from the schema directory so they resolve from the same module.
+func expandJSONFields(model *Model, dir string, o *options) error {
+	loaded := make(map[string]*types.Package)
+
+	for _, entity := range model.Entities {
+		for _, attribute := range entity.Attributes {
+			info := attribute.typeInfo
+			if info == nil || info.Type != field.TypeJSON || info.PkgPath == "" {
+				continue
+			}
+
+			pkg, ok := loaded[info.PkgPath]
+			if !ok {
+				pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedName | packages.NeedTypes, Dir: dir}, info.PkgPath)
+				if err != nil {
+					return fmt.Errorf("failed to load the package %s of the JSON field %s.%s: %w", info.PkgPath, entity.Name, attribute.Name, err)
+				}
+
+				if len(pkgs) == 1 && len(pkgs[0].Errors) == 0 {
+					pkg = pkgs[0].Types
+				}
+				loaded[info.PkgPath] = pkg
+			}
+
+			if pkg == nil {
+				o.warn(entity.Name, attribute.Name, "can't expand the JSON fields as the package %s doesn't build", info.PkgPath)
+				continue
+			}
+
+			// Pointers and slices of the struct hold the same fields.
+			ident := strings.TrimLeft(info.Ident, "*[]")
+			name := ident[strings.LastIndex(ident, ".")+1:]
+
+			if structType, ok := lookupStruct(pkg, name); ok {
+				attribute.Fields = jsonFields(structType, pkg)
+			}
+		}
+	}
+
+	return nil
+}
+
+// lookupStruct returns the struct type declared with the name in the package.
+func lookupStruct(pkg *types.Package, name string) (*types.Struct, bool) {
+	object, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, false
+	}
+
+	structType, ok := object.Type().Underlying().(*types.Struct)
+	return structType, ok
+}
+
+// jsonFields returns the exported fields of the struct as encoding/json marshals them, the fields of embedded
+// structs without a JSON name being promoted to the struct.
+func jsonFields(structType *types.Struct, pkg *types.Package) []*JSONField {
+	// The types are written as in the schema package, e.g. time.Time rather than by the full import path.
+	qualifier := func(other *types.Package) string {
+		if other == pkg {
+			return ""
+		}
+		return other.Name()
+	}
+
+	var fields []*JSONField
+	for i := 0; i < structType.NumFields(); i++ {
+		f := structType.Field(i)
+
+		name, _, _ := strings.Cut(reflect.StructTag(structType.Tag(i)).Get("json"), ",")
+		if name == "-" || (!f.Exported() && !f.Embedded()) {
+			continue
+		}
+
+		if f.Embedded() && name == "" {
+			if embedded, ok := f.Type().Underlying().(*types.Struct); ok {
+				fields = append(fields, jsonFields(embedded, pkg)...)
+				continue
+			}
+		}
+
+		if !f.Exported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name()
+		}
+
+		fields = append(fields, &JSONField{Name: name, Type: formatType(types.TypeString(f.Type(), qualifier))})
+	}
+
+	return fields
+}
+
+// jsonFieldsComment describes the fields of the JSON column, e.g. {street: string, city: string}.
+func jsonFieldsComment(fields []*JSONField) string {
+	described := make([]string, len(fields))
+	for i, f := range fields {
+		described[i] = f.Name + ": " + f.Type
+	}
+
+	return "{" + strings.Join(described, ", ") + "}"
+}
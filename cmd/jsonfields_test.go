@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateDiagramExpandJSON(t *testing.T) {
+	dir := t.TempDir()
+	mermaidPath, jsonPath := filepath.Join(dir, "erd.mmd"), filepath.Join(dir, "erd.json")
+
+	err := GenerateDiagram("../examples/customtypes/schema", "", Plain, "", "", WithExpandJSON(),
+		WithOutput(FormatMermaid, mermaidPath), WithOutput(FormatJSON, jsonPath))
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	mermaid, err := os.ReadFile(mermaidPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The Notes are left out by their json:"-" tag.
+	if expected := `  *schema-Address address "{street: string, city: string, zip: string, verified_at: timestamp}"` + "\n"; !strings.Contains(string(mermaid), expected) {
+		t.Errorf("Diagram is missing %q:\n%s", expected, mermaid)
+	}
+
+	content, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := `"fields": [`; !strings.Contains(string(content), expected) {
+		t.Errorf("JSON output is missing %q:\n%s", expected, content)
+	}
+}
+
+func TestJSONFieldsComment(t *testing.T) {
+	if comment := jsonFieldsComment([]*JSONField{{Name: "street", Type: "string"}, {Name: "tags", Type: "text[]"}}); comment != "{street: string, tags: text[]}" {
+		t.Errorf("Got comment %q", comment)
+	}
+}
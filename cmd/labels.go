@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// WithEdgeLabel labels the relationships of the edge with a human-readable verb like "owns" or "belongs to"
+// instead of the edge names. The edge is given by its name, or as Entity.edge when the name is used by several
+// entities, and can also be the name of the back-reference of the edge.
+func WithEdgeLabel(edge string, label string) Option {
+	return func(o *options) {
+		if o.edgeLabels == nil {
+			o.edgeLabels = make(map[string]string)
+		}
+		o.edgeLabels[edge] = label
+	}
+}
+
+// WithDisplayName labels the entity with a business-friendly name like "Purchase Order" in the diagram, taking
+// precedence over the DisplayName annotation of its schema type. Its relationships still use the name of the entity.
+func WithDisplayName(entity string, name string) Option {
+	return func(o *options) {
+		if o.displayNames == nil {
+			o.displayNames = make(map[string]string)
+		}
+		o.displayNames[entity] = name
+	}
+}
+
+// displayEntities sets the display names of WithDisplayName on the entities of the model.
+func displayEntities(model *Model, o *options) {
+	for _, name := range slices.Sorted(maps.Keys(o.displayNames)) {
+		entity := model.Entity(name)
+		if entity == nil {
+			o.warn(name, "", "is not an entity of the schema, it can't be given a display name")
+			continue
+		}
+		entity.DisplayName = o.displayNames[name]
+	}
+}
+
+// WithVerbLabels labels the relationships without a WithEdgeLabel with a verb derived from the edge name,
+// e.g. "has blog posts" for a blogPosts edge.
+func WithVerbLabels() Option {
+	return func(o *options) {
+		o.verbLabels = true
+	}
+}
+
+// WithForwardLabels labels the relationships by the name of their forward edge only, dropping the "-ref" suffix of
+// their back-reference which readers unfamiliar with ent take for a single identifier.
+func WithForwardLabels() Option {
+	return func(o *options) {
+		o.forwardLabels = true
+	}
+}
+
+// WithMultiplicityLabels appends the cardinalities of the relationships to their labels as text, e.g.
+// "cars-owner (0..1 to 0..*)", for readers unfamiliar with the crow's foot notation. They're appended whatever the
+// notation, the UML one included.
+func WithMultiplicityLabels() Option {
+	return func(o *options) {
+		o.multiplicities = true
+	}
+}
+
+// WithForeignKeyLabels appends the foreign key column realizing the relationships to their labels, e.g.
+// "posts-author (user_posts)", to write the joins from the diagram.
+func WithForeignKeyLabels() Option {
+	return func(o *options) {
+		o.fkLabels = true
+	}
+}
+
+// relationshipLabel returns the label of the relationship drawn in the diagram, followed by its foreign key column
+// and the multiplicities of its two ends.
+func relationshipLabel(rel *Relationship, o *options) string {
+	label := rel.Label
+	if o.fkLabels && rel.ForeignKey != nil {
+		label += " (" + rel.ForeignKey.Column + ")"
+	}
+	if o.multiplicities {
+		label += fmt.Sprintf(" (%s to %s)", umlMultiplicities[rel.FromCardinality], umlMultiplicities[rel.ToCardinality])
+	}
+
+	return label
+}
+
+// edgeNames returns the names the edge of the relationship is given by, its name then the one of its back-reference
+// unless it's to a join table, qualified by their entity before their bare names.
+func edgeNames(rel *Relationship, joinTable bool) []string {
+	names := []string{rel.From + "." + rel.Edge, rel.Edge}
+	if rel.Ref != "" && !joinTable {
+		names = append(names, rel.To+"."+rel.Ref, rel.Ref)
+	}
+
+	return names
+}
+
+// labelRelationships replaces the labels of the relationships with the configured or derived verbs, or their
+// forward edge names.
+func labelRelationships(model *Model, o *options) {
+	for _, rel := range model.Relationships {
+		// Both sides of a M2M edge have their own relationship to the join table, so the back-reference is the
+		// edge of the other side.
+		joinTable := false
+		if to := model.Entity(rel.To); to != nil {
+			joinTable = to.JoinTable
+		}
+
+		if label, ok := edgeLabel(rel, joinTable, o); ok {
+			o.logger.Debug("labeled relationship", "from", rel.From, "edge", rel.Edge, "label", label)
+			rel.Label = label
+		}
+	}
+}
+
+// edgeLabel looks up the label of the relationship by its edge, then by its back-reference unless it's to a join
+// table, qualified by their entity before their bare names.
+func edgeLabel(rel *Relationship, joinTable bool, o *options) (string, bool) {
+	for _, key := range edgeNames(rel, joinTable) {
+		if label, ok := o.edgeLabels[key]; ok {
+			return label, true
+		}
+	}
+
+	if o.verbLabels {
+		return "has " + edgeWords(rel.Edge), true
+	}
+
+	if o.forwardLabels {
+		return rel.Edge, true
+	}
+
+	return "", false
+}
+
+// edgeWords splits the camel or snake cased edge name into lower cased words.
+func edgeWords(name string) string {
+	var words []string
+	var word []rune
+
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || unicode.IsSpace(r):
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = nil
+			}
+			continue
+		case unicode.IsUpper(r) && len(word) > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))):
+			words = append(words, string(word))
+			word = nil
+		}
+
+		word = append(word, unicode.ToLower(r))
+	}
+
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEdgeWords(t *testing.T) {
+	for name, expected := range map[string]string{
+		"cars":       "cars",
+		"blogPosts":  "blog posts",
+		"blog_posts": "blog posts",
+		"HTTPRoutes": "http routes",
+		"ownerID":    "owner id",
+	} {
+		if words := edgeWords(name); words != expected {
+			t.Errorf("edgeWords(%q) = %q, expected %q", name, words, expected)
+		}
+	}
+}
+
+func TestForwardLabels(t *testing.T) {
+	model := &Model{
+		Entities:      []*Entity{{Name: "User"}, {Name: "Car"}, {Name: "Pet"}},
+		Relationships: []*Relationship{{From: "User", To: "Car", Label: "cars-owner", Edge: "cars", Ref: "owner"}, {From: "User", To: "Pet", Label: "pets-owner", Edge: "pets", Ref: "owner"}},
+	}
+
+	labelRelationships(model, newOptions([]Option{WithForwardLabels(), WithEdgeLabel("User.pets", "keeps")}))
+
+	if label := model.Relationships[0].Label; label != "cars" {
+		t.Errorf("Got label %q, expected the forward edge name", label)
+	}
+	if label := model.Relationships[1].Label; label != "keeps" {
+		t.Errorf("Got label %q, expected the edge label to take precedence", label)
+	}
+}
+
+func TestMultiplicityLabels(t *testing.T) {
+	model := &Model{
+		Entities:      []*Entity{{Name: "User"}, {Name: "Car"}},
+		Relationships: []*Relationship{{From: "User", To: "Car", FromCardinality: ExactlyOne, ToCardinality: ZeroOrMore, Label: "cars-owner"}},
+	}
+
+	var builder strings.Builder
+	if err := renderMermaid(context.Background(), &builder, model, newOptions([]Option{WithMultiplicityLabels(), WithValidate()})); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := ` User ||--o{ Car : "cars-owner (1 to 0..*)"`; !strings.Contains(builder.String(), expected) {
+		t.Errorf("The diagram is missing %q:\n%s", expected, builder.String())
+	}
+	if model.Relationships[0].Label != "cars-owner" {
+		t.Errorf("Expected the label of the model to be kept, got %q", model.Relationships[0].Label)
+	}
+}
+
+func TestGenerateDiagramMultiplicityLabelsUML(t *testing.T) {
+	mermaidPath := filepath.Join(t.TempDir(), "erd.mmd")
+
+	err := GenerateDiagram("../examples/start/schema", "", Plain, "", "", WithQuiet(), WithNotation(NotationUML), WithMultiplicityLabels(),
+		WithOutput(FormatMermaid, mermaidPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(mermaidPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := `User "0..1" -- "0..*" Car : cars-owner (0..1 to 0..*)`; !strings.Contains(string(content), expected) {
+		t.Errorf("The diagram is missing %q:\n%s", expected, content)
+	}
+}
+
+func TestForeignKeyLabels(t *testing.T) {
+	o := newOptions([]Option{WithForeignKeyLabels(), WithMultiplicityLabels()})
+
+	rel := &Relationship{From: "User", To: "Post", FromCardinality: ZeroOrOne, ToCardinality: ZeroOrMore, Label: "posts-author", ForeignKey: &ForeignKey{Entity: "Post", Column: "user_posts"}}
+	if label := relationshipLabel(rel, o); label != "posts-author (user_posts) (0..1 to 0..*)" {
+		t.Errorf("Got label %q", label)
+	}
+
+	// The relationships without a foreign key keep their label.
+	rel = &Relationship{From: "User", To: "Post", FromCardinality: ZeroOrOne, ToCardinality: ZeroOrMore, Label: "drafts"}
+	if label := relationshipLabel(rel, newOptions([]Option{WithForeignKeyLabels()})); label != "drafts" {
+		t.Errorf("Got label %q", label)
+	}
+}
+
+func TestGenerateDiagramEdgeLabels(t *testing.T) {
+	mermaidPath := filepath.Join(t.TempDir(), "erd.mmd")
+
+	err := GenerateDiagram("../examples/start/schema", "", Plain, "", "",
+		WithEdgeLabel("owner", "owns"), WithEdgeLabel("Group.users", "includes"), WithVerbLabels(), WithOutput(FormatMermaid, mermaidPath))
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	mermaid, err := os.ReadFile(mermaidPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		" User |o--o{ Car : owns\n",
+		" Group |o--o{ group_users : includes\n",
+		` User |o--o{ group_users : "has groups"` + "\n",
+	} {
+		if !strings.Contains(string(mermaid), expected) {
+			t.Errorf("Mermaid output is missing %q:\n%s", expected, mermaid)
+		}
+	}
+}
+
+func TestGenerateDiagramDisplayNames(t *testing.T) {
+	mermaidPath := filepath.Join(t.TempDir(), "erd.mmd")
+
+	err := GenerateDiagram("../examples/start/schema", "", Plain, "", "",
+		WithDisplayName("User", "Account Holder"), WithDisplayName("Pet", "Animal"), WithOutput(FormatMermaid, mermaidPath))
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	mermaid, err := os.ReadFile(mermaidPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		` User["Account Holder"] {` + "\n",
+		" User |o--o{ Car : ",
+	} {
+		if !strings.Contains(string(mermaid), expected) {
+			t.Errorf("Mermaid output is missing %q:\n%s", expected, mermaid)
+		}
+	}
+	if strings.Contains(string(mermaid), "Animal") {
+		t.Errorf("Mermaid output labels an entity not in the schema:\n%s", mermaid)
+	}
+}
+
+func TestMermaidDisplayNameMermaid10(t *testing.T) {
+	model := &Model{Entities: []*Entity{{Name: "PurchaseOrder", DisplayName: "Purchase Order"}}}
+
+	var builder strings.Builder
+	if err := renderMermaid(context.Background(), &builder, model, newOptions([]Option{WithMermaidVersion(Mermaid10)})); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(builder.String(), " %% PurchaseOrder is displayed as Purchase Order\n PurchaseOrder {") {
+		t.Errorf("Mermaid 10 output doesn't comment the display name:\n%s", builder.String())
+	}
+}
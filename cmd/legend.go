@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithLegend appends a legend explaining the notation used by the diagram to the target: a Markdown table after
+// diagrams in Markdown documents, or comments at the end of plain ones.
+func WithLegend() Option {
+	return func(o *options) {
+		o.legend = true
+	}
+}
+
+// legendEntry explains one notation of the diagram.
+type legendEntry struct {
+	notation string
+	meaning  string
+}
+
+var (
+	cardinalityLegend = []struct {
+		cardinality Cardinality
+		entry       legendEntry
+	}{
+		{ZeroOrOne, legendEntry{"|o / o|", "zero or one"}},
+		{ExactlyOne, legendEntry{"||", "exactly one"}},
+		{ZeroOrMore, legendEntry{"}o / o{", "zero or more"}},
+		{OneOrMore, legendEntry{"}| / |{", "one or more"}},
+	}
+
+	logicalLegend = legendEntry{"..", "logical relationship, not enforced by the database"}
+
+	keyLegend = []legendEntry{
+		{"PK", "primary key"},
+		{"FK", "foreign key"},
+		{"UK", "unique key"},
+	}
+
+	viewLegend = legendEntry{"(view)", "read-only view, without foreign keys"}
+
+	guardsLegend = legendEntry{"(policy,hooks,interceptors)", "guarded at runtime by a privacy policy, hooks or interceptors"}
+)
+
+// legendEntries returns the notations used by the model: the cardinalities and lines of its relationships, the keys
+// of its attributes, whether it has views or guarded entities and the abbreviated types and names.
+func legendEntries(model *Model, o *options) []legendEntry {
+	cardinalities := make(map[Cardinality]bool)
+	logical := false
+	for _, rel := range model.Relationships {
+		cardinalities[rel.FromCardinality] = true
+		cardinalities[rel.ToCardinality] = true
+		logical = logical || rel.Logical
+	}
+
+	keys := make(map[string]bool)
+	views := false
+	guarded := false
+	for _, entity := range model.Entities {
+		views = views || entity.View
+		guarded = guarded || len(entity.Guards) > 0
+		for _, attribute := range entity.Attributes {
+			if hiddenAttribute(attribute, o) {
+				continue
+			}
+
+			for _, key := range attribute.Keys {
+				keys[key] = true
+			}
+		}
+	}
+
+	var entries []legendEntry
+	for _, c := range cardinalityLegend {
+		if cardinalities[c.cardinality] {
+			entry := c.entry
+			if o.notation == NotationUML {
+				entry.notation = umlMultiplicities[c.cardinality]
+			}
+			entries = append(entries, entry)
+		}
+	}
+	if logical {
+		entries = append(entries, logicalLegend)
+	}
+
+	for _, entry := range keyLegend {
+		if keys[entry.notation] {
+			entries = append(entries, entry)
+		}
+	}
+
+	// The classDiagram marks them with annotations instead of the entity label.
+	if views {
+		entry := viewLegend
+		if o.notation == NotationUML {
+			entry.notation = "<<view>>"
+		}
+		entries = append(entries, entry)
+	}
+	if guarded {
+		entry := guardsLegend
+		if o.notation == NotationUML {
+			entry.notation = "<<policy,hooks,interceptors>>"
+		}
+		entries = append(entries, entry)
+	}
+
+	for _, abbreviation := range abbreviateModel(model, o).abbreviations {
+		entries = append(entries, legendEntry{abbreviation.Short, abbreviation.Full})
+	}
+
+	return entries
+}
+
+// renderLegend returns the legend of the model to append after the diagram in the output type.
+func renderLegend(model *Model, outputType OutputType, o *options) string {
+	entries := legendEntries(model, o)
+	if len(entries) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+
+	if outputType.isMarkdown() {
+		builder.WriteString("\n\n| Notation | Meaning |\n| -------- | ------- |\n")
+		for _, entry := range entries {
+			builder.WriteString(fmt.Sprintf("| `%s` | %s |\n", strings.ReplaceAll(entry.notation, "|", `\|`), entry.meaning))
+		}
+
+		return strings.TrimSuffix(builder.String(), "\n")
+	}
+
+	builder.WriteString("\n %% legend:")
+	for _, entry := range entries {
+		builder.WriteString(fmt.Sprintf("\n %%%%   %s  %s", entry.notation, entry.meaning))
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import "testing"
+
+func TestRenderLegend(t *testing.T) {
+	model := &Model{
+		Entities: []*Entity{
+			{Name: "User", Attributes: []*Attribute{{Name: "id", Type: "int", Keys: []string{"PK"}}, {Name: "registered_at", Type: "timestamp"}}},
+			{Name: "Car", Attributes: []*Attribute{{Name: "user_cars", Type: "int", Keys: []string{"FK"}}}},
+		},
+		Relationships: []*Relationship{{From: "User", To: "Car", FromCardinality: ZeroOrOne, ToCardinality: OneOrMore}},
+	}
+
+	o := &options{maxTypeLength: 6}
+
+	expected := "\n\n| Notation | Meaning |\n| -------- | ------- |\n" +
+		"| `\\|o / o\\|` | zero or one |\n" +
+		"| `}\\| / \\|{` | one or more |\n" +
+		"| `PK` | primary key |\n" +
+		"| `FK` | foreign key |\n" +
+		"| `times_` | timestamp |"
+
+	if got := renderLegend(model, Markdown, o); got != expected {
+		t.Errorf("Got Markdown legend %q, expected %q", got, expected)
+	}
+
+	expected = "\n %% legend:\n %%   |o / o|  zero or one\n %%   }| / |{  one or more\n %%   PK  primary key\n %%   FK  foreign key\n %%   times_  timestamp"
+	if got := renderLegend(model, Plain, o); got != expected {
+		t.Errorf("Got plain legend %q, expected %q", got, expected)
+	}
+
+	if got := renderLegend(&Model{}, Markdown, o); got != "" {
+		t.Errorf("Expected no legend for an empty model, got %q", got)
+	}
+}
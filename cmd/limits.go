@@ -0,0 +1,31 @@
+package cmd
+
+import "fmt"
+
+// WithEntityLimits warns when the diagram draws more than warn entities and fails with ErrTooManyEntities when it
+// draws more than fail, so CI flags a diagram grown beyond readability before it rots. The entities of a paginated
+// diagram (see WithPageSize) are counted per page. A limit of 0 is no limit.
+func WithEntityLimits(warn int, fail int) Option {
+	return func(o *options) {
+		o.warnEntities = warn
+		o.failEntities = fail
+	}
+}
+
+// checkEntityLimits compares the number of entities of the largest diagram of the model to the limits.
+func checkEntityLimits(model *Model, o *options) error {
+	count := len(model.Entities)
+	if o.pageSize > 0 {
+		count = min(count, o.pageSize)
+	}
+
+	const advice = "split it with --page-size, or draw parts of it with --entity or --domains"
+	switch {
+	case o.failEntities > 0 && count > o.failEntities:
+		return fmt.Errorf("%w: the diagram draws %d entities, more than %d: %s", ErrTooManyEntities, count, o.failEntities, advice)
+	case o.warnEntities > 0 && count > o.warnEntities:
+		o.warn("", "", "the diagram draws %d entities, more than %d: %s", count, o.warnEntities, advice)
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestEntityLimits(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		opts     []Option
+		warnings int
+		fails    bool
+	}{
+		{"under", []Option{WithEntityLimits(4, 4)}, 0, false},
+		{"warn", []Option{WithEntityLimits(3, 0)}, 1, false},
+		{"fail", []Option{WithEntityLimits(2, 3)}, 0, true},
+		{"paginated", []Option{WithEntityLimits(2, 2), WithPageSize(2)}, 0, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			o := newOptions(append([]Option{WithQuiet()}, tc.opts...))
+
+			_, err := loadModel(context.Background(), "../examples/start/schema", o)
+			if fails := errors.Is(err, ErrTooManyEntities); fails != tc.fails {
+				t.Errorf("Expected the limit to fail: %t, got %v", tc.fails, err)
+			}
+			if !tc.fails && err != nil {
+				t.Fatal(err)
+			}
+			if len(o.warnings) != tc.warnings {
+				t.Errorf("Expected %d warnings, got %v", tc.warnings, o.warnings)
+			}
+		})
+	}
+}
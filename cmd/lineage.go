@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// FormatOpenLineage is the list of the tables as OpenLineage datasets with their schema facet, to register the
+// schema in a data catalog (e.g. Marquez or DataHub).
+const FormatOpenLineage Format = "openlineage"
+
+const (
+	// defaultLineageNamespace is the namespace of the datasets when none is given.
+	defaultLineageNamespace = "ent"
+	lineageProducer         = "https://github.com/lespea/entmaid"
+	lineageSchemaURL        = "https://openlineage.io/spec/facets/1-1-1/SchemaDatasetFacet.json"
+	lineageDocumentationURL = "https://openlineage.io/spec/facets/1-0-1/DocumentationDatasetFacet.json"
+)
+
+// WithLineageNamespace sets the namespace of the OpenLineage datasets, the data source the tables are stored in
+// (e.g. postgres://db.acme.com:5432).
+func WithLineageNamespace(namespace string) Option {
+	return func(o *options) {
+		o.lineageNS = namespace
+	}
+}
+
+// LineageDataset is an OpenLineage dataset of a table.
+type LineageDataset struct {
+	Namespace string         `json:"namespace"`
+	Name      string         `json:"name"`
+	Facets    *LineageFacets `json:"facets"`
+}
+
+// LineageFacets are the facets of the dataset: its columns, and the entity it's the table of.
+type LineageFacets struct {
+	Schema        *LineageSchemaFacet        `json:"schema"`
+	Documentation *LineageDocumentationFacet `json:"documentation"`
+}
+
+// LineageSchemaFacet lists the columns of the table.
+type LineageSchemaFacet struct {
+	Producer  string          `json:"_producer"`
+	SchemaURL string          `json:"_schemaURL"`
+	Fields    []*LineageField `json:"fields"`
+}
+
+// LineageField is a column of the table, typed as in the diagram.
+type LineageField struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+}
+
+// LineageDocumentationFacet describes the table.
+type LineageDocumentationFacet struct {
+	Producer    string `json:"_producer"`
+	SchemaURL   string `json:"_schemaURL"`
+	Description string `json:"description"`
+}
+
+// renderOpenLineage writes the tables as OpenLineage datasets named schema.table, with the columns, their types and
+// the comments of their fields in the schema facet.
+func renderOpenLineage(_ context.Context, w io.Writer, model *Model, o *options) error {
+	namespace := o.lineageNS
+	if namespace == "" {
+		namespace = defaultLineageNamespace
+	}
+
+	datasets := make([]*LineageDataset, 0, len(model.Entities))
+	for _, entity := range model.Entities {
+		name := entity.Table
+		if entity.Schema != "" {
+			name = entity.Schema + "." + name
+		}
+
+		schema := &LineageSchemaFacet{Producer: lineageProducer, SchemaURL: lineageSchemaURL, Fields: []*LineageField{}}
+		for _, attribute := range entity.Attributes {
+			schema.Fields = append(schema.Fields, &LineageField{Name: attribute.Name, Type: attribute.Type, Description: attribute.comment})
+		}
+
+		documentation := &LineageDocumentationFacet{Producer: lineageProducer, SchemaURL: lineageDocumentationURL, Description: lineageDescription(entity)}
+
+		datasets = append(datasets, &LineageDataset{Namespace: namespace, Name: name, Facets: &LineageFacets{Schema: schema, Documentation: documentation}})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(datasets)
+}
+
+// lineageDescription describes what the table holds, as the diagram tags it.
+func lineageDescription(entity *Entity) string {
+	var description []string
+	switch {
+	case entity.JoinTable:
+		description = append(description, "Join table of "+entity.Name)
+	case entity.View:
+		description = append(description, "View of the "+entity.Name+" entity")
+	default:
+		description = append(description, "Table of the "+entity.Name+" entity")
+	}
+
+	if entity.URL != "" {
+		description = append(description, "defined in "+entity.URL)
+	}
+
+	return strings.Join(description, ", ")
+}
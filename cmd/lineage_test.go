@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestRenderOpenLineage(t *testing.T) {
+	model := &Model{
+		Entities: []*Entity{
+			{Name: "billing.Invoice", Table: "invoices", Schema: "billing", URL: "https://example.com/invoice.go", Attributes: []*Attribute{
+				{Name: "id", Type: "int", Keys: []string{"PK"}},
+				{Name: "total", Type: "numeric(10,2)", comment: "total with taxes"},
+			}},
+			{Name: "user_groups", Table: "user_groups", JoinTable: true},
+		},
+	}
+
+	for namespace, expected := range map[string]string{"": defaultLineageNamespace, "postgres://db:5432": "postgres://db:5432"} {
+		var buf bytes.Buffer
+		if err := renderOpenLineage(context.Background(), &buf, model, newOptions([]Option{WithLineageNamespace(namespace)})); err != nil {
+			t.Fatal(err)
+		}
+
+		var datasets []*LineageDataset
+		if err := json.Unmarshal(buf.Bytes(), &datasets); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(datasets) != 2 || datasets[0].Namespace != expected || datasets[0].Name != "billing.invoices" || datasets[1].Name != "user_groups" {
+			t.Fatalf("Unexpected datasets in the namespace %q:\n%s", namespace, buf.String())
+		}
+
+		fields := datasets[0].Facets.Schema.Fields
+		if len(fields) != 2 || *fields[1] != (LineageField{Name: "total", Type: "numeric(10,2)", Description: "total with taxes"}) {
+			t.Errorf("Unexpected fields %+v", fields)
+		}
+
+		if description := datasets[0].Facets.Documentation.Description; description != "Table of the billing.Invoice entity, defined in https://example.com/invoice.go" {
+			t.Errorf("Unexpected description %q", description)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LinkGitHub is the link template that links to the schema files on GitHub, derived from the origin remote and
+// its default branch.
+const LinkGitHub = "github"
+
+// WithLinks links every entity to its schema file using the URL template, in the formats that support links.
+// The template can contain {path} (the schema file relative to the git repository root), {line}, {entity}
+// and {table}, or be LinkGitHub.
+func WithLinks(template string) Option {
+	return func(o *options) {
+		o.linkTemplate = template
+	}
+}
+
+// addLinks sets the URL of every entity declared in a schema file.
+func addLinks(ctx context.Context, model *Model, o *options) error {
+	template := o.linkTemplate
+	if template == LinkGitHub {
+		var err error
+		template, err = githubLinkTemplate(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to link to GitHub: %w", err)
+		}
+	}
+
+	root, _ := gitOutput(ctx, "rev-parse", "--show-toplevel")
+
+	for _, entity := range model.Entities {
+		if entity.Source == nil {
+			continue
+		}
+
+		path := entity.Source.File
+		if root != "" {
+			if abs, err := filepath.Abs(path); err == nil {
+				if rel, err := filepath.Rel(root, abs); err == nil {
+					path = filepath.ToSlash(rel)
+				}
+			}
+		}
+
+		entity.URL = strings.NewReplacer(
+			"{path}", path,
+			"{line}", strconv.Itoa(entity.Source.Line),
+			"{entity}", entity.Name,
+			"{table}", entity.Table,
+		).Replace(template)
+
+		o.logger.Debug("linked entity", "entity", entity.Name, "url", entity.URL)
+	}
+
+	return nil
+}
+
+// EveryEntity is the entity WithEntityLink links every entity with, when neither the Linked annotation of its schema
+// type nor a WithEntityLink of its own links it.
+const EveryEntity = "*"
+
+// WithEntityLink links the entity to the external page documenting it, like its data catalog entry or runbook,
+// taking precedence over the Linked annotation of its schema type. The URL can contain {entity} and {table}, which
+// is mostly useful for EveryEntity, e.g. https://catalog.example.com/tables/{table}.
+func WithEntityLink(entity string, url string) Option {
+	return func(o *options) {
+		if o.entityLinks == nil {
+			o.entityLinks = make(map[string]string)
+		}
+		o.entityLinks[entity] = url
+	}
+}
+
+// linkEntities sets the links of WithEntityLink on the entities of the model.
+func linkEntities(model *Model, o *options) {
+	for _, name := range slices.Sorted(maps.Keys(o.entityLinks)) {
+		if name != EveryEntity && model.Entity(name) == nil {
+			o.warn(name, "", "is not an entity of the schema, it can't be linked")
+		}
+	}
+
+	for _, entity := range model.Entities {
+		url, ok := o.entityLinks[entity.Name]
+		if !ok && (entity.Link != "" || entity.JoinTable) {
+			continue
+		}
+		if !ok {
+			if url, ok = o.entityLinks[EveryEntity]; !ok {
+				continue
+			}
+		}
+
+		entity.Link = strings.NewReplacer("{entity}", entity.Name, "{table}", entity.Table).Replace(url)
+		o.logger.Debug("linked entity to its documentation", "entity", entity.Name, "link", entity.Link)
+	}
+}
+
+// githubRemoteRegex matches the owner and repository of both SSH and HTTPS GitHub remote URLs.
+var githubRemoteRegex = regexp.MustCompile(`github\.com[:/]([^/]+)/(.+?)(?:\.git)?/?$`)
+
+// githubLinkTemplate builds the link template for the repository of the origin remote, on its default branch so
+// the links don't change with every commit.
+func githubLinkTemplate(ctx context.Context) (string, error) {
+	remote, err := gitOutput(ctx, "remote", "get-url", "origin")
+	if err != nil {
+		return "", err
+	}
+
+	match := githubRemoteRegex.FindStringSubmatch(remote)
+	if match == nil {
+		return "", fmt.Errorf("origin remote %s is not on GitHub", remote)
+	}
+
+	branch := "main"
+	if head, err := gitOutput(ctx, "symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		branch = strings.TrimPrefix(head, "origin/")
+	}
+
+	return fmt.Sprintf("https://github.com/%s/%s/blob/%s/{path}#L{line}", match[1], match[2], branch), nil
+}
+
+func gitOutput(ctx context.Context, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+
+	return string(bytes.TrimSpace(out)), nil
+}
+
+// markdownLinks lists the links of the entities below a Markdown diagram, as Mermaid ER diagrams can't link them.
+// The entities link to their documentation when they have one, followed by their schema file.
+func markdownLinks(model *Model) string {
+	var builder strings.Builder
+
+	for _, entity := range model.Entities {
+		switch {
+		case entity.Link != "" && entity.URL != "":
+			builder.WriteString(fmt.Sprintf("\n- [%s](%s) ([schema](%s))", entity.Name, entity.Link, entity.URL))
+		case entity.Link != "":
+			builder.WriteString(fmt.Sprintf("\n- [%s](%s)", entity.Name, entity.Link))
+		case entity.URL != "":
+			builder.WriteString(fmt.Sprintf("\n- [%s](%s)", entity.Name, entity.URL))
+		}
+	}
+
+	if builder.Len() == 0 {
+		return ""
+	}
+
+	return "\n" + builder.String()
+}
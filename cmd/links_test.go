@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateDiagramLinks(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "readme.md")
+	if err := os.WriteFile(target, []byte("<!-- #start:entmaid -->\n<!-- #end:entmaid -->\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dbmlPath := filepath.Join(t.TempDir(), "schema.dbml")
+
+	err := GenerateDiagram("../examples/start/schema", target, Markdown, "", "",
+		WithLinks("https://example.com/{path}#L{line}?{entity}={table}"), WithOutput(FormatDBML, dbmlPath))
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	content, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "```\n\n- [Car](https://example.com/examples/start/schema/car.go#L"
+	if !strings.Contains(string(content), expected) || !strings.Contains(string(content), "?Car=cars)\n") {
+		t.Errorf("Target is missing the link to the Car schema:\n%s", content)
+	}
+
+	if strings.Contains(string(content), "[group_users]") {
+		t.Errorf("Join tables shouldn't be linked:\n%s", content)
+	}
+
+	dbml, err := os.ReadFile(dbmlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(dbml), "  Note: 'Defined in https://example.com/examples/start/schema/user.go#L") {
+		t.Errorf("DBML output is missing the note linking the User schema:\n%s", dbml)
+	}
+}
+
+func TestGithubRemoteRegex(t *testing.T) {
+	for _, remote := range []string{"git@github.com:lespea/entmaid.git", "https://github.com/lespea/entmaid", "https://github.com/lespea/entmaid.git"} {
+		match := githubRemoteRegex.FindStringSubmatch(remote)
+		if match == nil || match[1] != "lespea" || match[2] != "entmaid" {
+			t.Errorf("Failed to parse %s, got %q", remote, match)
+		}
+	}
+}
+
+func TestGenerateDiagramEntityLinks(t *testing.T) {
+	mermaidPath := filepath.Join(t.TempDir(), "erd.mmd")
+
+	// The Customer schema type is linked by its annotation, which the link of every other entity doesn't override.
+	err := GenerateDiagram("../examples/annotations/schema", "", Plain, "", "", WithNotation(NotationUML),
+		WithEntityLink(EveryEntity, "https://catalog.example.com/{entity}/{table}"), WithEntityLink("Order", "https://runbooks.example.com/orders"),
+		WithOutput(FormatMermaid, mermaidPath))
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	content, err := os.ReadFile(mermaidPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		` click Customer href "https://catalog.example.com/tables/customers"` + "\n",
+		` click Order href "https://runbooks.example.com/orders"` + "\n",
+		` click OrderLine href "https://catalog.example.com/OrderLine/order_lines"` + "\n",
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Diagram is missing %q:\n%s", expected, content)
+		}
+	}
+}
+
+func TestMarkdownLinks(t *testing.T) {
+	model := &Model{Entities: []*Entity{
+		{Name: "User", URL: "https://example.com/user.go", Link: "https://catalog.example.com/users"},
+		{Name: "Car", Link: "https://catalog.example.com/cars"},
+		{Name: "Group", URL: "https://example.com/group.go"},
+		{Name: "Pet"},
+	}}
+
+	expected := "\n\n- [User](https://catalog.example.com/users) ([schema](https://example.com/user.go))" +
+		"\n- [Car](https://catalog.example.com/cars)\n- [Group](https://example.com/group.go)"
+	if links := markdownLinks(model); links != expected {
+		t.Errorf("markdownLinks() = %q, expected %q", links, expected)
+	}
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// LintRule is a check of the lint pass of WithLint.
+type LintRule string
+
+const (
+	// LintBackRefs finds the edges without a back-reference, which can't be queried from the other side.
+	LintBackRefs LintRule = "back-refs"
+	// LintIndexes finds the foreign key columns that aren't the first column of an index, which the databases not
+	// indexing them by themselves (e.g. Postgres) scan when joining or cascading.
+	LintIndexes LintRule = "indexes"
+	// LintColumns finds the entities with more columns than WithLintMaxColumns.
+	LintColumns LintRule = "columns"
+	// LintNaming finds the column and table names not following the naming style of most of the others, snake_case
+	// or camelCase.
+	LintNaming LintRule = "naming"
+)
+
+// LintRules are every lint rule, in the order they're run.
+var LintRules = []LintRule{LintBackRefs, LintIndexes, LintColumns, LintNaming}
+
+// DefaultLintMaxColumns is the number of columns LintColumns allows by default.
+const DefaultLintMaxColumns = 30
+
+// ParseLintRule returns the lint rule of the name.
+func ParseLintRule(name string) (LintRule, bool) {
+	rule := LintRule(name)
+
+	return rule, slices.Contains(LintRules, rule)
+}
+
+// WithLint runs the lint rules over the model, every rule when none is given, reporting their findings as warnings
+// (see WithWarningHandler and WithStrict) that tell the rule.
+func WithLint(rules ...LintRule) Option {
+	return func(o *options) {
+		if len(rules) == 0 {
+			rules = LintRules
+		}
+		o.lintRules = append(o.lintRules, rules...)
+	}
+}
+
+// WithLintMaxColumns is the number of columns an entity can have before LintColumns reports it,
+// DefaultLintMaxColumns by default.
+func WithLintMaxColumns(columns int) Option {
+	return func(o *options) {
+		o.lintMaxColumns = columns
+	}
+}
+
+// WithLintOverlay draws the findings of WithLint in the Mermaid diagram, as comments below their entity which is
+// outlined as a warning when it isn't styled.
+func WithLintOverlay() Option {
+	return func(o *options) {
+		o.lintOverlay = true
+	}
+}
+
+// lintStyle outlines the entities with findings in the Mermaid diagram with WithLintOverlay, after the color of
+// the warnings of GitHub.
+const lintStyle = "stroke:#9a6700,stroke-width:2px,stroke-dasharray:4"
+
+// lintModel runs the lint rules over the model.
+func lintModel(model *Model, o *options) {
+	for _, rule := range LintRules {
+		if !slices.Contains(o.lintRules, rule) {
+			continue
+		}
+
+		switch rule {
+		case LintBackRefs:
+			lintBackRefs(model, o)
+		case LintIndexes:
+			lintIndexes(model, o)
+		case LintColumns:
+			lintColumns(model, o)
+		case LintNaming:
+			lintNaming(model, o)
+		}
+	}
+}
+
+// lint reports the finding of the rule about the entity, and keeps it on the entity for WithLintOverlay.
+func (o *options) lint(rule LintRule, entity *Entity, element string, format string, args ...any) {
+	w := Warning{Entity: entity.Name, Element: element, Message: fmt.Sprintf(format, args...), Rule: rule}
+
+	o.logger.Debug("lint finding", "rule", rule, "entity", w.Entity, "element", w.Element, "finding", w.Message)
+
+	entity.lints = append(entity.lints, w)
+	o.addWarning(w)
+}
+
+// lintBackRefs reports the edges of the relationships without a back-reference, once for the two relationships
+// to the join table of a M2M edge.
+func lintBackRefs(model *Model, o *options) {
+	reported := make(map[[2]string]bool)
+	for _, rel := range model.Relationships {
+		key := [2]string{rel.From, rel.Edge}
+		if rel.Edge == "" || rel.Ref != "" || reported[key] {
+			continue
+		}
+		reported[key] = true
+
+		if entity := model.Entity(rel.From); entity != nil {
+			o.lint(LintBackRefs, entity, rel.Edge, "the edge has no back-reference, %s can't be queried back from %s", rel.To, entity.Name)
+		}
+	}
+}
+
+// lintIndexes reports the foreign key columns that no index starts with, the primary key and the unique columns
+// included.
+func lintIndexes(model *Model, o *options) {
+	reported := make(map[[2]string]bool)
+	for _, rel := range model.Relationships {
+		fk := rel.ForeignKey
+		if fk == nil || reported[[2]string{fk.Entity, fk.Column}] {
+			continue
+		}
+		reported[[2]string{fk.Entity, fk.Column}] = true
+
+		entity := model.Entity(fk.Entity)
+		if entity == nil || indexedColumn(entity, fk.Column) {
+			continue
+		}
+
+		o.lint(LintIndexes, entity, fk.Column, "the foreign key column referencing %s is not indexed", fk.RefEntity)
+	}
+}
+
+// indexedColumn tells whether an index of the entity starts with the column.
+func indexedColumn(entity *Entity, column string) bool {
+	for _, index := range entity.Indexes {
+		if len(index.Columns) > 0 && index.Columns[0] == column {
+			return true
+		}
+	}
+
+	for _, attribute := range entity.Attributes {
+		if slices.Contains(attribute.Keys, "PK") {
+			// Only the first column of a composite primary key leads its index.
+			return attribute.Name == column
+		}
+	}
+
+	attribute := entity.Attribute(column)
+
+	return attribute != nil && slices.Contains(attribute.Keys, "UK")
+}
+
+// lintColumns reports the entities with more columns than allowed.
+func lintColumns(model *Model, o *options) {
+	limit := o.lintMaxColumns
+	if limit <= 0 {
+		limit = DefaultLintMaxColumns
+	}
+
+	for _, entity := range model.Entities {
+		if len(entity.Attributes) > limit {
+			o.lint(LintColumns, entity, "", "has %d columns, more than %d", len(entity.Attributes), limit)
+		}
+	}
+}
+
+var (
+	snakeCaseRegex = regexp.MustCompile(`^[a-z][a-z0-9]*(?:_[a-z0-9]+)+$`)
+	camelCaseRegex = regexp.MustCompile(`^[a-z][a-z0-9]*(?:[A-Z][a-z0-9]*)+$`)
+)
+
+// namingStyle returns the naming style of the name, "" for the names of a single word which fit both styles.
+func namingStyle(name string) string {
+	switch {
+	case snakeCaseRegex.MatchString(name):
+		return "snake_case"
+	case camelCaseRegex.MatchString(name):
+		return "camelCase"
+	default:
+		return ""
+	}
+}
+
+// lintNaming reports the columns and the tables named in the style used by fewer of them, snake_case winning ties
+// as ent names them this way.
+func lintNaming(model *Model, o *options) {
+	type name struct {
+		entity  *Entity
+		element string
+		name    string
+		style   string
+	}
+
+	var columns, tables []name
+	for _, entity := range model.Entities {
+		tables = append(tables, name{entity, "", entity.Table, namingStyle(entity.Table)})
+		for _, attribute := range entity.Attributes {
+			columns = append(columns, name{entity, attribute.Name, attribute.Name, namingStyle(attribute.Name)})
+		}
+	}
+
+	for _, group := range []struct {
+		kind  string
+		names []name
+	}{{"column", columns}, {"table", tables}} {
+		kind, names := group.kind, group.names
+		styles := make(map[string]int)
+		for _, n := range names {
+			styles[n.style]++
+		}
+
+		if styles["snake_case"] == 0 || styles["camelCase"] == 0 {
+			continue
+		}
+
+		style, minority := "snake_case", "camelCase"
+		if styles["camelCase"] > styles["snake_case"] {
+			style, minority = minority, style
+		}
+
+		for _, n := range names {
+			if n.style == minority {
+				o.lint(LintNaming, n.entity, n.element, "the %s name %s isn't in the %s of most %ss", kind, n.name, style, kind)
+			}
+		}
+	}
+}
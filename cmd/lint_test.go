@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLintModel(t *testing.T) {
+	user := &Entity{Name: "User", Table: "users", Attributes: []*Attribute{
+		{Name: "id", Keys: []string{"PK"}}, {Name: "first_name"}, {Name: "last_name"}, {Name: "createdAt"},
+	}}
+	car := &Entity{Name: "Car", Table: "cars", Attributes: []*Attribute{
+		{Name: "id", Keys: []string{"PK"}}, {Name: "user_cars", Keys: []string{"FK"}}, {Name: "plate_id", Keys: []string{"FK"}},
+	}, Indexes: []*Index{{Name: "car_plate_id", Columns: []string{"plate_id"}}}}
+	plate := &Entity{Name: "Plate", Table: "licensePlates", Attributes: []*Attribute{{Name: "id", Keys: []string{"PK"}}}}
+
+	model := &Model{
+		Entities: []*Entity{user, car, plate},
+		Relationships: []*Relationship{
+			{From: "User", To: "Car", Edge: "cars", Ref: "owner", ForeignKey: &ForeignKey{Entity: "Car", Column: "user_cars", RefEntity: "User", RefColumn: "id"}},
+			{From: "Car", To: "Plate", Edge: "plate", ForeignKey: &ForeignKey{Entity: "Car", Column: "plate_id", RefEntity: "Plate", RefColumn: "id"}},
+		},
+	}
+
+	o := newOptions([]Option{WithLint(), WithLintMaxColumns(3)})
+	lintModel(model, o)
+
+	expected := []Warning{
+		{Entity: "Car", Element: "plate", Message: "the edge has no back-reference, Plate can't be queried back from Car", Rule: LintBackRefs},
+		{Entity: "Car", Element: "user_cars", Message: "the foreign key column referencing User is not indexed", Rule: LintIndexes},
+		{Entity: "User", Message: "has 4 columns, more than 3", Rule: LintColumns},
+		{Entity: "User", Element: "createdAt", Message: "the column name createdAt isn't in the snake_case of most columns", Rule: LintNaming},
+	}
+	if !reflect.DeepEqual(o.warnings, expected) {
+		t.Errorf("Got warnings %v, expected %v", o.warnings, expected)
+	}
+
+	// The findings are kept on their entity for the overlay.
+	if len(user.lints) != 2 || len(car.lints) != 2 || len(plate.lints) != 0 {
+		t.Errorf("Unexpected findings of the entities: %v, %v, %v", user.lints, car.lints, plate.lints)
+	}
+}
+
+func TestLintModelRules(t *testing.T) {
+	model := &Model{Entities: []*Entity{{Name: "User", Table: "users", Attributes: []*Attribute{{Name: "userName"}, {Name: "last_name"}}}}}
+
+	o := newOptions([]Option{WithLint(LintColumns), WithLintMaxColumns(1)})
+	lintModel(model, o)
+
+	// The naming tie isn't reported without its rule.
+	if len(o.warnings) != 1 || o.warnings[0].Rule != LintColumns {
+		t.Errorf("Expected only the finding of the columns rule, got %v", o.warnings)
+	}
+}
+
+func TestWarningStringRule(t *testing.T) {
+	w := Warning{Entity: "Car", Element: "user_cars", Message: "the foreign key column referencing User is not indexed", Rule: LintIndexes}
+
+	if expected := "Car.user_cars: the foreign key column referencing User is not indexed (indexes)"; w.String() != expected {
+		t.Errorf("Got %q, expected %q", w.String(), expected)
+	}
+}
+
+func TestGenerateDiagramLintOverlay(t *testing.T) {
+	mermaidPath := filepath.Join(t.TempDir(), "erd.mmd")
+
+	var warnings []Warning
+	err := GenerateDiagram("../examples/start/schema", "", Plain, "", "", WithLint(LintIndexes), WithLintOverlay(),
+		WithWarningHandler(func(w Warning) { warnings = append(warnings, w) }), WithOutput(FormatMermaid, mermaidPath))
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	content, err := os.ReadFile(mermaidPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		" %% warning (indexes) Car.user_cars: the foreign key column referencing User is not indexed\n",
+		// Only the first column of the primary key of the join table leads its index.
+		" %% warning (indexes) group_users.user_id: the foreign key column referencing User is not indexed\n",
+		" style Car " + lintStyle + "\n",
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Diagram is missing %q:\n%s", expected, content)
+		}
+	}
+
+	if strings.Contains(string(content), "style User") {
+		t.Errorf("User has no finding but is outlined:\n%s", content)
+	}
+	if len(warnings) != 2 {
+		t.Errorf("Expected the 2 findings to be passed to the warning handler, got %v", warnings)
+	}
+}
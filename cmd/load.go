@@ -0,0 +1,370 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"entgo.io/ent/entc"
+	"entgo.io/ent/entc/gen"
+	"entgo.io/ent/entc/load"
+	"golang.org/x/tools/go/packages"
+)
+
+// WithCache stores the loaded schema in dir, keyed by a hash of the schema files and of the packages they import, so
+// later runs against an unchanged schema skip building and running the schema package. An empty dir uses the user's
+// cache directory.
+func WithCache(dir string) Option {
+	return func(o *options) {
+		o.cache = true
+		o.cacheDir = dir
+	}
+}
+
+// WithGenConfig loads the schema graph with a copy of the codegen config, so the features, ID type and annotations
+// the project generates its code with shape the diagram the same way. The Schema is always the loaded package, the
+// Package and Target default to the parent of the schema directory like entc.
+func WithGenConfig(cfg *gen.Config) Option {
+	return func(o *options) {
+		o.genConfig = cfg
+	}
+}
+
+// WithEntcOptions applies the options given to entc.Generate by the project's codegen (e.g. entc.FeatureNames or
+// entc.Storage) to the config the schema graph is loaded with, after WithGenConfig.
+func WithEntcOptions(opts ...entc.Option) Option {
+	return func(o *options) {
+		o.entcOptions = append(o.entcOptions, opts...)
+	}
+}
+
+// featureNames lists the names of the ent features, as given to entc.FeatureNames.
+func featureNames() []string {
+	names := make([]string, len(gen.AllFeatures))
+	for i, feature := range gen.AllFeatures {
+		names[i] = feature.Name
+	}
+
+	return names
+}
+
+// loadMu serializes loading schemas, entc builds the loader program in a temporary directory below the working
+// directory and concurrent (or abandoned, after a timeout) loads would remove it from under each other.
+var loadMu sync.Mutex
+
+// cachedSpec is the part of the loaded schema spec kept in the cache.
+type cachedSpec struct {
+	PkgPath string         `json:"pkgPath"`
+	Schemas []*load.Schema `json:"schemas"`
+	// Positions holds the position of each schema by name, which isn't part of their JSON encoding.
+	Positions map[string]string `json:"positions,omitempty"`
+}
+
+// resolveSchemaPath returns the directory of the schema, which can also be given as a Go import path
+// (e.g. github.com/acme/app/ent/schema) resolved from the module in the working directory.
+func resolveSchemaPath(schemaPath string) (string, error) {
+	if info, err := os.Stat(schemaPath); err == nil && info.IsDir() {
+		return schemaPath, nil
+	}
+
+	if filepath.IsAbs(schemaPath) || strings.HasPrefix(schemaPath, ".") {
+		return schemaPath, nil
+	}
+
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedName | packages.NeedFiles}, schemaPath)
+	if err != nil {
+		return "", err
+	}
+
+	if len(pkgs) != 1 {
+		return "", fmt.Errorf("import path %s matches %d packages, expected one", schemaPath, len(pkgs))
+	}
+
+	pkg := pkgs[0]
+	if len(pkg.Errors) > 0 {
+		return "", pkg.Errors[0]
+	}
+
+	if len(pkg.GoFiles) == 0 {
+		return "", fmt.Errorf("no Go files in the package %s", schemaPath)
+	}
+
+	return filepath.Dir(pkg.GoFiles[0]), nil
+}
+
+// loadGraph loads the schema graph, giving up once the context is done.
+// entc doesn't support cancellation so the load keeps running in the background until it finishes by itself, which
+// exiting waits for (see awaitLoads).
+func loadGraph(ctx context.Context, schemaPath string, o *options) (*gen.Graph, error) {
+	type result struct {
+		spec *cachedSpec
+		err  error
+	}
+
+	loadSpec := loadSchemaSpec
+	if o.specs != nil {
+		loadSpec = o.specs.load
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		spec, err := loadSpec(schemaPath, o)
+		done <- result{spec: spec, err: err}
+	}()
+
+	var spec *cachedSpec
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-done:
+		if res.err != nil {
+			return nil, res.err
+		}
+		spec = res.spec
+	}
+
+	// Mirrors entc.LoadGraph, which can't be given an already loaded spec.
+	abs, err := filepath.Abs(schemaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &gen.Config{}
+	if o.genConfig != nil {
+		copied := *o.genConfig
+		cfg = &copied
+	}
+
+	for _, opt := range o.entcOptions {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg.Schema = spec.PkgPath
+	if cfg.Package == "" {
+		cfg.Package = path.Dir(spec.PkgPath)
+	}
+	if cfg.Target == "" {
+		cfg.Target = filepath.Dir(abs)
+	}
+
+	return gen.NewGraph(cfg, spec.Schemas...)
+}
+
+// loadSchemaSpec loads the schemas from the schema package, using the cache when enabled.
+func loadSchemaSpec(schemaPath string, o *options) (*cachedSpec, error) {
+	var cachePath string
+	if o.cache {
+		var err error
+		cachePath, err = schemaCachePath(schemaPath, o.cacheDir)
+		if err != nil {
+			return nil, err
+		}
+
+		spec, err := readCachedSpec(cachePath)
+		if err == nil {
+			o.logger.Info("using cached schema", "cache", cachePath)
+			return spec, nil
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			o.logger.Warn("ignoring unreadable schema cache", "cache", cachePath, "error", err)
+		}
+	}
+
+	loadMu.Lock()
+	loaded, partial, err := loadSpec(schemaPath, o)
+	loadMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &cachedSpec{PkgPath: loaded.PkgPath, Schemas: loaded.Schemas, Positions: make(map[string]string, len(loaded.Schemas))}
+	for _, schema := range loaded.Schemas {
+		spec.Positions[schema.Name] = schema.Pos
+	}
+
+	// A partial spec is never cached, the next run should try the whole schema again.
+	if cachePath != "" && !partial {
+		if err := writeCacheFile(cachePath, spec); err != nil {
+			o.logger.Warn("failed to cache the schema", "cache", cachePath, "error", err)
+		}
+	}
+
+	return spec, nil
+}
+
+// loadSpec loads the schema package, reporting every diagnostic when it doesn't build, or loading the files that
+// do build in partial mode.
+func loadSpec(schemaPath string, o *options) (*load.SchemaSpec, bool, error) {
+	// The directory entc builds the loader program in is left behind when the process exits in the middle of it.
+	defer trackLoadDir(entcDir)()
+
+	loaded, err := (&load.Config{Path: schemaPath}).Load()
+	if err == nil {
+		return loaded, false, nil
+	}
+
+	diagnostics, diagnosticsErr := schemaDiagnostics(schemaPath)
+	if diagnosticsErr != nil || len(diagnostics) == 0 {
+		return nil, false, err
+	}
+
+	// Errors of go list without a position, e.g. a toolchain mismatch, aren't about the schema files.
+	if !slices.ContainsFunc(diagnostics, Diagnostic.located) {
+		return nil, false, fmt.Errorf("%w:%s", err, diagnosticLines(diagnostics))
+	}
+
+	if !o.partial {
+		return nil, false, &BuildError{Diagnostics: diagnostics}
+	}
+
+	loaded, err = loadPartialSpec(schemaPath, diagnostics, o)
+
+	return loaded, true, err
+}
+
+// schemaCachePath returns the cache file for the current content of the schema, the entmaid version is part of the
+// key so upgrading never reuses a schema loaded by an older release.
+func schemaCachePath(schemaPath string, cacheDir string) (string, error) {
+	cacheDir, err := cacheDirectory(cacheDir)
+	if err != nil {
+		return "", err
+	}
+
+	abs, err := filepath.Abs(schemaPath)
+	if err != nil {
+		return "", err
+	}
+
+	schemaHash, err := hashSchema(schemaPath)
+	if err != nil {
+		return "", fmt.Errorf("%w at the path %s: %w", ErrSchemaHash, schemaPath, err)
+	}
+
+	// The mixins, custom types and annotations of the schema can be declared in the packages it imports.
+	depsHash, err := hashDependencies(abs)
+	if err != nil {
+		return "", fmt.Errorf("%w at the path %s: %w", ErrSchemaHash, schemaPath, err)
+	}
+
+	key := sha256.Sum256([]byte(abs + "\x00" + schemaHash + "\x00" + depsHash + "\x00" + version()))
+
+	return filepath.Join(cacheDir, "schema-"+hex.EncodeToString(key[:])+".json"), nil
+}
+
+// hashDependencies returns a hash of the packages the schema package imports, but the standard library. The packages
+// of the modules required at a version are hashed by it, the others (of the main module, or replaced by a directory)
+// by their Go files.
+func hashDependencies(dir string) (string, error) {
+	mode := packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedModule
+	// Loaded from the directory, so it's resolved in the module of the schema. The packages that don't build are
+	// left to the load of the schema to report.
+	pkgs, err := packages.Load(&packages.Config{Mode: mode, Dir: dir}, ".")
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	var hashErr error
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		// The schema package is hashed by itself, and the standard library goes with the Go version.
+		if hashErr != nil || pkg.Module == nil || slices.Contains(pkgs, pkg) {
+			return
+		}
+
+		if !pkg.Module.Main && pkg.Module.Replace == nil {
+			fmt.Fprintf(hash, "%s\x00%s@%s\x00", pkg.PkgPath, pkg.Module.Path, pkg.Module.Version)
+			return
+		}
+
+		fmt.Fprintf(hash, "%s\x00", pkg.PkgPath)
+		for _, file := range pkg.GoFiles {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				hashErr = err
+				return
+			}
+
+			fmt.Fprintf(hash, "%s\x00%d\x00", filepath.Base(file), len(content))
+			hash.Write(content)
+		}
+	})
+	if hashErr != nil {
+		return "", hashErr
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func readCachedSpec(cachePath string) (*cachedSpec, error) {
+	content, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &cachedSpec{}
+	if err := json.Unmarshal(content, spec); err != nil {
+		return nil, err
+	}
+
+	for _, schema := range spec.Schemas {
+		schema.Pos = spec.Positions[schema.Name]
+	}
+
+	return spec, nil
+}
+
+// cacheDirectory returns the directory of the cache, the entmaid directory of the user's cache directory when empty.
+func cacheDirectory(cacheDir string) (string, error) {
+	if cacheDir != "" {
+		return cacheDir, nil
+	}
+
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(userCacheDir, "entmaid"), nil
+}
+
+// writeCacheFile atomically writes the value as JSON to the cache file, so concurrent runs never read a partial file.
+func writeCacheFile(cachePath string, value any) error {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(cachePath), ".entmaid-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), cachePath)
+}
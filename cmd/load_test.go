@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"entgo.io/ent/entc"
+	"entgo.io/ent/entc/gen"
+	"entgo.io/ent/schema/field"
+)
+
+func TestGenerateDiagramCache(t *testing.T) {
+	cacheDir := t.TempDir()
+	jsonPath := filepath.Join(t.TempDir(), "schema.json")
+
+	err := GenerateDiagram("../examples/start/schema", "", Markdown, "", "", WithCache(cacheDir), WithOutput(FormatJSON, jsonPath))
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	cached, err := filepath.Glob(filepath.Join(cacheDir, "schema-*.json"))
+	if err != nil || len(cached) != 1 {
+		t.Fatalf("Expected a single cached schema, got %v (%v)", cached, err)
+	}
+
+	// Rename a field in the cache so the next run proves it was loaded from there.
+	content, err := os.ReadFile(cached[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content = []byte(strings.ReplaceAll(string(content), `"name":"model"`, `"name":"title"`))
+	if err := os.WriteFile(cached[0], content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err = GenerateDiagram("../examples/start/schema", "", Markdown, "", "", WithCache(cacheDir), WithOutput(FormatJSON, jsonPath))
+	if err != nil {
+		t.Fatalf("Failed to generate diagram from the cache: %v", err)
+	}
+
+	output, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(output), `"name": "title"`) {
+		t.Errorf("Expected the cached schema to be used")
+	}
+}
+
+func TestSchemaCachePathDependencies(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":           "module example.com/app\n\ngo 1.23\n",
+		"schema/user.go":   "package schema\n\nimport _ \"example.com/app/mixin\"\n",
+		"mixin/mixin.go":   "package mixin\n\nconst Field = \"created_at\"\n",
+		"unused/unused.go": "package unused\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cachePath := func() string {
+		t.Helper()
+
+		path, err := schemaCachePath(filepath.Join(dir, "schema"), t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return filepath.Base(path)
+	}
+
+	initial := cachePath()
+
+	if err := os.WriteFile(filepath.Join(dir, "unused", "unused.go"), []byte("package unused\n\nconst A = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if path := cachePath(); path != initial {
+		t.Errorf("Expected the packages the schema doesn't import to keep the cache, got %s instead of %s", path, initial)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "mixin", "mixin.go"), []byte("package mixin\n\nconst Field = \"updated_at\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if path := cachePath(); path == initial {
+		t.Errorf("Expected a change of an imported package to change the cache")
+	}
+}
+
+func TestResolveSchemaPath(t *testing.T) {
+	dir, err := resolveSchemaPath("github.com/lespea/entmaid/examples/start/schema")
+	if err != nil {
+		t.Fatalf("Failed to resolve the import path: %v", err)
+	}
+
+	if !strings.HasSuffix(filepath.ToSlash(dir), "/examples/start/schema") {
+		t.Errorf("Resolved the import path to %s, expected the examples/start/schema directory", dir)
+	}
+
+	if dir, err := resolveSchemaPath("../examples/start/schema"); err != nil || dir != "../examples/start/schema" {
+		t.Errorf("Expected a directory to be used as is, got %s and %v", dir, err)
+	}
+
+	if _, err := resolveSchemaPath("github.com/lespea/entmaid/examples/missing"); err == nil {
+		t.Errorf("Expected an error for an import path without a package")
+	}
+}
+
+func TestLoadGraphGenConfig(t *testing.T) {
+	cfg := &gen.Config{Package: "github.com/acme/app/ent", IDType: &field.TypeInfo{Type: field.TypeString}}
+	o := newOptions([]Option{WithQuiet(), WithGenConfig(cfg), WithEntcOptions(entc.FeatureNames(gen.FeatureUpsert.Name))})
+
+	graph, err := loadGraph(context.Background(), "../examples/start/schema", o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if upsert, _ := graph.Config.FeatureEnabled(gen.FeatureUpsert.Name); graph.Config.Package != "github.com/acme/app/ent" || !upsert {
+		t.Errorf("Expected the graph to be loaded with the given config, got %+v", graph.Config)
+	}
+
+	if len(cfg.Features) != 0 {
+		t.Error("Expected the entc options to be applied to a copy of the given config")
+	}
+
+	model, err := buildModel(context.Background(), graph, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entity := range model.Entities {
+		if entity.Name == "User" && entity.Attributes[0].Type != "string" {
+			t.Errorf("Expected the IDs to be strings, got %s %s", entity.Attributes[0].Type, entity.Attributes[0].Name)
+		}
+	}
+}
+
+func TestFeatureNames(t *testing.T) {
+	names := featureNames()
+	if len(names) != len(gen.AllFeatures) || !slices.Contains(names, "sql/upsert") || !slices.Contains(names, "schema/snapshot") {
+		t.Errorf("Expected the names of every ent feature, got %v", names)
+	}
+}
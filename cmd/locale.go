@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Locale translates the labels of the diagram for non-English readers, the diagrams of several locales are drawn
+// from the same schema. The entities and relationships keep their names, only their labels are translated.
+type Locale struct {
+	// Entities are the labels of the entities by their name, drawn as their display name.
+	Entities map[string]string `json:"entities,omitempty"`
+	// Fields are the labels of the fields by their name, or as Entity.field when the name is used by several
+	// entities, drawn first in the comments of the attributes.
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// WithLocale translates the labels of the entities and fields of the diagram, see Locale. The translations of the
+// entities take precedence over their display names.
+func WithLocale(locale Locale) Option {
+	return func(o *options) {
+		o.locale = &locale
+	}
+}
+
+// readLocale reads the locale from a JSON file.
+func readLocale(localePath string) (*Locale, error) {
+	content, err := os.ReadFile(localePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the locale file: %w", err)
+	}
+
+	var locale Locale
+	if err := json.Unmarshal(content, &locale); err != nil {
+		return nil, inFile(localePath, fmt.Errorf("%w: invalid locale file %s: %w", errUsage, localePath, err))
+	}
+
+	return &locale, nil
+}
+
+// localizeModel sets the translated labels of the locale on the entities and attributes of the model, warning about
+// the names of the locale that aren't in the schema.
+func localizeModel(model *Model, locale *Locale, o *options) {
+	for _, name := range slices.Sorted(maps.Keys(locale.Entities)) {
+		entity := model.Entity(name)
+		if entity == nil {
+			o.warn(name, "", "is not an entity of the schema, it can't be translated")
+			continue
+		}
+		entity.DisplayName = locale.Entities[name]
+	}
+
+	// The labels of the fields of every entity are set first, the labels of the fields of an entity override them.
+	keys := slices.SortedFunc(maps.Keys(locale.Fields), func(a string, b string) int {
+		if qualifiedA, qualifiedB := strings.Contains(a, "."), strings.Contains(b, "."); qualifiedA != qualifiedB {
+			if qualifiedA {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(a, b)
+	})
+
+	for _, key := range keys {
+		entityName, fieldName, qualified := strings.Cut(key, ".")
+		if !qualified {
+			entityName, fieldName = "", key
+		}
+
+		found := false
+		for _, entity := range model.Entities {
+			if qualified && entity.Name != entityName {
+				continue
+			}
+			if attribute := entity.Attribute(fieldName); attribute != nil {
+				attribute.Label, found = locale.Fields[key], true
+			}
+		}
+
+		if !found {
+			o.warn(key, "", "is not a field of the schema, it can't be translated")
+		}
+	}
+}
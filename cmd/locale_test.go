@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalizeModel(t *testing.T) {
+	o := newOptions([]Option{WithQuiet(), WithDisplayName("Car", "Vehicle"), WithLocale(Locale{
+		Entities: map[string]string{"Car": "Voiture", "User": "Utilisateur", "Missing": "Manquant"},
+		Fields:   map[string]string{"name": "nom", "Group.name": "intitulé", "User.missing": "manquant"},
+	})})
+
+	model, err := loadModel(context.Background(), "../examples/start/schema", o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name := model.Entity("Car").DisplayName; name != "Voiture" {
+		t.Errorf("Expected the translation to take precedence over the display name, got %q", name)
+	}
+	if label := model.Entity("User").Attribute("name").Label; label != "nom" {
+		t.Errorf("Expected the label of every entity, got %q", label)
+	}
+	if label := model.Entity("Group").Attribute("name").Label; label != "intitulé" {
+		t.Errorf("Expected the label of the entity to take precedence, got %q", label)
+	}
+	if len(o.warnings) != 2 {
+		t.Errorf("Expected warnings about the unknown entity and field, got %v", o.warnings)
+	}
+
+	var builder strings.Builder
+	if err := renderMermaid(context.Background(), &builder, model, o); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{` User["Utilisateur"] {`, ` string name "nom`} {
+		if !strings.Contains(builder.String(), expected) {
+			t.Errorf("Mermaid output is missing %q:\n%s", expected, builder.String())
+		}
+	}
+}
+
+func TestReadLocale(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"valid.json":   `{"entities": {"User": "Utilisateur"}, "fields": {"User.name": "nom"}}`,
+		"invalid.json": `{"entities": ["User"]}`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	locale, err := readLocale(filepath.Join(dir, "valid.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locale.Entities["User"] != "Utilisateur" || locale.Fields["User.name"] != "nom" {
+		t.Errorf("Unexpected locale %+v", locale)
+	}
+
+	for _, name := range []string{"invalid.json", "missing.json"} {
+		if _, err := readLocale(filepath.Join(dir, name)); err == nil {
+			t.Errorf("Expected %s to be rejected", name)
+		}
+	}
+}
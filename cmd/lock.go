@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// lockedFile is a file held under an exclusive advisory lock, so parallel runs placing diagrams in a shared file
+// take turns instead of interleaving their writes. It's read and written through the locked handle as Windows
+// locks are mandatory for the other handles.
+type lockedFile struct {
+	file *os.File
+	// content is what the file held when it was read, to detect the changes made without taking the lock.
+	content []byte
+}
+
+// lockTarget locks the file at path, waiting for the other entmaid runs holding it, and reads it. The file is
+// created when create is set and it doesn't exist yet.
+func lockTarget(path string, create bool) (*lockedFile, error) {
+	flags := os.O_RDWR
+	if create {
+		flags |= os.O_CREATE
+	}
+
+	file, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	locked := &lockedFile{file: file}
+	if locked.content, err = locked.read(); err != nil {
+		locked.unlock()
+		return nil, err
+	}
+
+	return locked, nil
+}
+
+func (l *lockedFile) read() ([]byte, error) {
+	if _, err := l.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(l.file)
+}
+
+// write replaces the content of the file, unless it changed since it was read as another process not taking the
+// lock (e.g. an editor) wrote it in the meantime and its changes would be lost.
+func (l *lockedFile) write(content []byte) error {
+	current, err := l.read()
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(current, l.content) {
+		return fmt.Errorf("%s was modified by another process while placing the diagram, run again", l.file.Name())
+	}
+
+	if err := l.file.Truncate(0); err != nil {
+		return err
+	}
+
+	if _, err := l.file.WriteAt(content, 0); err != nil {
+		return err
+	}
+
+	l.content = content
+
+	return nil
+}
+
+func (l *lockedFile) unlock() {
+	_ = unlockFile(l.file)
+	l.file.Close()
+}
@@ -0,0 +1,14 @@
+//go:build !unix && !windows
+
+package cmd
+
+import "os"
+
+// Platforms without file locks (e.g. wasm) only rely on the check for concurrent changes before writing.
+func lockFile(*os.File) error {
+	return nil
+}
+
+func unlockFile(*os.File) error {
+	return nil
+}
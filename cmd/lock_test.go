@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLockTargetConcurrentChange(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows locks keep the other handles from writing the file")
+	}
+
+	path := filepath.Join(t.TempDir(), "readme.md")
+	if err := os.WriteFile(path, []byte("before"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	target, err := lockTarget(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.unlock()
+
+	if string(target.content) != "before" {
+		t.Errorf("Expected the content to be read with the lock, got %q", target.content)
+	}
+
+	if err := os.WriteFile(path, []byte("edited"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := target.write([]byte("after")); err == nil || !strings.Contains(err.Error(), "modified by another process") {
+		t.Errorf("Expected the concurrent change to be detected, got %v", err)
+	}
+
+	if content, _ := os.ReadFile(path); string(content) != "edited" {
+		t.Errorf("Expected the concurrent change to be kept, got %q", content)
+	}
+}
+
+func TestInsertConcurrently(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "readme.md")
+	start, end := DefaultPatterns(path)
+	if err := os.WriteFile(path, []byte("# Shared\n\n"+start+"\n"+end+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	diagrams := []string{"erDiagram\n A {\n }", "erDiagram\n B {\n }", "erDiagram\n C {\n }", "erDiagram\n D {\n }"}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(diagrams)*5)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = insertMultiLineString(path, diagrams[i%len(diagrams)], start, end, newOptions(nil))
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Errorf("Expected the writes to take turns, got %v", err)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Count(string(content), start) != 1 || strings.Count(string(content), "erDiagram") != 1 || !strings.HasPrefix(string(content), "# Shared\n") {
+		t.Errorf("Expected a single diagram between the markers, got %q", content)
+	}
+}
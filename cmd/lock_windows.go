@@ -0,0 +1,36 @@
+//go:build windows
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32         = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = kernel32.NewProc("LockFileEx")
+	procUnlockFileEx = kernel32.NewProc("UnlockFileEx")
+)
+
+const lockfileExclusiveLock = 0x2
+
+// lockFile locks the whole file, as far as LockFileEx can count.
+func lockFile(file *os.File) error {
+	var overlapped syscall.Overlapped
+	if r, _, err := procLockFileEx.Call(file.Fd(), lockfileExclusiveLock, 0, ^uintptr(0), ^uintptr(0), uintptr(unsafe.Pointer(&overlapped))); r == 0 {
+		return err
+	}
+
+	return nil
+}
+
+func unlockFile(file *os.File) error {
+	var overlapped syscall.Overlapped
+	if r, _, err := procUnlockFileEx.Call(file.Fd(), 0, ^uintptr(0), ^uintptr(0), uintptr(unsafe.Pointer(&overlapped))); r == 0 {
+		return err
+	}
+
+	return nil
+}
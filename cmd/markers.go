@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// StdioTarget is the target path reading the document from stdin and writing it, with the diagram placed in it,
+// to stdout instead of rewriting a file in place.
+const StdioTarget = "-"
+
+// readTargetFile reads the target file, or stdin for the StdioTarget.
+func readTargetFile(filePath string, o *options) ([]byte, error) {
+	if filePath == StdioTarget {
+		return io.ReadAll(o.stdin)
+	}
+
+	return os.ReadFile(filePath)
+}
+
+// insertMultiLineString places the multi-line string between the start and end patterns in the file.
+// When createMarkers is set and neither pattern is present, the patterns are appended to the file along with the
+// string, creating the file if needed.
+// The file is locked from the moment it's read until it's written, see lockTarget.
+func insertMultiLineString(filePath string, multiLineString string, startPattern string, endPattern string, o *options) error {
+	var target *lockedFile
+	var content []byte
+	var err error
+	if filePath == StdioTarget {
+		content, err = readTargetFile(filePath, o)
+	} else if target, err = lockTarget(filePath, o.createMarkers); err == nil {
+		defer target.unlock()
+		content = target.content
+	}
+	if err != nil {
+		return inFile(filePath, fmt.Errorf("%w: %w", ErrTarget, err))
+	}
+
+	fileContent := string(content)
+
+	var updatedContent string
+	if o.createMarkers && !strings.Contains(fileContent, startPattern) && !strings.Contains(fileContent, endPattern) {
+		if err := guardMultiLineString(multiLineString, startPattern, endPattern); err != nil {
+			return inFile(filePath, err)
+		}
+		updatedContent = appendMultiLineString(fileContent, multiLineString, startPattern, endPattern)
+	} else {
+		updatedContent, err = spliceMultiLineString(fileContent, multiLineString, startPattern, endPattern, o.allMarkers)
+		if err != nil {
+			return inFile(filePath, err)
+		}
+	}
+
+	// The whole document is passed through, whether the diagram changed or not.
+	if filePath == StdioTarget {
+		if _, err := io.WriteString(o.stdout, updatedContent); err != nil {
+			return inFile(filePath, fmt.Errorf("%w: %w", ErrTarget, err))
+		}
+
+		return nil
+	}
+
+	// Leave the file untouched when the diagram didn't change, unless it was just created.
+	if len(content) > 0 && updatedContent == fileContent {
+		return nil
+	}
+
+	// Write the updated content back to the file
+	if err := target.write([]byte(updatedContent)); err != nil {
+		return inFile(filePath, fmt.Errorf("%w: %w", ErrTarget, err))
+	}
+
+	o.recordModified(filePath, len(updatedContent))
+
+	return nil
+}
+
+// checkMultiLineString returns the first line of the file that differs once the multi-line string is placed between
+// the patterns, or 0 when the file is already up to date.
+// The generation time of any header is ignored so timestamped diagrams can still be checked.
+func checkMultiLineString(filePath string, multiLineString string, startPattern string, endPattern string, o *options) (int, error) {
+	content, err := readTargetFile(filePath, o)
+	if err != nil {
+		return 0, inFile(filePath, fmt.Errorf("%w: %w", ErrTarget, err))
+	}
+
+	fileContent := string(content)
+
+	updatedContent, err := spliceMultiLineString(fileContent, multiLineString, startPattern, endPattern, o.allMarkers)
+	if err != nil {
+		return 0, inFile(filePath, err)
+	}
+
+	return firstDifferentLine(fileContent, updatedContent), nil
+}
+
+// firstDifferentLine returns the 1-based number of the first line that differs between the contents, ignoring the
+// generation time of any header, or 0 when they're the same.
+func firstDifferentLine(a string, b string) int {
+	a, b = stripHeaderTimestamp(a), stripHeaderTimestamp(b)
+	if a == b {
+		return 0
+	}
+
+	aLines, bLines := strings.Split(a, "\n"), strings.Split(b, "\n")
+	for i := range min(len(aLines), len(bLines)) {
+		if aLines[i] != bLines[i] {
+			return i + 1
+		}
+	}
+
+	return min(len(aLines), len(bLines)) + 1
+}
+
+// markerRegion holds the positions of a start pattern and the end pattern closing it.
+type markerRegion struct {
+	startIndex int
+	endIndex   int
+}
+
+// spliceMultiLineString replaces everything between the start and end patterns with the multi-line string.
+// Unless all is set the content must contain exactly one pair of patterns, otherwise every pair is replaced.
+func spliceMultiLineString(fileContent string, multiLineString string, startPattern string, endPattern string, all bool) (string, error) {
+	if err := guardMultiLineString(multiLineString, startPattern, endPattern); err != nil {
+		return "", err
+	}
+
+	regions, err := findMarkerRegions(fileContent, startPattern, endPattern)
+	if err != nil {
+		return "", err
+	}
+
+	if len(regions) > 1 && !all {
+		return "", atLine(fileContent, regions[1].startIndex, fmt.Errorf("%w: found %d pairs of starting (%s) and ending (%s) strings in the file, expected only one", ErrDuplicateMarkers, len(regions), startPattern, endPattern))
+	}
+
+	newline := lineEnding(fileContent)
+	multiLineString = withLineEnding(multiLineString, newline)
+
+	var builder strings.Builder
+	builder.Grow(len(fileContent) + len(regions)*len(multiLineString))
+
+	previousEnd := 0
+	for _, region := range regions {
+		// Keep the start pattern along with the line break after it, adding one when the pattern isn't followed by
+		// a line break (e.g. when both patterns are on the same line).
+		afterStart := region.startIndex + len(startPattern)
+		if rest := fileContent[afterStart:region.endIndex]; strings.HasPrefix(rest, "\r\n") {
+			afterStart += 2
+		} else if strings.HasPrefix(rest, "\n") {
+			afterStart++
+		}
+
+		builder.WriteString(fileContent[previousEnd:afterStart])
+		if !strings.HasSuffix(fileContent[:afterStart], "\n") {
+			builder.WriteString(newline)
+		}
+
+		// Keep the indentation of the end pattern.
+		endIndex := region.endIndex
+		lineStart := strings.LastIndexByte(fileContent[afterStart:endIndex], '\n') + afterStart + 1
+		if strings.Trim(fileContent[lineStart:endIndex], " \t") == "" {
+			endIndex = lineStart
+		}
+
+		// Construct the updated content with the generated multi-line string
+		builder.WriteString(multiLineString + newline)
+		previousEnd = endIndex
+	}
+
+	builder.WriteString(fileContent[previousEnd:])
+
+	return builder.String(), nil
+}
+
+// lineEnding returns the line break used by the content, "\r\n" when its first line ends with one and "\n" otherwise.
+func lineEnding(content string) string {
+	if index := strings.IndexByte(content, '\n'); index > 0 && content[index-1] == '\r' {
+		return "\r\n"
+	}
+
+	return "\n"
+}
+
+// withLineEnding converts the line breaks of the content to the newline.
+func withLineEnding(content string, newline string) string {
+	if newline == "\n" {
+		return content
+	}
+
+	return strings.ReplaceAll(strings.ReplaceAll(content, "\r\n", "\n"), "\n", newline)
+}
+
+// guardMultiLineString refuses a multi-line string holding the start or end pattern (e.g. through the comment of a
+// field), the next run would take it for the markers and splice the diagram into the middle of it.
+func guardMultiLineString(multiLineString string, startPattern string, endPattern string) error {
+	for _, pattern := range []string{startPattern, endPattern} {
+		if pattern != "" && strings.Contains(multiLineString, pattern) {
+			return fmt.Errorf("%w: the generated content holds the %s string, use other patterns or remove it from the schema", ErrMarkerInContent, pattern)
+		}
+	}
+
+	return nil
+}
+
+// findMarkerRegions locates every pair of start and end patterns in the content, making sure each start pattern is
+// closed by an end pattern before the next one begins.
+func findMarkerRegions(fileContent string, startPattern string, endPattern string) ([]markerRegion, error) {
+	var regions []markerRegion
+
+	offset := 0
+	for {
+		startIndex := strings.Index(fileContent[offset:], startPattern)
+		endIndex := strings.Index(fileContent[offset:], endPattern)
+
+		if startIndex == -1 {
+			if endIndex != -1 {
+				return nil, atLine(fileContent, offset+endIndex, fmt.Errorf("%w: ending (%s) string found without a starting (%s) string before it", ErrMarkerOrder, endPattern, startPattern))
+			}
+
+			break
+		}
+
+		startIndex += offset
+		if endIndex != -1 && endIndex+offset < startIndex {
+			return nil, atLine(fileContent, offset+endIndex, fmt.Errorf("%w: ending (%s) string found before the starting (%s) string", ErrMarkerOrder, endPattern, startPattern))
+		}
+
+		afterStart := startIndex + len(startPattern)
+
+		endIndex = strings.Index(fileContent[afterStart:], endPattern)
+		if endIndex == -1 {
+			return nil, atLine(fileContent, startIndex, fmt.Errorf("%w: starting (%s) string found without an ending (%s) string after it", ErrMarkerNotFound, startPattern, endPattern))
+		}
+
+		endIndex += afterStart
+		if nextStart := strings.Index(fileContent[afterStart:endIndex], startPattern); nextStart != -1 {
+			return nil, atLine(fileContent, afterStart+nextStart, fmt.Errorf("%w: starting (%s) string found again before the ending (%s) string", ErrMarkerOrder, startPattern, endPattern))
+		}
+
+		regions = append(regions, markerRegion{startIndex: startIndex, endIndex: endIndex})
+		offset = endIndex + len(endPattern)
+	}
+
+	// Check if the starting and ending strings are found
+	if len(regions) == 0 {
+		return nil, fmt.Errorf("%w: starting (%s) or ending (%s) string not found in the file", ErrMarkerNotFound, startPattern, endPattern)
+	}
+
+	return regions, nil
+}
+
+// appendMultiLineString adds the start and end patterns with the multi-line string between them to the end of the
+// content, separated from any existing content by a blank line.
+func appendMultiLineString(fileContent string, multiLineString string, startPattern string, endPattern string) string {
+	newline := lineEnding(fileContent)
+
+	var builder strings.Builder
+
+	builder.WriteString(fileContent)
+
+	if fileContent != "" {
+		if !strings.HasSuffix(fileContent, "\n") {
+			builder.WriteString(newline)
+		}
+		builder.WriteString(newline)
+	}
+
+	builder.WriteString(startPattern + newline)
+	builder.WriteString(withLineEnding(multiLineString, newline) + newline)
+	builder.WriteString(endPattern + newline)
+
+	return builder.String()
+}
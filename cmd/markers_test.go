@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInsertMultiLineStringCreateMarkers(t *testing.T) {
+	testCases := []struct {
+		name     string
+		existing *string
+		expected string
+	}{
+		{name: "missing file", expected: "<s>\ndiagram\n<e>\n"},
+		{name: "empty file", existing: ptr(""), expected: "<s>\ndiagram\n<e>\n"},
+		{name: "trailing newline", existing: ptr("# Title\n"), expected: "# Title\n\n<s>\ndiagram\n<e>\n"},
+		{name: "no trailing newline", existing: ptr("# Title"), expected: "# Title\n\n<s>\ndiagram\n<e>\n"},
+		{name: "markers present", existing: ptr("<s>\nold\n<e>\n"), expected: "<s>\ndiagram\n<e>\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "readme.md")
+			if tc.existing != nil {
+				if err := os.WriteFile(path, []byte(*tc.existing), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := insertMultiLineString(path, "diagram", "<s>", "<e>", &options{createMarkers: true}); err != nil {
+				t.Fatalf("Failed to insert: %v", err)
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(content) != tc.expected {
+				t.Errorf("Got %q, expected %q", content, tc.expected)
+			}
+		})
+	}
+}
+
+func TestInsertMultiLineStringMissingMarkers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "readme.md")
+	if err := os.WriteFile(path, []byte("# Title\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := insertMultiLineString(path, "diagram", "<s>", "<e>", &options{}); err == nil {
+		t.Errorf("Expected an error when the markers are missing")
+	}
+}
+
+func TestSpliceMultiLineStringValidation(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		all      bool
+		expected string
+		fails    bool
+	}{
+		{name: "single pair", content: "a\n<s>\nold\n<e>\nb", expected: "a\n<s>\nnew\n<e>\nb"},
+		{name: "missing", content: "a\nb", fails: true},
+		{name: "missing end", content: "<s>\nold\n", fails: true},
+		{name: "missing start", content: "old\n<e>\n", fails: true},
+		{name: "reversed", content: "<e>\nold\n<s>\n", fails: true},
+		{name: "nested start", content: "<s>\n<s>\nold\n<e>\n", fails: true},
+		{name: "stray end", content: "<s>\nold\n<e>\n<e>\n", fails: true},
+		{name: "duplicate pairs", content: "<s>\none\n<e>\n<s>\ntwo\n<e>\n", fails: true},
+		{name: "duplicate pairs all", content: "<s>\none\n<e>\nmid\n<s>\ntwo\n<e>\n", all: true, expected: "<s>\nnew\n<e>\nmid\n<s>\nnew\n<e>\n"},
+		{name: "crlf", content: "a\r\n<s>\r\nold\r\n<e>\r\nb\r\n", expected: "a\r\n<s>\r\nnew\r\n<e>\r\nb\r\n"},
+		{name: "no trailing newline", content: "<s>\nold\n<e>", expected: "<s>\nnew\n<e>"},
+		{name: "marker at eof", content: "<s>", fails: true},
+		{name: "start marker at eof", content: "<e>\n<s>", fails: true},
+		{name: "same line", content: "a <s><e> b", expected: "a <s>\nnew\n<e> b"},
+		{name: "empty", content: "<s>\n<e>\n", expected: "<s>\nnew\n<e>\n"},
+		{name: "indented end", content: "<s>\n  old\n  <e>\n", expected: "<s>\nnew\n  <e>\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			updated, err := spliceMultiLineString(tc.content, "new", "<s>", "<e>", tc.all)
+			if tc.fails {
+				if err == nil {
+					t.Errorf("Expected an error, got %q", updated)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Failed to splice: %v", err)
+			}
+
+			if updated != tc.expected {
+				t.Errorf("Got %q, expected %q", updated, tc.expected)
+			}
+
+			// Running again must leave the content as it is.
+			again, err := spliceMultiLineString(updated, "new", "<s>", "<e>", tc.all)
+			if err != nil {
+				t.Fatalf("Failed to splice again: %v", err)
+			}
+			if again != updated {
+				t.Errorf("Splicing again changed %q into %q", updated, again)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestSpliceMultiLineStringErrors(t *testing.T) {
+	testCases := []struct {
+		content  string
+		expected error
+		line     int
+	}{
+		{content: "nothing here", expected: ErrMarkerNotFound},
+		{content: "<e>\n<s>\n", expected: ErrMarkerOrder, line: 1},
+		{content: "# Title\n<s>\n<s>\n<e>\n", expected: ErrMarkerOrder, line: 3},
+		{content: "<s>\n<e>\n\n<s>\n", expected: ErrMarkerNotFound, line: 4},
+		{content: "<s>\n<e>\n<s>\n<e>\n", expected: ErrDuplicateMarkers, line: 3},
+	}
+
+	for _, tc := range testCases {
+		_, err := spliceMultiLineString(tc.content, "new", "<s>", "<e>", false)
+		if !errors.Is(err, tc.expected) {
+			t.Errorf("Splicing %q returned %v, expected %v", tc.content, err, tc.expected)
+		}
+
+		line := 0
+		var fileErr *FileError
+		if errors.As(err, &fileErr) {
+			line = fileErr.Line
+		}
+
+		if line != tc.line {
+			t.Errorf("Splicing %q failed at line %d, expected %d", tc.content, line, tc.line)
+		}
+	}
+}
+
+func TestGenerateDiagramStdio(t *testing.T) {
+	expected, err := os.ReadFile("../examples/start/readme-expected.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stale := strings.Replace(string(expected), "User {", "Person {", 1)
+
+	var stdout bytes.Buffer
+	err = GenerateDiagram("../examples/start/schema", StdioTarget, Markdown, defaultStartPattern, defaultEndPattern, WithStdio(strings.NewReader(stale), &stdout))
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	if stdout.String() != string(expected) {
+		t.Errorf("Got %q on stdout, expected %q", stdout.String(), expected)
+	}
+
+	err = GenerateDiagram("../examples/start/schema", StdioTarget, Markdown, defaultStartPattern, defaultEndPattern, WithCheck(), WithQuiet(), WithStdio(strings.NewReader(stale), &stdout))
+	if !errors.Is(err, ErrStaleDiagram) {
+		t.Errorf("Expected the stale document on stdin to fail the check, got %v", err)
+	}
+}
+
+func TestSpliceMultiLineStringMarkerInContent(t *testing.T) {
+	for _, generated := range []string{"%% before <s> after", "erDiagram\n  User {\n    string name \"<e>\"\n  }"} {
+		if _, err := spliceMultiLineString("<s>\nold\n<e>\n", generated, "<s>", "<e>", false); !errors.Is(err, ErrMarkerInContent) {
+			t.Errorf("Splicing %q returned %v, expected %v", generated, err, ErrMarkerInContent)
+		}
+	}
+
+	// The end pattern is searched for after the start pattern only, even when the start pattern contains it.
+	updated, err := spliceMultiLineString("<<e>>\nold\n<e>\n", "new", "<<e>>", "<e>", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated != "<<e>>\nnew\n<e>\n" {
+		t.Errorf("Unexpected splice of overlapping patterns: %q", updated)
+	}
+}
+
+func FuzzSpliceMultiLineString(f *testing.F) {
+	f.Add("# Title\n\n<!-- #start:entmaid -->\nold\n<!-- #end:entmaid -->\n\nfooter\n", "erDiagram\n User {\n }", "<!-- #start:entmaid -->", "<!-- #end:entmaid -->", false)
+	f.Add("a\r\n  <!-- s --><!-- e -->\r\nb\r\n<!-- s -->\r\n\t<!-- e -->", "x\ny", "<!-- s -->", "<!-- e -->", true)
+	f.Add("// #start:entmaid\n// old\n// #end:entmaid\npackage ent\n", "// new", "// #start:entmaid", "// #end:entmaid", false)
+	f.Add("<!-- e --><!-- s -->", "", "<!-- s -->", "<!-- e -->", false)
+
+	f.Fuzz(func(t *testing.T, content string, diagram string, startPattern string, endPattern string, all bool) {
+		// The patterns are whole lines of the target, as the flags and the default patterns give them.
+		for _, pattern := range []string{startPattern, endPattern} {
+			if pattern == "" || strings.ContainsAny(pattern, "\r\n") {
+				t.Skip()
+			}
+		}
+
+		spliced, err := spliceMultiLineString(content, diagram, startPattern, endPattern, all)
+		if err != nil {
+			return
+		}
+
+		regions, err := findMarkerRegions(content, startPattern, endPattern)
+		if err != nil {
+			t.Fatalf("Spliced a content whose markers can't be found: %v", err)
+		}
+
+		// Everything outside of the markers is kept as is.
+		first, last := regions[0], regions[len(regions)-1]
+		if prefix := content[:first.startIndex+len(startPattern)]; !strings.HasPrefix(spliced, prefix) {
+			t.Fatalf("The content before the markers changed:\n%q\n%q", prefix, spliced)
+		}
+		if suffix := content[last.endIndex:]; !strings.HasSuffix(spliced, suffix) {
+			t.Fatalf("The content after the markers changed:\n%q\n%q", suffix, spliced)
+		}
+
+		splicedRegions, err := findMarkerRegions(spliced, startPattern, endPattern)
+		if err != nil || len(splicedRegions) != len(regions) {
+			t.Fatalf("The spliced content has %d pairs of markers instead of %d (%v):\n%q", len(splicedRegions), len(regions), err, spliced)
+		}
+		newline := lineEnding(content)
+		for _, region := range splicedRegions {
+			if between := spliced[region.startIndex:region.endIndex]; !strings.Contains(between, withLineEnding(diagram, newline)) {
+				t.Fatalf("The diagram isn't between the markers:\n%q", between)
+			}
+		}
+
+		// Running again on an up to date target changes nothing.
+		again, err := spliceMultiLineString(spliced, diagram, startPattern, endPattern, all)
+		if err != nil || again != spliced {
+			t.Fatalf("Splicing the same diagram again changed the content (%v):\n%q\n%q", err, spliced, again)
+		}
+	})
+}
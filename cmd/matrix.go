@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FormatMatrix is a Markdown table of the entities by the entities, its cells listing the relationships between the
+// entity of the row and the one of the column with their cardinalities, for audits easier to scan than a diagram.
+// FormatMatrixCSV is the same table as CSV, for spreadsheets.
+const (
+	FormatMatrix    Format = "matrix"
+	FormatMatrixCSV Format = "matrix-csv"
+)
+
+// relationshipMatrix returns the names of the entities and the relationships between them by the index of the
+// entity of the row and of the column. Every relationship is listed in both cells of its entities, from the point of
+// view of the row.
+func relationshipMatrix(model *Model) ([]string, [][][]string) {
+	names := make([]string, len(model.Entities))
+	index := make(map[string]int, len(model.Entities))
+	for i, entity := range model.Entities {
+		names[i], index[entity.Name] = entity.Name, i
+	}
+
+	cells := make([][][]string, len(names))
+	for i := range cells {
+		cells[i] = make([][]string, len(names))
+	}
+
+	for _, rel := range model.Relationships {
+		from, ok := index[rel.From]
+		if !ok {
+			continue
+		}
+		to, ok := index[rel.To]
+		if !ok {
+			continue
+		}
+
+		cells[from][to] = append(cells[from][to], matrixCell(rel.Label, rel.FromCardinality, rel.ToCardinality))
+		if from != to {
+			cells[to][from] = append(cells[to][from], matrixCell(rel.Label, rel.ToCardinality, rel.FromCardinality))
+		}
+	}
+
+	return names, cells
+}
+
+// matrixCell describes a relationship from the entity of the row, e.g. "cars-owner (0..1 to 0..*)".
+func matrixCell(label string, rowCardinality Cardinality, columnCardinality Cardinality) string {
+	return fmt.Sprintf("%s (%s to %s)", label, umlMultiplicities[rowCardinality], umlMultiplicities[columnCardinality])
+}
+
+// renderMatrix writes the relationship matrix of the model as a Markdown table.
+func renderMatrix(_ context.Context, w io.Writer, model *Model, _ *options) error {
+	names, cells := relationshipMatrix(model)
+	escape := strings.NewReplacer("|", `\|`, "\n", " ")
+
+	var builder strings.Builder
+	builder.WriteString("| |")
+	for _, name := range names {
+		builder.WriteString(" " + escape.Replace(name) + " |")
+	}
+	builder.WriteString("\n|---|" + strings.Repeat("---|", len(names)) + "\n")
+
+	for i, name := range names {
+		builder.WriteString("| **" + escape.Replace(name) + "** |")
+		for _, cell := range cells[i] {
+			if len(cell) > 0 {
+				builder.WriteString(" " + escape.Replace(strings.Join(cell, "<br>")))
+			}
+			builder.WriteString(" |")
+		}
+		builder.WriteString("\n")
+	}
+
+	if _, err := io.WriteString(w, builder.String()); err != nil {
+		return fmt.Errorf("%w: failed to write string: %w", ErrRender, err)
+	}
+
+	return nil
+}
+
+// renderMatrixCSV writes the relationship matrix of the model as CSV, the relationships of a cell separated by
+// semicolons.
+func renderMatrixCSV(_ context.Context, w io.Writer, model *Model, _ *options) error {
+	names, cells := relationshipMatrix(model)
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(append([]string{""}, names...)); err != nil {
+		return err
+	}
+
+	for i, name := range names {
+		row := []string{name}
+		for _, cell := range cells[i] {
+			row = append(row, strings.Join(cell, "; "))
+		}
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
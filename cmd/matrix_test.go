@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestRenderMatrix(t *testing.T) {
+	model := &Model{
+		Entities: []*Entity{{Name: "User"}, {Name: "Car"}, {Name: "Node|Tree"}},
+		Relationships: []*Relationship{
+			{From: "User", To: "Car", FromCardinality: ZeroOrOne, ToCardinality: ZeroOrMore, Label: "cars-owner"},
+			{From: "User", To: "Car", FromCardinality: ExactlyOne, ToCardinality: ZeroOrOne, Label: "favorite"},
+			{From: "Node|Tree", To: "Node|Tree", FromCardinality: ZeroOrOne, ToCardinality: ZeroOrMore, Label: "children-parent"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := renderMatrix(context.Background(), &buf, model, newOptions(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `| | User | Car | Node\|Tree |
+|---|---|---|---|
+| **User** | | cars-owner (0..1 to 0..*)<br>favorite (1 to 0..1) | |
+| **Car** | cars-owner (0..* to 0..1)<br>favorite (0..1 to 1) | | |
+| **Node\|Tree** | | | children-parent (0..1 to 0..*) |
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected matrix:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+
+	buf.Reset()
+	if err := renderMatrixCSV(context.Background(), &buf, model, newOptions(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = `,User,Car,Node|Tree
+User,,cars-owner (0..1 to 0..*); favorite (1 to 0..1),
+Car,cars-owner (0..* to 0..1); favorite (0..1 to 1),,
+Node|Tree,,,children-parent (0..1 to 0..*)
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected CSV matrix:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
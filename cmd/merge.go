@@ -0,0 +1,61 @@
+package cmd
+
+// WithMergeEdges draws the relationships between the same two entities as a single line in Mermaid diagrams,
+// labeled by all of their labels, for compact diagrams. The cardinalities of the line allow those of every merged
+// relationship, and it's only logical when all of them are.
+func WithMergeEdges() Option {
+	return func(o *options) {
+		o.mergeEdges = true
+	}
+}
+
+// mergeRelationships returns the relationships with the ones between the same two entities merged into the first
+// of them, whichever side they're drawn from.
+func mergeRelationships(relationships []*Relationship) []*Relationship {
+	var merged []*Relationship
+	byPair := make(map[[2]string]*Relationship)
+
+	for _, rel := range relationships {
+		if first, ok := byPair[[2]string{rel.From, rel.To}]; ok {
+			first.FromCardinality = widerCardinality(first.FromCardinality, rel.FromCardinality)
+			first.ToCardinality = widerCardinality(first.ToCardinality, rel.ToCardinality)
+			first.Label += ", " + rel.Label
+			first.Logical = first.Logical && rel.Logical
+			first.Weight = max(first.Weight, rel.Weight)
+			continue
+		}
+
+		if first, ok := byPair[[2]string{rel.To, rel.From}]; ok {
+			first.FromCardinality = widerCardinality(first.FromCardinality, rel.ToCardinality)
+			first.ToCardinality = widerCardinality(first.ToCardinality, rel.FromCardinality)
+			first.Label += ", " + rel.Label
+			first.Logical = first.Logical && rel.Logical
+			first.Weight = max(first.Weight, rel.Weight)
+			continue
+		}
+
+		// The relationships of the model are shared with the other outputs, so the merged one is a copy.
+		first := *rel
+		byPair[[2]string{rel.From, rel.To}] = &first
+		merged = append(merged, &first)
+	}
+
+	return merged
+}
+
+// widerCardinality returns the cardinality allowing both: optional when either is and many when either is.
+func widerCardinality(a Cardinality, b Cardinality) Cardinality {
+	optional := a == ZeroOrOne || a == ZeroOrMore || b == ZeroOrOne || b == ZeroOrMore
+	many := !isSingle(a) || !isSingle(b)
+
+	switch {
+	case optional && many:
+		return ZeroOrMore
+	case optional:
+		return ZeroOrOne
+	case many:
+		return OneOrMore
+	default:
+		return ExactlyOne
+	}
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import "testing"
+
+func TestMergeRelationships(t *testing.T) {
+	sender := &Relationship{From: "User", To: "Message", FromCardinality: ExactlyOne, ToCardinality: ZeroOrMore, Label: "sent-sender"}
+	recipient := &Relationship{From: "User", To: "Message", FromCardinality: ZeroOrOne, ToCardinality: ZeroOrMore, Label: "received-recipient"}
+	// Drawn from the other side, its cardinalities are swapped when merged.
+	author := &Relationship{From: "Message", To: "User", FromCardinality: OneOrMore, ToCardinality: ExactlyOne, Label: "author", Logical: true}
+	cars := &Relationship{From: "User", To: "Car", FromCardinality: ZeroOrOne, ToCardinality: ZeroOrMore, Label: "cars-owner"}
+
+	merged := mergeRelationships([]*Relationship{sender, recipient, cars, author})
+	if len(merged) != 2 {
+		t.Fatalf("Expected 2 relationships, got %d", len(merged))
+	}
+
+	if got := merged[0]; got.Label != "sent-sender, received-recipient, author" || got.FromCardinality != ZeroOrOne || got.ToCardinality != ZeroOrMore || got.Logical {
+		t.Errorf("Unexpected merged relationship %+v", got)
+	}
+
+	if merged[1].Label != "cars-owner" {
+		t.Errorf("Expected the relationship to the Car to be kept, got %+v", merged[1])
+	}
+
+	if sender.Label != "sent-sender" || sender.FromCardinality != ExactlyOne {
+		t.Errorf("Expected the relationships of the model to be left as is, got %+v", sender)
+	}
+}
+
+func TestWiderCardinality(t *testing.T) {
+	tests := []struct {
+		a, b     Cardinality
+		expected Cardinality
+	}{
+		{ExactlyOne, ExactlyOne, ExactlyOne},
+		{ExactlyOne, ZeroOrOne, ZeroOrOne},
+		{ExactlyOne, OneOrMore, OneOrMore},
+		{ZeroOrOne, OneOrMore, ZeroOrMore},
+		{OneOrMore, OneOrMore, OneOrMore},
+		{ZeroOrMore, ExactlyOne, ZeroOrMore},
+	}
+
+	for _, tt := range tests {
+		if got := widerCardinality(tt.a, tt.b); got != tt.expected {
+			t.Errorf("widerCardinality(%s, %s) = %s, expected %s", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,522 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/thediveo/enumflag/v2"
+
+	"github.com/lespea/entmaid/annotation"
+)
+
+// MermaidVersion is the major version of the Mermaid renderer the diagram targets, older renderers bundled in
+// wikis and IDEs reject the syntax added since.
+type MermaidVersion enumflag.Flag
+
+const (
+	// Mermaid11 is the default, as it's the version GitHub renders diagrams with.
+	Mermaid11 MermaidVersion = iota
+	Mermaid10
+)
+
+var MermaidVersionIds = map[MermaidVersion][]string{
+	Mermaid11: {"11"},
+	Mermaid10: {"10"},
+}
+
+// WithMermaidVersion only emits the syntax supported by the version of Mermaid.
+func WithMermaidVersion(version MermaidVersion) Option {
+	return func(o *options) {
+		o.mermaidVersion = version
+	}
+}
+
+// renderMermaid writes the model as a Mermaid ERD diagram, or a class diagram in the UML notation.
+// A non-empty header is written as a comment line directly after the diagram type.
+func renderMermaid(ctx context.Context, w io.Writer, model *Model, o *options) error {
+	var builder strings.Builder
+	builder.Grow(mermaidSize(model))
+
+	abbreviations := abbreviateModel(model, o)
+
+	// Mermaid 10 doesn't support front matter so the title is only a comment.
+	if o.title != "" && o.mermaidVersion != Mermaid10 {
+		builder.WriteString(mermaidFrontMatter(o.title))
+	}
+
+	if o.notation == NotationUML {
+		builder.WriteString("classDiagram\n")
+	} else {
+		builder.WriteString("erDiagram\n")
+	}
+
+	if o.title != "" && o.mermaidVersion == Mermaid10 {
+		builder.WriteString(fmt.Sprintf(" %%%% %s\n", mermaidLine(o.title)))
+	}
+
+	if o.description != "" {
+		for _, line := range strings.Split(strings.TrimSpace(o.description), "\n") {
+			builder.WriteString(strings.TrimRight(fmt.Sprintf(" %%%% %s", mermaidLine(strings.TrimSpace(line))), " ") + "\n")
+		}
+	}
+
+	if model.Header != "" {
+		builder.WriteString(fmt.Sprintf(" %%%% %s\n", mermaidLine(model.Header)))
+	}
+
+	if model.pages > 0 {
+		builder.WriteString(fmt.Sprintf(" %%%% diagram %d of %d\n", model.page, model.pages))
+	}
+
+	if o.notation == NotationUML && o.mixins {
+		mermaidMixins(&builder, model, abbreviations, o)
+	}
+
+	for i, entity := range model.Entities {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// The entities, the join tables and the relationships are drawn in sections of their own, so the diff of a
+		// change to the schema is easy to review.
+		if i == 0 && !entity.JoinTable {
+			builder.WriteString(" %% entities\n\n")
+		}
+
+		if group := entity.group(); o.groupBySchema && group != "" && (i == 0 || model.Entities[i-1].group() != group) {
+			kind := "schema"
+			if entity.Domain != "" {
+				kind = "domain"
+			}
+			builder.WriteString(fmt.Sprintf(" %%%% %s %s\n\n", kind, group))
+		}
+
+		if entity.JoinTable && entity.drawnOn == 0 && (i == 0 || !model.Entities[i-1].JoinTable) {
+			builder.WriteString(" %% join tables\n\n")
+		}
+
+		if o.notation == NotationUML {
+			mermaidClass(&builder, entity, abbreviations, o)
+		} else {
+			mermaidEntity(&builder, entity, abbreviations, o)
+		}
+
+		// Mermaid can't draw indexes so they're listed as comments below their entity.
+		for _, index := range entity.Indexes {
+			kind := "index"
+			if index.Unique {
+				kind = "unique index"
+			}
+
+			builder.WriteString(mermaidLine(" %% "+entity.Name+" "+kind+" "+index.Name+" ("+strings.Join(index.Columns, ", ")+")") + "\n")
+		}
+
+		// Nor check constraints, which are part of the column constraints reviewed with the detailed types.
+		if o.detailedTypes {
+			for _, check := range entity.Checks {
+				builder.WriteString(mermaidLine(" %% "+entity.Name+" check "+check.describe()) + "\n")
+			}
+		}
+
+		if o.lintOverlay {
+			for _, lint := range entity.lints {
+				rule := lint.Rule
+				lint.Rule = ""
+				builder.WriteString(mermaidLine(" %% warning ("+string(rule)+") "+lint.String()) + "\n")
+			}
+		}
+
+		builder.WriteString("\n")
+	}
+
+	relationships := sortedRelationships(model.Relationships)
+	if o.mergeEdges {
+		relationships = mergeRelationships(relationships)
+	}
+
+	if len(relationships) > 0 {
+		builder.WriteString(" %% relationships\n\n")
+	}
+
+	for _, rel := range relationships {
+		if o.notation == NotationUML {
+			builder.WriteString(" " + mermaidAssociation(rel, o) + "\n")
+			continue
+		}
+
+		builder.WriteString(" " + mermaidEntityName(rel.From) + " " + getMermaidRelationship(rel) + " " + mermaidEntityName(rel.To) + " : " + mermaidLabel(relationshipLabel(rel, o)) + "\n")
+	}
+
+	if o.notation == NotationUML && o.mixins {
+		for _, line := range mermaidGeneralizations(model) {
+			builder.WriteString(" " + line + "\n")
+		}
+	}
+
+	// Mermaid 10 can't style the entities of ER diagrams.
+	if o.mermaidVersion != Mermaid10 {
+		styled := false
+		for _, entity := range model.Entities {
+			style := mermaidStyle(entity.Style)
+			if style == "" && o.lintOverlay && len(entity.lints) > 0 {
+				style = lintStyle
+			}
+			if style != "" {
+				if !styled {
+					builder.WriteString("\n")
+					styled = true
+				}
+				name := mermaidEntityName(entity.Name)
+				if o.notation == NotationUML {
+					name = mermaidClassName(entity.Name)
+				}
+				builder.WriteString(fmt.Sprintf(" style %s %s\n", name, style))
+			}
+		}
+	}
+
+	// Only the classDiagram can bind clicks, the links of the erDiagram are listed below the Markdown diagrams.
+	if o.notation == NotationUML {
+		linked := false
+		for _, entity := range model.Entities {
+			if entity.Link != "" {
+				if !linked {
+					builder.WriteString("\n")
+					linked = true
+				}
+				builder.WriteString(fmt.Sprintf(" click %s href \"%s\"\n", mermaidClassName(entity.Name), strings.ReplaceAll(entity.Link, `"`, "%22")))
+			}
+		}
+	}
+
+	if len(abbreviations.abbreviations) > 0 {
+		builder.WriteString("\n")
+		for _, abbreviation := range abbreviations.abbreviations {
+			builder.WriteString(fmt.Sprintf(" %%%% %s = %s\n", abbreviation.Short, mermaidLine(abbreviation.Full)))
+		}
+	}
+
+	if o.validate && o.notation == NotationCrowsFoot {
+		if err := validateMermaid(builder.String()); err != nil {
+			return err
+		}
+	}
+
+	if _, err := io.WriteString(w, builder.String()); err != nil {
+		return fmt.Errorf("%w: failed to write string: %w", ErrRender, err)
+	}
+
+	return nil
+}
+
+// sortedRelationships returns the relationships sorted by the entities they're from and to, then by label, so adding
+// an edge only adds its line to the diagram wherever it's declared in the schema.
+func sortedRelationships(relationships []*Relationship) []*Relationship {
+	sorted := slices.Clone(relationships)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+
+		return a.Label < b.Label
+	})
+
+	return sorted
+}
+
+// mermaidSize estimates the length of the diagram of the model, to allocate the builder once for large schemas.
+func mermaidSize(model *Model) int {
+	size := 64 + len(model.Header) + 64*len(model.Relationships)
+	for _, entity := range model.Entities {
+		size += 16 + 2*len(entity.Name) + 48*len(entity.Attributes) + 64*len(entity.Indexes)
+	}
+
+	return size
+}
+
+// mermaidEntity writes the entity with its attributes as an entity of the erDiagram.
+func mermaidEntity(builder *strings.Builder, entity *Entity, abbreviations *abbreviator, o *options) {
+	// Views and guarded entities are told apart from the tables by their label, erDiagram has no other way to
+	// style an entity. Mermaid 10 doesn't support entity labels so they're only marked by a comment.
+	var tags []string
+	if entity.View {
+		tags = append(tags, "view")
+	}
+	if len(entity.Guards) > 0 {
+		tags = append(tags, strings.Join(entity.Guards, ","))
+	}
+	if entity.drawnOn > 0 {
+		tags = append(tags, fmt.Sprintf("see diagram %d", entity.drawnOn))
+	}
+
+	// The label of the entity is its display name, the identifier of the relationships stays its name.
+	label := entity.Name
+	if entity.DisplayName != "" {
+		label = entity.DisplayName
+	}
+
+	switch {
+	case (len(tags) > 0 || entity.DisplayName != "") && o.mermaidVersion == Mermaid10:
+		if entity.DisplayName != "" {
+			builder.WriteString(fmt.Sprintf(" %%%% %s is displayed as %s\n", mermaidLine(entity.Name), mermaidLine(entity.DisplayName)))
+		}
+		if entity.View {
+			builder.WriteString(fmt.Sprintf(" %%%% %s is a view\n", mermaidLine(entity.Name)))
+		}
+		if len(entity.Guards) > 0 {
+			builder.WriteString(fmt.Sprintf(" %%%% %s is guarded by %s\n", mermaidLine(entity.Name), strings.Join(entity.Guards, ",")))
+		}
+		if entity.drawnOn > 0 {
+			builder.WriteString(fmt.Sprintf(" %%%% %s is drawn in diagram %d\n", mermaidLine(entity.Name), entity.drawnOn))
+		}
+		builder.WriteString(fmt.Sprintf(" %s {\n", mermaidEntityName(entity.Name)))
+	case len(tags) > 0:
+		builder.WriteString(fmt.Sprintf(" %s[\"%s (%s)\"] {\n", mermaidEntityName(entity.Name), mermaidComment(label), strings.Join(tags, ") (")))
+	case entity.DisplayName != "":
+		builder.WriteString(fmt.Sprintf(" %s[\"%s\"] {\n", mermaidEntityName(entity.Name), mermaidComment(label)))
+	default:
+		builder.WriteString(" " + mermaidEntityName(entity.Name) + " {\n")
+	}
+
+	var attributes []*Attribute
+	for _, attribute := range entity.Attributes {
+		if !hiddenAttribute(attribute, o) {
+			attributes = append(attributes, attribute)
+		}
+	}
+
+	attributes, more := truncateAttributes(entity, attributes, o)
+	for _, attribute := range attributes {
+		typeName, name := abbreviations.typeName(attribute.Type), abbreviations.attributeName(attribute.Name)
+		typeWord, nameWord := mermaidWord(typeName), mermaidWord(name)
+		builder.WriteString("  ")
+		builder.WriteString(typeWord)
+		builder.WriteByte(' ')
+		builder.WriteString(nameWord)
+		for i, key := range attribute.Keys {
+			if i == 0 {
+				builder.WriteByte(' ')
+			} else {
+				builder.WriteByte(',')
+			}
+			builder.WriteString(key)
+		}
+
+		// Attribute types and names can only be ASCII words, the comment keeps the ones that had to be changed
+		// (e.g. non-ASCII names) as they really are.
+		var details []string
+		if attribute.Label != "" {
+			details = append(details, attribute.Label)
+		}
+		if typeWord != typeName {
+			details = append(details, "type: "+typeName)
+		}
+		if nameWord != name {
+			details = append(details, "column: "+name)
+		}
+		if comment := attributeComment(attribute, o); comment != "" {
+			details = append(details, comment)
+		}
+		if len(details) > 0 {
+			builder.WriteString(` "`)
+			builder.WriteString(mermaidComment(strings.Join(details, ", ")))
+			builder.WriteByte('"')
+		}
+		builder.WriteByte('\n')
+	}
+
+	// The row counting the attributes left out can't be a comment, as Mermaid wouldn't draw it.
+	if more > 0 {
+		builder.WriteString(fmt.Sprintf("  __ __ \"%s\"\n", moreAttributes(more)))
+	}
+
+	builder.WriteString(" }\n")
+}
+
+// mermaidStyle returns the CSS properties of the style statement of an entity, or "" when it isn't styled.
+func mermaidStyle(style *annotation.Style) string {
+	if style == nil {
+		return ""
+	}
+
+	var properties []string
+	for _, property := range [][2]string{{"fill", style.Fill}, {"stroke", style.Stroke}, {"color", style.Color}} {
+		// The properties are separated by commas, so the commas of a value (e.g. rgb(1,2,3)) are escaped.
+		value := strings.Join(strings.FieldsFunc(property[1], func(r rune) bool { return r == ';' || unicode.IsSpace(r) }), "")
+		if value = strings.ReplaceAll(value, ",", `\,`); value != "" {
+			properties = append(properties, property[0]+":"+value)
+		}
+	}
+
+	return strings.Join(properties, ",")
+}
+
+// attributeComment describes the details of the attribute that don't have a dedicated place in the diagram.
+func attributeComment(attribute *Attribute, o *options) string {
+	var details []string
+	if attribute.Classification != "" {
+		details = append(details, string(attribute.Classification))
+	}
+	if attribute.Default != "" {
+		details = append(details, "default: "+attribute.Default)
+	}
+	if attribute.Immutable {
+		details = append(details, "immutable")
+	}
+	if len(attribute.Fields) > 0 {
+		details = append(details, jsonFieldsComment(attribute.Fields))
+	}
+	if attribute.GlobalID != nil {
+		details = append(details, globalIDComment(*attribute.GlobalID))
+	}
+	if o.nullability {
+		details = append(details, columnNullability(attribute))
+	}
+
+	return strings.Join(details, ", ")
+}
+
+var (
+	// mermaidEntityCodeRegex matches what Mermaid decodes as an entity code, e.g. #quot; or #35;.
+	mermaidEntityCodeRegex = regexp.MustCompile(`#(\w+;)`)
+	// mermaidCommentEscaper and mermaidLineEscaper are built once, a replacer is expensive to build for each string.
+	mermaidCommentEscaper = strings.NewReplacer(`"`, "#quot;", "`", "#96;", "\r\n", " ", "\r", " ", "\n", " ", "\t", " ")
+	mermaidLineEscaper    = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+)
+
+// mermaidComment makes the text safe to use in a quoted string (attribute comments, entity and relationship labels),
+// which can't contain double quotes or span several lines. Double quotes and backticks, which turn a quoted string
+// into a Markdown string, are written as entity codes, and so is the # of text Mermaid would decode as one.
+func mermaidComment(s string) string {
+	if strings.IndexByte(s, '#') != -1 {
+		s = mermaidEntityCodeRegex.ReplaceAllString(s, "#35;$1")
+	}
+
+	return mermaidCommentEscaper.Replace(s)
+}
+
+// mermaidLine makes the text safe to use in a %% comment, which ends with the line.
+func mermaidLine(s string) string {
+	return mermaidLineEscaper.Replace(s)
+}
+
+// mermaidInvalidWordRegex matches the characters that can't be part of an attribute type or name.
+var mermaidInvalidWordRegex = regexp.MustCompile(`[^A-Za-z0-9_\-\[\]()]`)
+
+// isMermaidName tells whether the entity name or relationship label can be used without quoting it, that is whether
+// it matches ^[A-Za-z_][A-Za-z0-9_-]*$. It's checked byte by byte as it's called for every entity and relationship.
+func isMermaidName(name string) bool {
+	for i := range len(name) {
+		c := name[i]
+		if !isASCIILetter(c) && c != '_' && (i == 0 || !isASCIIDigit(c) && c != '-') {
+			return false
+		}
+	}
+
+	return name != ""
+}
+
+// isMermaidWord tells whether the attribute type or name, which can't be quoted, matches
+// ^[*A-Za-z_][A-Za-z0-9_\-\[\]()]*$.
+func isMermaidWord(word string) bool {
+	for i := range len(word) {
+		c := word[i]
+		if isASCIILetter(c) || c == '_' || i == 0 && c == '*' {
+			continue
+		}
+		if i == 0 || !isASCIIDigit(c) && !strings.ContainsRune("-[]()", rune(c)) {
+			return false
+		}
+	}
+
+	return word != ""
+}
+
+func isASCIILetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isASCIIDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// mermaidKeywords are the words of the erDiagram grammar, which break the parsing when used as a bare name.
+var mermaidKeywords = map[string]bool{
+	"erdiagram": true, "title": true, "acctitle": true, "accdescr": true, "direction": true, "style": true,
+	"classdef": true, "class": true, "one": true, "only": true, "zero": true, "many": true, "more": true,
+	"or": true, "optionally": true, "to": true, "u": true, "pk": true, "fk": true, "uk": true,
+}
+
+// mermaidEntityName quotes the entity name when it can't be used as is.
+func mermaidEntityName(name string) string {
+	if isMermaidName(name) && !mermaidKeywords[strings.ToLower(name)] {
+		return name
+	}
+
+	return `"` + mermaidComment(name) + `"`
+}
+
+// mermaidLabel quotes the relationship label when it can't be used as is.
+func mermaidLabel(label string) string {
+	if isMermaidName(label) && !mermaidKeywords[strings.ToLower(label)] {
+		return label
+	}
+
+	return `"` + mermaidComment(label) + `"`
+}
+
+// mermaidWord replaces the characters that can't be part of an attribute type or name, which Mermaid doesn't allow
+// to be quoted, with underscores.
+func mermaidWord(word string) string {
+	if isMermaidWord(word) {
+		return word
+	}
+
+	word = mermaidInvalidWordRegex.ReplaceAllString(word, "_")
+	if !isMermaidWord(word) {
+		word = "_" + word
+	}
+
+	return word
+}
+
+// mermaidLeftEnds and mermaidRightEnds are the crow's foot ends of the relationships by cardinality.
+var (
+	mermaidLeftEnds  = map[Cardinality]string{ZeroOrOne: "|o", ExactlyOne: "||", ZeroOrMore: "}o", OneOrMore: "}|"}
+	mermaidRightEnds = map[Cardinality]string{ZeroOrOne: "o|", ExactlyOne: "||", ZeroOrMore: "o{", OneOrMore: "|{"}
+)
+
+// getMermaidRelationship returns the crow's foot notation between the two ends of the relationship.
+func getMermaidRelationship(rel *Relationship) string {
+	line := "--"
+	if rel.Logical {
+		line = ".."
+	}
+
+	return mermaidLeftEnds[rel.FromCardinality] + line + mermaidRightEnds[rel.ToCardinality]
+}
+
+func addMermaidToType(mermaidCode string, outputType OutputType) string {
+	switch outputType {
+	case Markdown:
+		return fmt.Sprintf("```mermaid\n%s\n```", mermaidCode)
+	case Hugo:
+		return fmt.Sprintf("{{< mermaid >}}\n%s\n{{< /mermaid >}}", mermaidCode)
+	case Obsidian:
+		return fmt.Sprintf("~~~mermaid\n%s\n~~~", mermaidCode)
+	case Plain, GoDoc:
+		return mermaidCode
+	default:
+		return mermaidCode
+	}
+}
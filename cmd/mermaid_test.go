@@ -0,0 +1,422 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/lespea/entmaid/annotation"
+)
+
+func TestMermaidNames(t *testing.T) {
+	entities := map[string]string{
+		"User":        "User",
+		"group_users": "group_users",
+		"Order Item":  `"Order Item"`,
+		"2fa":         `"2fa"`,
+		"class":       `"class"`,
+		`say "hi"`:    `"say #quot;hi#quot;"`,
+	}
+
+	for name, expected := range entities {
+		if got := mermaidEntityName(name); got != expected {
+			t.Errorf("mermaidEntityName(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+
+	labels := map[string]string{
+		"cars-owner":  "cars-owner",
+		"written by":  `"written by"`,
+		"to":          `"to"`,
+		"line\nbreak": `"line break"`,
+	}
+
+	for label, expected := range labels {
+		if got := mermaidLabel(label); got != expected {
+			t.Errorf("mermaidLabel(%q) = %q, expected %q", label, got, expected)
+		}
+	}
+
+	words := map[string]string{
+		"registered_at":    "registered_at",
+		"text[]":           "text[]",
+		"varchar(64)":      "varchar(64)",
+		"double precision": "double_precision",
+		"2fa_secret":       "_2fa_secret",
+		"schema-Address":   "schema-Address",
+		"map[string]*T":    "map[string]_T",
+	}
+
+	for word, expected := range words {
+		if got := mermaidWord(word); got != expected {
+			t.Errorf("mermaidWord(%q) = %q, expected %q", word, got, expected)
+		}
+	}
+}
+
+func TestMermaidComment(t *testing.T) {
+	comments := map[string]string{
+		"default: unknown":        "default: unknown",
+		`default: "unknown"`:      "default: #quot;unknown#quot;",
+		"default: `now()`":        "default: #96;now()#96;",
+		"first line\nsecond line": "first line second line",
+		"windows\r\nline":         "windows line",
+		"tab\tseparated":          "tab separated",
+		"default: #fff":           "default: #fff",
+		"default: #quot;":         "default: #35;quot;",
+		"日本語のコメント":                "日本語のコメント",
+	}
+
+	for comment, expected := range comments {
+		if got := mermaidComment(comment); got != expected {
+			t.Errorf("mermaidComment(%q) = %q, expected %q", comment, got, expected)
+		}
+	}
+
+	if got := mermaidLine("title\n erDiagram"); got != "title  erDiagram" {
+		t.Errorf("Expected the comment to stay on one line, got %q", got)
+	}
+}
+
+func TestRenderMermaidEscaping(t *testing.T) {
+	model := &Model{
+		Header: "generated\nby entmaid",
+		Entities: []*Entity{{
+			Name:       "User",
+			Attributes: []*Attribute{{Name: "name", Type: "string", Default: "say \"hi\"\nbye `now`"}},
+			Indexes:    []*Index{{Name: "user\nname", Columns: []string{"name"}}},
+		}},
+		Relationships: []*Relationship{{From: "User", To: "User", FromCardinality: ZeroOrOne, ToCardinality: ZeroOrMore, Label: `the "best"` + "\nfriends"}},
+	}
+
+	var builder strings.Builder
+	if err := renderMermaid(context.Background(), &builder, model, newOptions([]Option{WithValidate(), WithTitle("two\nlines"), WithMermaidVersion(Mermaid10)})); err != nil {
+		t.Fatalf("Expected the escaped diagram to be valid, got %v:\n%s", err, builder.String())
+	}
+
+	for _, expected := range []string{
+		" %% two lines\n",
+		" %% generated by entmaid\n",
+		`  string name "default: say #quot;hi#quot; bye #96;now#96;"` + "\n",
+		" %% User index user name (name)\n",
+		` User |o--o{ User : "the #quot;best#quot; friends"` + "\n",
+	} {
+		if !strings.Contains(builder.String(), expected) {
+			t.Errorf("The diagram is missing %q:\n%s", expected, builder.String())
+		}
+	}
+}
+
+func TestRenderMermaidVersion(t *testing.T) {
+	model := &Model{Entities: []*Entity{{Name: "ActiveUser", View: true, Attributes: []*Attribute{{Name: "id", Type: "int"}}}}}
+
+	for version, expected := range map[MermaidVersion]string{
+		Mermaid11: " ActiveUser[\"ActiveUser (view)\"] {\n",
+		Mermaid10: " %% ActiveUser is a view\n ActiveUser {\n",
+	} {
+		var builder strings.Builder
+		if err := renderMermaid(context.Background(), &builder, model, newOptions([]Option{WithMermaidVersion(version), WithValidate()})); err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(builder.String(), expected) {
+			t.Errorf("Mermaid %s diagram is missing %q:\n%s", MermaidVersionIds[version][0], expected, builder.String())
+		}
+	}
+}
+
+func TestAddMermaidToType(t *testing.T) {
+	for outputType, expected := range map[OutputType]string{
+		Markdown: "```mermaid\nerDiagram\n```",
+		Plain:    "erDiagram",
+		Hugo:     "{{< mermaid >}}\nerDiagram\n{{< /mermaid >}}",
+		Obsidian: "~~~mermaid\nerDiagram\n~~~",
+		GoDoc:    "erDiagram",
+	} {
+		if got := addMermaidToType("erDiagram", outputType); got != expected {
+			t.Errorf("addMermaidToType(%s) = %q, expected %q", OutputTypeIds[outputType][0], got, expected)
+		}
+	}
+}
+
+func TestGenerateDiagramGoDoc(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "doc.go")
+	content := "// Package ent is the schema.\n//\n// #start:entmaid\n// #end:entmaid\npackage ent\n"
+	if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := GenerateDiagram("../examples/start/schema", target, GoDoc, "", "", WithQuiet(), WithSummary(SummaryBelow)); err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(written), "// #start:entmaid\n//\n//\terDiagram\n//\t %% entities\n//\n//\t Car {\n") {
+		t.Errorf("Expected the diagram as a code block of the doc comment:\n%s", written)
+	}
+
+	// gofmt reformats the doc comments, it mustn't touch the diagram.
+	formatted, err := format.Source(written)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(formatted) != string(written) {
+		t.Errorf("Expected gofmt to keep the diagram as is, got:\n%s", formatted)
+	}
+}
+
+func TestGenerateDiagramGoTargetOutputType(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "doc.go")
+	content := "// #start:entmaid\n// #end:entmaid\npackage ent\n"
+	if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, outputType := range []OutputType{Markdown, Plain} {
+		err := GenerateDiagram("../examples/start/schema", target, outputType, "", "", WithQuiet())
+		if !errors.Is(err, ErrTarget) {
+			t.Errorf("Expected the %s output type to be refused for a Go target, got %v", OutputTypeIds[outputType][0], err)
+		}
+	}
+
+	// The TUI writes the target without going through GenerateDiagram.
+	model, err := loadModel(context.Background(), "../examples/start/schema", newOptions([]Option{WithQuiet()}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeTarget(context.Background(), model, target, Markdown, "", "", newOptions([]Option{WithQuiet()})); !errors.Is(err, ErrTarget) {
+		t.Errorf("Expected writing the markdown output type to a Go target to be refused, got %v", err)
+	}
+
+	if written, err := os.ReadFile(target); err != nil || string(written) != content {
+		t.Errorf("Expected the Go target to be left as is, got %v:\n%s", err, written)
+	}
+}
+
+func TestFenceMermaid(t *testing.T) {
+	o := newOptions([]Option{WithTitle("Shop"), WithFence(":::mermaid {{.Type}}", "::: {{.Title}}")})
+	if got, err := fenceMermaid("erDiagram", Markdown, o); err != nil || got != ":::mermaid markdown\nerDiagram\n::: Shop" {
+		t.Errorf("Got %q (%v), expected the templated fence", got, err)
+	}
+
+	if got, err := fenceMermaid("erDiagram", Hugo, newOptions(nil)); err != nil || got != addMermaidToType("erDiagram", Hugo) {
+		t.Errorf("Got %q (%v), expected the fence of the output type", got, err)
+	}
+
+	if _, err := fenceMermaid("erDiagram", Markdown, newOptions([]Option{WithFence("{{.Missing}}", "")})); !errors.Is(err, ErrRender) {
+		t.Errorf("Expected an invalid template to fail with ErrRender, got %v", err)
+	}
+
+	var builder strings.Builder
+	if err := renderMermaid(context.Background(), &builder, &Model{}, newOptions([]Option{WithTitle(`Shop: "v2"`), WithValidate()})); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "---\ntitle: \"Shop: \\\"v2\\\"\"\n---\nerDiagram\n"; builder.String() != expected {
+		t.Errorf("Got %q, expected %q", builder.String(), expected)
+	}
+}
+
+func TestDescribeDiagram(t *testing.T) {
+	mermaidPath := filepath.Join(t.TempDir(), "erd.mmd")
+
+	err := GenerateDiagram("../examples/annotations/schema", "", Plain, "", "", WithQuiet(), WithDescription("For the\n\nsupport team."), WithOutput(FormatMermaid, mermaidPath))
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	content, err := os.ReadFile(mermaidPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The title is annotated in the schema, the description of the option takes precedence over the annotated one.
+	if expected := "---\ntitle: \"Orders\"\n---\nerDiagram\n %% For the\n %%\n %% support team.\n %% entities\n\n Customer {\n"; !strings.HasPrefix(string(content), expected) {
+		t.Errorf("Expected the diagram to start with %q:\n%s", expected, content)
+	}
+
+	var builder strings.Builder
+	if err := Render(context.Background(), &builder, &Model{Title: "Shop", Description: "All of it."}, FormatMermaid, WithMermaidVersion(Mermaid10)); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "erDiagram\n %% Shop\n %% All of it.\n"; builder.String() != expected {
+		t.Errorf("Got %q, expected %q", builder.String(), expected)
+	}
+}
+
+func TestMermaidStyle(t *testing.T) {
+	styles := map[*annotation.Style]string{
+		nil:                                    "",
+		{}:                                     "",
+		{Fill: "#f96"}:                         "fill:#f96",
+		{Fill: "rgb(1, 2, 3)", Stroke: "red;"}: `fill:rgb(1\,2\,3),stroke:red`,
+		{Stroke: "#c00", Color: "white"}:       "stroke:#c00,color:white",
+	}
+
+	for style, expected := range styles {
+		if got := mermaidStyle(style); got != expected {
+			t.Errorf("mermaidStyle(%+v) = %q, expected %q", style, got, expected)
+		}
+	}
+
+	merged := annotation.Styled(annotation.Style{Fill: "#f96"}).Merge(annotation.Styled(annotation.Style{Fill: "#fdd"})).(annotation.Annotation)
+	if merged.Style == nil || merged.Style.Fill != "#fdd" {
+		t.Errorf("Expected the last style to take precedence, got %+v", merged.Style)
+	}
+}
+
+func TestRenderMermaidHideIDs(t *testing.T) {
+	model := &Model{
+		Entities: []*Entity{
+			{Name: "User", Attributes: []*Attribute{{Name: "id", Type: "int", Keys: []string{"PK"}}, {Name: "name", Type: "string"}}},
+			{Name: "group_users", JoinTable: true, Attributes: []*Attribute{{Name: "user_id", Type: "int", Keys: []string{"PK", "FK"}}}},
+		},
+	}
+
+	var builder strings.Builder
+	if err := renderMermaid(context.Background(), &builder, model, newOptions([]Option{WithHideIDs(), WithValidate()})); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(builder.String(), "int id PK") {
+		t.Errorf("Expected the ID to be hidden:\n%s", builder.String())
+	}
+	if !strings.Contains(builder.String(), "  int user_id PK,FK\n") {
+		t.Errorf("Expected the foreign key of the join table to be kept:\n%s", builder.String())
+	}
+}
+
+func TestMermaidWordChecks(t *testing.T) {
+	nameRegex := regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+	wordRegex := regexp.MustCompile(`^[*A-Za-z_][A-Za-z0-9_\-\[\]()]*$`)
+
+	for _, s := range []string{"", "User", "_id", "2fa", "a-b", "-a", "*a", "a*", "[]string", "map[string]int", "f(x)", "é", "a b", "a.b"} {
+		if got, expected := isMermaidName(s), nameRegex.MatchString(s); got != expected {
+			t.Errorf("isMermaidName(%q) = %t, expected %t", s, got, expected)
+		}
+		if got, expected := isMermaidWord(s), wordRegex.MatchString(s); got != expected {
+			t.Errorf("isMermaidWord(%q) = %t, expected %t", s, got, expected)
+		}
+	}
+}
+
+// largeModel builds a model of the size of a large schema, with every entity holding a foreign key to the previous one.
+func largeModel(entities int) *Model {
+	model := &Model{}
+	for i := range entities {
+		entity := &Entity{Name: fmt.Sprintf("Entity%d", i), Table: fmt.Sprintf("entity%ds", i)}
+		entity.Attributes = append(entity.Attributes, &Attribute{Name: "id", Type: "int", Keys: []string{"PK"}})
+		for j := range 10 {
+			entity.Attributes = append(entity.Attributes, &Attribute{Name: fmt.Sprintf("field_%d", j), Type: "string", Default: "auto"})
+		}
+
+		if i > 0 {
+			entity.Attributes = append(entity.Attributes, &Attribute{Name: "parent_id", Type: "int", Keys: []string{"FK"}})
+			model.Relationships = append(model.Relationships, &Relationship{
+				From: model.Entities[i-1].Name, To: entity.Name, FromCardinality: ZeroOrOne, ToCardinality: ZeroOrMore,
+				Label: "children", Edge: "children",
+			})
+		}
+
+		model.Entities = append(model.Entities, entity)
+	}
+
+	return model
+}
+
+func BenchmarkRenderMermaid(b *testing.B) {
+	model := largeModel(300)
+
+	for _, notation := range []Notation{NotationCrowsFoot, NotationUML} {
+		b.Run(NotationIds[notation][0], func(b *testing.B) {
+			o := newOptions([]Option{WithQuiet(), WithNotation(notation)})
+
+			b.ReportAllocs()
+			for range b.N {
+				if err := renderMermaid(context.Background(), io.Discard, model, o); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// mermaidEntityCodes are the entity codes the escaping writes, which Mermaid decodes back.
+var mermaidEntityCodes = map[string]string{"quot": `"`, "96": "`", "35": "#"}
+
+func FuzzMermaidEscaping(f *testing.F) {
+	f.Add("User", "name", "string", "the name", "owns")
+	f.Add(`Us"er {`, "na me\n }", "map[string]*x.Y", "#quot; `code` \r\n #35;", "to")
+	f.Add("PK", "*", "", "%% comment", `"}`)
+	f.Add("erDiagram", "FK", "[]", "\"\n erDiagram\n", "one or more")
+
+	f.Fuzz(func(t *testing.T, entityName string, fieldName string, fieldType string, comment string, label string) {
+		if entityName == "Other" {
+			t.Skip()
+		}
+
+		// The text of a quoted string reads back as the original once Mermaid decodes the entity codes.
+		escaped := mermaidComment(comment)
+		if strings.ContainsAny(escaped, "\"`\r\n") {
+			t.Fatalf("mermaidComment(%q) = %q can't be quoted", comment, escaped)
+		}
+		decoded := mermaidEntityCodeRegex.ReplaceAllStringFunc(escaped, func(code string) string {
+			decoded, ok := mermaidEntityCodes[code[1:len(code)-1]]
+			if !ok {
+				t.Fatalf("mermaidComment(%q) = %q holds the entity code %s", comment, escaped, code)
+			}
+			return decoded
+		})
+		if expected := strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ", "\t", " ").Replace(comment); decoded != expected {
+			t.Fatalf("mermaidComment(%q) = %q decodes to %q", comment, escaped, decoded)
+		}
+
+		if line := mermaidLine(comment); strings.ContainsAny(line, "\r\n") {
+			t.Fatalf("mermaidLine(%q) = %q spans several lines", comment, line)
+		}
+
+		// Hostile names and comments only ever give a valid diagram of the same entities and relationships.
+		model := &Model{
+			Entities: []*Entity{
+				{Name: entityName, Attributes: []*Attribute{{Name: fieldName, Type: fieldType, Default: comment}}},
+				{Name: "Other", DisplayName: comment},
+			},
+			Relationships: []*Relationship{{From: entityName, To: "Other", FromCardinality: ZeroOrOne, ToCardinality: ZeroOrMore, Label: label}},
+		}
+
+		var builder strings.Builder
+		if err := renderMermaid(context.Background(), &builder, model, newOptions([]Option{WithQuiet()})); err != nil {
+			t.Fatal(err)
+		}
+
+		diagram := builder.String()
+		if err := validateMermaid(diagram); err != nil {
+			t.Fatalf("Rendered an invalid diagram: %v\n%s", err, diagram)
+		}
+
+		var entities, relationships int
+		for _, line := range strings.Split(diagram, "\n") {
+			trimmed := strings.TrimSpace(line)
+			switch {
+			case mermaidEntityLineRegex.MatchString(trimmed):
+				entities++
+			case mermaidRelationshipRegex.MatchString(trimmed):
+				relationships++
+			}
+		}
+		if entities != 2 || relationships != 1 {
+			t.Fatalf("Rendered %d entities and %d relationships instead of 2 and 1:\n%s", entities, relationships, diagram)
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WithMixins draws the mixins of the schema as abstract classes holding their fields, with generalization arrows to
+// the entities using them, in the UML notation. The mixins are named as they're listed by the Mixin method of the
+// schema types, e.g. mixin.Time.
+func WithMixins() Option {
+	return func(o *options) {
+		o.mixins = true
+	}
+}
+
+// resolveMixins names the mixins of the entities from the Mixin methods of the schema package.
+func resolveMixins(model *Model, dir string) error {
+	mixins, err := schemaMixins(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read the mixins of the schema: %w", err)
+	}
+
+	for _, entity := range model.Entities {
+		entity.mixins = mixins[entity.typeName]
+	}
+
+	return nil
+}
+
+// schemaMixins parses the schema package, returning the mixins listed by the Mixin method of every schema type. The
+// mixins that aren't a composite literal or a call (e.g. a variable) are left unnamed.
+func schemaMixins(dir string) (map[string][]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	mixins := make(map[string][]string)
+	fset := token.NewFileSet()
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !strings.HasSuffix(entry.Name(), ".go") || strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(dir, entry.Name()), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Name.Name != "Mixin" || fn.Recv == nil || len(fn.Recv.List) != 1 || fn.Body == nil {
+				continue
+			}
+
+			receiver := fn.Recv.List[0].Type
+			if star, ok := receiver.(*ast.StarExpr); ok {
+				receiver = star.X
+			}
+
+			ident, ok := receiver.(*ast.Ident)
+			if !ok {
+				continue
+			}
+
+			ast.Inspect(fn.Body, func(node ast.Node) bool {
+				ret, ok := node.(*ast.ReturnStmt)
+				if !ok || len(ret.Results) != 1 {
+					return true
+				}
+
+				if list, ok := ret.Results[0].(*ast.CompositeLit); ok {
+					mixins[ident.Name] = mixinNames(list.Elts)
+				}
+
+				return false
+			})
+		}
+	}
+
+	return mixins, nil
+}
+
+// mixinNames returns the names of the mixins of the list, e.g. mixin.Time for mixin.Time{} and mixin.AnnotateFields
+// for mixin.AnnotateFields(...).
+func mixinNames(elements []ast.Expr) []string {
+	names := make([]string, len(elements))
+	for i, element := range elements {
+		if unary, ok := element.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+			element = unary.X
+		}
+
+		switch element := element.(type) {
+		case *ast.CompositeLit:
+			names[i] = types.ExprString(element.Type)
+		case *ast.CallExpr:
+			names[i] = types.ExprString(element.Fun)
+		}
+	}
+
+	return names
+}
+
+// mixinName returns the name of the mixin the attribute is declared in, "" when it isn't mixed in or the mixin
+// isn't named.
+func mixinName(entity *Entity, attribute *Attribute) string {
+	if attribute.position == nil || !attribute.position.MixedIn || attribute.position.MixinIndex >= len(entity.mixins) {
+		return ""
+	}
+
+	return entity.mixins[attribute.position.MixinIndex]
+}
+
+// mermaidMixins writes the abstract classes of the mixins used by the entities, in the order they're first used,
+// with the fields of their first entity.
+func mermaidMixins(builder *strings.Builder, model *Model, abbreviations *abbreviator, o *options) {
+	written := make(map[string]bool)
+
+	for _, entity := range model.Entities {
+		for _, name := range entity.mixins {
+			if name == "" || written[name] {
+				continue
+			}
+			written[name] = true
+
+			mixin := &Entity{Name: name, abstract: true}
+			for _, attribute := range entity.Attributes {
+				if mixinName(entity, attribute) == name {
+					mixin.Attributes = append(mixin.Attributes, attribute)
+				}
+			}
+
+			mermaidClass(builder, mixin, abbreviations, o)
+			builder.WriteString("\n")
+		}
+	}
+}
+
+// mermaidGeneralizations returns the generalization arrows from the mixins to the entities using them.
+func mermaidGeneralizations(model *Model) []string {
+	var lines []string
+	for _, entity := range model.Entities {
+		for _, name := range entity.mixins {
+			if name != "" {
+				lines = append(lines, fmt.Sprintf("%s <|-- %s", mermaidClassName(name), mermaidClassName(entity.Name)))
+			}
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"go/ast"
+	"go/parser"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateDiagramMixins(t *testing.T) {
+	err := GenerateDiagram("../examples/mixins/schema", "../examples/mixins/readme.md", Markdown, defaultStartPattern, defaultEndPattern,
+		WithNotation(NotationUML), WithMixins())
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	if !compareFiles("../examples/mixins/readme.md", "../examples/mixins/readme-expected.md") {
+		t.Errorf("Generated file does not match the expected output")
+	}
+}
+
+func TestMixinNames(t *testing.T) {
+	list, err := parser.ParseExpr(`[]ent.Mixin{mixin.Time{}, &AuditMixin{}, mixin.AnnotateFields(mixin.Time{}, entsql.Default("x")), shared}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := mixinNames(list.(*ast.CompositeLit).Elts)
+	if expected := []string{"mixin.Time", "AuditMixin", "mixin.AnnotateFields", ""}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Got the mixins %q, expected %q", names, expected)
+	}
+}
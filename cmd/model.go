@@ -0,0 +1,800 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"entgo.io/ent/entc/gen"
+	"entgo.io/ent/entc/load"
+	"entgo.io/ent/schema/field"
+
+	"github.com/lespea/entmaid/annotation"
+)
+
+// Model is the format agnostic description of the entities and relationships in the schema graph,
+// every diagram format is rendered from it.
+type Model struct {
+	// Header is an optional comment placed at the top of the rendered diagram.
+	Header string `json:"header,omitempty"`
+	// Title and Description describe the diagram, from the annotation of the schema or WithTitle and
+	// WithDescription.
+	Title         string          `json:"title,omitempty"`
+	Description   string          `json:"description,omitempty"`
+	Entities      []*Entity       `json:"entities"`
+	Relationships []*Relationship `json:"relationships"`
+
+	// page and pages number the diagram when the model is one page of a paginated one.
+	page, pages int
+}
+
+// Entity is a table in the diagram, either for an ent schema type or a M2M join table.
+type Entity struct {
+	Name      string `json:"name"`
+	Table     string `json:"table"`
+	JoinTable bool   `json:"joinTable,omitempty"`
+	// View entities are declared with ent.View, they're read-only and never have foreign keys.
+	View bool `json:"view,omitempty"`
+	// Guards are the kinds of runtime guards (policy, hooks, interceptors) of the schema type, set with WithGuards.
+	Guards []string `json:"guards,omitempty"`
+	// Schema is the database schema the table is stored in, the name of the entity is qualified by it.
+	Schema string `json:"schema,omitempty"`
+	// Domain is the group of the entity inferred or set with WithDomains and WithDomain, grouped by over its Schema.
+	Domain     string       `json:"domain,omitempty"`
+	Attributes []*Attribute `json:"attributes"`
+	Indexes    []*Index     `json:"indexes,omitempty"`
+	// Checks are the check constraints of the table, drawn in the Mermaid diagram with WithDetailedTypes.
+	Checks []*Check `json:"checks,omitempty"`
+	// Source is where the schema type is declared, URL links to it when links are enabled.
+	Source *Source `json:"source,omitempty"`
+	URL    string  `json:"url,omitempty"`
+	// Style highlights the entity, from the annotation of its schema type.
+	Style *annotation.Style `json:"style,omitempty"`
+	// DisplayName is the business-friendly name the entity is labeled with, from the annotation of its schema type or
+	// WithDisplayName.
+	DisplayName string `json:"displayName,omitempty"`
+	// Link is the URL of the external page documenting the entity (data catalog, runbook), from the annotation of its
+	// schema type or WithEntityLink.
+	Link string `json:"link,omitempty"`
+
+	// drawnOn is the page a stub of the entity points to, when it's drawn on another page of a paginated diagram.
+	drawnOn int
+	// maxFields lowers the number of attributes of WithMaxFields listed for the entity, for WithSizeBudget.
+	maxFields int
+	// lints are the findings of WithLint about the entity, for WithLintOverlay.
+	lints []Warning
+	// typeName is the name of the schema type, mixins are the names of its mixins with WithMixins.
+	typeName string
+	mixins   []string
+	// abstract entities are the classes of the mixins.
+	abstract bool
+	// edge is the name of the M2M edge of a join table named after it by ent, for WithShortJoinTables.
+	edge string
+	// visibility is the audience of the entity from its annotation, for WithVisibility.
+	visibility annotation.Visibility
+}
+
+// Check is a check constraint declared with the entsql annotation of the schema type, only the unnamed one has no
+// name.
+type Check struct {
+	Name       string `json:"name,omitempty"`
+	Expression string `json:"expression"`
+}
+
+// describe returns the name of the check constraint, if any, followed by its parenthesized expression.
+func (c *Check) describe() string {
+	if c.Name == "" {
+		return "(" + c.Expression + ")"
+	}
+
+	return c.Name + " (" + c.Expression + ")"
+}
+
+// Index is an index declared by the schema over one or more columns of the entity.
+type Index struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique,omitempty"`
+}
+
+// Source is a position in a schema file, relative to the working directory when possible.
+type Source struct {
+	File string `json:"file"`
+	Line int    `json:"line,omitempty"`
+}
+
+// Attribute is a column of an entity.
+type Attribute struct {
+	Name string `json:"name"`
+	// Type is the diagram friendly type of the column, GoType is the type it was derived from.
+	Type   string   `json:"type"`
+	GoType string   `json:"goType,omitempty"`
+	Keys   []string `json:"keys,omitempty"`
+	// Default is the literal default value of the column, or "auto" when it's computed by a function.
+	Default string `json:"default,omitempty"`
+	// Immutable columns are set on creation and can never be updated.
+	Immutable bool `json:"immutable,omitempty"`
+	// Classification is the sensitivity of the data of the column, from the annotation of its field.
+	Classification annotation.Classification `json:"classification,omitempty"`
+	// Fields are the fields of the Go struct held by a JSON column, with WithExpandJSON.
+	Fields []*JSONField `json:"fields,omitempty"`
+	// GlobalID is the first ID of the range of the entity in the global ID space, set on its primary key with
+	// WithGlobalIDs.
+	GlobalID *int `json:"globalId,omitempty"`
+	// Label is the translated name of the column, with WithLocale.
+	Label string `json:"label,omitempty"`
+
+	// position is where the field is declared in the schema, for the source map.
+	position *load.Position
+	// typeInfo is the type of the field, to look up the struct of JSON columns.
+	typeInfo *field.TypeInfo
+	// optional, nillable, enums and comment describe the field further for the OpenAPI components.
+	optional bool
+	nillable bool
+	enums    []string
+	comment  string
+	// visibility is the audience of the field from its annotation, for WithVisibility.
+	visibility annotation.Visibility
+}
+
+// Cardinality is how many entities can be on one end of a relationship.
+type Cardinality string
+
+const (
+	ZeroOrOne  Cardinality = "zero-or-one"
+	ExactlyOne Cardinality = "exactly-one"
+	ZeroOrMore Cardinality = "zero-or-more"
+	OneOrMore  Cardinality = "one-or-more"
+)
+
+// Relationship connects two entities, the cardinalities describe how many of each end can be related.
+type Relationship struct {
+	From            string      `json:"from"`
+	To              string      `json:"to"`
+	FromCardinality Cardinality `json:"fromCardinality"`
+	ToCardinality   Cardinality `json:"toCardinality"`
+	Label           string      `json:"label"`
+	// Edge and Ref are the names of the ent edge and its back-reference (if any) the relationship was built from.
+	Edge string `json:"edge"`
+	Ref  string `json:"ref,omitempty"`
+	// ForeignKey is the column realizing the relationship.
+	ForeignKey *ForeignKey `json:"foreignKey,omitempty"`
+	// Logical relationships are annotated as not enforced by the database, they're drawn dashed.
+	Logical bool `json:"logical,omitempty"`
+	// Weight is how thick the line of the relationship is drawn, 0 for the default width.
+	Weight int `json:"weight,omitempty"`
+
+	// kind is the kind of relationship of the edge, for WithOnlyEdges and WithoutEdges.
+	kind EdgeKind
+}
+
+// ForeignKey is a column of an entity that references the primary key of another entity.
+type ForeignKey struct {
+	Entity    string `json:"entity"`
+	Column    string `json:"column"`
+	RefEntity string `json:"refEntity"`
+	RefColumn string `json:"refColumn"`
+}
+
+// Entity returns the entity with the given name, or nil when there isn't one.
+func (m *Model) Entity(name string) *Entity {
+	for _, entity := range m.Entities {
+		if entity.Name == name {
+			return entity
+		}
+	}
+
+	return nil
+}
+
+// Attribute returns the attribute with the given name, or nil when there isn't one.
+func (e *Entity) Attribute(name string) *Attribute {
+	for _, attribute := range e.Attributes {
+		if attribute.Name == name {
+			return attribute
+		}
+	}
+
+	return nil
+}
+
+// buildModel walks the schema graph and collects the entities and relationships to draw.
+func buildModel(ctx context.Context, graph *gen.Graph, o *options) (*Model, error) {
+	model := &Model{}
+	// describedBy is the schema type whose annotation describes the diagram.
+	var describedBy string
+
+	for _, node := range graph.Nodes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		o.logger.Debug("processing node", "node", node.Name, "fields", len(node.Fields), "edges", len(node.Edges))
+
+		if o.compactEdges && node.IsEdgeSchema() {
+			o.skipEntity(entityName(node), "drawn as the relationships it's the edge schema of with --compact-edge-schemas")
+			continue
+		}
+
+		entity := &Entity{Name: entityName(node), Table: node.Table(), Schema: databaseSchema(node), Source: newSource(node.Pos()), View: node.IsView(), typeName: node.Name}
+		nodeAnnotation := schemaAnnotation(node.Annotations, node.Name, "", o)
+		entity.Style, entity.visibility = nodeAnnotation.Style, nodeAnnotation.Visibility
+		entity.DisplayName, entity.Link = nodeAnnotation.DisplayName, nodeAnnotation.Link
+		if diagram := nodeAnnotation.Diagram; diagram != nil {
+			if describedBy != "" {
+				o.warn(node.Name, "", "the diagram is already described by %s", describedBy)
+			} else {
+				model.Title, model.Description, describedBy = diagram.Title, diagram.Description, node.Name
+			}
+		}
+		if o.guards {
+			entity.Guards = entityGuards(node)
+		}
+		entity.Checks = entityChecks(node)
+
+		// Edge schemas can be identified by several of their edge fields instead of an ID column.
+		compositeID := make(map[string]bool)
+		if node.HasOneFieldID() {
+			// The ID is always named id in the schema, its column can be renamed through StorageKey.
+			id := newAttribute(node.ID, o, "PK")
+			id.Name = idName(node)
+			if o.globalIDs {
+				id.GlobalID = globalIDStart(node)
+			}
+			entity.Attributes = append(entity.Attributes, id)
+		} else if node.HasCompositeID() {
+			for _, field := range node.EdgeSchema.ID {
+				compositeID[field.Name] = true
+			}
+		}
+
+		for _, field := range node.Fields {
+			var attribute *Attribute
+			if compositeID[field.Name] {
+				attribute = newAttribute(field, o, "PK")
+			} else {
+				attribute = newAttribute(field, o)
+			}
+
+			fieldAnnotation := schemaAnnotation(field.Annotations, node.Name, field.Name, o)
+			attribute.Classification, attribute.visibility = fieldAnnotation.Classification, fieldAnnotation.Visibility
+			entity.Attributes = append(entity.Attributes, attribute)
+		}
+
+		if entity.View {
+			o.logger.Debug("skipping the foreign keys and edges of view", "node", node.Name)
+			model.Entities = append(model.Entities, entity)
+			continue
+		}
+
+		for _, foreignKey := range node.ForeignKeys {
+			// Edge fields are already an attribute of the entity, they only need to be marked as FK.
+			if foreignKey.UserDefined {
+				if attribute := entity.Attribute(foreignKey.Field.Name); attribute != nil {
+					attribute.Keys = append(attribute.Keys, "FK")
+					continue
+				}
+			}
+
+			entity.Attributes = append(entity.Attributes, newAttribute(foreignKey.Field, o, "FK"))
+		}
+
+		for _, index := range node.Indexes {
+			entity.Indexes = append(entity.Indexes, &Index{Name: index.Name, Columns: index.Columns, Unique: index.Unique})
+		}
+
+		model.Entities = append(model.Entities, entity)
+
+		for _, edge := range node.Edges {
+			if edge.Type == nil || (edge.M2M() && edge.Rel.Table == "") {
+				return nil, fmt.Errorf("%w: %s.%s is missing its type or join table", ErrUnsupportedEdge, node.Name, edge.Name)
+			}
+
+			// Ent handles M2M relationships in a way that we can't easily generate an accurate ERD with it.
+			// SO we attempt to extract out the actual M2M table to properly display it. The join table of an edge
+			// schema is the table of its own entity.
+			if edge.M2M() && edge.Through == nil {
+				// We need to map the relationship between both base tables, but only create the table once.
+				if !edge.IsInverse() {
+					rel := edge.Rel
+					o.logger.Debug("adding join table", "node", node.Name, "edge", edge.Name, "table", rel.Table)
+
+					if !node.HasOneFieldID() || !edge.Type.HasOneFieldID() {
+						o.warn(node.Name, edge.Name, "columns of the join table %s are shown as int", rel.Table)
+					}
+
+					joinTable := &Entity{Name: joinTableName(node, edge), Table: rel.Table, Schema: joinTableSchema(node, edge), JoinTable: true}
+					if rel.Table == node.Label()+"_"+edge.Name {
+						joinTable.edge = edge.Name
+					}
+					// The first column references the owner of the edge and the second one its type.
+					for i, column := range rel.Columns {
+						joinTable.Attributes = append(joinTable.Attributes, joinTableColumn(column, []*gen.Type{node, edge.Type}[min(i, 1)], o))
+					}
+
+					model.Entities = append(model.Entities, joinTable)
+				}
+			}
+		}
+	}
+
+	model.Entities = joinTablesLast(model.Entities)
+
+	// The relationships are drawn from their assoc edges, the inverse edges are only drawn when their assoc edge
+	// wasn't reached (e.g. its node isn't part of the graph).
+	drawn := make(map[*gen.Edge]bool)
+	var inverses []struct {
+		node *gen.Type
+		edge *gen.Edge
+	}
+
+	for _, node := range graph.Nodes {
+		if node.IsView() || (o.compactEdges && node.IsEdgeSchema()) {
+			continue
+		}
+
+		for _, edge := range node.Edges {
+			// The M2M edges through an edge schema are drawn by the edges of the edge schema to both entities,
+			// or directly from their assoc edge when compact.
+			if o.compactEdges && edge.Type.IsEdgeSchema() {
+				continue
+			}
+			if edge.M2M() && edge.Through != nil {
+				if o.compactEdges && !edge.IsInverse() {
+					o.logger.Debug("processing edge", "node", node.Name, "edge", edge.Name, "through", edge.Through.Name)
+					rel := newEdgeSchemaRelationship(node, edge)
+					uniqueEdgeSchema(rel, node, edge, o)
+					overrideRelationship(rel, node, edge, o)
+					model.Relationships = append(model.Relationships, rel)
+				}
+				continue
+			}
+
+			// Need to handle M2M relationships a bit more special.
+			if edge.M2M() {
+				o.logger.Debug("processing edge", "node", node.Name, "edge", edge.Name, "via", edge.Rel.Table)
+				rel := newJoinTableRelationship(node, edge)
+				overrideRelationship(rel, node, edge, o)
+				model.Relationships = append(model.Relationships, rel)
+				continue
+			}
+
+			if edge.IsInverse() {
+				o.logger.Debug("deferring inverse edge drawn from its assoc edge", "node", node.Name, "edge", edge.Name)
+				inverses = append(inverses, struct {
+					node *gen.Type
+					edge *gen.Edge
+				}{node, edge})
+				continue
+			}
+
+			o.logger.Debug("processing edge", "node", node.Name, "edge", edge.Name, "to", edge.Type.Name)
+			rel := newRelationship(node, edge)
+			uniqueForeignKey(rel, node, edge, o)
+			overrideRelationship(rel, node, edge, o)
+			model.Relationships = append(model.Relationships, rel)
+			drawn[edge] = true
+		}
+	}
+
+	for _, inverse := range inverses {
+		// The assoc edge is declared on the type the inverse edge points to, the relationship is drawn from it as
+		// it would have been. Without one it's drawn from the inverse edge itself.
+		node, edge := inverse.edge.Type, inverse.edge.Ref
+		if edge == nil {
+			node, edge = inverse.node, inverse.edge
+		}
+		if drawn[edge] {
+			continue
+		}
+
+		o.logger.Debug("processing inverse edge without a drawn assoc edge", "node", inverse.node.Name, "edge", inverse.edge.Name)
+		rel := newRelationship(node, edge)
+		uniqueForeignKey(rel, node, edge, o)
+		overrideRelationship(rel, node, edge, o)
+		model.Relationships = append(model.Relationships, rel)
+		drawn[edge] = true
+	}
+
+	return model, nil
+}
+
+// mergeModels combines the models built from several schema directories into one, warning about the entities
+// declared by more than one of them.
+func mergeModels(schemaPaths []string, models []*Model, o *options) *Model {
+	merged := &Model{}
+
+	declaredBy := make(map[string]string)
+	for i, model := range models {
+		for _, entity := range model.Entities {
+			if other, ok := declaredBy[entity.Name]; ok {
+				if other == schemaPaths[i] {
+					o.warn(entity.Name, "", "collides with another entity of the same name")
+				} else {
+					o.warn(entity.Name, "", "is declared by both the schemas at %s and %s", other, schemaPaths[i])
+				}
+			}
+			declaredBy[entity.Name] = schemaPaths[i]
+		}
+
+		merged.Entities = append(merged.Entities, model.Entities...)
+		merged.Relationships = append(merged.Relationships, model.Relationships...)
+		if merged.Title == "" && merged.Description == "" {
+			merged.Title, merged.Description = model.Title, model.Description
+		}
+	}
+
+	if o.domainSource != DomainNone || len(o.domains) > 0 {
+		assignDomains(merged, o)
+	}
+
+	sortEntities(merged, o.sort)
+	sortAttributes(merged, o.fieldOrder)
+	merged.Entities = joinTablesLast(merged.Entities)
+
+	if o.groupBySchema {
+		sort.SliceStable(merged.Entities, func(i, j int) bool {
+			return merged.Entities[i].group() < merged.Entities[j].group()
+		})
+	}
+
+	return merged
+}
+
+// joinTablesLast moves the join tables after the other entities, sorted by name, instead of following the entity
+// declaring their edge so adding an edge doesn't shuffle the diagram.
+func joinTablesLast(entities []*Entity) []*Entity {
+	var joinTables []*Entity
+	sorted := make([]*Entity, 0, len(entities))
+	for _, entity := range entities {
+		if entity.JoinTable {
+			joinTables = append(joinTables, entity)
+		} else {
+			sorted = append(sorted, entity)
+		}
+	}
+
+	sort.SliceStable(joinTables, func(i, j int) bool {
+		return joinTables[i].Name < joinTables[j].Name
+	})
+
+	return append(sorted, joinTables...)
+}
+
+// WithGroupBySchema orders the entities by the database schema they're stored in, or by their domain with
+// WithDomains, with a comment starting each group in the Mermaid diagram.
+func WithGroupBySchema() Option {
+	return func(o *options) {
+		o.groupBySchema = true
+	}
+}
+
+// newSource parses the filename:line position entc records for the schema types.
+func newSource(pos string) *Source {
+	if pos == "" {
+		return nil
+	}
+
+	source := &Source{File: pos}
+	if i := strings.LastIndex(pos, ":"); i != -1 {
+		if line, err := strconv.Atoi(pos[i+1:]); err == nil {
+			source.File, source.Line = pos[:i], line
+		}
+	}
+
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, source.File); err == nil {
+			source.File = rel
+		}
+	}
+	source.File = filepath.ToSlash(source.File)
+
+	return source
+}
+
+func newAttribute(f *gen.Field, o *options, keys ...string) *Attribute {
+	goType := f.Type.String()
+
+	return &Attribute{
+		Name: f.Name, Type: attributeType(f, o), GoType: goType, Keys: keys, Default: formatDefault(f), Immutable: f.Immutable,
+		position: f.Position, typeInfo: f.Type, optional: f.Optional, nillable: f.Nillable, enums: f.EnumValues(), comment: f.Comment(),
+	}
+}
+
+// formatDefault returns the default value of the field as it should be shown, empty when it doesn't have one.
+func formatDefault(f *gen.Field) string {
+	if !f.Default {
+		return ""
+	}
+
+	value := f.DefaultValue()
+	if f.DefaultFunc() || value == nil {
+		return "auto"
+	}
+
+	switch value := value.(type) {
+	case string:
+		return value
+	case bool, float64, int64, uint64:
+		return fmt.Sprint(value)
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return "auto"
+		}
+
+		return string(encoded)
+	}
+}
+
+// newRelationship describes a non M2M edge, which is realized by a foreign key on one of the two entities.
+func newRelationship(node *gen.Type, edge *gen.Edge) *Relationship {
+	from, to := getEdgeCardinalities(edge)
+
+	rel := &Relationship{
+		From:            entityName(node),
+		To:              entityName(edge.Type),
+		FromCardinality: from,
+		ToCardinality:   to,
+		Label:           edge.Name + getEdgeRefName(edge.Ref),
+		Edge:            edge.Name,
+		kind:            edgeKind(node, edge),
+	}
+
+	if edge.Ref != nil {
+		rel.Ref = edge.Ref.Name
+	}
+
+	if len(edge.Rel.Columns) > 0 {
+		holder, referenced := edge.Type, node
+		if edge.OwnFK() {
+			holder, referenced = node, edge.Type
+		}
+
+		rel.ForeignKey = &ForeignKey{Entity: entityName(holder), Column: edge.Rel.Column(), RefEntity: entityName(referenced), RefColumn: idName(referenced)}
+	}
+
+	return rel
+}
+
+// overrideRelationship applies the relationship annotations of the edge and of its back-reference, which declares
+// the cardinalities from the other side. The annotation of the edge takes precedence.
+func overrideRelationship(rel *Relationship, node *gen.Type, edge *gen.Edge, o *options) {
+	var from, to annotation.Cardinality
+	var logical bool
+	var weight int
+
+	if edge.Ref != nil && edge.Ref != edge {
+		if ref := schemaAnnotation(edge.Ref.Annotations, edge.Type.Name, edge.Ref.Name, o).Relationship; ref != nil {
+			from, to, logical, weight = ref.To, ref.From, ref.Logical, ref.Weight
+		}
+	}
+
+	if own := schemaAnnotation(edge.Annotations, node.Name, edge.Name, o).Relationship; own != nil {
+		if own.From != "" {
+			from = own.From
+		}
+		if own.To != "" {
+			to = own.To
+		}
+		logical = logical || own.Logical
+		if own.Weight != 0 {
+			weight = own.Weight
+		}
+	}
+
+	rel.Logical = logical
+	if weight < 0 {
+		o.warn(node.Name, edge.Name, "the weight %d of the relationship isn't positive", weight)
+	} else {
+		rel.Weight = weight
+	}
+
+	if from == "" && to == "" {
+		return
+	}
+
+	// The relationships of M2M edges are to the join table: only how many rows of it an entity has follows the
+	// cardinality of the entity the edge points to, the other side is drawn by the relationship of the back-reference.
+	if edge.M2M() && edge.Through == nil {
+		if from != "" && (edge.Ref == nil || edge.Ref == edge) {
+			o.warn(node.Name, edge.Name, "the cardinality from the other side of a M2M edge can't be drawn without a back-reference")
+		}
+		from = ""
+	}
+
+	for _, override := range []struct {
+		cardinality annotation.Cardinality
+		target      *Cardinality
+	}{{from, &rel.FromCardinality}, {to, &rel.ToCardinality}} {
+		switch cardinality := Cardinality(override.cardinality); cardinality {
+		case "":
+		case ZeroOrOne, ExactlyOne, ZeroOrMore, OneOrMore:
+			*override.target = cardinality
+		default:
+			o.warn(node.Name, edge.Name, "unknown cardinality %q", cardinality)
+		}
+	}
+}
+
+// newJoinTableRelationship describes one side of a M2M edge, connecting the node to the join table.
+func newJoinTableRelationship(node *gen.Type, edge *gen.Edge) *Relationship {
+	rel := &Relationship{
+		From:            entityName(node),
+		To:              joinTableName(node, edge),
+		FromCardinality: ZeroOrOne,
+		ToCardinality:   ZeroOrMore,
+		Label:           edge.Name + getEdgeRefName(edge.Ref),
+		Edge:            edge.Name,
+		kind:            EdgeM2M,
+	}
+
+	if edge.Ref != nil {
+		rel.Ref = edge.Ref.Name
+	}
+
+	// The join table columns are ordered (owner, reference), so the inverse side references the second one.
+	if column := len(edge.Rel.Columns) - 1; column >= 0 {
+		if !edge.IsInverse() {
+			column = 0
+		}
+
+		rel.ForeignKey = &ForeignKey{Entity: joinTableName(node, edge), Column: edge.Rel.Columns[column], RefEntity: entityName(node), RefColumn: idName(node)}
+	}
+
+	return rel
+}
+
+// schemaAnnotation decodes the entmaid annotation from the annotations of a schema type, field or edge, warning
+// about annotations that can't be decoded.
+func schemaAnnotation(annotations gen.Annotations, entity string, element string, o *options) annotation.Annotation {
+	var decoded annotation.Annotation
+
+	raw, ok := annotations[annotation.Name]
+	if !ok {
+		return decoded
+	}
+
+	encoded, err := json.Marshal(raw)
+	if err == nil {
+		err = json.Unmarshal(encoded, &decoded)
+	}
+	if err != nil {
+		o.warn(entity, element, "invalid %s annotation: %v", annotation.Name, err)
+	}
+
+	return decoded
+}
+
+// databaseSchema returns the database schema the node is stored in through the entsql.Schema annotation, if any.
+func databaseSchema(node *gen.Type) string {
+	if ant := node.EntSQL(); ant != nil {
+		return ant.Schema
+	}
+
+	return ""
+}
+
+// entityChecks returns the check constraints of the entsql annotation of the node, the unnamed one first and the
+// named ones by name.
+func entityChecks(node *gen.Type) []*Check {
+	ant := node.EntSQL()
+	if ant == nil {
+		return nil
+	}
+
+	var checks []*Check
+	if ant.Check != "" {
+		checks = append(checks, &Check{Expression: ant.Check})
+	}
+	for _, name := range slices.Sorted(maps.Keys(ant.Checks)) {
+		checks = append(checks, &Check{Name: name, Expression: ant.Checks[name]})
+	}
+
+	return checks
+}
+
+// entityName returns the name of the node's entity, qualified by its database schema so entities stored in different
+// schemas can't collide.
+func entityName(node *gen.Type) string {
+	return qualifiedName(databaseSchema(node), node.Name)
+}
+
+// joinTableSchema returns the database schema of the M2M edge's join table, which is annotated on the assoc edge or
+// otherwise the same as the schema of the node owning the edge.
+func joinTableSchema(node *gen.Type, edge *gen.Edge) string {
+	owner := node
+	if edge.IsInverse() && edge.Ref != nil {
+		owner, edge = edge.Type, edge.Ref
+	}
+
+	if ant := edge.EntSQL(); ant != nil && ant.Schema != "" {
+		return ant.Schema
+	}
+
+	return databaseSchema(owner)
+}
+
+func joinTableName(node *gen.Type, edge *gen.Edge) string {
+	return qualifiedName(joinTableSchema(node, edge), edge.Rel.Table)
+}
+
+func qualifiedName(schema string, name string) string {
+	if schema == "" {
+		return name
+	}
+
+	return schema + "." + name
+}
+
+// idName returns the column of the single ID of the node.
+func idName(node *gen.Type) string {
+	if node.HasOneFieldID() {
+		return node.ID.StorageKey()
+	}
+
+	return "id"
+}
+
+// joinTableColumn is the column of a M2M join table referencing the node, typed like its ID. The columns referencing a
+// node without a single ID are shown as int.
+func joinTableColumn(column string, node *gen.Type, o *options) *Attribute {
+	if !node.HasOneFieldID() {
+		return &Attribute{Name: column, Type: "int", Keys: []string{"PK", "FK"}}
+	}
+
+	attribute := newAttribute(node.ID, o, "PK", "FK")
+	attribute.Name, attribute.Default, attribute.Immutable, attribute.position, attribute.comment = column, "", false, nil, ""
+
+	return attribute
+}
+
+// getEdgeCardinalities returns how many of the edge owner and the edge type can be related. Each side is derived
+// from the edge pointing to it, so the Unique and Required of both the edge and its back-reference are kept apart.
+func getEdgeCardinalities(edge *gen.Edge) (Cardinality, Cardinality) {
+	if edge.M2M() {
+		return ZeroOrMore, ZeroOrMore
+	}
+
+	to := edgeCardinality(edge)
+	if edge.Ref != nil && edge.Ref != edge {
+		return edgeCardinality(edge.Ref), to
+	}
+
+	// Without a back-reference the owner side can only be derived from the relation type.
+	if edge.M2O() {
+		return ZeroOrMore, to
+	}
+
+	return ZeroOrOne, to
+}
+
+// edgeCardinality returns how many entities the edge points to: a unique edge at most one and a non-unique edge any
+// number of them, required edges at least one.
+func edgeCardinality(edge *gen.Edge) Cardinality {
+	switch {
+	case edge.Unique && !edge.Optional:
+		return ExactlyOne
+	case edge.Unique:
+		return ZeroOrOne
+	case !edge.Optional:
+		return OneOrMore
+	default:
+		return ZeroOrMore
+	}
+}
+
+func getEdgeRefName(ref *gen.Edge) string {
+	if ref == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("-%s", ref.Name)
+}
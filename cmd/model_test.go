@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"entgo.io/ent/entc/gen"
+
+	"github.com/lespea/entmaid/annotation"
+)
+
+func TestGenerateDiagramGroupBySchema(t *testing.T) {
+	dir := t.TempDir()
+	mermaidPath := filepath.Join(dir, "erd.mmd")
+	dbmlPath := filepath.Join(dir, "erd.dbml")
+
+	err := GenerateDiagram("../examples/multischema/schema", "", Plain, "", "",
+		WithGroupBySchema(), WithOutput(FormatMermaid, mermaidPath), WithOutput(FormatDBML, dbmlPath))
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	mermaid, err := os.ReadFile(mermaidPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth, billing := strings.Index(string(mermaid), " %% schema auth\n"), strings.Index(string(mermaid), " %% schema billing\n")
+	if auth == -1 || billing == -1 || auth > billing {
+		t.Errorf("Expected the auth schema to be grouped before the billing one:\n%s", mermaid)
+	}
+
+	dbml, err := os.ReadFile(dbmlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"Table auth.User {\n", "Ref: billing.Invoice.user_invoices > auth.User.id\n"} {
+		if !strings.Contains(string(dbml), expected) {
+			t.Errorf("DBML output is missing %q:\n%s", expected, dbml)
+		}
+	}
+}
+
+func TestGenerateDiagramMergedSchemas(t *testing.T) {
+	jsonPath := filepath.Join(t.TempDir(), "erd.json")
+
+	var warnings []Warning
+	err := GenerateDiagram("../examples/m2m2types/schema", "", Plain, "", "",
+		WithSchema("../examples/edgefield/schema"), WithOutput(FormatJSON, jsonPath), WithWarningHandler(func(w Warning) {
+			warnings = append(warnings, w)
+		}))
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	content, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{`"name": "Group"`, `"name": "Post"`} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Merged diagram is missing %s:\n%s", expected, content)
+		}
+	}
+
+	var collision bool
+	for _, w := range warnings {
+		if w.Entity == "User" && strings.Contains(w.Message, "declared by both the schemas at ../examples/m2m2types/schema and ../examples/edgefield/schema") {
+			collision = true
+		}
+	}
+
+	if !collision {
+		t.Errorf("Expected a warning about User being declared by both schemas, got %v", warnings)
+	}
+}
+
+func TestGenerateDiagramViews(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "erd.json")
+	dbmlPath := filepath.Join(dir, "erd.dbml")
+
+	err := GenerateDiagram("../examples/views/schema", "", Plain, "", "",
+		WithOutput(FormatJSON, jsonPath), WithOutput(FormatDBML, dbmlPath))
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	model, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(model), `"view": true`) {
+		t.Errorf("Expected ActiveUser to be marked as a view:\n%s", model)
+	}
+
+	dbml, err := os.ReadFile(dbmlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(dbml), "Table ActiveUser {\n  id int\n  name string\n\n  Note: 'View'\n}") {
+		t.Errorf("Expected the view without keys in the DBML output:\n%s", dbml)
+	}
+}
+
+func TestGenerateDiagramStyles(t *testing.T) {
+	dbmlPath := filepath.Join(t.TempDir(), "erd.dbml")
+
+	if err := GenerateDiagram("../examples/annotations/schema", "", Plain, "", "", WithOutput(FormatDBML, dbmlPath)); err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	dbml, err := os.ReadFile(dbmlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(dbml), "Table Customer [headercolor: #fdd] {\n") || !strings.Contains(string(dbml), "Table Order {\n") {
+		t.Errorf("Expected only the Customer header to be colored:\n%s", dbml)
+	}
+}
+
+func TestOverrideRelationship(t *testing.T) {
+	user, car := &gen.Type{Name: "User"}, &gen.Type{Name: "Car"}
+	owner := &gen.Edge{Name: "owner", Type: user, Annotations: gen.Annotations{annotation.Name: annotation.Cardinalities(annotation.OneOrMore, "")}}
+	cars := &gen.Edge{Name: "cars", Type: car, Ref: owner, Annotations: gen.Annotations{annotation.Name: annotation.Logical()}}
+
+	o := newOptions([]Option{WithQuiet()})
+	rel := &Relationship{FromCardinality: ZeroOrOne, ToCardinality: ZeroOrMore}
+	overrideRelationship(rel, user, cars, o)
+
+	// The cardinalities of the back-reference are declared from the other side.
+	if rel.FromCardinality != ZeroOrOne || rel.ToCardinality != OneOrMore || !rel.Logical {
+		t.Errorf("Expected a logical zero-or-one to one-or-more relationship, got %+v", rel)
+	}
+
+	cars.Annotations = gen.Annotations{annotation.Name: annotation.Cardinalities("many", "")}
+	overrideRelationship(rel, user, cars, o)
+
+	if len(o.warnings) != 1 || o.warnings[0].Message != `unknown cardinality "many"` {
+		t.Errorf("Expected a warning about the unknown cardinality, got %v", o.warnings)
+	}
+}
+
+func TestGetEdgeCardinalities(t *testing.T) {
+	tests := []struct {
+		name           string
+		rel            gen.Rel
+		unique         bool
+		required       bool
+		refUnique      bool
+		refRequired    bool
+		expectedFrom   Cardinality
+		expectedTo     Cardinality
+		expectedSymbol string
+	}{
+		{"O2M", gen.O2M, false, false, true, false, ZeroOrOne, ZeroOrMore, "|o--o{"},
+		{"O2M required", gen.O2M, false, true, true, false, ZeroOrOne, OneOrMore, "|o--|{"},
+		{"O2M required back-reference", gen.O2M, false, false, true, true, ExactlyOne, ZeroOrMore, "||--o{"},
+		{"O2M required both", gen.O2M, false, true, true, true, ExactlyOne, OneOrMore, "||--|{"},
+		{"M2O", gen.M2O, true, false, false, false, ZeroOrMore, ZeroOrOne, "}o--o|"},
+		{"M2O required", gen.M2O, true, true, false, false, ZeroOrMore, ExactlyOne, "}o--||"},
+		{"M2O required back-reference", gen.M2O, true, false, false, true, OneOrMore, ZeroOrOne, "}|--o|"},
+		{"M2O required both", gen.M2O, true, true, false, true, OneOrMore, ExactlyOne, "}|--||"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edge := &gen.Edge{Name: "edge", Unique: tt.unique, Optional: !tt.required, Rel: gen.Relation{Type: tt.rel}}
+			edge.Ref = &gen.Edge{Name: "ref", Unique: tt.refUnique, Optional: !tt.refRequired, Ref: edge}
+
+			from, to := getEdgeCardinalities(edge)
+			if from != tt.expectedFrom || to != tt.expectedTo {
+				t.Errorf("Expected %s to %s, got %s to %s", tt.expectedFrom, tt.expectedTo, from, to)
+			}
+
+			if symbol := getMermaidRelationship(&Relationship{FromCardinality: from, ToCardinality: to}); symbol != tt.expectedSymbol {
+				t.Errorf("Expected %s, got %s", tt.expectedSymbol, symbol)
+			}
+		})
+	}
+}
+
+func TestGetEdgeCardinalitiesWithoutBackReference(t *testing.T) {
+	from, to := getEdgeCardinalities(&gen.Edge{Name: "owner", Unique: true, Rel: gen.Relation{Type: gen.M2O}})
+	if from != ZeroOrMore || to != ExactlyOne {
+		t.Errorf("Expected zero-or-more to exactly-one, got %s to %s", from, to)
+	}
+
+	from, to = getEdgeCardinalities(&gen.Edge{Name: "spouse", Unique: true, Optional: true, Rel: gen.Relation{Type: gen.O2O}})
+	if from != ZeroOrOne || to != ZeroOrOne {
+		t.Errorf("Expected zero-or-one to zero-or-one, got %s to %s", from, to)
+	}
+}
+
+func TestBuildModelInverseEdgeOnly(t *testing.T) {
+	o := newOptions([]Option{WithQuiet()})
+
+	graph, err := loadGraph(context.Background(), "../examples/start/schema", o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without the User node, the cars edge is never reached and only the inverse owner edge of the Car is.
+	var nodes []*gen.Type
+	for _, node := range graph.Nodes {
+		if node.Name != "User" {
+			nodes = append(nodes, node)
+		}
+	}
+	graph.Nodes = nodes
+
+	model, err := buildModel(context.Background(), graph, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var drawn []string
+	for _, rel := range model.Relationships {
+		if rel.To == "Car" {
+			drawn = append(drawn, rel.From+" "+getMermaidRelationship(rel)+" "+rel.To+" : "+rel.Label)
+		}
+	}
+
+	if len(drawn) != 1 || drawn[0] != "User |o--o{ Car : cars-owner" {
+		t.Errorf("Expected the relationship of the inverse edge to be drawn once from its assoc edge, got %v", drawn)
+	}
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import "fmt"
+
+// WithModelHook runs the hook on the model of the diagram once it's built, filtered and labeled, before its
+// redaction, analysis and rendering in every format. The hook can rename, filter or annotate the entities and
+// relationships of the model in place, for the customizations without an option. The hooks run in the order they're
+// given, and generating stops at the first one failing.
+func WithModelHook(hook func(*Model) error) Option {
+	return func(o *options) {
+		o.modelHooks = append(o.modelHooks, hook)
+	}
+}
+
+// runModelHooks runs the hooks of WithModelHook on the model.
+func runModelHooks(model *Model, o *options) error {
+	for i, hook := range o.modelHooks {
+		o.logger.Debug("running the model hook", "hook", i+1)
+
+		if err := hook(model); err != nil {
+			return fmt.Errorf("%w %d: %w", ErrModelHook, i+1, err)
+		}
+	}
+
+	return nil
+}
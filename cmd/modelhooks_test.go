@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGenerateDiagramModelHook(t *testing.T) {
+	mermaidPath := filepath.Join(t.TempDir(), "erd.mmd")
+
+	var order []string
+	err := GenerateDiagram("../examples/start/schema", "", Plain, "", "",
+		WithModelHook(func(model *Model) error {
+			order = append(order, "first")
+			model.Entity("User").DisplayName = "Account"
+			model.Entities = slices.DeleteFunc(model.Entities, func(entity *Entity) bool { return entity.Name == "Car" })
+			model.Relationships = slices.DeleteFunc(model.Relationships, func(rel *Relationship) bool { return rel.To == "Car" })
+			return nil
+		}),
+		WithModelHook(func(model *Model) error {
+			order = append(order, "second")
+			if model.Entity("Car") != nil {
+				t.Error("Expected the second hook to see the model of the first one")
+			}
+			return nil
+		}),
+		WithOutput(FormatMermaid, mermaidPath))
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	content, err := os.ReadFile(mermaidPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(content), ` User["Account"] {`) || strings.Contains(string(content), "Car") {
+		t.Errorf("Expected the diagram of the hooked model:\n%s", content)
+	}
+	if !slices.Equal(order, []string{"first", "second"}) {
+		t.Errorf("Expected the hooks to run in order, got %v", order)
+	}
+}
+
+func TestInspectModelHookError(t *testing.T) {
+	failure := errors.New("no owner")
+	_, err := Inspect("../examples/start/schema", WithModelHook(func(*Model) error { return failure }))
+	if !errors.Is(err, ErrModelHook) || !errors.Is(err, failure) {
+		t.Errorf("Expected the error of the hook, got %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/thediveo/enumflag/v2"
+)
+
+// Notation is how the Mermaid diagram draws the cardinalities of the relationships.
+type Notation enumflag.Flag
+
+const (
+	// NotationCrowsFoot is the default, drawing an erDiagram with crow's foot ends.
+	NotationCrowsFoot Notation = iota
+	// NotationUML draws a classDiagram with the UML multiplicities (0..1, 1, 0..*, 1..*) at the ends of the
+	// associations.
+	NotationUML
+)
+
+var NotationIds = map[Notation][]string{
+	NotationCrowsFoot: {"crowsfoot"},
+	NotationUML:       {"uml"},
+}
+
+// WithNotation draws the Mermaid diagram with the notation. The other formats keep theirs, DBML only has its own
+// reference operators. The erDiagram grammar checked by WithValidate doesn't apply to the UML notation.
+func WithNotation(notation Notation) Option {
+	return func(o *options) {
+		o.notation = notation
+	}
+}
+
+// umlMultiplicities are the UML multiplicities of the cardinalities.
+var umlMultiplicities = map[Cardinality]string{
+	ZeroOrOne:  "0..1",
+	ExactlyOne: "1",
+	ZeroOrMore: "0..*",
+	OneOrMore:  "1..*",
+}
+
+var (
+	// mermaidClassNameRegex matches the class names the classDiagram accepts, which can't be quoted.
+	mermaidClassNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// mermaidInvalidClassNameRegex matches the characters that can't be part of a class name.
+	mermaidInvalidClassNameRegex = regexp.MustCompile(`[^A-Za-z0-9_]`)
+	// umlMemberEscaper replaces the parentheses, which turn an attribute into a method, and the braces, which close
+	// the class early, with brackets.
+	umlMemberEscaper = strings.NewReplacer("(", "[", ")", "]", "{", "[", "}", "]")
+)
+
+// mermaidClassKeywords are the words of the classDiagram grammar, which break the parsing when used as a class name.
+var mermaidClassKeywords = map[string]bool{
+	"classdiagram": true, "class": true, "namespace": true, "note": true, "style": true, "classdef": true,
+	"direction": true, "link": true, "click": true, "callback": true, "call": true, "href": true, "cssclass": true,
+}
+
+// mermaidClassName replaces the characters that can't be part of a class name with underscores.
+func mermaidClassName(name string) string {
+	if mermaidClassNameRegex.MatchString(name) && !mermaidClassKeywords[strings.ToLower(name)] {
+		return name
+	}
+
+	if name = mermaidInvalidClassNameRegex.ReplaceAllString(name, "_"); !mermaidClassNameRegex.MatchString(name) ||
+		mermaidClassKeywords[strings.ToLower(name)] {
+		name = "_" + name
+	}
+
+	return name
+}
+
+// mermaidClass writes the entity as a class of the classDiagram, its views, guards and pages being the annotations of
+// the class.
+func mermaidClass(builder *strings.Builder, entity *Entity, abbreviations *abbreviator, o *options) {
+	name := mermaidClassName(entity.Name)
+	if name != entity.Name {
+		builder.WriteString(fmt.Sprintf(" %%%% %s is %s\n", name, mermaidLine(entity.Name)))
+	}
+
+	// The label of the class is declared on its own, Mermaid merges it with the class declaring the members.
+	if entity.DisplayName != "" {
+		if o.mermaidVersion == Mermaid10 {
+			builder.WriteString(fmt.Sprintf(" %%%% %s is displayed as %s\n", name, mermaidLine(entity.DisplayName)))
+		} else {
+			builder.WriteString(fmt.Sprintf(" class %s[\"%s\"]\n", name, mermaidComment(entity.DisplayName)))
+		}
+	}
+	builder.WriteString(fmt.Sprintf(" class %s {\n", name))
+
+	if entity.abstract {
+		builder.WriteString("  <<abstract>>\n")
+	}
+	if entity.View {
+		builder.WriteString("  <<view>>\n")
+	}
+	if len(entity.Guards) > 0 {
+		builder.WriteString(fmt.Sprintf("  <<%s>>\n", strings.Join(entity.Guards, ",")))
+	}
+	if entity.drawnOn > 0 {
+		builder.WriteString(fmt.Sprintf("  <<see diagram %d>>\n", entity.drawnOn))
+	}
+
+	var attributes []*Attribute
+	for _, attribute := range entity.Attributes {
+		// The fields of the mixins are drawn in their classes with WithMixins.
+		if !hiddenAttribute(attribute, o) && (!o.mixins || mixinName(entity, attribute) == "") {
+			attributes = append(attributes, attribute)
+		}
+	}
+
+	attributes, more := truncateAttributes(entity, attributes, o)
+	for _, attribute := range attributes {
+		member := abbreviations.typeName(attribute.Type) + " " + abbreviations.attributeName(attribute.Name)
+		if len(attribute.Keys) > 0 {
+			member += " " + strings.Join(attribute.Keys, ",")
+		}
+		if comment := attributeComment(attribute, o); comment != "" {
+			member += " [" + comment + "]"
+		}
+
+		builder.WriteString("  " + umlMemberEscaper.Replace(mermaidLine(member)) + "\n")
+	}
+
+	if more > 0 {
+		builder.WriteString("  " + moreAttributes(more) + "\n")
+	}
+
+	builder.WriteString(" }\n")
+}
+
+// WithArrows draws the associations of the UML notation as arrows from the entity holding the foreign key to the one
+// it references, as the lines of the erDiagram don't tell which side holds it.
+func WithArrows() Option {
+	return func(o *options) {
+		o.arrows = true
+	}
+}
+
+// mermaidAssociation returns the association of the classDiagram with the UML multiplicities at its ends, a dashed
+// link for logical relationships. With WithArrows, it's drawn from the holder of the foreign key.
+func mermaidAssociation(rel *Relationship, o *options) string {
+	from, fromCardinality, to, toCardinality := rel.From, rel.FromCardinality, rel.To, rel.ToCardinality
+
+	line := "--"
+	if rel.Logical {
+		line = ".."
+	}
+
+	if o.arrows && rel.ForeignKey != nil {
+		line += ">"
+		if rel.ForeignKey.Entity != rel.From {
+			from, fromCardinality, to, toCardinality = to, toCardinality, from, fromCardinality
+		}
+	}
+
+	return fmt.Sprintf("%s \"%s\" %s \"%s\" %s : %s", mermaidClassName(from), umlMultiplicities[fromCardinality], line,
+		umlMultiplicities[toCardinality], mermaidClassName(to), mermaidLine(relationshipLabel(rel, o)))
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRenderMermaidUML(t *testing.T) {
+	model := &Model{
+		Entities: []*Entity{
+			{Name: "User", Attributes: []*Attribute{{Name: "id", Type: "int", Keys: []string{"PK"}}, {Name: "created", Type: "timestamp", Default: "now()"}}},
+			{Name: "ActiveUser", View: true, Guards: []string{"policy"}},
+			{Name: "Order Item"},
+		},
+		Relationships: []*Relationship{
+			{From: "User", To: "Order Item", FromCardinality: ExactlyOne, ToCardinality: OneOrMore, Label: "items-owner"},
+			{From: "User", To: "ActiveUser", FromCardinality: ZeroOrOne, ToCardinality: ZeroOrMore, Label: "active", Logical: true},
+		},
+	}
+
+	var builder strings.Builder
+	if err := renderMermaid(context.Background(), &builder, model, newOptions([]Option{WithNotation(NotationUML), WithValidate()})); err != nil {
+		t.Fatal(err)
+	}
+
+	diagram := builder.String()
+	if !strings.HasPrefix(diagram, "classDiagram\n") {
+		t.Errorf("Expected a class diagram, got:\n%s", diagram)
+	}
+
+	for _, expected := range []string{
+		" class User {\n  int id PK\n  timestamp created [default: now[]]\n }\n",
+		" class ActiveUser {\n  <<view>>\n  <<policy>>\n }\n",
+		" %% Order_Item is Order Item\n class Order_Item {\n",
+		` User "1" -- "1..*" Order_Item : items-owner` + "\n",
+		` User "0..1" .. "0..*" ActiveUser : active` + "\n",
+	} {
+		if !strings.Contains(diagram, expected) {
+			t.Errorf("The diagram is missing %q:\n%s", expected, diagram)
+		}
+	}
+}
+
+func TestMermaidClassName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"User":        "User",
+		"group_users": "group_users",
+		"Order Item":  "Order_Item",
+		"2fa":         "_2fa",
+		"class":       "_class",
+	} {
+		if got := mermaidClassName(name); got != expected {
+			t.Errorf("mermaidClassName(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
+
+func TestLegendUML(t *testing.T) {
+	model := &Model{
+		Entities:      []*Entity{{Name: "User"}, {Name: "Car", View: true}},
+		Relationships: []*Relationship{{From: "User", To: "Car", FromCardinality: ZeroOrOne, ToCardinality: ZeroOrMore}},
+	}
+
+	legend := renderLegend(model, Plain, newOptions([]Option{WithNotation(NotationUML)}))
+	for _, expected := range []string{"0..1  zero or one", "0..*  zero or more", "<<view>>  read-only view"} {
+		if !strings.Contains(legend, expected) {
+			t.Errorf("The legend is missing %q:\n%s", expected, legend)
+		}
+	}
+}
+
+func TestMermaidAssociationArrows(t *testing.T) {
+	o := newOptions([]Option{WithNotation(NotationUML), WithArrows()})
+
+	for rel, expected := range map[*Relationship]string{
+		{From: "User", To: "Car", FromCardinality: ZeroOrOne, ToCardinality: ZeroOrMore, Label: "cars-owner", ForeignKey: &ForeignKey{Entity: "Car", RefEntity: "User"}}:            `Car "0..*" --> "0..1" User : cars-owner`,
+		{From: "Car", To: "User", FromCardinality: ZeroOrMore, ToCardinality: ExactlyOne, Label: "owner", ForeignKey: &ForeignKey{Entity: "Car", RefEntity: "User"}, Logical: true}: `Car "0..*" ..> "1" User : owner`,
+		{From: "User", To: "Team", FromCardinality: ZeroOrMore, ToCardinality: ZeroOrOne, Label: "team"}:                                                                            `User "0..*" -- "0..1" Team : team`,
+	} {
+		if got := mermaidAssociation(rel, o); got != expected {
+			t.Errorf("mermaidAssociation(%s) = %q, expected %q", rel.Label, got, expected)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// defaultNotionURL is the URL of the public Notion API.
+	defaultNotionURL = "https://api.notion.com"
+	// notionVersion is the version of the Notion API the requests are made with.
+	notionVersion = "2022-06-28"
+	// notionTextSize is the maximum length of a rich text object of a Notion block, longer diagrams are split.
+	notionTextSize = 2000
+)
+
+// NotionPage is the Notion page the diagram is pushed to, as a Mermaid code block Notion previews as a diagram.
+// The first Mermaid code block of the page is replaced by the diagram, or the block is appended to the page when it
+// has none, so the rest of the page can be written around it.
+type NotionPage struct {
+	// BaseURL is the URL of the Notion API, the public API when empty.
+	BaseURL string
+	ID      string
+	// Token is the secret of the Notion integration the page is shared with.
+	Token string
+}
+
+// WithNotionPage pushes the Mermaid diagram to the page, or compares it to the page in check mode.
+func WithNotionPage(page NotionPage) Option {
+	return func(o *options) {
+		o.notionPage = &page
+	}
+}
+
+// notionText is a rich text object of a Notion block.
+type notionText struct {
+	Type string `json:"type"`
+	Text struct {
+		Content string `json:"content"`
+	} `json:"text"`
+}
+
+// notionBlock is the part of the Notion block object needed to find and update the Mermaid code block.
+type notionBlock struct {
+	Object string      `json:"object,omitempty"`
+	ID     string      `json:"id,omitempty"`
+	Type   string      `json:"type"`
+	Code   *notionCode `json:"code,omitempty"`
+}
+
+type notionCode struct {
+	RichText []notionText `json:"rich_text"`
+	Language string       `json:"language"`
+}
+
+// notionChildren is a page of the children blocks of a Notion block.
+type notionChildren struct {
+	Results    []notionBlock `json:"results"`
+	HasMore    bool          `json:"has_more"`
+	NextCursor string        `json:"next_cursor"`
+}
+
+// notionCodeBlock returns the Mermaid code block of the diagram, split in rich text objects Notion accepts.
+func notionCodeBlock(diagram string) *notionCode {
+	code := &notionCode{Language: "mermaid"}
+
+	runes := []rune(diagram)
+	for start := 0; start < len(runes); start += notionTextSize {
+		var text notionText
+		text.Type = "text"
+		text.Text.Content = string(runes[start:min(start+notionTextSize, len(runes))])
+		code.RichText = append(code.RichText, text)
+	}
+
+	return code
+}
+
+// plainText returns the content of the code block.
+func (c *notionCode) plainText() string {
+	var builder strings.Builder
+	for _, text := range c.RichText {
+		builder.WriteString(text.Text.Content)
+	}
+
+	return builder.String()
+}
+
+// pushNotion replaces the first Mermaid code block of the Notion page with the diagram, unless it's already up to
+// date, or appends it to the page.
+func pushNotion(ctx context.Context, model *Model, o *options) error {
+	page := o.notionPage
+
+	var builder strings.Builder
+	if err := renderMermaid(ctx, &builder, model, o); err != nil {
+		return fmt.Errorf("%w as %s: %w", ErrRender, FormatMermaid, err)
+	}
+	code := notionCodeBlock(builder.String())
+
+	baseURL := page.BaseURL
+	if baseURL == "" {
+		baseURL = defaultNotionURL
+	}
+	childrenURL := fmt.Sprintf("%s/v1/blocks/%s/children", strings.TrimSuffix(baseURL, "/"), url.PathEscape(page.ID))
+
+	existing, err := findNotionCodeBlock(ctx, childrenURL, page)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil && strings.TrimSpace(existing.Code.plainText()) == strings.TrimSpace(code.plainText()) {
+		o.logger.Info("notion page is unchanged", "page", page.ID)
+		return nil
+	}
+
+	if o.check {
+		return fmt.Errorf("the Mermaid %w on the Notion page %s", ErrStaleDiagram, page.ID)
+	}
+
+	if existing != nil {
+		blockURL := fmt.Sprintf("%s/v1/blocks/%s", strings.TrimSuffix(baseURL, "/"), url.PathEscape(existing.ID))
+		if err := notionRequest(ctx, http.MethodPatch, blockURL, page, map[string]any{"code": code}, nil); err != nil {
+			return err
+		}
+	} else {
+		children := map[string]any{"children": []notionBlock{{Object: "block", Type: "code", Code: code}}}
+		if err := notionRequest(ctx, http.MethodPatch, childrenURL, page, children, nil); err != nil {
+			return err
+		}
+	}
+
+	o.logger.Info("pushed diagram to notion", "page", page.ID)
+
+	return nil
+}
+
+// findNotionCodeBlock returns the first Mermaid code block among the children of the page, nil when there's none.
+func findNotionCodeBlock(ctx context.Context, childrenURL string, page *NotionPage) (*notionBlock, error) {
+	cursor := ""
+	for {
+		pageURL := childrenURL + "?page_size=100"
+		if cursor != "" {
+			pageURL += "&start_cursor=" + url.QueryEscape(cursor)
+		}
+
+		var children notionChildren
+		if err := notionRequest(ctx, http.MethodGet, pageURL, page, nil, &children); err != nil {
+			return nil, err
+		}
+
+		for _, block := range children.Results {
+			if block.Type == "code" && block.Code != nil && block.Code.Language == "mermaid" {
+				return &block, nil
+			}
+		}
+
+		if !children.HasMore || children.NextCursor == "" {
+			return nil, nil
+		}
+		cursor = children.NextCursor
+	}
+}
+
+func notionRequest(ctx context.Context, method string, url string, page *NotionPage, body any, result any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrNotion, err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNotion, err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Notion-Version", notionVersion)
+	req.Header.Set("Authorization", "Bearer "+page.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNotion, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%w: %s %s returned %s: %s", ErrNotion, method, url, resp.Status, bytes.TrimSpace(message))
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotion, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNotionCodeBlock(t *testing.T) {
+	diagram := strings.Repeat("é", notionTextSize+1)
+
+	code := notionCodeBlock(diagram)
+	if len(code.RichText) != 2 || len([]rune(code.RichText[0].Text.Content)) != notionTextSize || code.Language != "mermaid" {
+		t.Errorf("Expected the diagram split in two rich texts, got %d", len(code.RichText))
+	}
+	if code.plainText() != diagram {
+		t.Error("Expected the rich texts to join back into the diagram")
+	}
+}
+
+func TestPushNotion(t *testing.T) {
+	blocks := []notionBlock{{Object: "block", ID: "intro", Type: "paragraph"}}
+	var patched []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("Notion-Version") != notionVersion {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/blocks/42/children":
+			_ = json.NewEncoder(w).Encode(notionChildren{Results: blocks})
+		case r.Method == http.MethodPatch && r.URL.Path == "/v1/blocks/42/children":
+			var body struct {
+				Children []notionBlock `json:"children"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			body.Children[0].ID = "diagram"
+			blocks = append(blocks, body.Children...)
+			patched = append(patched, r.URL.Path)
+		case r.Method == http.MethodPatch && r.URL.Path == "/v1/blocks/diagram":
+			var body struct {
+				Code *notionCode `json:"code"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			blocks[1].Code = body.Code
+			patched = append(patched, r.URL.Path)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	page := WithNotionPage(NotionPage{BaseURL: server.URL + "/", ID: "42", Token: "secret"})
+
+	err := GenerateDiagram("../examples/start/schema", "", Plain, "", "", page, WithCheck())
+	if !errors.Is(err, ErrStaleDiagram) {
+		t.Errorf("Expected the page without a diagram to be stale, got %v", err)
+	}
+
+	if err := GenerateDiagram("../examples/start/schema", "", Plain, "", "", page); err != nil {
+		t.Fatalf("Failed to push the diagram: %v", err)
+	}
+	if len(blocks) != 2 || !strings.Contains(blocks[1].Code.plainText(), "User |o--o{ Car") {
+		t.Errorf("Expected the diagram appended to the page, got %+v", blocks)
+	}
+
+	if err := GenerateDiagram("../examples/start/schema", "", Plain, "", "", page, WithCheck()); err != nil {
+		t.Errorf("Expected the pushed page to be up to date, got %v", err)
+	}
+
+	blocks[1].Code = notionCodeBlock("erDiagram\n")
+	if err := GenerateDiagram("../examples/start/schema", "", Plain, "", "", page); err != nil {
+		t.Fatalf("Failed to update the diagram: %v", err)
+	}
+	if expected := []string{"/v1/blocks/42/children", "/v1/blocks/diagram"}; strings.Join(patched, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected the existing code block to be updated in place, got the requests %v", patched)
+	}
+
+	err = GenerateDiagram("../examples/start/schema", "", Plain, "", "", WithNotionPage(NotionPage{BaseURL: server.URL, ID: "42"}))
+	if !errors.Is(err, ErrNotion) || ExitCode(err) != ExitTarget {
+		t.Errorf("Expected unauthorized requests to fail with ErrNotion, got %v", err)
+	}
+}
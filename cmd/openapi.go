@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+
+	"entgo.io/ent/schema/field"
+)
+
+// FormatOpenAPI is the components.schemas fragment of an OpenAPI 3.1 document, with a schema per entity.
+const FormatOpenAPI Format = "openapi"
+
+// openAPIInvalidNameRegex matches the characters OpenAPI doesn't allow in the names of the components.
+var openAPIInvalidNameRegex = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// OpenAPISchema is the part of the OpenAPI schema object the entities are described with.
+type OpenAPISchema struct {
+	// Type is a string, or a list of the type and "null" for the nillable fields.
+	Type        any                       `json:"type,omitempty"`
+	Format      string                    `json:"format,omitempty"`
+	Description string                    `json:"description,omitempty"`
+	Enum        []string                  `json:"enum,omitempty"`
+	Minimum     *int                      `json:"minimum,omitempty"`
+	ReadOnly    bool                      `json:"readOnly,omitempty"`
+	Items       *OpenAPISchema            `json:"items,omitempty"`
+	Properties  map[string]*OpenAPISchema `json:"properties,omitempty"`
+	Required    []string                  `json:"required,omitempty"`
+}
+
+// openAPITypes are the OpenAPI types and formats of the ent field types.
+var openAPITypes = map[field.Type][2]string{
+	field.TypeBool:    {"boolean", ""},
+	field.TypeTime:    {"string", "date-time"},
+	field.TypeUUID:    {"string", "uuid"},
+	field.TypeBytes:   {"string", "byte"},
+	field.TypeEnum:    {"string", ""},
+	field.TypeString:  {"string", ""},
+	field.TypeOther:   {"string", ""},
+	field.TypeInt8:    {"integer", "int32"},
+	field.TypeInt16:   {"integer", "int32"},
+	field.TypeInt32:   {"integer", "int32"},
+	field.TypeInt:     {"integer", "int64"},
+	field.TypeInt64:   {"integer", "int64"},
+	field.TypeUint8:   {"integer", "int32"},
+	field.TypeUint16:  {"integer", "int32"},
+	field.TypeUint32:  {"integer", "int64"},
+	field.TypeUint:    {"integer", "int64"},
+	field.TypeUint64:  {"integer", "int64"},
+	field.TypeFloat32: {"number", "float"},
+	field.TypeFloat64: {"number", "double"},
+}
+
+// renderOpenAPI writes the components.schemas fragment of an OpenAPI 3.1 document describing the entities, to be
+// merged into the spec of a REST API. The join tables are left out, the views are read-only.
+func renderOpenAPI(_ context.Context, w io.Writer, model *Model, _ *options) error {
+	schemas := make(map[string]*OpenAPISchema)
+
+	for _, entity := range model.Entities {
+		if entity.JoinTable {
+			continue
+		}
+
+		schema := &OpenAPISchema{Type: "object", Properties: make(map[string]*OpenAPISchema), ReadOnly: entity.View}
+		for _, attribute := range entity.Attributes {
+			schema.Properties[attribute.Name] = openAPIProperty(attribute)
+			if !attribute.optional {
+				schema.Required = append(schema.Required, attribute.Name)
+			}
+		}
+
+		schemas[openAPIName(entity.Name)] = schema
+	}
+
+	document := map[string]any{"components": map[string]any{"schemas": schemas}}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(document)
+}
+
+// openAPIName returns the name of the component of the entity, replacing the characters OpenAPI doesn't allow.
+func openAPIName(name string) string {
+	return openAPIInvalidNameRegex.ReplaceAllString(name, "_")
+}
+
+// openAPIProperty returns the schema of the attribute, from the type of its field.
+func openAPIProperty(attribute *Attribute) *OpenAPISchema {
+	property := &OpenAPISchema{Description: attribute.comment, ReadOnly: attribute.Immutable && !attribute.optional}
+
+	switch {
+	case attribute.typeInfo == nil:
+		// The columns of the join tables aren't fields of the schema.
+		property.Type, property.Format = "integer", "int64"
+	case attribute.typeInfo.Type == field.TypeJSON:
+		property.Type = "object"
+		if strings.HasPrefix(strings.TrimLeft(attribute.GoType, "*"), "[]") {
+			property.Type, property.Items = "array", &OpenAPISchema{}
+		}
+	default:
+		types := openAPITypes[attribute.typeInfo.Type]
+		property.Type, property.Format = types[0], types[1]
+		if strings.HasPrefix(attribute.typeInfo.Type.String(), "uint") {
+			property.Minimum = new(int)
+		}
+		if len(attribute.enums) > 0 {
+			property.Enum = attribute.enums
+		}
+	}
+
+	if attribute.nillable {
+		property.Type = []any{property.Type, "null"}
+	}
+
+	return property
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"entgo.io/ent/schema/field"
+)
+
+func TestRenderOpenAPI(t *testing.T) {
+	model := &Model{
+		Entities: []*Entity{
+			{Name: "User", Attributes: []*Attribute{
+				{Name: "id", Type: "int", GoType: "int", Keys: []string{"PK"}, typeInfo: &field.TypeInfo{Type: field.TypeInt}},
+				{Name: "role", Type: "enum", GoType: "user.Role", typeInfo: &field.TypeInfo{Type: field.TypeEnum}, enums: []string{"admin", "user"}},
+				{Name: "deleted_at", Type: "timestamp", GoType: "*time.Time", typeInfo: &field.TypeInfo{Type: field.TypeTime}, optional: true, nillable: true},
+				{Name: "tags", Type: "json", GoType: "[]string", typeInfo: &field.TypeInfo{Type: field.TypeJSON}, comment: "the tags of the user"},
+				{Name: "age", Type: "uint8", GoType: "uint8", typeInfo: &field.TypeInfo{Type: field.TypeUint8}, optional: true},
+			}},
+			{Name: "Order Item", View: true},
+			{Name: "user_groups", JoinTable: true},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := renderOpenAPI(context.Background(), &buf, model, newOptions(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	var document struct {
+		Components struct {
+			Schemas map[string]*OpenAPISchema `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &document); err != nil {
+		t.Fatalf("The fragment isn't valid JSON: %v\n%s", err, buf.String())
+	}
+
+	schemas := document.Components.Schemas
+	if _, ok := schemas["user_groups"]; ok || len(schemas) != 2 {
+		t.Errorf("Expected the schemas of the entities without the join tables, got %v", schemas)
+	}
+	if view := schemas["Order_Item"]; view == nil || !view.ReadOnly {
+		t.Errorf("Expected the view to be a read-only Order_Item, got %+v", view)
+	}
+
+	user := schemas["User"]
+	if !reflect.DeepEqual(user.Required, []string{"id", "role", "tags"}) {
+		t.Errorf("Got the required properties %v", user.Required)
+	}
+
+	for name, expected := range map[string]*OpenAPISchema{
+		"id":         {Type: "integer", Format: "int64"},
+		"role":       {Type: "string", Enum: []string{"admin", "user"}},
+		"deleted_at": {Type: []any{"string", "null"}, Format: "date-time"},
+		"tags":       {Type: "array", Items: &OpenAPISchema{}, Description: "the tags of the user"},
+		"age":        {Type: "integer", Format: "int32", Minimum: new(int)},
+	} {
+		if got := user.Properties[name]; !reflect.DeepEqual(got, expected) {
+			t.Errorf("Got the property %s %+v, expected %+v", name, got, expected)
+		}
+	}
+}
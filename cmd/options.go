@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+
+	"entgo.io/ent/entc"
+	"entgo.io/ent/entc/gen"
+)
+
+// Option configures how GenerateDiagram renders and writes the diagram.
+type Option func(*options)
+
+type options struct {
+	header            bool
+	headerTimestamp   bool
+	reproducible      bool
+	check             bool
+	createMarkers     bool
+	allMarkers        bool
+	strict            bool
+	quiet             bool
+	logger            *slog.Logger
+	outputs           []output
+	rawOutput         bool
+	modelHooks        []func(*Model) error
+	mmdcPath          string
+	cache             bool
+	cacheDir          string
+	genConfig         *gen.Config
+	entcOptions       []entc.Option
+	gitAdd            bool
+	linkTemplate      string
+	typeAliases       map[string]string
+	typeStyle         TypeStyle
+	stripTypePackages bool
+	maxTypeLength     int
+	maxNameLength     int
+	maxFields         int
+	sizeBudget        int
+	legend            bool
+	groupBySchema     bool
+	domainSource      DomainSource
+	domains           []domain
+	analyze           bool
+	lintRules         []LintRule
+	lintMaxColumns    int
+	lintOverlay       bool
+	pruneOrphans      bool
+	stubHubs          int
+	edgeLabels        map[string]string
+	displayNames      map[string]string
+	entityLinks       map[string]string
+	verbLabels        bool
+	forwardLabels     bool
+	multiplicities    bool
+	fkLabels          bool
+	mergeEdges        bool
+	overview          bool
+	incremental       bool
+	globalIDs         bool
+	visibility        Visibility
+	redaction         *Redaction
+	locale            *Locale
+	graphSnapshot     string
+	entSnapshot       string
+	skipUnchanged     bool
+	specs             *specMemo
+	skipUnchangedKey  string
+	changedSince      string
+	followEdges       []EdgeKind
+	noFollowEdges     []string
+	// changedEntities are the entities declared in the files changed since the ref, for the pull request comment.
+	changedEntities []string
+	prCommentPath   string
+	incrementalKey  string
+	pageSize        int
+	warnEntities    int
+	failEntities    int
+	sort            EntityOrder
+	fieldOrder      FieldOrder
+	weightBy        RelationshipWeight
+	summary         SummaryPosition
+	validate        bool
+	guards          bool
+	hideIDs         bool
+	mixins          bool
+	arrows          bool
+	detailedTypes   bool
+	nullability     bool
+	expandJSON      bool
+	partial         bool
+	goldenPath      string
+	goldenUpdate    bool
+	reportPath      string
+	resultJSON      io.Writer
+	outcome         Outcome
+	ignoreFile      string
+	mermaidVersion  MermaidVersion
+	dialect         Dialect
+	notation        Notation
+	confluenceMacro string
+	confluencePage  *ConfluencePage
+	notionPage      *NotionPage
+	webhookURL      string
+	lineageNS       string
+	compactEdges    bool
+	joinTableNames  map[string]string
+	shortJoinTables bool
+	onlyEdges       []EdgeKind
+	noEdges         []EdgeKind
+	index           bool
+	title           string
+	description     string
+	fencePrefix     string
+	fenceSuffix     string
+	entities        []string
+	schemaPaths     []string
+
+	// stdin and stdout are the streams of the StdioTarget.
+	stdin  io.Reader
+	stdout io.Writer
+
+	// modified holds the files written during the run and written their sizes, guarded by mu as outputs are
+	// written concurrently. skipped holds the entities left out of the diagram.
+	mu       sync.Mutex
+	modified []string
+	written  []ReportFile
+	skipped  []SkippedEntity
+
+	warnings       []Warning
+	warningHandler func(Warning)
+	skipHandler    func(SkippedEntity)
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+func (o *options) recordModified(path string, size int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// A target written again, e.g. for its index, is recorded once with its final size.
+	for i := range o.written {
+		if o.written[i].Path == path {
+			o.written[i].Bytes = size
+			return
+		}
+	}
+
+	o.modified = append(o.modified, path)
+	o.written = append(o.written, ReportFile{Path: path, Bytes: size})
+}
+
+// skipEntity records why the entity was left out of the diagram.
+func (o *options) skipEntity(name string, reason string) {
+	o.logger.Debug("skipped entity", "entity", name, "reason", reason)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.skipped = append(o.skipped, SkippedEntity{Entity: name, Reason: reason})
+	if o.skipHandler != nil {
+		o.skipHandler(o.skipped[len(o.skipped)-1])
+	}
+}
+
+// WithSchema merges the schema in the directory at path into the diagram, e.g. for several services sharing a
+// database. Entities declared by more than one of the schemas are reported as warnings.
+func WithSchema(path string) Option {
+	return func(o *options) {
+		o.schemaPaths = append(o.schemaPaths, path)
+	}
+}
+
+// WithHeader prepends a comment line to the generated diagram containing the entmaid version and a hash of the
+// schema inputs, optionally along with the time it was generated.
+func WithHeader(timestamp bool) Option {
+	return func(o *options) {
+		o.header = true
+		o.headerTimestamp = timestamp
+	}
+}
+
+// WithReproducible leaves the entmaid version and the generation time out of the header and the summary, so the
+// output only depends on the schema and the options, for builds requiring byte-identical outputs like Nix or Bazel.
+func WithReproducible() Option {
+	return func(o *options) {
+		o.reproducible = true
+	}
+}
+
+// WithCheck verifies the diagram in the target file is up-to-date instead of writing it.
+func WithCheck() Option {
+	return func(o *options) {
+		o.check = true
+	}
+}
+
+// WithCreateMarkers appends the start and end patterns along with the diagram to the end of the target file when
+// neither pattern is found, instead of failing.
+func WithCreateMarkers() Option {
+	return func(o *options) {
+		o.createMarkers = true
+	}
+}
+
+// WithAllMarkers places the diagram between every pair of start and end patterns in the target file instead of
+// requiring exactly one pair.
+func WithAllMarkers() Option {
+	return func(o *options) {
+		o.allMarkers = true
+	}
+}
+
+// WithWarningHandler calls the handler for every schema construct that was skipped or couldn't be fully represented.
+func WithWarningHandler(handler func(Warning)) Option {
+	return func(o *options) {
+		o.warningHandler = handler
+	}
+}
+
+// WithSkipHandler calls the handler for every entity of the schema left out of the diagram, with the reason why.
+func WithSkipHandler(handler func(SkippedEntity)) Option {
+	return func(o *options) {
+		o.skipHandler = handler
+	}
+}
+
+// WithStrict fails with ErrWarnings, before the target file is touched, when any warnings were raised.
+func WithStrict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithLogger logs which nodes and edges were processed or skipped to the logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithStdio sets the streams the document is read from and written to when the target is StdioTarget, instead of
+// os.Stdin and os.Stdout.
+func WithStdio(stdin io.Reader, stdout io.Writer) Option {
+	return func(o *options) {
+		o.stdin = stdin
+		o.stdout = stdout
+	}
+}
+
+// WithQuiet stops GenerateDiagram from printing its success message.
+func WithQuiet() Option {
+	return func(o *options) {
+		o.quiet = true
+	}
+}
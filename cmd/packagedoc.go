@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var exportPackageDocCmd = &cobra.Command{
+	Use:   "godoc <file>",
+	Short: "Write the package documentation of a Go file listing the entities and their relationships",
+	Long: `Write the doc comment of a Go file (e.g. ent/doc.go) listing the entities of the schema with their table and the
+relationships between them, along with a link to the rendered diagram given by --diagram-url, so the documentation of
+the package shown on pkg.go.dev stays in sync with the schema.
+
+The list is placed between the "// #start:entmaid" and "// #end:entmaid" lines of the doc comment of an existing file,
+or the file is created with them. Like the other exports, --check verifies the documentation is up to date.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWithOptions(cmd, func(ctx context.Context, opts []Option) error {
+			return exportPackageDoc(ctx, schemaPaths[0], args[0], packageDocName, packageDocURL, newOptions(opts))
+		})
+	},
+}
+
+var (
+	packageDocName string
+	packageDocURL  string
+)
+
+func init() {
+	exportPackageDocCmd.Flags().StringVar(&packageDocName, "package", "", "name of the package of a created file (default is the name of its directory)")
+	exportPackageDocCmd.Flags().StringVar(&packageDocURL, "diagram-url", "", "URL of the rendered diagram linked to from the documentation, e.g. the README holding it")
+	exportCmd.AddCommand(exportPackageDocCmd)
+}
+
+// packageNameRegex matches the characters that can't be part of a package name.
+var packageNameRegex = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// exportPackageDoc places the documentation of the model between the patterns of the Go file, creating the file when
+// it doesn't exist yet, or compares it to what's already there in check mode.
+func exportPackageDoc(ctx context.Context, schemaPath string, path string, packageName string, diagramURL string, o *options) error {
+	model, err := loadModel(ctx, schemaPath, o)
+	if err != nil {
+		return err
+	}
+
+	doc := packageDoc(model, diagramURL)
+	startPattern, endPattern := DefaultPatterns(".go")
+
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		if packageName == "" {
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				return inFile(path, fmt.Errorf("%w: %w", ErrTarget, err))
+			}
+			packageName = packageDocPackage(filepath.Base(filepath.Dir(abs)))
+		}
+
+		content := fmt.Sprintf("// Package %s holds the entities of the ent schema, listed below with their relationships.\n//\n%s\n%s\n%s\npackage %s\n",
+			packageName, startPattern, doc, endPattern, packageName)
+
+		return writeFile(path, []byte(content), "package documentation", o)
+	}
+
+	if o.check {
+		line, err := checkMultiLineString(path, doc, startPattern, endPattern, o)
+		if err != nil {
+			return fmt.Errorf("failed to check the package documentation in the file: %w", err)
+		}
+
+		if line > 0 {
+			return &FileError{Path: path, Line: line, Err: fmt.Errorf("the package documentation %w in %s", ErrStaleDiagram, path)}
+		}
+
+		return nil
+	}
+
+	if err := insertMultiLineString(path, doc, startPattern, endPattern, o); err != nil {
+		return fmt.Errorf("failed to insert the package documentation into the file: %w", err)
+	}
+
+	o.logger.Info("wrote package documentation", "target", path, "entities", len(model.Entities))
+
+	return nil
+}
+
+// packageDocPackage turns the name of a directory into a package name, e.g. "ent" for "ent" or "my_schema" for
+// "my-schema".
+func packageDocPackage(dir string) string {
+	name := strings.Trim(packageNameRegex.ReplaceAllString(strings.ToLower(dir), "_"), "_")
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "ent" + name
+	}
+
+	return name
+}
+
+// packageDoc lists the entities and relationships of the model as the lines of a doc comment, formatted as gofmt
+// formats them. The M2M edges are listed as relationships between their sides instead of through their join table.
+func packageDoc(model *Model, diagramURL string) string {
+	overview := overviewModel(model)
+
+	var builder strings.Builder
+	builder.WriteString("//\n// # Entities\n//\n")
+	for _, entity := range overview.Entities {
+		kind := "table"
+		if entity.View {
+			kind = "view"
+		}
+		builder.WriteString(fmt.Sprintf("//   - %s (%s %s)\n", goDocLine(entity.Name), kind, goDocLine(entity.Table)))
+	}
+
+	if len(overview.Relationships) > 0 {
+		builder.WriteString("//\n// # Relationships\n//\n")
+		for _, rel := range overview.Relationships {
+			builder.WriteString(fmt.Sprintf("//   - %s to %s, %s to %s: %s\n", goDocLine(rel.From), goDocLine(rel.To),
+				packageDocCardinality(rel.FromCardinality), packageDocCardinality(rel.ToCardinality), goDocLine(rel.Label)))
+		}
+	}
+
+	if diagramURL != "" {
+		builder.WriteString("//\n// The diagram of the schema is at " + goDocLine(diagramURL) + ".\n")
+	}
+	builder.WriteString("//")
+
+	return builder.String()
+}
+
+// packageDocCardinality describes how many entities can be on an end of a relationship in words.
+func packageDocCardinality(cardinality Cardinality) string {
+	if cardinality == ZeroOrMore || cardinality == OneOrMore {
+		return "many"
+	}
+
+	return "one"
+}
+
+// goDocLine keeps the text on its line of the doc comment.
+func goDocLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
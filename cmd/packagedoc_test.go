@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportPackageDoc(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "my-schema", "doc.go")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err := exportPackageDoc(ctx, "../examples/start/schema", path, "", "https://example.com/erd", newOptions([]Option{WithQuiet()})); err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"// Package my_schema holds",
+		"// # Entities\n//\n//   - Car (table cars)\n//   - Group (table groups)\n//   - User (table users)\n",
+		"//   - User to Car, one to many: cars-owner\n",
+		"//   - Group to User, many to many: users-groups\n",
+		"// The diagram of the schema is at https://example.com/erd.\n",
+		"package my_schema\n",
+	} {
+		if !strings.Contains(string(written), expected) {
+			t.Errorf("The documentation is missing %q:\n%s", expected, written)
+		}
+	}
+
+	// gofmt reformats the doc comments, it mustn't touch the documentation.
+	if formatted, err := format.Source(written); err != nil || string(formatted) != string(written) {
+		t.Errorf("Expected gofmt to keep the documentation as is (%v), got:\n%s", err, formatted)
+	}
+
+	// An existing file only has the part between its patterns updated.
+	edited := strings.Replace(string(written), "holds the entities", "is the schema", 1)
+	edited = strings.Replace(edited, "//   - Car (table cars)\n", "", 1)
+	if err := os.WriteFile(path, []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	check := newOptions([]Option{WithQuiet(), WithCheck()})
+	if err := exportPackageDoc(ctx, "../examples/start/schema", path, "", "https://example.com/erd", check); ExitCode(err) != ExitStale {
+		t.Errorf("Expected the stale documentation to fail the check, got %v", err)
+	}
+
+	if err := exportPackageDoc(ctx, "../examples/start/schema", path, "other", "https://example.com/erd", newOptions([]Option{WithQuiet()})); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(updated), "// Package my_schema is the schema") || !strings.Contains(string(updated), "//   - Car (table cars)\n") {
+		t.Errorf("Expected the documentation between the patterns to be updated only:\n%s", updated)
+	}
+
+	if err := exportPackageDoc(ctx, "../examples/start/schema", path, "", "https://example.com/erd", check); err != nil {
+		t.Errorf("Expected the documentation to be up to date: %v", err)
+	}
+}
+
+func TestPackageDocPackage(t *testing.T) {
+	for dir, expected := range map[string]string{
+		"ent":       "ent",
+		"My-Schema": "my_schema",
+		"2024":      "ent2024",
+		"---":       "ent",
+	} {
+		if got := packageDocPackage(dir); got != expected {
+			t.Errorf("packageDocPackage(%q) = %q, expected %q", dir, got, expected)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+// WithPageSize splits the diagram of the target into numbered diagrams of at most size entities each, e.g. as GitHub
+// refuses to render a very large one. The relationships between the entities of different diagrams are drawn on
+// both, to a stub of the other entity naming the diagram it's drawn in.
+func WithPageSize(size int) Option {
+	return func(o *options) {
+		o.pageSize = size
+	}
+}
+
+// paginateModel splits the entities of the model into pages of at most size entities, in order. Every page holds
+// the relationships of its entities, and stubs of the entities of other pages they're related to.
+func paginateModel(model *Model, size int) []*Model {
+	pageOf := make(map[string]int, len(model.Entities))
+	var pages []*Model
+
+	for i, entity := range model.Entities {
+		if i%size == 0 {
+			pages = append(pages, &Model{Header: model.Header, page: len(pages) + 1})
+		}
+
+		page := pages[len(pages)-1]
+		page.Entities = append(page.Entities, entity)
+		pageOf[entity.Name] = page.page
+	}
+
+	stubbed := make([]map[string]bool, len(pages))
+	for i := range stubbed {
+		stubbed[i] = make(map[string]bool)
+	}
+
+	addStub := func(page int, name string) {
+		other, ok := pageOf[name]
+		if !ok || other == page || stubbed[page-1][name] {
+			return
+		}
+		stubbed[page-1][name] = true
+
+		stub := *model.Entity(name)
+		stub.Attributes, stub.Indexes, stub.drawnOn = nil, nil, other
+		pages[page-1].Entities = append(pages[page-1].Entities, &stub)
+	}
+
+	for _, rel := range model.Relationships {
+		drawnOn := []int{pageOf[rel.From]}
+		if to := pageOf[rel.To]; to != drawnOn[0] {
+			drawnOn = append(drawnOn, to)
+		}
+
+		for _, page := range drawnOn {
+			// Relationships to entities left out of the model (e.g. filtered) aren't drawn anyway.
+			if page == 0 {
+				continue
+			}
+
+			pages[page-1].Relationships = append(pages[page-1].Relationships, rel)
+			addStub(page, rel.From)
+			addStub(page, rel.To)
+		}
+	}
+
+	for _, page := range pages {
+		page.pages = len(pages)
+	}
+
+	return pages
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPaginateModel(t *testing.T) {
+	model := &Model{
+		Entities: []*Entity{
+			{Name: "User", Attributes: []*Attribute{{Name: "id", Type: "int", Keys: []string{"PK"}}}},
+			{Name: "Car"},
+			{Name: "Group"},
+		},
+		Relationships: []*Relationship{
+			{From: "User", To: "Car", FromCardinality: ZeroOrOne, ToCardinality: ZeroOrMore, Label: "cars-owner"},
+			{From: "Group", To: "User", FromCardinality: ZeroOrMore, ToCardinality: ZeroOrMore, Label: "users"},
+		},
+	}
+
+	pages := paginateModel(model, 2)
+	if len(pages) != 2 {
+		t.Fatalf("Expected 2 pages, got %d", len(pages))
+	}
+
+	if len(pages[0].Relationships) != 2 || len(pages[1].Relationships) != 1 {
+		t.Errorf("Expected the relationship across the pages on both, got %d and %d", len(pages[0].Relationships), len(pages[1].Relationships))
+	}
+
+	var builder strings.Builder
+	if err := renderMermaid(context.Background(), &builder, pages[1], newOptions([]Option{WithValidate()})); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{" %% diagram 2 of 2\n", " Group {\n }\n", " User[\"User (see diagram 1)\"] {\n }\n", " Group }o--o{ User : users\n"} {
+		if !strings.Contains(builder.String(), expected) {
+			t.Errorf("The second page is missing %q:\n%s", expected, builder.String())
+		}
+	}
+
+	// The stubs are copies, the entity keeps its attributes on its own page.
+	if len(model.Entities[0].Attributes) != 1 || model.Entities[0].drawnOn != 0 {
+		t.Errorf("Expected the entities of the model to be left as is, got %+v", model.Entities[0])
+	}
+}
+
+func TestGenerateDiagramPageSize(t *testing.T) {
+	var stdout bytes.Buffer
+	err := GenerateDiagram("../examples/start/schema", StdioTarget, Markdown, defaultStartPattern, defaultEndPattern,
+		WithPageSize(2), WithStdio(strings.NewReader(defaultStartPattern+"\n"+defaultEndPattern+"\n"), &stdout))
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	for _, expected := range []string{"**Diagram 1 of 2**\n\n```mermaid\n", "**Diagram 2 of 2**\n\n```mermaid\n", ` Car["Car (see diagram 1)"] {`} {
+		if !strings.Contains(stdout.String(), expected) {
+			t.Errorf("The target is missing %q:\n%s", expected, stdout.String())
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+const markerName = "entmaid"
+
+// commentStyles maps a target file extension to the line comment syntax used to hide the markers in that format.
+var commentStyles = map[string][2]string{
+	".md":       {"<!-- ", " -->"},
+	".markdown": {"<!-- ", " -->"},
+	".mdx":      {"{/* ", " */}"},
+	".html":     {"<!-- ", " -->"},
+	".htm":      {"<!-- ", " -->"},
+	".xml":      {"<!-- ", " -->"},
+	".go":       {"// ", ""},
+	".js":       {"// ", ""},
+	".ts":       {"// ", ""},
+	".adoc":     {"// ", ""},
+	".mmd":      {"%% ", ""},
+	".mermaid":  {"%% ", ""},
+	".sql":      {"-- ", ""},
+	".py":       {"# ", ""},
+	".sh":       {"# ", ""},
+	".yaml":     {"# ", ""},
+	".yml":      {"# ", ""},
+	".toml":     {"# ", ""},
+	".rst":      {".. ", ""},
+	".tex":      {"% ", ""},
+}
+
+// DefaultPatterns returns the start and end patterns used for the target file when none are given, based on the
+// comment syntax of its extension. Unknown extensions use the Markdown (HTML comment) markers.
+func DefaultPatterns(targetPath string) (string, string) {
+	style, ok := commentStyles[strings.ToLower(filepath.Ext(targetPath))]
+	if !ok {
+		style = commentStyles[".md"]
+	}
+
+	return style[0] + "#start:" + markerName + style[1], style[0] + "#end:" + markerName + style[1]
+}
+
+// IndexPatterns returns the start and end patterns of the index of the diagrams in the target file, in the comment
+// syntax of its extension like DefaultPatterns. They never contain the default patterns nor are contained by them.
+func IndexPatterns(targetPath string) (string, string) {
+	start, end := DefaultPatterns(targetPath)
+
+	return strings.Replace(start, "#start:", "#start-index:", 1), strings.Replace(end, "#end:", "#end-index:", 1)
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import "testing"
+
+func TestDefaultPatterns(t *testing.T) {
+	testCases := []struct {
+		targetPath string
+		start      string
+		end        string
+	}{
+		{targetPath: "README.md", start: defaultStartPattern, end: defaultEndPattern},
+		{targetPath: "docs/ERD.MD", start: defaultStartPattern, end: defaultEndPattern},
+		{targetPath: "ent/doc.go", start: "// #start:entmaid", end: "// #end:entmaid"},
+		{targetPath: "erd.mmd", start: "%% #start:entmaid", end: "%% #end:entmaid"},
+		{targetPath: "schema.sql", start: "-- #start:entmaid", end: "-- #end:entmaid"},
+		{targetPath: "notes", start: defaultStartPattern, end: defaultEndPattern},
+	}
+
+	for _, tc := range testCases {
+		start, end := DefaultPatterns(tc.targetPath)
+		if start != tc.start || end != tc.end {
+			t.Errorf("DefaultPatterns(%q) = (%q, %q), expected (%q, %q)", tc.targetPath, start, end, tc.start, tc.end)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// pluginPrefix is the prefix of the executables rendering the formats entmaid doesn't know, like protoc plugins:
+// an output in the plantuml format is rendered by the entmaid-plantuml executable found in the PATH.
+const pluginPrefix = "entmaid-"
+
+// pluginFormatRegex matches the formats that can name a plugin, which can't hold a path.
+var pluginFormatRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// pluginRenderer returns the renderer running the plugin of the format, when it's in the PATH. The plugin is given
+// the JSON model (as written by the json format) on stdin and writes the rendered text to stdout, the format and
+// the entmaid version are in the ENTMAID_FORMAT and ENTMAID_VERSION environment variables. A plugin failing with a
+// message on stderr fails the output with it.
+func pluginRenderer(format Format) (renderer, bool) {
+	if !pluginFormatRegex.MatchString(string(format)) {
+		return nil, false
+	}
+
+	path, err := exec.LookPath(pluginPrefix + string(format))
+	if err != nil {
+		return nil, false
+	}
+
+	return func(ctx context.Context, w io.Writer, model *Model, o *options) error {
+		var input bytes.Buffer
+		if err := renderJSON(ctx, &input, model, o); err != nil {
+			return err
+		}
+
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, path)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = &input, w, &stderr
+		cmd.Env = append(os.Environ(), "ENTMAID_FORMAT="+string(format), "ENTMAID_VERSION="+version())
+
+		o.logger.Info("running plugin", "format", format, "path", path)
+		if err := cmd.Run(); err != nil {
+			if message := bytes.TrimSpace(stderr.Bytes()); len(message) > 0 {
+				return fmt.Errorf("failed to run %s: %w: %s", path, err, message)
+			}
+
+			return fmt.Errorf("failed to run %s: %w", path, err)
+		}
+
+		return nil
+	}, true
+}
+
+// pluginFormats returns the formats of the plugins in the PATH, for the completion of the outputs.
+func pluginFormats() []string {
+	var formats []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			name := entry.Name()
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+
+			format, ok := strings.CutPrefix(name, pluginPrefix)
+			if !ok || !pluginFormatRegex.MatchString(format) || slices.Contains(formats, format) {
+				continue
+			}
+			if _, known := renderers[Format(format)]; known {
+				continue
+			}
+
+			if _, err := exec.LookPath(filepath.Join(dir, entry.Name())); err == nil {
+				formats = append(formats, format)
+			}
+		}
+	}
+
+	slices.Sort(formats)
+
+	return formats
+}
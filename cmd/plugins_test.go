@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGenerateDiagramPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the plugins of the test are shell scripts")
+	}
+
+	bin := t.TempDir()
+	for name, script := range map[string]string{
+		"entmaid-echo": "#!/bin/sh\nprintf 'format=%s\\n' \"$ENTMAID_FORMAT\"\ncat\n",
+		"entmaid-fail": "#!/bin/sh\necho 'unsupported schema' >&2\nexit 3\n",
+	} {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	if formats := pluginFormats(); !slices.Contains(formats, "echo") || !slices.Contains(formats, "fail") {
+		t.Errorf("Expected the plugins to be found in the PATH, got %v", formats)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "erd.txt")
+	if err := GenerateDiagram("../examples/start/schema", "", Plain, "", "", WithQuiet(), WithOutput("echo", outputPath)); err != nil {
+		t.Fatalf("Failed to render with the plugin: %v", err)
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), "format=echo\n{") || !strings.Contains(string(content), `"name": "Car"`) {
+		t.Errorf("Expected the plugin to be given the JSON model, got:\n%s", content)
+	}
+
+	err = GenerateDiagram("../examples/start/schema", "", Plain, "", "", WithQuiet(), WithOutput("fail", outputPath))
+	if !errors.Is(err, ErrRender) || !strings.Contains(err.Error(), "unsupported schema") {
+		t.Errorf("Expected the failure of the plugin with its message, got %v", err)
+	}
+
+	err = GenerateDiagram("../examples/start/schema", "", Plain, "", "", WithQuiet(), WithOutput("missing", outputPath))
+	if !errors.Is(err, ErrUnknownFormat) || !strings.Contains(err.Error(), "entmaid-missing") {
+		t.Errorf("Expected an unknown format without a plugin, got %v", err)
+	}
+
+	if _, ok := pluginRenderer("../echo"); ok {
+		t.Error("Expected a format holding a path not to run a plugin")
+	}
+}
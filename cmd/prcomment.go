@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// WithPRComment writes a Markdown comment for the pull request to the file at path ("-" for stdout): the list of
+// the changes to the schema since the ref of WithChangedSince and the diagram of the changed entities. A file with
+// the .json extension holds the {"body": ...} payload of the GitHub and GitLab comment APIs instead.
+func WithPRComment(path string) Option {
+	return func(o *options) {
+		o.prCommentPath = path
+	}
+}
+
+// prChanges are the changes of the pull request listed before its diagram. Changed are the entities declared in
+// the changed schema files, which aren't otherwise added.
+type prChanges struct {
+	Changed []string
+	Shape   *ShapeChanges
+}
+
+// writePRComment writes the comment of the pull request, comparing the shape of the diagram to the one of the target
+// at the ref.
+func writePRComment(ctx context.Context, model *Model, targetPath string, startPattern string, endPattern string, o *options) error {
+	if o.check {
+		o.logger.Info("skipping the pull request comment in check mode", "path", o.prCommentPath)
+		return nil
+	}
+
+	var builder strings.Builder
+	if err := renderMermaid(ctx, &builder, model, o); err != nil {
+		return err
+	}
+	diagram := builder.String()
+
+	changes := &prChanges{Shape: &ShapeChanges{}}
+	if targetPath != "" && targetPath != StdioTarget {
+		previous := previousDiagram(ctx, targetPath, startPattern, endPattern, o)
+		changes.Shape = prShapeChanges(previous, diagram, o)
+	}
+	for _, name := range o.changedEntities {
+		if !slices.Contains(changes.Shape.AddedEntities, name) {
+			changes.Changed = append(changes.Changed, name)
+		}
+	}
+
+	body := prCommentBody(changes, addMermaidToType(diagram, Markdown), o)
+
+	content := []byte(body)
+	if strings.EqualFold(filepath.Ext(o.prCommentPath), ".json") {
+		payload, err := json.MarshalIndent(map[string]string{"body": body}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrRender, err)
+		}
+		content = append(payload, '\n')
+	}
+
+	if o.prCommentPath == StdioTarget {
+		if _, err := o.stdout.Write(content); err != nil {
+			return fmt.Errorf("%w: failed to write the pull request comment: %w", ErrTarget, err)
+		}
+
+		return nil
+	}
+
+	return writeFile(o.prCommentPath, content, "pull request comment", o)
+}
+
+// previousDiagram returns the diagrams between the patterns of the target at the ref, or "" when the target or its
+// patterns didn't exist then.
+func previousDiagram(ctx context.Context, targetPath string, startPattern string, endPattern string, o *options) string {
+	path := filepath.ToSlash(targetPath)
+	if !filepath.IsAbs(targetPath) {
+		path = "./" + path
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", "show", o.changedSince+":"+path)
+	cmd.Stderr = &stderr
+
+	content, err := cmd.Output()
+	if err != nil {
+		o.logger.Info("comparing to an empty diagram, the target isn't in the ref", "ref", o.changedSince, "error", strings.TrimSpace(stderr.String()))
+		return ""
+	}
+
+	regions, err := findMarkerRegions(string(content), startPattern, endPattern)
+	if err != nil {
+		o.logger.Info("comparing to an empty diagram, the target of the ref has no diagram", "ref", o.changedSince, "error", err)
+		return ""
+	}
+
+	var previous strings.Builder
+	for _, region := range regions {
+		previous.WriteString(string(content[region.startIndex+len(startPattern):region.endIndex]) + "\n")
+	}
+
+	return previous.String()
+}
+
+// prShapeChanges compares the shape of the previous diagram of the whole schema to the diagram of the changed
+// entities, leaving out what isn't drawn because it didn't change.
+func prShapeChanges(previous string, current string, o *options) *ShapeChanges {
+	skipped := make(map[string]bool, len(o.skipped))
+	for _, s := range o.skipped {
+		skipped[s.Entity] = true
+	}
+
+	var lines []string
+	for _, line := range strings.Split(previous, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if match := mermaidEntityLineRegex.FindStringSubmatch(trimmed); match != nil && skipped[strings.Trim(match[1], `"`)] {
+			continue
+		}
+		if match := mermaidRelationshipRegex.FindStringSubmatch(trimmed); match != nil {
+			if skipped[strings.Trim(match[1], `"`)] || skipped[strings.Trim(match[2], `"`)] {
+				continue
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	return shapeChanges(strings.Join(lines, "\n"), current)
+}
+
+// prCommentBody lists the changes followed by the diagram.
+func prCommentBody(changes *prChanges, diagram string, o *options) string {
+	var builder strings.Builder
+	builder.WriteString("### Schema changes\n\n")
+
+	kinds := []struct {
+		name   string
+		values []string
+	}{
+		{"Added entities", changes.Shape.AddedEntities},
+		{"Changed entities", changes.Changed},
+		{"Removed entities", changes.Shape.RemovedEntities},
+		{"Added relationships", changes.Shape.AddedRelationships},
+		{"Removed relationships", changes.Shape.RemovedRelationships},
+	}
+
+	listed := false
+	for _, kind := range kinds {
+		if len(kind.values) > 0 {
+			builder.WriteString(fmt.Sprintf("- **%s**: %s\n", kind.name, strings.Join(kind.values, ", ")))
+			listed = true
+		}
+	}
+	if !listed {
+		builder.WriteString(fmt.Sprintf("No entity changed since `%s`.\n", o.changedSince))
+	}
+
+	builder.WriteString("\n" + diagram + "\n")
+	if !o.reproducible {
+		builder.WriteString(fmt.Sprintf("\n<sub>Generated by entmaid %s</sub>\n", version()))
+	}
+
+	return builder.String()
+}
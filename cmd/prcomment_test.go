@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPRShapeChanges(t *testing.T) {
+	previous := "erDiagram\n Car {\n }\n\n Group {\n }\n\n Session {\n }\n\n User {\n }\n\n" +
+		" User |o--o{ Car : cars-owner\n Group |o--o{ Session : sessions\n User |o--o{ Session : sessions\n"
+	current := "erDiagram\n Group {\n }\n\n Pet {\n }\n\n User {\n }\n\n User |o--o{ Pet : pets\n"
+
+	// Car isn't drawn as it didn't change, Session was removed.
+	o := newOptions([]Option{WithQuiet(), WithChangedSince("origin/main")})
+	o.skipEntity("Car", "unchanged since origin/main")
+
+	changes := prShapeChanges(previous, current, o)
+	if !slices.Equal(changes.AddedEntities, []string{"Pet"}) || !slices.Equal(changes.RemovedEntities, []string{"Session"}) {
+		t.Errorf("Unexpected entity changes %+v", changes)
+	}
+	if !slices.Equal(changes.AddedRelationships, []string{"User to Pet (pets)"}) ||
+		!slices.Equal(changes.RemovedRelationships, []string{"Group to Session (sessions)", "User to Session (sessions)"}) {
+		t.Errorf("Unexpected relationship changes %+v", changes)
+	}
+}
+
+func TestWritePRComment(t *testing.T) {
+	model, err := loadModel(context.Background(), "../examples/start/schema", newOptions([]Option{WithQuiet()}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "comment.json")
+	o := newOptions([]Option{WithQuiet(), WithReproducible(), WithChangedSince("origin/main"), WithPRComment(path)})
+	o.changedEntities = []string{"Group"}
+
+	if err := writePRComment(context.Background(), model, "", "", "", o); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var payload struct {
+		Body string `json:"body"`
+	}
+	if err := json.Unmarshal(content, &payload); err != nil {
+		t.Fatalf("Expected the JSON payload, got %v:\n%s", err, content)
+	}
+
+	if !strings.HasPrefix(payload.Body, "### Schema changes\n\n- **Changed entities**: Group\n\n```mermaid\nerDiagram\n") {
+		t.Errorf("Unexpected body:\n%s", payload.Body)
+	}
+	if strings.Contains(payload.Body, "Generated by") {
+		t.Errorf("Expected a reproducible body:\n%s", payload.Body)
+	}
+
+	if body := prCommentBody(&prChanges{Shape: &ShapeChanges{}}, "", o); !strings.Contains(body, "No entity changed since `origin/main`.") {
+		t.Errorf("Expected the body to tell nothing changed:\n%s", body)
+	}
+}
@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ConfigFileName is the configuration file of the named profiles, looked up from the working directory up to the
+// root of the repository like the ignore file.
+const ConfigFileName = ".entmaid.json"
+
+// Config is the configuration file of the profiles: Flags are shared by every profile, and each profile sets its own
+// flags (filters, format, target...) on top of them. The flags are named as on the command line, without the dashes.
+type Config struct {
+	Flags    map[string]any            `json:"flags,omitempty"`
+	Profiles map[string]map[string]any `json:"profiles"`
+}
+
+var (
+	configPath string
+	profiles   []string
+)
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate the diagrams of the named profiles of the configuration file",
+	Long: `Generate the diagrams of the named profiles of the .entmaid.json configuration file, every profile with its
+own flags (filters, format, target...) on top of the flags shared by all of them. Without --profile, every profile is
+generated in the order of their names.
+
+The flags given on the command line take precedence over the ENTMAID_* environment variables, which take precedence
+over the configuration file.`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{jsonResultAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := findConfig()
+		if err != nil {
+			return err
+		}
+
+		names := profiles
+		if len(names) == 0 {
+			names = slices.Sorted(maps.Keys(config.Profiles))
+		}
+
+		return runProfiles(cmd.Flags(), config, names, func(name string) error {
+			return runWithOptions(cmd, func(ctx context.Context, opts []Option) error {
+				return GenerateDiagramContext(ctx, schemaPaths[0], targetPath, targetOutputType(cmd), startPattern, endPattern, opts...)
+			})
+		})
+	},
+}
+
+func init() {
+	generateCmd.Flags().StringVar(&configPath, "config", "", "configuration file of the profiles, instead of the "+ConfigFileName+" of the working directory or its parents up to the repository root")
+	generateCmd.Flags().StringArrayVar(&profiles, "profile", nil, "generate the diagram of this profile of the configuration file, can be repeated (all of them by default)")
+	rootCmd.AddCommand(generateCmd)
+}
+
+// findConfig reads the configuration file of --config, or the one found from the working directory.
+func findConfig() (*Config, error) {
+	path := configPath
+	if path == "" {
+		var err error
+		if path, err = findRepoFile(ConfigFileName); err != nil {
+			return nil, err
+		}
+		if path == "" {
+			return nil, fmt.Errorf("%w: no %s file found, pass --config", errUsage, ConfigFileName)
+		}
+	}
+
+	return readConfig(path)
+}
+
+// readConfig parses the configuration file.
+func readConfig(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the configuration file: %w", err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, inFile(path, fmt.Errorf("%w: invalid configuration file %s: %w", errUsage, path, err))
+	}
+
+	if len(config.Profiles) == 0 {
+		return nil, inFile(path, fmt.Errorf("%w: the configuration file %s has no profile", errUsage, path))
+	}
+
+	return &config, nil
+}
+
+// runProfiles runs every named profile with its flags set, restoring the flags between the profiles. The flags
+// changed on the command line or by the environment are left as they are.
+func runProfiles(flags *pflag.FlagSet, config *Config, names []string, run func(name string) error) error {
+	profiles := newProfileFlags(flags)
+
+	for _, name := range names {
+		if _, ok := config.Profiles[name]; !ok {
+			return fmt.Errorf("%w: unknown profile %q, the profiles are %v", errUsage, name, slices.Sorted(maps.Keys(config.Profiles)))
+		}
+
+		err := profiles.apply(config, name)
+		if err == nil {
+			err = run(name)
+		}
+		if err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// profileFlags sets the flags of the profiles one after the other, restoring the flags to their value before the
+// first profile in between.
+type profileFlags struct {
+	flags    *pflag.FlagSet
+	defaults map[string][]string
+}
+
+func newProfileFlags(flags *pflag.FlagSet) *profileFlags {
+	defaults := make(map[string][]string)
+	flags.VisitAll(func(flag *pflag.Flag) {
+		defaults[flag.Name] = flagValues(flag)
+	})
+
+	return &profileFlags{flags: flags, defaults: defaults}
+}
+
+// apply restores the flags that weren't changed on the command line or by the environment and sets the shared flags
+// of the configuration file, and the flags of the named profile unless the name is empty. A nil configuration only
+// restores the flags.
+func (p *profileFlags) apply(config *Config, name string) error {
+	p.flags.VisitAll(func(flag *pflag.Flag) {
+		if !flag.Changed {
+			_ = setFlagValues(flag, p.defaults[flag.Name])
+		}
+	})
+
+	if config == nil {
+		return nil
+	}
+
+	values := []map[string]any{config.Flags}
+	if name != "" {
+		profile, ok := config.Profiles[name]
+		if !ok {
+			return fmt.Errorf("%w: unknown profile %q, the profiles are %v", errUsage, name, slices.Sorted(maps.Keys(config.Profiles)))
+		}
+		values = append(values, profile)
+	}
+
+	for _, values := range values {
+		if err := applyConfigFlags(p.flags, values); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyConfigFlags sets the flags that weren't changed on the command line or by the environment to their value in
+// the configuration file: a string, number or boolean, or a list of them for the flags that can be repeated.
+func applyConfigFlags(flags *pflag.FlagSet, values map[string]any) error {
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		flag := flags.Lookup(name)
+		if flag == nil {
+			return fmt.Errorf("%w: unknown flag %q", errUsage, name)
+		}
+		if flag.Changed {
+			continue
+		}
+
+		var strings []string
+		switch value := values[name].(type) {
+		case []any:
+			for _, item := range value {
+				s, ok := configValue(item)
+				if !ok {
+					return fmt.Errorf("%w: invalid value %v of the flag %q", errUsage, item, name)
+				}
+				strings = append(strings, s)
+			}
+		default:
+			s, ok := configValue(value)
+			if !ok {
+				return fmt.Errorf("%w: invalid value %v of the flag %q", errUsage, value, name)
+			}
+			strings = []string{s}
+		}
+
+		if err := setFlagValues(flag, strings); err != nil {
+			return fmt.Errorf("%w: invalid value of the flag %q: %w", errUsage, name, err)
+		}
+	}
+
+	return nil
+}
+
+// configValue returns the value of the configuration file as it would be given on the command line.
+func configValue(value any) (string, bool) {
+	switch value := value.(type) {
+	case string:
+		return value, true
+	case bool:
+		return strconv.FormatBool(value), true
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
+
+// flagValues returns the current value of the flag, the values of the flags that can be repeated.
+func flagValues(flag *pflag.Flag) []string {
+	if slice, ok := flag.Value.(pflag.SliceValue); ok {
+		return slices.Clone(slice.GetSlice())
+	}
+
+	return []string{flag.Value.String()}
+}
+
+// setFlagValues sets the flag to the values without marking it as changed, replacing the values of the flags that
+// can be repeated.
+func setFlagValues(flag *pflag.Flag, values []string) error {
+	if slice, ok := flag.Value.(pflag.SliceValue); ok {
+		return slice.Replace(values)
+	}
+
+	if len(values) != 1 {
+		return fmt.Errorf("the flag can't be repeated")
+	}
+
+	return flag.Value.Set(values[0])
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestRunProfiles(t *testing.T) {
+	flags := pflag.NewFlagSet("entmaid", pflag.ContinueOnError)
+	target := flags.String("target", "./ent/erd.md", "")
+	title := flags.String("title", "", "")
+	header := flags.Bool("header", false, "")
+	entities := flags.StringArray("entity", nil, "")
+
+	if err := flags.Parse([]string{"--title", "from the flag"}); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &Config{
+		Flags: map[string]any{"header": true, "title": "from the config"},
+		Profiles: map[string]map[string]any{
+			"billing-detail": {"target": "docs/billing.md", "entity": []any{"Invoice", "Payment"}},
+			"full":           {"target": "docs/full.md", "header": false},
+		},
+	}
+
+	type run struct {
+		target   string
+		title    string
+		header   bool
+		entities []string
+	}
+
+	var runs []run
+	err := runProfiles(flags, config, []string{"billing-detail", "full"}, func(name string) error {
+		runs = append(runs, run{*target, *title, *header, *entities})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []run{
+		{"docs/billing.md", "from the flag", true, []string{"Invoice", "Payment"}},
+		{"docs/full.md", "from the flag", false, []string{}},
+	}
+	if !reflect.DeepEqual(runs, expected) {
+		t.Errorf("Got the runs %+v, expected %+v", runs, expected)
+	}
+
+	if err := runProfiles(flags, config, []string{"overview"}, func(string) error { return nil }); !errors.Is(err, errUsage) {
+		t.Errorf("Expected a usage error for an unknown profile, got %v", err)
+	}
+
+	config.Profiles["full"]["page-size"] = 40
+	if err := runProfiles(flags, config, []string{"full"}, func(string) error { return nil }); !errors.Is(err, errUsage) {
+		t.Errorf("Expected a usage error for an unknown flag, got %v", err)
+	}
+}
+
+func TestReadConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, ConfigFileName)
+	if err := os.WriteFile(path, []byte(`{"profiles": {"overview": {"hide-ids": true}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := readConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]any{"hide-ids": true}; !reflect.DeepEqual(config.Profiles["overview"], expected) {
+		t.Errorf("Got the profile %v, expected %v", config.Profiles["overview"], expected)
+	}
+
+	for _, content := range []string{`{"profiles": {}}`, `{"profiles": [`} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readConfig(path); !errors.Is(err, errUsage) {
+			t.Errorf("Expected a usage error for %s, got %v", content, err)
+		}
+	}
+}
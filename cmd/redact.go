@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"slices"
+)
+
+// Redaction makes the diagram safe to publish, it's applied to the model once everything else is done so the same
+// flags draw the internal diagram and its public variant.
+type Redaction struct {
+	// Drop are the patterns of the entities left out of the diagram (e.g. "Audit*"), as in path.Match, along with
+	// their relationships and join tables.
+	Drop []string `json:"drop,omitempty"`
+	// Rename draws the entities under other names, by their name.
+	Rename map[string]string `json:"rename,omitempty"`
+	// StripComments leaves out everything drawn in the comments of the attributes (the defaults, classifications,
+	// immutability, JSON fields and global IDs) along with the descriptions of the fields and the links to the
+	// schema files and documentation.
+	StripComments bool `json:"stripComments,omitempty"`
+}
+
+// WithRedaction applies the redaction to the model of the diagram, see Redaction.
+func WithRedaction(redaction Redaction) Option {
+	return func(o *options) {
+		o.redaction = &redaction
+	}
+}
+
+// readRedaction reads the redaction from a JSON file, checking its patterns.
+func readRedaction(redactionPath string) (*Redaction, error) {
+	content, err := os.ReadFile(redactionPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the redaction file: %w", err)
+	}
+
+	var redaction Redaction
+	if err := json.Unmarshal(content, &redaction); err != nil {
+		return nil, inFile(redactionPath, fmt.Errorf("%w: invalid redaction file %s: %w", errUsage, redactionPath, err))
+	}
+
+	for _, pattern := range redaction.Drop {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return nil, inFile(redactionPath, fmt.Errorf("%w: invalid pattern %q in %s", errUsage, pattern, redactionPath))
+		}
+	}
+
+	return &redaction, nil
+}
+
+// redactModel returns the model with the entities of the redaction dropped, renamed and their comments stripped.
+func redactModel(model *Model, redaction *Redaction, o *options) *Model {
+	var names []string
+	dropped := make(map[string]bool)
+	for _, entity := range model.Entities {
+		if slices.ContainsFunc(redaction.Drop, func(pattern string) bool {
+			matched, _ := path.Match(pattern, entity.Name)
+			return matched
+		}) {
+			dropped[entity.Name] = true
+			o.skipEntity(entity.Name, "dropped by the redaction")
+		} else if !entity.JoinTable {
+			names = append(names, entity.Name)
+		}
+	}
+
+	// The join tables are dropped along with one of their sides, or on their own.
+	redacted := filterEntities(model, names)
+	redacted.Entities = slices.DeleteFunc(redacted.Entities, func(entity *Entity) bool {
+		return dropped[entity.Name]
+	})
+	redacted.Relationships = slices.DeleteFunc(redacted.Relationships, func(rel *Relationship) bool {
+		return dropped[rel.From] || dropped[rel.To]
+	})
+
+	renamed := make(map[string]string, len(redaction.Rename))
+	for _, name := range slices.Sorted(maps.Keys(redaction.Rename)) {
+		entity, newName := redacted.Entity(name), redaction.Rename[name]
+		switch {
+		case entity == nil && model.Entity(name) == nil:
+			o.warn(name, "", "is not an entity of the schema, it can't be renamed")
+		case entity == nil:
+		case redacted.Entity(newName) != nil:
+			o.warn(name, "", "the entity can't be renamed %s, an entity already has this name", newName)
+		default:
+			// The display name would give the original name away.
+			entity.Name, entity.DisplayName, renamed[name] = newName, "", newName
+		}
+	}
+	renameRelationships(redacted, renamed)
+
+	if redaction.StripComments {
+		for _, entity := range redacted.Entities {
+			entity.URL, entity.Source, entity.Link = "", nil, ""
+			for _, attribute := range entity.Attributes {
+				attribute.Default, attribute.Immutable, attribute.Classification = "", false, ""
+				attribute.Fields, attribute.GlobalID, attribute.comment = nil, nil, ""
+			}
+		}
+	}
+
+	return redacted
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRedactModel(t *testing.T) {
+	o := newOptions([]Option{WithQuiet(), WithRedaction(Redaction{
+		Drop:          []string{"Order?ine"},
+		Rename:        map[string]string{"Customer": "Client", "Order": "Client", "Missing": "Other"},
+		StripComments: true,
+	})})
+
+	model, err := loadModel(context.Background(), "../examples/annotations/schema", o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if names, expected := entityNames(model.Entities), []string{"Client", "Order"}; !slices.Equal(names, expected) {
+		t.Errorf("Expected the entities %v, got %v", expected, names)
+	}
+	for _, rel := range model.Relationships {
+		if model.Entity(rel.From) == nil || model.Entity(rel.To) == nil {
+			t.Errorf("The relationship %s-%s is to a redacted entity", rel.From, rel.To)
+		}
+	}
+	if email := model.Entity("Client").Attribute("email"); email == nil || email.Classification != "" {
+		t.Errorf("Expected the classification of the email to be stripped: %+v", email)
+	}
+	if len(o.warnings) != 2 {
+		t.Errorf("Expected warnings about the taken and unknown names, got %v", o.warnings)
+	}
+	if len(o.skipped) != 1 || o.skipped[0].Entity != "OrderLine" {
+		t.Errorf("Expected OrderLine to be skipped, got %v", o.skipped)
+	}
+}
+
+func TestReadRedaction(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"valid.json":   `{"drop": ["Audit*"], "rename": {"User": "Member"}, "stripComments": true}`,
+		"pattern.json": `{"drop": ["[Audit"]}`,
+		"invalid.json": `{"drop": "Audit*"}`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	redaction, err := readRedaction(filepath.Join(dir, "valid.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(redaction.Drop, []string{"Audit*"}) || redaction.Rename["User"] != "Member" || !redaction.StripComments {
+		t.Errorf("Unexpected redaction %+v", redaction)
+	}
+
+	for _, name := range []string{"pattern.json", "invalid.json", "missing.json"} {
+		if _, err := readRedaction(filepath.Join(dir, name)); err == nil {
+			t.Errorf("Expected %s to be rejected", name)
+		}
+	}
+}
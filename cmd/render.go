@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ReadModel reads the model written by the json format, e.g. a snapshot of the schema pasted in the playground.
+func ReadModel(r io.Reader) (*Model, error) {
+	var model Model
+	if err := json.NewDecoder(r).Decode(&model); err != nil {
+		return nil, fmt.Errorf("%w: invalid model: %w", errUsage, err)
+	}
+
+	return &model, nil
+}
+
+// Render writes the model in the format without loading the schema or accessing any file, for the tools embedding
+// the renderers like the js/wasm build of the playground. Only the options of the rendering apply (e.g.
+// WithNotation or WithMermaidVersion), the ones shaping the model while the schema is loaded (e.g. WithEntities)
+// don't. The svg format and the plugins, which run other programs, can't be rendered this way.
+func Render(ctx context.Context, w io.Writer, model *Model, format Format, opts ...Option) error {
+	render, ok := renderers[format]
+	if !ok || format == FormatSVG {
+		return fmt.Errorf("%w %q", ErrUnknownFormat, format)
+	}
+
+	o := newOptions(opts)
+	describeDiagram(model, o)
+
+	if err := render(ctx, w, model, o); err != nil {
+		return fmt.Errorf("%w as %s: %w", ErrRender, format, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRenderModelSnapshot(t *testing.T) {
+	snapshot, err := os.Open("testdata/fixtures/start/json.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer snapshot.Close()
+
+	model, err := ReadModel(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := os.ReadFile("testdata/fixtures/start/mermaid.mmd")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var builder strings.Builder
+	if err := Render(context.Background(), &builder, model, FormatMermaid, WithReproducible()); err != nil {
+		t.Fatal(err)
+	}
+	if builder.String() != string(expected) {
+		t.Errorf("Expected the snapshot to render as the loaded schema:\n%s", builder.String())
+	}
+
+	if err := Render(context.Background(), &builder, model, FormatSVG); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Expected the svg format to be refused, got %v", err)
+	}
+	if _, err := ReadModel(strings.NewReader("{")); !errors.Is(err, errUsage) {
+		t.Errorf("Expected an invalid snapshot to be refused, got %v", err)
+	}
+}
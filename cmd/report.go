@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// WithReport writes a JSON RunReport of what the run did to the file at path once it's done, even when it fails,
+// for the tools wrapping entmaid.
+func WithReport(path string) Option {
+	return func(o *options) {
+		o.reportPath = path
+	}
+}
+
+// WithResultJSON prints the RunReport of the run to w as a line of JSON once it's done, even when it fails, instead
+// of the message telling the outcome of the run, for the wrappers and editors parsing it.
+func WithResultJSON(w io.Writer) Option {
+	return func(o *options) {
+		o.resultJSON = w
+	}
+}
+
+// Outcome is what a run of GenerateDiagram did to the diagram.
+type Outcome string
+
+const (
+	// OutcomeGenerated is the outcome of a run generating the diagram.
+	OutcomeGenerated Outcome = "generated"
+	// OutcomeUpToDate is the outcome of a run checking the diagram is up to date.
+	OutcomeUpToDate Outcome = "up-to-date"
+	// OutcomeUnchanged is the outcome of a run skipped by WithSkipUnchanged.
+	OutcomeUnchanged Outcome = "unchanged"
+	// OutcomeError is the outcome of a failed run, along with its error.
+	OutcomeError Outcome = "error"
+)
+
+// outcomeMessages are the messages printed for the outcomes.
+var outcomeMessages = map[Outcome]string{
+	OutcomeGenerated: "Mermaid file generated successfully.",
+	OutcomeUpToDate:  "Mermaid diagram is up to date.",
+	OutcomeUnchanged: "Mermaid diagram is unchanged.",
+}
+
+// RunReport describes a run of GenerateDiagram.
+type RunReport struct {
+	Outcome Outcome `json:"outcome"`
+	Check   bool    `json:"check"`
+	// Modified lists the files written by the run, the unchanged ones are left out.
+	Modified []ReportFile `json:"modified"`
+	// Entities are the names of the entities drawn in the diagram.
+	Entities []string        `json:"entities"`
+	Skipped  []SkippedEntity `json:"skipped"`
+	Warnings []Warning       `json:"warnings"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// ReportFile is a file written by the run.
+type ReportFile struct {
+	Path  string `json:"path"`
+	Bytes int    `json:"bytes"`
+}
+
+// SkippedEntity is an entity of the schema left out of the diagram.
+type SkippedEntity struct {
+	Entity string `json:"entity"`
+	Reason string `json:"reason"`
+}
+
+// newRunReport builds the report of the run, the model is nil when it couldn't be loaded.
+func newRunReport(model *Model, err error, o *options) *RunReport {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	report := &RunReport{
+		Outcome:  o.outcome,
+		Check:    o.check,
+		Modified: append([]ReportFile{}, o.written...),
+		Entities: []string{},
+		Skipped:  append([]SkippedEntity{}, o.skipped...),
+		Warnings: append([]Warning{}, o.warnings...),
+	}
+
+	if model != nil {
+		for _, entity := range model.Entities {
+			report.Entities = append(report.Entities, entity.Name)
+		}
+	}
+
+	if err != nil {
+		report.Outcome = OutcomeError
+		report.Error = err.Error()
+	}
+
+	return report
+}
+
+func writeReport(path string, report *RunReport) error {
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, append(content, '\n'), 0o644); err != nil {
+		return inFile(path, fmt.Errorf("%w: failed to write the report: %w", ErrTarget, err))
+	}
+
+	return nil
+}
+
+// writeResultJSON prints the report as a line of JSON, so the results of several runs (e.g. of a batch) are JSON Lines.
+func writeResultJSON(w io.Writer, report *RunReport) error {
+	return json.NewEncoder(w).Encode(report)
+}
+
+// printOutcome records the outcome of the run and prints its message, unless quiet or printing the result as JSON.
+// The message is left out when show is false, e.g. when the document is written to stdout.
+func printOutcome(outcome Outcome, show bool, o *options) {
+	o.outcome = outcome
+	if show && !o.quiet && o.resultJSON == nil {
+		fmt.Println(outcomeMessages[outcome])
+	}
+}
+
+// writeSkipped prints a summary of the entities left out of the diagram, a line per reason in the order they were
+// first skipped for it.
+func writeSkipped(w io.Writer, skipped []SkippedEntity) error {
+	var reasons []string
+	entities := make(map[string][]string)
+	for _, s := range skipped {
+		if _, ok := entities[s.Reason]; !ok {
+			reasons = append(reasons, s.Reason)
+		}
+		entities[s.Reason] = append(entities[s.Reason], s.Entity)
+	}
+
+	if _, err := fmt.Fprintf(w, "skipped %s:\n", pluralize(len(skipped), "entity", "entities")); err != nil {
+		return err
+	}
+
+	for _, reason := range reasons {
+		if _, err := fmt.Fprintf(w, "  %s: %s\n", reason, strings.Join(entities[reason], ", ")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
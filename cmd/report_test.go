@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestReport(t *testing.T) {
+	dir := t.TempDir()
+	reportPath := filepath.Join(dir, "report.json")
+	jsonPath := filepath.Join(dir, "schema.json")
+
+	err := GenerateDiagram("../examples/start/schema", "", Markdown, "", "", WithQuiet(), WithReport(reportPath),
+		WithEntities("User", "Missing"), WithOutput(FormatJSON, jsonPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := readReport(t, reportPath)
+
+	info, err := os.Stat(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(report.Modified, []ReportFile{{Path: jsonPath, Bytes: int(info.Size())}}) {
+		t.Errorf("Expected the JSON output to be reported as modified, got %v", report.Modified)
+	}
+
+	if !reflect.DeepEqual(report.Entities, []string{"User"}) {
+		t.Errorf("Expected the drawn entities, got %v", report.Entities)
+	}
+
+	if len(report.Skipped) != 3 || report.Skipped[0].Reason != "not drawn with --entity" {
+		t.Errorf("Expected the unselected entities to be reported as skipped, got %v", report.Skipped)
+	}
+
+	if len(report.Warnings) != 1 || report.Warnings[0].Entity != "Missing" {
+		t.Errorf("Expected the warning about the missing entity, got %v", report.Warnings)
+	}
+}
+
+func TestReportError(t *testing.T) {
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+
+	err := GenerateDiagram("../examples/start/schema", filepath.Join(t.TempDir(), "missing.md"), Markdown, "", "", WithQuiet(), WithReport(reportPath), WithCheck())
+	if !errors.Is(err, ErrTarget) {
+		t.Fatalf("Expected the missing target to fail, got %v", err)
+	}
+
+	if report := readReport(t, reportPath); report.Error != err.Error() || !report.Check || len(report.Modified) != 0 {
+		t.Errorf("Expected the report to hold the error, got %+v", report)
+	}
+}
+
+func TestResultJSON(t *testing.T) {
+	targetPath := filepath.Join(t.TempDir(), "README.md")
+	if err := os.WriteFile(targetPath, []byte("<!-- #start:entmaid -->\n<!-- #end:entmaid -->\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var result strings.Builder
+	for _, opts := range [][]Option{nil, {WithCheck()}} {
+		err := GenerateDiagram("../examples/start/schema", targetPath, Markdown, "", "", append(opts, WithResultJSON(&result))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := GenerateDiagram("../examples/start/schema", filepath.Join(t.TempDir(), "missing.md"), Markdown, "", "", WithResultJSON(&result), WithCheck())
+	if !errors.Is(err, ErrTarget) {
+		t.Fatalf("Expected the missing target to fail, got %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(result.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a line of JSON per run, got:\n%s", result.String())
+	}
+
+	reports := make([]RunReport, len(lines))
+	for i, line := range lines {
+		if err := json.Unmarshal([]byte(line), &reports[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if reports[0].Outcome != OutcomeGenerated || len(reports[0].Modified) != 1 || reports[0].Modified[0].Path != targetPath {
+		t.Errorf("Expected the generated target, got %+v", reports[0])
+	}
+	if reports[1].Outcome != OutcomeUpToDate || !reports[1].Check || len(reports[1].Modified) != 0 {
+		t.Errorf("Expected the target to be up to date, got %+v", reports[1])
+	}
+	if reports[2].Outcome != OutcomeError || reports[2].Error != err.Error() {
+		t.Errorf("Expected the failed run to hold the error, got %+v", reports[2])
+	}
+}
+
+// jsonResultCLI sets --json and the output of the results for the test.
+func jsonResultCLI(t *testing.T) *strings.Builder {
+	t.Helper()
+
+	var result strings.Builder
+	previousOutput, previousTarget, previousSchemas := resultOutput, targetPath, schemaPaths
+	t.Cleanup(func() {
+		resultOutput, targetPath, schemaPaths, jsonResult = previousOutput, previousTarget, previousSchemas, false
+		rootCmd.SilenceUsage = false
+		rootCmd.SetArgs(nil)
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.PersistentFlags().Lookup("json").Changed = false
+	})
+	resultOutput, jsonResult = &resultWriter{w: &result}, true
+
+	return &result
+}
+
+// readResult reads the single line of JSON printed by --json.
+func readResult(t *testing.T, result *strings.Builder) *RunReport {
+	t.Helper()
+
+	if strings.Count(result.String(), "\n") != 1 {
+		t.Fatalf("Expected a single line of JSON, got:\n%s", result.String())
+	}
+
+	report := &RunReport{}
+	if err := json.Unmarshal([]byte(result.String()), report); err != nil {
+		t.Fatal(err)
+	}
+
+	return report
+}
+
+func TestResultJSONUsageError(t *testing.T) {
+	result := jsonResultCLI(t)
+	targetPath, schemaPaths = StdioTarget, []string{"../examples/start/schema"}
+
+	err := runWithOptions(rootCmd, func(ctx context.Context, opts []Option) error {
+		t.Fatal("Didn't expect the run to start")
+		return nil
+	})
+	if !errors.Is(err, errUsage) {
+		t.Fatalf("Expected --json to be refused with the target '-', got %v", err)
+	}
+
+	if report := readResult(t, result); report.Outcome != OutcomeError || !strings.Contains(report.Error, "--json") {
+		t.Errorf("Expected the usage error as the result, got %+v", report)
+	}
+}
+
+func TestResultJSONFlagError(t *testing.T) {
+	result := jsonResultCLI(t)
+
+	var stderr strings.Builder
+	rootCmd.SetArgs([]string{"--json", "--no-such-flag"})
+	rootCmd.SetOut(&stderr)
+	rootCmd.SetErr(&stderr)
+
+	err := rootCmd.Execute()
+	if !errors.Is(err, errUsage) {
+		t.Fatalf("Expected the unknown flag to be a usage error, got %v", err)
+	}
+	if strings.Contains(stderr.String(), "Usage:") {
+		t.Errorf("Expected the usage to be left out with --json, got:\n%s", stderr.String())
+	}
+
+	// Execute prints the error when nothing was printed yet.
+	if resultOutput.printed {
+		t.Fatalf("Didn't expect a result before Execute prints it, got:\n%s", result.String())
+	}
+	if err := writeErrorResult(err); err != nil {
+		t.Fatal(err)
+	}
+	if report := readResult(t, result); report.Outcome != OutcomeError || !strings.Contains(report.Error, "no-such-flag") {
+		t.Errorf("Expected the flag error as the result, got %+v", report)
+	}
+}
+
+func TestResultJSONUnsupportedCommand(t *testing.T) {
+	jsonResultCLI(t)
+
+	for _, command := range []*cobra.Command{statsCmd, doctorCmd, exportSiteCmd, selfUpdateCmd} {
+		if err := rootCmd.PersistentPreRunE(command, nil); !errors.Is(err, errUsage) {
+			t.Errorf("Expected --json to be refused by %s, got %v", command.CommandPath(), err)
+		}
+	}
+
+	for _, command := range []*cobra.Command{rootCmd, generateCmd, batchCmd} {
+		if err := rootCmd.PersistentPreRunE(command, nil); err != nil {
+			t.Errorf("Expected --json to be supported by %s, got %v", command.CommandPath(), err)
+		}
+	}
+}
+
+func readReport(t *testing.T, path string) *RunReport {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := &RunReport{}
+	if err := json.Unmarshal(content, report); err != nil {
+		t.Fatal(err)
+	}
+
+	return report
+}
+
+func TestWriteSkipped(t *testing.T) {
+	var skipped []SkippedEntity
+	o := newOptions([]Option{WithQuiet(), WithEntities("User"), WithSkipHandler(func(s SkippedEntity) {
+		skipped = append(skipped, s)
+	})})
+	if _, err := loadModel(context.Background(), "../examples/start/schema", o); err != nil {
+		t.Fatal(err)
+	}
+
+	var builder strings.Builder
+	if err := writeSkipped(&builder, append(skipped, SkippedEntity{Entity: "Session", Reason: "ignored by .entmaidignore"})); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "skipped 4 entities:\n  not drawn with --entity: Car, Group, group_users\n  ignored by .entmaidignore: Session\n"
+	if builder.String() != expected {
+		t.Errorf("Got %q, expected %q", builder.String(), expected)
+	}
+}
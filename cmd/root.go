@@ -1,8 +1,21 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
 
+	"entgo.io/ent/entc"
 	"github.com/spf13/cobra"
 	"github.com/thediveo/enumflag/v2"
 )
@@ -12,47 +25,845 @@ type OutputType enumflag.Flag
 const (
 	Markdown OutputType = iota
 	Plain
+	// Hugo wraps the diagram in the mermaid shortcode of Hugo themes.
+	Hugo
+	// Obsidian fences the diagram as an Obsidian note expects it, with a tilde fence that doesn't clash with the
+	// backtick fences of the note.
+	Obsidian
+	// GoDoc places the diagram in the package documentation of a Go file (e.g. doc.go), as a code block of its doc
+	// comment shown by pkg.go.dev, the default for Go targets.
+	GoDoc
 )
 
 var OutputTypeIds = map[OutputType][]string{
 	Markdown: {"markdown"},
 	Plain:    {"plain"},
+	Hugo:     {"hugo"},
+	Obsidian: {"obsidian"},
+	GoDoc:    {"godoc"},
+}
+
+// isMarkdown reports whether the output type is placed in a Markdown document, which can hold tables and links
+// next to the diagram.
+func (t OutputType) isMarkdown() bool {
+	return t != Plain && t != GoDoc
+}
+
+// targetOutputType returns the --outputType, or GoDoc for a Go target when it isn't set.
+func targetOutputType(cmd *cobra.Command) OutputType {
+	if !cmd.Flags().Changed("outputType") && strings.EqualFold(filepath.Ext(targetPath), ".go") {
+		return GoDoc
+	}
+
+	return outputType
 }
 
 var (
-	schemaPath   string
+	schemaPaths  []string
 	targetPath   string
 	startPattern string
 	endPattern   string
 	outputType   OutputType
+
+	header          bool
+	headerTimestamp bool
+	reproducible    bool
+	check           bool
+	createMarkers   bool
+	allMarkers      bool
+	warningsJSON    bool
+	strict          bool
+	verbosity       int
+	quiet           bool
+	jsonResult      bool
+	timeout         time.Duration
+	outputs         []string
+	rawOutput       bool
+	mmdcPath        string
+	cache           bool
+	cacheDir        string
+	gitAddFiles     bool
+	skipUnchanged   bool
+	errorFormat     ErrorFormat
+	linkTemplate    string
+	typeAliases     []string
+	features        []string
+	maxTypeLength   int
+	maxNameLength   int
+	maxFields       int
+	sizeBudget      int
+	pageSize        int
+	legend          bool
+	groupBySchema   bool
+	domainSource    DomainSource
+	domains         []string
+	analyze         bool
+	lint            []string
+	lintMaxColumns  int
+	lintOverlay     bool
+	pruneOrphans    bool
+	stubHubs        int
+	warnEntities    int
+	failEntities    int
+	edgeLabels      []string
+	displayNames    []string
+	entityLinks     []string
+	verbLabels      bool
+	forwardLabels   bool
+	multiplicities  bool
+	fkLabels        bool
+	validate        bool
+	mergeEdges      bool
+	guards          bool
+	hideIDs         bool
+	mixins          bool
+	arrows          bool
+	noIgnore        bool
+	detailedTypes   bool
+	nullability     bool
+	globalIDs       bool
+	expandJSON      bool
+	partial         bool
+	goldenPath      string
+	goldenUpdate    bool
+	reportPath      string
+	ignoreFile      string
+	mermaidVersion  MermaidVersion
+	databaseDialect Dialect
+	notation        Notation
+	entityOrder     EntityOrder
+	fieldOrder      FieldOrder
+	weightBy        RelationshipWeight
+	typeStyle       TypeStyle
+	noTypePackages  bool
+	summary         SummaryPosition
+	visibility      Visibility
+	redactPath      string
+	localePath      string
+	graphJSON       string
+	entSnapshotPath string
+	changedSince    string
+	followEdges     []string
+	noFollowEdges   []string
+	prComment       string
+	confluenceMacro string
+	confluenceURL   string
+	confluencePage  string
+	notionPage      string
+	webhookURL      string
+	lineageNS       string
+	compactEdges    bool
+	joinTableNames  []string
+	shortJoinTables bool
+	onlyEdges       []string
+	noEdges         []string
+	diagramIndex    bool
+	title           string
+	description     string
+	fencePrefix     string
+	fenceSuffix     string
+	entities        []string
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "entmaid",
 	Short: "A CLI for generating a mermaid.js Entity Relationship (ER) diagram for an Ent Schema, without needing a live database!",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		if err := GenerateDiagram(schemaPath, targetPath, outputType, startPattern, endPattern); err != nil {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := applyEnv(cmd.Flags(), os.LookupEnv); err != nil {
 			return err
 		}
 
+		if jsonResult {
+			// The usage printed along with the errors would be mixed with the result.
+			cmd.Root().SilenceUsage = true
+			if cmd.Annotations[jsonResultAnnotation] == "" {
+				return fmt.Errorf("%w: --json isn't supported by '%s'", errUsage, cmd.CommandPath())
+			}
+		}
+
 		return nil
 	},
+	Annotations: map[string]string{jsonResultAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWithOptions(cmd, func(ctx context.Context, opts []Option) error {
+			if skipUnchanged {
+				// The flags set on the command line are the settings the diagram is generated with.
+				opts = append(opts, WithSkipUnchanged(strings.Join(hookArgs(cmd), "\x00")))
+			}
+
+			return GenerateDiagramContext(ctx, schemaPaths[0], targetPath, targetOutputType(cmd), startPattern, endPattern, opts...)
+		})
+	},
+}
+
+// jsonResultAnnotation marks the commands printing the results of their runs with --json.
+const jsonResultAnnotation = "entmaid-json-result"
+
+// resultWriter is where --json prints the results, recording whether any was printed.
+type resultWriter struct {
+	w       io.Writer
+	printed bool
+}
+
+func (r *resultWriter) Write(p []byte) (int, error) {
+	r.printed = true
+	return r.w.Write(p)
+}
+
+var resultOutput = &resultWriter{w: os.Stdout}
+
+// writeErrorResult prints the result of a command failing before any of its runs printed one with --json, e.g. on
+// the wrong flags.
+func writeErrorResult(err error) error {
+	o := newOptions(nil)
+	o.check = check
+
+	return writeResultJSON(resultOutput, newRunReport(nil, err, o))
+}
+
+// runWithOptions runs the command with the options built from the flags, bounding it by the timeout and reporting
+// any warnings once it's done.
+func runWithOptions(cmd *cobra.Command, run func(ctx context.Context, opts []Option) error) (err error) {
+	// The run prints its result by itself, the wrong flags keep it from starting.
+	running := false
+	if jsonResult {
+		defer func() {
+			if err != nil && !running {
+				err = errors.Join(err, writeErrorResult(err))
+			}
+		}()
+	}
+
+	if len(schemaPaths) == 0 {
+		return fmt.Errorf("%w: at least one schema is required", errUsage)
+	}
+
+	var opts []Option
+	for _, schemaPath := range schemaPaths[1:] {
+		opts = append(opts, WithSchema(schemaPath))
+	}
+	if header || headerTimestamp {
+		opts = append(opts, WithHeader(headerTimestamp))
+	}
+	if reproducible {
+		if headerTimestamp {
+			return fmt.Errorf("%w: --header-timestamp can't be used with --reproducible", errUsage)
+		}
+		opts = append(opts, WithReproducible())
+	}
+	if check {
+		opts = append(opts, WithCheck())
+	}
+	if createMarkers {
+		opts = append(opts, WithCreateMarkers())
+	}
+	if allMarkers {
+		opts = append(opts, WithAllMarkers())
+	}
+	if strict {
+		opts = append(opts, WithStrict())
+	}
+	if validate {
+		if notation != NotationCrowsFoot {
+			return fmt.Errorf("%w: --validate only checks the crow's foot notation", errUsage)
+		}
+		opts = append(opts, WithValidate())
+	}
+	if goldenPath != "" {
+		opts = append(opts, WithGolden(goldenPath, goldenUpdate))
+	} else if goldenUpdate {
+		return fmt.Errorf("%w: --update requires a --golden snapshot", errUsage)
+	}
+	if reportPath != "" {
+		opts = append(opts, WithReport(reportPath))
+	}
+	if noIgnore && ignoreFile != "" {
+		return fmt.Errorf("%w: --ignore-file can't be used with --no-ignore", errUsage)
+	}
+	if !noIgnore {
+		path := ignoreFile
+		if path == "" {
+			var err error
+			if path, err = findRepoFile(IgnoreFileName); err != nil {
+				return err
+			}
+		}
+		if path != "" {
+			opts = append(opts, WithIgnoreFile(path))
+		}
+	}
+	if partial {
+		opts = append(opts, WithPartial())
+	}
+	if hideIDs {
+		opts = append(opts, WithHideIDs())
+	}
+	if guards {
+		opts = append(opts, WithGuards())
+	}
+	if mergeEdges {
+		opts = append(opts, WithMergeEdges())
+	}
+	if compactEdges {
+		opts = append(opts, WithCompactEdgeSchemas())
+	}
+	for _, joinTableName := range joinTableNames {
+		table, name, ok := strings.Cut(joinTableName, "=")
+		if !ok || table == "" || name == "" {
+			return fmt.Errorf("%w: join table name %q must be given as table=name", errUsage, joinTableName)
+		}
+		opts = append(opts, WithJoinTableName(table, name))
+	}
+	if shortJoinTables {
+		opts = append(opts, WithShortJoinTables())
+	}
+	for _, names := range []struct {
+		names  []string
+		option func(...EdgeKind) Option
+	}{{onlyEdges, WithOnlyEdges}, {noEdges, WithoutEdges}} {
+		for _, name := range names.names {
+			kind, ok := ParseEdgeKind(name)
+			if !ok {
+				return fmt.Errorf("%w: unknown edge kind %q, expected o2o, o2m, m2o or m2m", errUsage, name)
+			}
+			opts = append(opts, names.option(kind))
+		}
+	}
+	if quiet {
+		opts = append(opts, WithQuiet())
+	}
+	opts = append(opts, WithLogger(newLogger(verbosity, quiet)))
+	if jsonResult {
+		if targetPath == StdioTarget {
+			return fmt.Errorf("%w: --json prints the result to stdout, it can't be used with the target '-'", errUsage)
+		}
+		if errorFormat == ErrorFormatGitHub {
+			return fmt.Errorf("%w: --json prints the result to stdout, it can't be used with --format-errors github", errUsage)
+		}
+		opts = append(opts, WithResultJSON(resultOutput))
+	}
+
+	if rawOutput {
+		if targetPath == "" || targetPath == StdioTarget {
+			return fmt.Errorf("%w: --raw is written next to the target file, use --output mermaid=path without one", errUsage)
+		}
+		opts = append(opts, WithRawOutput())
+	}
+	for _, out := range outputs {
+		name, path, ok := strings.Cut(out, "=")
+		format := Format(name)
+		if !ok {
+			// A path alone is written in the format of its extension.
+			if format, ok = PathFormat(out); !ok {
+				return fmt.Errorf("%w: output %q must be given as format=path, or as a path with the extension of a format", errUsage, out)
+			}
+			path = out
+		}
+		if path == "" {
+			return fmt.Errorf("%w: output %q must be given as format=path", errUsage, out)
+		}
+		opts = append(opts, WithOutput(format, path))
+	}
+	for _, typeAlias := range typeAliases {
+		goType, alias, ok := strings.Cut(typeAlias, "=")
+		if !ok || goType == "" || alias == "" {
+			return fmt.Errorf("%w: type alias %q must be given as type=alias", errUsage, typeAlias)
+		}
+		opts = append(opts, WithTypeAlias(goType, alias))
+	}
+	if detailedTypes {
+		opts = append(opts, WithDetailedTypes())
+	}
+	if nullability {
+		opts = append(opts, WithNullability())
+	}
+	if globalIDs {
+		opts = append(opts, WithGlobalIDs())
+	}
+	if expandJSON {
+		opts = append(opts, WithExpandJSON())
+	}
+	for _, edgeLabel := range edgeLabels {
+		edge, label, ok := strings.Cut(edgeLabel, "=")
+		if !ok || edge == "" || label == "" {
+			return fmt.Errorf("%w: edge label %q must be given as edge=label", errUsage, edgeLabel)
+		}
+		opts = append(opts, WithEdgeLabel(edge, label))
+	}
+	for _, displayName := range displayNames {
+		entity, name, ok := strings.Cut(displayName, "=")
+		if !ok || entity == "" || name == "" {
+			return fmt.Errorf("%w: display name %q must be given as Entity=name", errUsage, displayName)
+		}
+		opts = append(opts, WithDisplayName(entity, name))
+	}
+	for _, entityLink := range entityLinks {
+		entity, url, ok := strings.Cut(entityLink, "=")
+		if !ok || entity == "" || url == "" {
+			return fmt.Errorf("%w: entity link %q must be given as Entity=url", errUsage, entityLink)
+		}
+		opts = append(opts, WithEntityLink(entity, url))
+	}
+	if verbLabels {
+		opts = append(opts, WithVerbLabels())
+	}
+	if forwardLabels {
+		opts = append(opts, WithForwardLabels())
+	}
+	if multiplicities {
+		opts = append(opts, WithMultiplicityLabels())
+	}
+	if fkLabels {
+		opts = append(opts, WithForeignKeyLabels())
+	}
+	if maxTypeLength > 0 {
+		opts = append(opts, WithMaxTypeLength(maxTypeLength))
+	}
+	if maxNameLength > 0 {
+		opts = append(opts, WithMaxNameLength(maxNameLength))
+	}
+	if maxFields > 0 {
+		opts = append(opts, WithMaxFields(maxFields))
+	}
+	if sizeBudget > 0 {
+		opts = append(opts, WithSizeBudget(sizeBudget))
+	}
+	if pageSize > 0 {
+		opts = append(opts, WithPageSize(pageSize))
+	}
+	if title != "" {
+		opts = append(opts, WithTitle(title))
+	}
+	if description != "" {
+		opts = append(opts, WithDescription(description))
+	}
+	if fencePrefix != "" || fenceSuffix != "" {
+		opts = append(opts, WithFence(fencePrefix, fenceSuffix))
+	}
+	if len(entities) > 0 {
+		opts = append(opts, WithEntities(entities...))
+	}
+	if changedSince != "" {
+		opts = append(opts, WithChangedSince(changedSince))
+	}
+	if (len(followEdges) > 0 || len(noFollowEdges) > 0) && changedSince == "" {
+		return fmt.Errorf("%w: --follow-edges and --no-follow-edges require --changed-since", errUsage)
+	}
+	for _, name := range followEdges {
+		kind, ok := ParseEdgeKind(name)
+		if !ok {
+			return fmt.Errorf("%w: unknown edge kind %q, expected o2o, o2m, m2o or m2m", errUsage, name)
+		}
+		opts = append(opts, WithFollowEdges(kind))
+	}
+	if len(noFollowEdges) > 0 {
+		opts = append(opts, WithoutFollowingEdges(noFollowEdges...))
+	}
+	if prComment != "" {
+		if changedSince == "" {
+			return fmt.Errorf("%w: --pr-comment requires --changed-since", errUsage)
+		}
+		if prComment == StdioTarget && targetPath == StdioTarget {
+			return fmt.Errorf("%w: --pr-comment and --target can't both be written to stdout", errUsage)
+		}
+		opts = append(opts, WithPRComment(prComment))
+	}
+	if legend {
+		opts = append(opts, WithLegend())
+	}
+	if domainSource != DomainNone {
+		opts = append(opts, WithDomains(domainSource))
+	}
+	for _, domain := range domains {
+		pattern, name, ok := strings.Cut(domain, "=")
+		if _, err := path.Match(pattern, ""); !ok || err != nil || pattern == "" || name == "" {
+			return fmt.Errorf("%w: domain %q must be given as pattern=domain", errUsage, domain)
+		}
+		opts = append(opts, WithDomain(pattern, name))
+	}
+	if groupBySchema {
+		opts = append(opts, WithGroupBySchema())
+	}
+	if analyze {
+		opts = append(opts, WithAnalyze())
+	}
+	for _, name := range lint {
+		if name == "all" {
+			opts = append(opts, WithLint())
+			continue
+		}
+
+		rule, ok := ParseLintRule(name)
+		if !ok {
+			return fmt.Errorf("%w: unknown lint rule %q, expected all, back-refs, indexes, columns or naming", errUsage, name)
+		}
+		opts = append(opts, WithLint(rule))
+	}
+	if lintMaxColumns > 0 {
+		opts = append(opts, WithLintMaxColumns(lintMaxColumns))
+	}
+	if lintOverlay {
+		opts = append(opts, WithLintOverlay())
+	}
+	if pruneOrphans {
+		opts = append(opts, WithPruneOrphans())
+	}
+	if stubHubs > 0 {
+		opts = append(opts, WithStubHubs(stubHubs))
+	}
+	if warnEntities > 0 || failEntities > 0 {
+		opts = append(opts, WithEntityLimits(warnEntities, failEntities))
+	}
+	if mermaidVersion != Mermaid11 {
+		opts = append(opts, WithMermaidVersion(mermaidVersion))
+	}
+	if databaseDialect != DialectAny {
+		opts = append(opts, WithDialect(databaseDialect))
+	}
+	if notation != NotationCrowsFoot {
+		opts = append(opts, WithNotation(notation))
+	}
+	if mixins {
+		if notation != NotationUML {
+			return fmt.Errorf("%w: --mixins is only drawn with --notation uml", errUsage)
+		}
+		opts = append(opts, WithMixins())
+	}
+	if arrows {
+		if notation != NotationUML {
+			return fmt.Errorf("%w: --arrows is only drawn with --notation uml", errUsage)
+		}
+		opts = append(opts, WithArrows())
+	}
+	if entityOrder != OrderName {
+		opts = append(opts, WithSort(entityOrder))
+	}
+	if fieldOrder != FieldOrderSchema {
+		opts = append(opts, WithFieldOrder(fieldOrder))
+	}
+	if weightBy != WeightNone {
+		opts = append(opts, WithWeightBy(weightBy))
+	}
+	if typeStyle != TypeStyleLower {
+		opts = append(opts, WithTypeStyle(typeStyle))
+	}
+	if noTypePackages {
+		opts = append(opts, WithoutTypePackages())
+	}
+	if visibility != VisibilityPrivate {
+		opts = append(opts, WithVisibility(visibility))
+	}
+	if redactPath != "" {
+		redaction, err := readRedaction(redactPath)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, WithRedaction(*redaction))
+	}
+	if graphJSON != "" {
+		if cmd.Flags().Changed("schema") {
+			return fmt.Errorf("%w: --graph-json replaces loading the --schema, they can't be used together", errUsage)
+		}
+		opts = append(opts, WithGraphSnapshot(graphJSON))
+	}
+	if entSnapshotPath != "" {
+		if cmd.Flags().Changed("schema") || graphJSON != "" {
+			return fmt.Errorf("%w: --ent-snapshot replaces loading the --schema, it can't be used with it or --graph-json", errUsage)
+		}
+		opts = append(opts, WithEntSnapshot(entSnapshotPath))
+	}
+	if localePath != "" {
+		locale, err := readLocale(localePath)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, WithLocale(*locale))
+	}
+	if summary != SummaryNone {
+		opts = append(opts, WithSummary(summary))
+	}
+	if confluenceMacro != "" {
+		opts = append(opts, WithConfluenceMacro(confluenceMacro))
+	}
+	if confluenceURL != "" || confluencePage != "" {
+		if confluenceURL == "" || confluencePage == "" {
+			return fmt.Errorf("%w: --confluence-url and --confluence-page must be given together", errUsage)
+		}
+		opts = append(opts, WithConfluencePage(ConfluencePage{
+			BaseURL: confluenceURL,
+			ID:      confluencePage,
+			User:    os.Getenv("CONFLUENCE_USER"),
+			Token:   os.Getenv("CONFLUENCE_TOKEN"),
+		}))
+	}
+	if notionPage != "" {
+		token := os.Getenv("NOTION_TOKEN")
+		if token == "" {
+			return fmt.Errorf("%w: --notion-page requires the token of a Notion integration in $NOTION_TOKEN", errUsage)
+		}
+		opts = append(opts, WithNotionPage(NotionPage{ID: notionPage, Token: token}))
+	}
+	if webhookURL != "" {
+		if !check || notation != NotationCrowsFoot {
+			return fmt.Errorf("%w: --webhook requires --check and the crow's foot notation", errUsage)
+		}
+		opts = append(opts, WithWebhook(webhookURL))
+	}
+	if diagramIndex {
+		if !targetOutputType(cmd).isMarkdown() || targetPath == "" || targetPath == StdioTarget {
+			return fmt.Errorf("%w: --index requires a Markdown target file", errUsage)
+		}
+		opts = append(opts, WithIndex())
+	}
+	if lineageNS != "" {
+		opts = append(opts, WithLineageNamespace(lineageNS))
+	}
+	if mmdcPath != "" {
+		opts = append(opts, WithMermaidCLI(mmdcPath))
+	}
+	if cache || cacheDir != "" {
+		opts = append(opts, WithCache(cacheDir))
+	}
+	if gitAddFiles {
+		opts = append(opts, WithGitAdd())
+	}
+	if linkTemplate != "" {
+		opts = append(opts, WithLinks(linkTemplate))
+	}
+	if len(features) > 0 {
+		for _, feature := range features {
+			if !slices.Contains(featureNames(), feature) {
+				return fmt.Errorf("%w: unknown feature %q, expected one of %s", errUsage, feature, strings.Join(featureNames(), ", "))
+			}
+		}
+		opts = append(opts, WithEntcOptions(entc.FeatureNames(features...)))
+	}
+
+	var warnings []Warning
+	opts = append(opts, WithWarningHandler(func(w Warning) {
+		warnings = append(warnings, w)
+	}))
+
+	var skipped []SkippedEntity
+	opts = append(opts, WithSkipHandler(func(s SkippedEntity) {
+		skipped = append(skipped, s)
+	}))
+
+	ctx := cmd.Context()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	running = true
+	err = run(ctx, opts)
+
+	switch {
+	case warningsJSON || (len(warnings) > 0 && !quiet && errorFormat == ErrorFormatText):
+		if werr := writeWarnings(os.Stderr, warnings, warningsJSON); werr != nil && err == nil {
+			err = werr
+		}
+	case len(warnings) > 0 && !quiet:
+		if werr := writeGitHubWarnings(os.Stdout, warnings); werr != nil && err == nil {
+			err = werr
+		}
+	}
+
+	// The summary answers why an entity isn't drawn, it can't be mixed with the warnings as JSON.
+	if len(skipped) > 0 && !quiet && !warningsJSON {
+		if werr := writeSkipped(os.Stderr, skipped); werr != nil && err == nil {
+			err = werr
+		}
+	}
+
+	return err
+}
+
+// newLogger logs to stderr at a level based on the number of -v flags, only errors are logged when quiet.
+func newLogger(verbosity int, quiet bool) *slog.Logger {
+	level := slog.LevelWarn
+	switch {
+	case quiet:
+		level = slog.LevelError
+	case verbosity == 1:
+		level = slog.LevelInfo
+	case verbosity > 1:
+		level = slog.LevelDebug
+	}
+
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
 }
 
 func Execute() {
-	err := rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	// The first signal lets the run finish the files it's writing and clean up, a second one stops it right away.
+	context.AfterFunc(ctx, stop)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+
+	awaitLoads(shutdownGrace, newLogger(verbosity, quiet))
+
 	if err != nil {
-		os.Exit(1)
+		switch {
+		case jsonResult && !resultOutput.printed:
+			_ = writeErrorResult(err)
+		case errorFormat == ErrorFormatGitHub && !jsonResult:
+			_ = writeGitHubErrors(os.Stdout, err)
+		}
+
+		os.Exit(ExitCode(err))
 	}
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringVarP(&schemaPath, "schema", "s", "./ent/schema", "directory containing the schemas")
-	rootCmd.PersistentFlags().StringVarP(&targetPath, "target", "t", "./ent/erd.md", "target file to output diagram")
-	rootCmd.PersistentFlags().StringVar(&startPattern, "startPattern", "<!-- #start:entmaid -->", "target directory for schemas")
-	rootCmd.PersistentFlags().StringVar(&endPattern, "endPattern", "<!-- #end:entmaid -->", "target directory for schemas")
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		if jsonResult {
+			cmd.Root().SilenceUsage = true
+		}
+
+		return fmt.Errorf("%w: %w", errUsage, err)
+	})
+
+	rootCmd.PersistentFlags().StringArrayVarP(&schemaPaths, "schema", "s", []string{"./ent/schema"}, "directory or Go import path of the schemas, can be repeated to merge several into one diagram")
+	rootCmd.PersistentFlags().StringVarP(&targetPath, "target", "t", "./ent/erd.md", "target file to output diagram ('-' to read it from stdin and write it to stdout, empty to only write the --output files)")
+	rootCmd.PersistentFlags().StringVar(&startPattern, "startPattern", "", "pattern marking the start of the diagram in the target (default based on the target's extension, e.g. \"<!-- #start:entmaid -->\" for Markdown)")
+	rootCmd.PersistentFlags().StringVar(&endPattern, "endPattern", "", "pattern marking the end of the diagram in the target (default based on the target's extension, e.g. \"<!-- #end:entmaid -->\" for Markdown)")
 	rootCmd.PersistentFlags().VarP(
 		enumflag.New(&outputType, "outputType", OutputTypeIds, enumflag.EnumCaseSensitive),
 		"outputType", "o",
-		"set the desired output type: can be 'markdown' (useful for GitHub), 'plain'")
+		"set the desired output type: can be 'markdown' (useful for GitHub), 'plain', 'hugo' (mermaid shortcode), 'obsidian', 'godoc' (doc comment of a Go file, the default for .go targets)")
+	rootCmd.PersistentFlags().Var(
+		enumflag.New(&mermaidVersion, "mermaid-version", MermaidVersionIds, enumflag.EnumCaseSensitive),
+		"mermaid-version",
+		"major version of the Mermaid renderer to emit syntax for: can be '11', '10' (for older renderers bundled in wikis and IDEs)")
+	rootCmd.PersistentFlags().Var(
+		enumflag.New(&databaseDialect, "dialect", DialectIds, enumflag.EnumCaseSensitive),
+		"dialect",
+		"database to show the column types of: can be 'any', 'postgres' (real array columns), 'mysql' or 'sqlite', preferring its SchemaType")
+	rootCmd.PersistentFlags().Var(
+		enumflag.New(&notation, "notation", NotationIds, enumflag.EnumCaseSensitive),
+		"notation",
+		"notation of the relationships in the Mermaid diagram: can be 'crowsfoot' (erDiagram), 'uml' (classDiagram with 0..1, 1, 0..* and 1..* multiplicities)")
+	rootCmd.PersistentFlags().Var(
+		enumflag.New(&entityOrder, "sort", EntityOrderIds, enumflag.EnumCaseSensitive),
+		"sort",
+		"order the entities are declared in, which Mermaid lays them out by: can be 'name', 'degree' (most relationships first), 'topo' (referenced entities first)")
+	rootCmd.PersistentFlags().Var(
+		enumflag.New(&fieldOrder, "field-order", FieldOrderIds, enumflag.EnumCaseSensitive),
+		"field-order",
+		"order the attributes of the entities are listed in: can be 'schema' (as declared), 'name', 'keys' (primary keys, then foreign keys, then the others)")
+	rootCmd.PersistentFlags().Var(
+		enumflag.New(&weightBy, "weight-by", RelationshipWeightIds, enumflag.EnumCaseSensitive),
+		"weight-by",
+		"what the width of the relationships not annotated with a weight is derived from in the drawio and graphml outputs: can be 'none', 'edges' (relationships between the same entities), 'multiplicity' (many sides)")
+	rootCmd.PersistentFlags().Var(
+		enumflag.New(&typeStyle, "type-style", TypeStyleIds, enumflag.EnumCaseSensitive),
+		"type-style",
+		"how the types of the attributes are written: can be 'lower' (SQL-like, e.g. varchar(255)), 'upper' (SQL, e.g. VARCHAR(255)), 'go' (the Go types, e.g. time.Time)")
+	rootCmd.PersistentFlags().BoolVar(&noTypePackages, "no-type-packages", false, "drop the package qualifiers of the custom Go types, e.g. Period for schema.Period")
+	rootCmd.PersistentFlags().Var(
+		enumflag.New(&summary, "summary", SummaryPositionIds, enumflag.EnumCaseSensitive),
+		"summary",
+		"place a one-line summary of the number of entities and relationships in the target: can be 'none', 'above' or 'below' the diagram")
+	rootCmd.PersistentFlags().StringVar(&title, "title", "", "title of the diagram, set in its front matter (a comment for --mermaid-version 10)")
+	rootCmd.PersistentFlags().StringVar(&description, "description", "", "description of the diagram, written as a comment block at its top")
+	rootCmd.PersistentFlags().StringVar(&fencePrefix, "fence-prefix", "", "line placed before the diagram in the target instead of the fence of the --outputType, a template using {{.Type}} and {{.Title}} (e.g. ':::mermaid')")
+	rootCmd.PersistentFlags().StringVar(&fenceSuffix, "fence-suffix", "", "line placed after the diagram in the target instead of the fence of the --outputType, a template like --fence-prefix (e.g. ':::')")
+	rootCmd.PersistentFlags().StringArrayVar(&entities, "entity", nil, "only draw this entity and its relationships to the other drawn ones, can be repeated (pick them interactively with 'entmaid tui')")
+	rootCmd.PersistentFlags().StringVar(&changedSince, "changed-since", "", "only draw the entities of the schema files changed since this git ref (e.g. origin/main) and the entities related to them, for pull requests")
+	rootCmd.PersistentFlags().StringSliceVar(&followEdges, "follow-edges", nil, "with --changed-since, only draw the entities related to the changed ones through the relationships of these kinds: o2o, o2m (or m2o) and m2m, can be repeated or comma separated")
+	rootCmd.PersistentFlags().StringSliceVar(&noFollowEdges, "no-follow-edges", nil, "with --changed-since, don't draw the entities related to the changed ones only through these edges (name or Entity.name, of the edge or its back-reference), e.g. audit_logs, can be repeated or comma separated")
+	rootCmd.PersistentFlags().StringVar(&prComment, "pr-comment", "", "with --changed-since, write a Markdown comment for the pull request to this file ('-' for stdout) listing the changes since the ref above the diagram, as the {\"body\": ...} payload of the GitHub and GitLab APIs for .json files")
+	rootCmd.PersistentFlags().BoolVar(&rawOutput, "raw", false, "also write the raw Mermaid code of the diagram next to the target, e.g. README.mmd for README.md, identical to the diagram of the target")
+	rootCmd.PersistentFlags().StringArrayVar(&outputs, "output", nil, "also write the whole diagram to a file as format=path, or as a path in the format of its extension (e.g. erd.dbml), can be repeated (formats: "+strings.Join(Formats(), ", ")+", or any other rendered by an entmaid-<format> executable in the PATH)")
+	rootCmd.PersistentFlags().StringVar(&lineageNS, "lineage-namespace", "", "namespace of the datasets of the openlineage format, the data source of the tables (e.g. postgres://db.acme.com:5432, default \"ent\")")
+	rootCmd.PersistentFlags().StringArrayVar(&typeAliases, "type-alias", nil, "show the fields of a Go type as written in the schema (e.g. pgtype.Range) as another type, given as type=alias, can be repeated ('other' sets the type of field.Other fields without a SchemaType)")
+	rootCmd.PersistentFlags().BoolVar(&detailedTypes, "detailed-types", false, "show the size and precision of the columns, e.g. varchar(255) for a MaxLen or the numeric(10,2) SchemaType of any field, and list the check constraints")
+	rootCmd.PersistentFlags().BoolVar(&nullability, "nullability", false, "append NOT NULL or NULL to the comments of the attributes, e.g. with --detailed-types for a reference of the column constraints")
+	rootCmd.PersistentFlags().BoolVar(&globalIDs, "global-ids", false, "load the schema with the sql/globalid feature and note the range of IDs of every entity in the global ID space in the comment of its primary key")
+	rootCmd.PersistentFlags().BoolVar(&expandJSON, "expand-json", false, "list the exported fields of the Go struct held by field.JSON columns (one level deep) in the comment of the column")
+	rootCmd.PersistentFlags().StringSliceVar(&features, "feature", nil, "ent feature to load the schema with like entc's --feature flag, as enabled in generate.go (e.g. sql/upsert), can be repeated or comma separated")
+	rootCmd.PersistentFlags().StringArrayVar(&edgeLabels, "edge-label", nil, "label the relationships of an edge (name or Entity.name, of the edge or its back-reference) with a verb, given as edge=label, can be repeated")
+	rootCmd.PersistentFlags().StringArrayVar(&entityLinks, "entity-link", nil, "link an entity to the page documenting it (e.g. 'User=https://catalog.example.com/users'), '*' links every other entity and the URL can contain {entity} and {table}, can be repeated")
+	rootCmd.PersistentFlags().StringArrayVar(&displayNames, "display-name", nil, "label an entity with a business-friendly name (e.g. 'PurchaseOrder=Purchase Order') in the diagram, its relationships keep its name, can be repeated")
+	rootCmd.PersistentFlags().BoolVar(&verbLabels, "verb-labels", false, "label the relationships without an --edge-label with a verb derived from the edge name (e.g. 'has blog posts')")
+	rootCmd.PersistentFlags().BoolVar(&forwardLabels, "forward-labels", false, "label the relationships without an --edge-label by their forward edge name only, without the '-ref' suffix of the back-reference")
+	rootCmd.PersistentFlags().BoolVar(&multiplicities, "multiplicity-labels", false, "append the cardinalities of the relationships to their labels as text, e.g. 'cars-owner (0..1 to 0..*)', for readers unfamiliar with crow's foot")
+	rootCmd.PersistentFlags().BoolVar(&fkLabels, "fk-labels", false, "append the foreign key column realizing the relationships to their labels, e.g. 'posts-author (user_posts)'")
+	rootCmd.PersistentFlags().IntVar(&maxTypeLength, "max-type-length", 0, "abbreviate attribute types longer than this in the Mermaid diagram, expanding them in comments below it (0 for no limit)")
+	rootCmd.PersistentFlags().IntVar(&pageSize, "page-size", 0, "split the diagram of the target into numbered diagrams of at most this many entities, with stubs for the related entities of other diagrams (0 for no limit)")
+	rootCmd.PersistentFlags().IntVar(&maxNameLength, "max-name-length", 0, "abbreviate attribute names longer than this in the Mermaid diagram, expanding them in comments below it (0 for no limit)")
+	rootCmd.PersistentFlags().IntVar(&maxFields, "max-fields", 0, "list at most this many attributes of every entity in the Mermaid diagram, always keeping the keys, with a row counting the others (0 for no limit)")
+	rootCmd.PersistentFlags().IntVar(&sizeBudget, "size-budget", 0, "fit the Mermaid code of every diagram of the target in this many bytes, eliding its comments, then its relationship labels, then its attributes but the keys as needed, warning about what was elided (0 for no limit)")
+	rootCmd.PersistentFlags().BoolVar(&legend, "legend", false, "append a legend of the cardinalities, keys and abbreviations used by the diagram to the target")
+	rootCmd.PersistentFlags().BoolVar(&groupBySchema, "group-by-schema", false, "order the entities by the database schema they're stored in (from entsql.Schema annotations), or by their --domains")
+	rootCmd.PersistentFlags().Var(
+		enumflag.New(&domainSource, "domains", DomainSourceIds, enumflag.EnumCaseSensitive),
+		"domains",
+		"infer the domains of the entities, grouped by instead of their database schema: can be 'none', 'prefix' (first word of their name shared by several), 'package' (directory of their --schema)")
+	rootCmd.PersistentFlags().StringArrayVar(&domains, "domain", nil, "put the entities matched by a pattern in a domain, given as pattern=domain (e.g. 'Billing*=billing'), overriding the inferred one, can be repeated")
+	rootCmd.PersistentFlags().Var(
+		enumflag.New(&visibility, "visibility", VisibilityIds, enumflag.EnumCaseSensitive),
+		"visibility",
+		"audience of the diagram, drawing the entities and fields annotated as visible to it: 'public' ones only, 'internal' and public ones, or 'private' for everything")
+	rootCmd.PersistentFlags().StringVar(&graphJSON, "graph-json", "", "draw the snapshot of the model written by --output json instead of loading the --schema, for builds without the Go module or the ent toolchain")
+	rootCmd.PersistentFlags().StringVar(&entSnapshotPath, "ent-snapshot", "", "draw the schema snapshot of ent's schema/snapshot feature (e.g. ent/internal/schema.go, or the JSON it holds) instead of loading the --schema, for repos vendoring the snapshot but not the schema")
+	rootCmd.PersistentFlags().StringVar(&localePath, "locale", "", "JSON file of the translated labels of the entities and fields (by name or as Entity.field) for the readers of another language")
+	rootCmd.PersistentFlags().StringVar(&redactPath, "redact", "", "JSON file of a redaction for a diagram safe to publish: the entity patterns to drop, the entities to rename and whether to strip the comments and links")
+	rootCmd.PersistentFlags().StringVar(&mmdcPath, "mmdc", "mmdc", "path to the mermaid-cli executable used for svg outputs")
+	rootCmd.PersistentFlags().StringVar(&confluenceMacro, "confluence-macro", "", "name of the Confluence macro the diagram is wrapped in for the confluence format, matching the installed Mermaid app (default is a code block)")
+	rootCmd.PersistentFlags().StringVar(&confluenceURL, "confluence-url", "", "URL of Confluence (e.g. https://acme.atlassian.net/wiki) to push the diagram to, with --confluence-page")
+	rootCmd.PersistentFlags().StringVar(&confluencePage, "confluence-page", "", "ID of the Confluence page whose body is replaced by the diagram, authenticated by $CONFLUENCE_USER and $CONFLUENCE_TOKEN")
+	rootCmd.PersistentFlags().StringVar(&notionPage, "notion-page", "", "ID of the Notion page whose first Mermaid code block is replaced by the diagram (appended when there's none), authenticated by $NOTION_TOKEN")
+	rootCmd.PersistentFlags().StringVar(&webhookURL, "webhook", "", "with --check, post the entities and relationships added to or removed from a stale diagram to this URL (e.g. a Slack incoming webhook)")
+	rootCmd.PersistentFlags().BoolVar(&cache, "cache", false, "reuse the loaded schema from the cache while the schema files are unchanged")
+	rootCmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", "", "directory to cache loaded schemas in (implies --cache, default is the user's cache directory)")
+	rootCmd.PersistentFlags().BoolVar(&gitAddFiles, "git-add", false, "stage the files modified by entmaid in git")
+	rootCmd.Flags().BoolVar(&skipUnchanged, "skip-unchanged", false, "skip the run when the schema directories and the files written by the last run with the same flags didn't change since, tracked in the --cache-dir (the hooks installed by entmaid pass it)")
+	rootCmd.PersistentFlags().StringVar(&linkTemplate, "link-template", "", "link the entities to their schema files with a URL template using {path}, {line}, {entity} and {table}, or 'github' for the origin remote (listed below Markdown diagrams, as notes in DBML and in JSON)")
+	rootCmd.PersistentFlags().BoolVar(&header, "header", false, "prepend a comment with the entmaid version and a hash of the schema to the diagram")
+	rootCmd.PersistentFlags().BoolVar(&reproducible, "reproducible", false, "leave the entmaid version and generation time out of the header and summary, for byte-identical outputs")
+	rootCmd.PersistentFlags().BoolVar(&headerTimestamp, "header-timestamp", false, "include the generation time in the header (implies --header)")
+	rootCmd.PersistentFlags().StringVar(&goldenPath, "golden", "", "compare the whole Mermaid diagram to this golden snapshot file, failing on any change to the rendering")
+	rootCmd.PersistentFlags().BoolVar(&goldenUpdate, "update", false, "rewrite the --golden snapshot instead of comparing the diagram to it")
+	rootCmd.PersistentFlags().StringVar(&reportPath, "report", "", "write a JSON report of the run to this file: the files modified, the entities drawn and skipped, and the warnings")
+	rootCmd.PersistentFlags().StringVar(&ignoreFile, "ignore-file", "", "file of the Entity and Entity.field patterns never drawn, instead of the .entmaidignore of the working directory or its parents up to the repository root")
+	rootCmd.PersistentFlags().BoolVar(&noIgnore, "no-ignore", false, "draw everything, without the patterns of the .entmaidignore file")
+	rootCmd.PersistentFlags().BoolVar(&check, "check", false, "verify the diagram in the target file is up to date instead of writing it")
+	rootCmd.PersistentFlags().BoolVar(&diagramIndex, "index", false, "also list the diagrams of the target with links to them and their entities between the index patterns, e.g. \"<!-- #start-index:entmaid -->\" for Markdown")
+	rootCmd.PersistentFlags().BoolVar(&createMarkers, "create-markers", false, "append the start and end patterns with the diagram to the target file when they are missing")
+	rootCmd.PersistentFlags().BoolVar(&allMarkers, "all-markers", false, "place the diagram between every pair of start and end patterns instead of requiring exactly one")
+	rootCmd.PersistentFlags().BoolVar(&warningsJSON, "warnings-json", false, "print the warnings about skipped schema constructs to stderr as JSON")
+	rootCmd.PersistentFlags().Var(
+		enumflag.New(&errorFormat, "format-errors", ErrorFormatIds, enumflag.EnumCaseSensitive),
+		"format-errors",
+		"how to report errors and warnings: can be 'text', 'github' (also print them as GitHub Actions workflow commands, shown inline on pull requests)")
+	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "log which nodes and edges are processed or skipped, repeat (-vv) for more detail")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "only print errors")
+	rootCmd.PersistentFlags().BoolVar(&jsonResult, "json", false, "print the result of the run to stdout as a line of JSON (its outcome, the modified files, the drawn and skipped entities, the warnings and the error) instead of the success message")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "give up when generating takes longer than this, e.g. 30s (0 for no limit)")
+	rootCmd.PersistentFlags().BoolVar(&analyze, "analyze", false, "warn about entities without relationships and foreign keys referencing each other in a cycle")
+	rootCmd.PersistentFlags().StringSliceVar(&lint, "lint", nil, "warn about the findings of these lint rules: back-refs (edges without a back-reference), indexes (foreign keys without an index), columns (entities with too many columns), naming (names mixing snake_case and camelCase), or all; can be repeated or comma separated")
+	rootCmd.PersistentFlags().IntVar(&lintMaxColumns, "lint-max-columns", DefaultLintMaxColumns, "the number of columns an entity can have before the columns lint rule reports it")
+	rootCmd.PersistentFlags().BoolVar(&lintOverlay, "lint-overlay", false, "draw the lint findings in the Mermaid diagram, as comments below their entity outlined as a warning")
+	rootCmd.PersistentFlags().IntVar(&warnEntities, "warn-if-entities-over", 0, "warn when the diagram, or a page of it with --page-size, draws more entities than this (0 for no limit)")
+	rootCmd.PersistentFlags().IntVar(&failEntities, "fail-if-entities-over", 0, "fail when the diagram, or a page of it with --page-size, draws more entities than this (0 for no limit)")
+	rootCmd.PersistentFlags().BoolVar(&pruneOrphans, "prune-orphans", false, "omit the entities without relationships, including views, from the diagram")
+	rootCmd.PersistentFlags().IntVar(&stubHubs, "stub-hubs", 0, "draw the entities with more relationships than this as dimmed stubs without their attributes, for overview diagrams (0 to draw them all)")
+	rootCmd.PersistentFlags().BoolVar(&validate, "validate", false, "check the Mermaid diagram against the erDiagram grammar before writing anything, failing with the offending line")
+	rootCmd.PersistentFlags().BoolVar(&mergeEdges, "merge-edges", false, "draw the relationships between the same two entities as a single line labeled by all of their edges")
+	rootCmd.PersistentFlags().BoolVar(&compactEdges, "compact-edge-schemas", false, "draw the M2M edges through an edge schema as a direct relationship labeled with its fields, e.g. 'groups-users (role)', instead of drawing the edge schema between them")
+	rootCmd.PersistentFlags().StringArrayVar(&joinTableNames, "join-table-name", nil, "draw the join table of a M2M edge under another name, given as table=name (e.g. group_users=memberships), can be repeated")
+	rootCmd.PersistentFlags().StringSliceVar(&onlyEdges, "only-edges", nil, "only draw the relationships of these kinds: o2o, o2m (or m2o) and m2m, can be repeated or comma separated")
+	rootCmd.PersistentFlags().StringSliceVar(&noEdges, "no-edges", nil, "leave the relationships of these kinds out of the diagram, along with the join tables of the m2m edges, like --only-edges")
+	rootCmd.PersistentFlags().BoolVar(&shortJoinTables, "short-join-tables", false, "draw the join tables named by ent after their edge without the prefix of the entity owning it, e.g. 'users' instead of 'group_users'")
+	rootCmd.PersistentFlags().BoolVar(&guards, "guards", false, "mark the entities guarded by a privacy policy, hooks or interceptors, e.g. with a (policy,hooks) label")
+	rootCmd.PersistentFlags().BoolVar(&hideIDs, "hide-ids", false, "leave the primary key columns out of the Mermaid diagram, keeping the ones that are also foreign keys")
+	rootCmd.PersistentFlags().BoolVar(&mixins, "mixins", false, "draw the mixins as abstract classes with generalization arrows to the entities using them, with --notation uml")
+	rootCmd.PersistentFlags().BoolVar(&arrows, "arrows", false, "draw the relationships as arrows from the entity holding the foreign key to the one it references, with --notation uml")
+	rootCmd.PersistentFlags().BoolVar(&partial, "partial", false, "draw the schemas that still build when some files of the schema package don't compile, warning about the skipped files")
+	rootCmd.PersistentFlags().BoolVar(&strict, "strict", false, "fail without writing the target when any warnings are raised")
+
+	registerCompletions()
 }
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// shutdownGrace is how long exiting waits for the loads abandoned by an interrupted or timed out run to finish and
+// remove their temporary directories, before removing them itself.
+var shutdownGrace = 10 * time.Second
+
+// entcDir is the directory entc builds the loader program in, below the working directory.
+const entcDir = ".entc"
+
+// loadDirs are the temporary directories of the loads in flight.
+var loadDirs sync.Map
+
+// trackLoadDir records the temporary directory of a load until the returned function is called, once the load
+// removed it.
+func trackLoadDir(dir string) func() {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+
+	loadDirs.Store(dir, struct{}{})
+
+	return func() {
+		loadDirs.Delete(dir)
+	}
+}
+
+// awaitLoads waits for the loads left running in the background, as entc can't cancel them, to remove their
+// temporary directories, and removes the ones still there after the grace period. Exiting without it would leave
+// them in the working directory and next to the schema when the run is interrupted, e.g. restarted by a supervisor.
+func awaitLoads(grace time.Duration, logger *slog.Logger) {
+	if loadMu.TryLock() {
+		loadMu.Unlock()
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		loadMu.Lock()
+		loadMu.Unlock()
+		close(done)
+	}()
+
+	logger.Info("waiting for the schema load to clean up", "grace", grace)
+
+	select {
+	case <-done:
+		return
+	case <-time.After(grace):
+	}
+
+	loadDirs.Range(func(dir, _ any) bool {
+		logger.Warn("removing the temporary directory of the abandoned schema load", "dir", dir)
+		if err := os.RemoveAll(dir.(string)); err != nil {
+			logger.Warn("failed to remove the temporary directory", "dir", dir, "error", err)
+		}
+		return true
+	})
+}
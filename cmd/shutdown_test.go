@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAwaitLoads(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "entmaid-partial-1")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	defer trackLoadDir(dir)()
+
+	// Without a load in flight, the directories are left to the loads tracking them.
+	awaitLoads(time.Millisecond, newLogger(0, true))
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("Expected the directory to be kept without a load in flight: %v", err)
+	}
+
+	loadMu.Lock()
+	defer loadMu.Unlock()
+
+	start := time.Now()
+	awaitLoads(50*time.Millisecond, newLogger(0, true))
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected to wait for the load in flight, returned after %s", elapsed)
+	}
+
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected the directory of the abandoned load to be removed, got %v", err)
+	}
+}
+
+func TestAwaitLoadsFinished(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "entmaid-partial-2")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	untrack := trackLoadDir(dir)
+
+	// The load finishes within the grace period, removing its directory by itself.
+	loadMu.Lock()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		untrack()
+		loadMu.Unlock()
+	}()
+
+	awaitLoads(time.Minute, newLogger(0, true))
+
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("Expected the directory to be left to the finished load: %v", err)
+	}
+}
@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// siteIndex is the name of the index page of the site bundle, which Docusaurus and MkDocs serve as the page of
+// the directory.
+const siteIndex = "index.md"
+
+// siteDefaultGroup is the group of the entities without a database schema.
+const siteDefaultGroup = "erd"
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the diagram in a layout other tools consume",
+}
+
+var exportSiteCmd = &cobra.Command{
+	Use:   "site <dir>",
+	Short: "Write a Mermaid file per database schema and an index page for a Docusaurus or MkDocs site",
+	Long: `Write a Mermaid file per database schema (or a single one when the entities aren't stored in several) into the
+directory, along with an index Markdown page with front matter embedding and linking to every diagram.
+
+With --overview the index page only embeds an overview diagram of the entities and their relationships, without
+columns, linking to a page per database schema with its detailed diagram.
+
+With --incremental the diagrams whose entities and relationships didn't change since the last export into the
+directory are neither rendered nor written again, which saves regenerating hundreds of files for a small schema change.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWithOptions(cmd, func(ctx context.Context, opts []Option) error {
+			if siteOverview {
+				opts = append(opts, WithOverview())
+			}
+			if siteIncremental {
+				// The flags set on the command line are the settings the diagrams are rendered with.
+				opts = append(opts, WithIncremental(strings.Join(hookArgs(cmd), "\x00")))
+			}
+
+			return exportSite(ctx, schemaPaths[0], args[0], newOptions(opts))
+		})
+	},
+}
+
+var (
+	siteOverview    bool
+	siteIncremental bool
+)
+
+func init() {
+	exportSiteCmd.Flags().BoolVar(&siteOverview, "overview", false, "embed an overview diagram without columns in the index page, linking to a detailed page per database schema")
+	exportSiteCmd.Flags().BoolVar(&siteIncremental, "incremental", false, "only render and write the diagrams whose entities changed since the last export into the directory, tracked in the --cache-dir")
+	exportCmd.AddCommand(exportSiteCmd)
+	rootCmd.AddCommand(exportCmd)
+}
+
+// WithOverview makes the index page of the site bundle an overview diagram of the entities and their relationships
+// only, with a detailed page per group, as one diagram can't serve both the high-level and the column-level view.
+func WithOverview() Option {
+	return func(o *options) {
+		o.overview = true
+	}
+}
+
+// siteGroup is the part of the model drawn in one diagram of the site.
+type siteGroup struct {
+	name  string
+	model *Model
+}
+
+// exportSite writes the diagram of every group and the index page into the directory, or compares them to the
+// existing files in check mode.
+func exportSite(ctx context.Context, schemaPath string, dir string, o *options) error {
+	model, err := loadModel(ctx, schemaPath, o)
+	if err != nil {
+		return err
+	}
+
+	if !o.check {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return inFile(dir, fmt.Errorf("%w: %w", ErrTarget, err))
+		}
+	}
+
+	groups := siteGroups(model)
+	state := readSiteState(dir, o)
+	exported := make(map[string]bool, len(groups))
+
+	var index strings.Builder
+	index.WriteString("---\ntitle: Entity Relationship Diagrams\ndescription: The entities of the ent schema and their relationships.\n---\n\n")
+	index.WriteString("# Entity Relationship Diagrams\n")
+
+	if o.overview {
+		var builder strings.Builder
+		if err := renderMermaid(ctx, &builder, overviewModel(model), o); err != nil {
+			return fmt.Errorf("%w of the overview: %w", ErrRender, err)
+		}
+
+		index.WriteString("\n" + addMermaidToType(builder.String(), Markdown) + "\n")
+	}
+
+	for _, group := range groups {
+		file, page := siteFileName(group.name)+".mmd", siteFileName(group.name)+".md"
+		files := []string{file}
+		if o.overview {
+			files = append(files, page)
+		}
+
+		var hash string
+		if state != nil {
+			hash = modelHash(group.model, o)
+		}
+		exported[file] = true
+
+		diagram, unchanged := state.unchanged(file, hash, files...)
+		if unchanged {
+			o.logger.Info("diagram is unchanged since the last export", "group", group.name, "path", filepath.Join(dir, file))
+		} else {
+			var builder strings.Builder
+			if err := renderMermaid(ctx, &builder, group.model, o); err != nil {
+				return fmt.Errorf("%w of %s: %w", ErrRender, group.name, err)
+			}
+			diagram = builder.String()
+
+			if err := writeFile(filepath.Join(dir, file), []byte(diagram), string(FormatMermaid), o); err != nil {
+				return err
+			}
+		}
+
+		if o.overview {
+			index.WriteString(fmt.Sprintf("\n## [%s](%s)\n\n", group.name, page))
+			for _, entity := range group.model.Entities {
+				// The join tables are only drawn in the detailed diagrams.
+				if !entity.JoinTable {
+					index.WriteString(fmt.Sprintf("- %s\n", entity.Name))
+				}
+			}
+
+			if !unchanged {
+				var detail strings.Builder
+				detail.WriteString(fmt.Sprintf("---\ntitle: %s\n---\n\n# %s\n\n", group.name, group.name))
+				detail.WriteString(fmt.Sprintf("Back to the [overview](%s), source: [%s](%s)\n\n", siteIndex, file, file))
+				detail.WriteString(addMermaidToType(diagram, Markdown) + "\n")
+
+				if err := writeFile(filepath.Join(dir, page), []byte(detail.String()), "page", o); err != nil {
+					return err
+				}
+			}
+			state.record(file, hash, diagram)
+			continue
+		}
+
+		index.WriteString(fmt.Sprintf("\n## %s\n\n", group.name))
+		for _, entity := range group.model.Entities {
+			index.WriteString(fmt.Sprintf("- %s\n", entity.Name))
+		}
+		index.WriteString(fmt.Sprintf("\nSource: [%s](%s)\n\n", file, file))
+		index.WriteString(addMermaidToType(diagram, Markdown) + "\n")
+		state.record(file, hash, diagram)
+	}
+
+	if err := writeFile(filepath.Join(dir, siteIndex), []byte(index.String()), "index", o); err != nil {
+		return err
+	}
+
+	state.write(exported, o)
+
+	if !o.quiet {
+		if o.check {
+			fmt.Println("Site bundle is up to date.")
+		} else {
+			fmt.Println("Site bundle exported successfully.")
+		}
+	}
+
+	return nil
+}
+
+// siteGroups splits the model by database schema or domain, in the order the schemas are first used. Every group holds the
+// relationships to its entities, so the entities of other groups they're related to are still drawn.
+func siteGroups(model *Model) []*siteGroup {
+	var groups []*siteGroup
+	byName := make(map[string]*siteGroup)
+	groupOf := make(map[string]*siteGroup)
+
+	for _, entity := range model.Entities {
+		name := entity.group()
+		if name == "" {
+			name = siteDefaultGroup
+		}
+
+		group, ok := byName[name]
+		if !ok {
+			group = &siteGroup{name: name, model: &Model{Header: model.Header}}
+			byName[name] = group
+			groups = append(groups, group)
+		}
+
+		group.model.Entities = append(group.model.Entities, entity)
+		groupOf[entity.Name] = group
+	}
+
+	for _, rel := range model.Relationships {
+		from, to := groupOf[rel.From], groupOf[rel.To]
+		if from != nil {
+			from.model.Relationships = append(from.model.Relationships, rel)
+		}
+		if to != nil && to != from {
+			to.model.Relationships = append(to.model.Relationships, rel)
+		}
+	}
+
+	return groups
+}
+
+// overviewModel returns the model without the columns and indexes of the entities, and the join tables of the M2M
+// edges replaced by a relationship between the entities on their sides, with how many rows of the join table each of
+// them has as cardinalities.
+func overviewModel(model *Model) *Model {
+	overview := &Model{Header: model.Header}
+	joinTables := make(map[string][]*Relationship)
+
+	for _, entity := range model.Entities {
+		if entity.JoinTable {
+			joinTables[entity.Name] = nil
+			continue
+		}
+
+		outline := *entity
+		outline.Attributes, outline.Indexes = nil, nil
+		overview.Entities = append(overview.Entities, &outline)
+	}
+
+	for _, rel := range model.Relationships {
+		if sides, ok := joinTables[rel.To]; ok {
+			joinTables[rel.To] = append(sides, rel)
+			// The M2M relationship is drawn once both sides of the join table are known, where the first one was.
+			if len(sides) == 1 {
+				overview.Relationships = append(overview.Relationships, &Relationship{
+					From:            sides[0].From,
+					To:              rel.From,
+					FromCardinality: rel.ToCardinality,
+					ToCardinality:   sides[0].ToCardinality,
+					Label:           sides[0].Label,
+					Edge:            sides[0].Edge,
+					Ref:             sides[0].Ref,
+					kind:            EdgeM2M,
+				})
+			}
+			continue
+		}
+
+		overview.Relationships = append(overview.Relationships, rel)
+	}
+
+	return overview
+}
+
+var siteFileNameRegex = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// siteFileName turns the group name into a file name safe on every OS and in URLs.
+func siteFileName(name string) string {
+	return siteFileNameRegex.ReplaceAllString(strings.ToLower(name), "-")
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportSite(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "erd")
+
+	if err := exportSite(context.Background(), "../examples/multischema/schema", dir, newOptions([]Option{WithQuiet()})); err != nil {
+		t.Fatalf("Failed to export the site: %v", err)
+	}
+
+	billing, err := os.ReadFile(filepath.Join(dir, "billing.mmd"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{` "billing.Invoice" {` + "\n", `"auth.User" |o--o{ "billing.Invoice"`} {
+		if !strings.Contains(string(billing), expected) {
+			t.Errorf("billing.mmd is missing %q:\n%s", expected, billing)
+		}
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, siteIndex))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"---\ntitle: Entity Relationship Diagrams\n", "## auth\n", "[billing.mmd](billing.mmd)", "```mermaid\nerDiagram\n"} {
+		if !strings.Contains(string(index), expected) {
+			t.Errorf("index.md is missing %q:\n%s", expected, index)
+		}
+	}
+
+	if err := exportSite(context.Background(), "../examples/multischema/schema", dir, newOptions([]Option{WithQuiet(), WithCheck()})); err != nil {
+		t.Errorf("Expected the exported site to be up to date, got %v", err)
+	}
+
+	err = exportSite(context.Background(), "../examples/start/schema", dir, newOptions([]Option{WithQuiet(), WithCheck()}))
+	if !errors.Is(err, ErrTarget) {
+		t.Errorf("Expected the missing erd.mmd to fail the check, got %v", err)
+	}
+}
+
+func TestExportSiteOverview(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := exportSite(context.Background(), "../examples/start/schema", dir, newOptions([]Option{WithQuiet(), WithOverview()})); err != nil {
+		t.Fatalf("Failed to export the site: %v", err)
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, siteIndex))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The join table of the M2M edge is replaced by a relationship between its sides.
+	for _, expected := range []string{" User {\n }\n", " Group }o--o{ User : users-groups\n", "## [erd](erd.md)\n"} {
+		if !strings.Contains(string(index), expected) {
+			t.Errorf("index.md is missing %q:\n%s", expected, index)
+		}
+	}
+
+	for _, unexpected := range []string{"group_users", "int id PK"} {
+		if strings.Contains(string(index), unexpected) {
+			t.Errorf("Expected the overview to be without %q:\n%s", unexpected, index)
+		}
+	}
+
+	page, err := os.ReadFile(filepath.Join(dir, "erd.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"Back to the [overview](index.md)", " group_users {\n", "  int id PK\n"} {
+		if !strings.Contains(string(page), expected) {
+			t.Errorf("erd.md is missing %q:\n%s", expected, page)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+)
+
+// WithGraphSnapshot draws the model of the snapshot at path, as written by the json format, instead of loading the
+// schema, so the docs builds without the Go module or the ent toolchain (e.g. air-gapped) draw the same diagram.
+// The options reading the schema files (WithMixins, WithExpandJSON and the package domains) are left to the run
+// writing the snapshot, which holds what they add, and WithHeader keeps the header of the snapshot.
+func WithGraphSnapshot(path string) Option {
+	return func(o *options) {
+		o.graphSnapshot = path
+	}
+}
+
+// readGraphSnapshot reads the model of the snapshot.
+func readGraphSnapshot(snapshotPath string, o *options) (*Model, error) {
+	file, err := os.Open(snapshotPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w from the graph snapshot %s: %w", ErrSchemaLoad, snapshotPath, err)
+	}
+	defer file.Close()
+
+	model, err := ReadModel(file)
+	if err != nil {
+		return nil, inFile(snapshotPath, err)
+	}
+
+	// The visibilities of the annotations aren't part of the JSON model.
+	if o.visibility != VisibilityPrivate {
+		o.warn("", "", "the graph snapshot %s doesn't hold the visibilities, write it with --visibility instead", snapshotPath)
+	}
+
+	o.logger.Info("loaded graph snapshot", "snapshot", snapshotPath, "entities", len(model.Entities))
+
+	return model, nil
+}
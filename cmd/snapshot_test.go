@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateDiagramGraphSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	snapshotPath, mermaidPath := filepath.Join(dir, "schema.json"), filepath.Join(dir, "erd.mmd")
+
+	err := GenerateDiagram("../examples/start/schema", "", Plain, "", "", WithQuiet(), WithHeader(false), WithReproducible(),
+		WithOutput(FormatJSON, snapshotPath), WithOutput(FormatMermaid, mermaidPath))
+	if err != nil {
+		t.Fatalf("Failed to write the snapshot: %v", err)
+	}
+
+	expected, err := os.ReadFile(mermaidPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The schema isn't loaded at all, the missing path proves it.
+	snapshotMermaidPath := filepath.Join(dir, "snapshot.mmd")
+	err = GenerateDiagram("../examples/missing/schema", "", Plain, "", "", WithQuiet(), WithHeader(false), WithReproducible(),
+		WithGraphSnapshot(snapshotPath), WithOutput(FormatMermaid, snapshotMermaidPath))
+	if err != nil {
+		t.Fatalf("Failed to draw the snapshot: %v", err)
+	}
+
+	drawn, err := os.ReadFile(snapshotMermaidPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(drawn) != string(expected) {
+		t.Errorf("Expected the snapshot to be drawn as its schema, with its header:\n%s\nexpected:\n%s", drawn, expected)
+	}
+
+	err = GenerateDiagram("../examples/missing/schema", "", Plain, "", "", WithQuiet(), WithGraphSnapshot(snapshotPath), WithOutput(FormatMermaid, snapshotMermaidPath))
+	if drawn, _ := os.ReadFile(snapshotMermaidPath); err != nil || strings.HasPrefix(string(drawn), "%%") {
+		t.Errorf("Expected the header of the snapshot to be left out without WithHeader, got %v:\n%s", err, drawn)
+	}
+
+	err = GenerateDiagram("../examples/missing/schema", "", Plain, "", "", WithQuiet(), WithGraphSnapshot(filepath.Join(dir, "missing.json")))
+	if !errors.Is(err, ErrSchemaLoad) {
+		t.Errorf("Expected a missing snapshot to fail loading, got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/thediveo/enumflag/v2"
+)
+
+// EntityOrder is the order the entities are declared in the diagram, which Mermaid's layout is sensitive to.
+type EntityOrder enumflag.Flag
+
+const (
+	// OrderName declares the entities by the name of their schema type, as ent loads them, the default.
+	OrderName EntityOrder = iota
+	// OrderDegree declares the entities with the most relationships first, so the hubs are laid out first.
+	OrderDegree
+	// OrderTopo declares the entities referenced by foreign keys before the entities holding them.
+	OrderTopo
+)
+
+var EntityOrderIds = map[EntityOrder][]string{
+	OrderName:   {"name"},
+	OrderDegree: {"degree"},
+	OrderTopo:   {"topo"},
+}
+
+// WithSort declares the entities in the order, the join tables are always declared after the other entities.
+func WithSort(order EntityOrder) Option {
+	return func(o *options) {
+		o.sort = order
+	}
+}
+
+// sortEntities orders the entities of the model, the ties are kept in the order ent loads them.
+func sortEntities(model *Model, order EntityOrder) {
+	switch order {
+	case OrderDegree:
+		degrees := make(map[string]int)
+		for _, rel := range model.Relationships {
+			degrees[rel.From]++
+			degrees[rel.To]++
+		}
+
+		sort.SliceStable(model.Entities, func(i, j int) bool {
+			return degrees[model.Entities[i].Name] > degrees[model.Entities[j].Name]
+		})
+	case OrderTopo:
+		model.Entities = topoSortEntities(model)
+	}
+}
+
+// topoSortEntities returns the entities with the ones referenced by foreign keys first, in load order among the
+// entities whose references are all declared. The entities of a cycle follow in load order once nothing else can be.
+func topoSortEntities(model *Model) []*Entity {
+	references := make(map[string]map[string]bool)
+	for _, rel := range model.Relationships {
+		fk := rel.ForeignKey
+		if fk == nil || fk.Entity == fk.RefEntity {
+			continue
+		}
+
+		if references[fk.Entity] == nil {
+			references[fk.Entity] = make(map[string]bool)
+		}
+		references[fk.Entity][fk.RefEntity] = true
+	}
+
+	declared := make(map[string]bool, len(model.Entities))
+	sorted := make([]*Entity, 0, len(model.Entities))
+
+	for len(sorted) < len(model.Entities) {
+		progress := false
+		for _, entity := range model.Entities {
+			if declared[entity.Name] || !referencesDeclared(references[entity.Name], declared) {
+				continue
+			}
+
+			declared[entity.Name] = true
+			sorted = append(sorted, entity)
+			progress = true
+		}
+
+		// Only cycles are left, the first of their entities is declared to break them.
+		if !progress {
+			for _, entity := range model.Entities {
+				if !declared[entity.Name] {
+					declared[entity.Name] = true
+					sorted = append(sorted, entity)
+					break
+				}
+			}
+		}
+	}
+
+	return sorted
+}
+
+func referencesDeclared(references map[string]bool, declared map[string]bool) bool {
+	for name := range references {
+		if !declared[name] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FieldOrder is the order the attributes of the entities are listed in.
+type FieldOrder enumflag.Flag
+
+const (
+	// FieldOrderSchema lists the attributes in the order the schema declares them, after the ID, the default.
+	FieldOrderSchema FieldOrder = iota
+	// FieldOrderName lists the attributes by their name.
+	FieldOrderName
+	// FieldOrderKeys lists the primary keys first, then the foreign keys and then the other attributes, each in the
+	// order the schema declares them, so the relationships are easier to trace.
+	FieldOrderKeys
+)
+
+var FieldOrderIds = map[FieldOrder][]string{
+	FieldOrderSchema: {"schema"},
+	FieldOrderName:   {"name"},
+	FieldOrderKeys:   {"keys"},
+}
+
+// WithFieldOrder lists the attributes of every entity in the order.
+func WithFieldOrder(order FieldOrder) Option {
+	return func(o *options) {
+		o.fieldOrder = order
+	}
+}
+
+// sortAttributes orders the attributes of every entity of the model, the ties are kept in the order of the schema.
+func sortAttributes(model *Model, order FieldOrder) {
+	for _, entity := range model.Entities {
+		switch order {
+		case FieldOrderName:
+			sort.SliceStable(entity.Attributes, func(i, j int) bool {
+				return entity.Attributes[i].Name < entity.Attributes[j].Name
+			})
+		case FieldOrderKeys:
+			sort.SliceStable(entity.Attributes, func(i, j int) bool {
+				return keyRank(entity.Attributes[i]) < keyRank(entity.Attributes[j])
+			})
+		}
+	}
+}
+
+// keyRank ranks the primary keys before the foreign keys before the other attributes.
+func keyRank(attribute *Attribute) int {
+	switch {
+	case slices.Contains(attribute.Keys, "PK"):
+		return 0
+	case slices.Contains(attribute.Keys, "FK"):
+		return 1
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func entityNames(entities []*Entity) []string {
+	names := make([]string, len(entities))
+	for i, entity := range entities {
+		names[i] = entity.Name
+	}
+
+	return names
+}
+
+func TestSortEntities(t *testing.T) {
+	newModel := func() *Model {
+		return &Model{
+			Entities: []*Entity{{Name: "Car"}, {Name: "Group"}, {Name: "Tag"}, {Name: "User"}},
+			Relationships: []*Relationship{
+				{From: "User", To: "Car", ForeignKey: &ForeignKey{Entity: "Car", RefEntity: "User"}},
+				{From: "Group", To: "User", ForeignKey: &ForeignKey{Entity: "User", RefEntity: "Group"}},
+				{From: "User", To: "User", ForeignKey: &ForeignKey{Entity: "User", RefEntity: "User"}},
+			},
+		}
+	}
+
+	tests := []struct {
+		order    EntityOrder
+		expected []string
+	}{
+		{OrderName, []string{"Car", "Group", "Tag", "User"}},
+		{OrderDegree, []string{"User", "Car", "Group", "Tag"}},
+		{OrderTopo, []string{"Group", "Tag", "User", "Car"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(EntityOrderIds[tt.order][0], func(t *testing.T) {
+			model := newModel()
+			sortEntities(model, tt.order)
+
+			if got := entityNames(model.Entities); strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTopoSortEntitiesCycle(t *testing.T) {
+	model := &Model{
+		Entities: []*Entity{{Name: "A"}, {Name: "B"}, {Name: "C"}},
+		Relationships: []*Relationship{
+			{ForeignKey: &ForeignKey{Entity: "A", RefEntity: "B"}},
+			{ForeignKey: &ForeignKey{Entity: "B", RefEntity: "A"}},
+			{ForeignKey: &ForeignKey{Entity: "C", RefEntity: "A"}},
+		},
+	}
+
+	if got := strings.Join(entityNames(topoSortEntities(model)), ","); got != "A,B,C" {
+		t.Errorf("Expected the cycle to be broken at its first entity, got %s", got)
+	}
+}
+
+func TestSortAttributes(t *testing.T) {
+	newModel := func() *Model {
+		return &Model{Entities: []*Entity{{Name: "Car", Attributes: []*Attribute{
+			{Name: "id", Keys: []string{"PK"}},
+			{Name: "model"},
+			{Name: "owner_id", Keys: []string{"FK"}},
+			{Name: "color"},
+			{Name: "dealer_id", Keys: []string{"FK"}},
+		}}}}
+	}
+
+	tests := []struct {
+		order    FieldOrder
+		expected string
+	}{
+		{FieldOrderSchema, "id,model,owner_id,color,dealer_id"},
+		{FieldOrderName, "color,dealer_id,id,model,owner_id"},
+		{FieldOrderKeys, "id,owner_id,dealer_id,model,color"},
+	}
+
+	for _, tt := range tests {
+		t.Run(FieldOrderIds[tt.order][0], func(t *testing.T) {
+			model := newModel()
+			sortAttributes(model, tt.order)
+
+			var names []string
+			for _, attribute := range model.Entities[0].Attributes {
+				names = append(names, attribute.Name)
+			}
+
+			if got := strings.Join(names, ","); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
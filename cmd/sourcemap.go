@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// SourceMap maps the entities of the diagram and their fields back to where they're declared in the schema, so
+// tools can deep-link from a rendered diagram into the code.
+type SourceMap struct {
+	Entities []*SourceMapEntity `json:"entities"`
+}
+
+// SourceMapEntity is the schema file an entity is declared in, join tables aren't declared in any.
+type SourceMapEntity struct {
+	Entity string            `json:"entity"`
+	Table  string            `json:"table"`
+	File   string            `json:"file"`
+	Line   int               `json:"line,omitempty"`
+	URL    string            `json:"url,omitempty"`
+	Fields []*SourceMapField `json:"fields,omitempty"`
+}
+
+// SourceMapField is the position of a field in the list returned by the schema's Fields method, or by the Fields
+// method of one of its mixins when MixedIn is set.
+type SourceMapField struct {
+	Field      string `json:"field"`
+	Index      int    `json:"index"`
+	MixedIn    bool   `json:"mixedIn,omitempty"`
+	MixinIndex int    `json:"mixinIndex,omitempty"`
+}
+
+// renderSourceMap writes the source map of the model as JSON.
+func renderSourceMap(_ context.Context, w io.Writer, model *Model, _ *options) error {
+	sourceMap := &SourceMap{Entities: []*SourceMapEntity{}}
+
+	for _, entity := range model.Entities {
+		if entity.Source == nil {
+			continue
+		}
+
+		mapped := &SourceMapEntity{
+			Entity: entity.Name,
+			Table:  entity.Table,
+			File:   entity.Source.File,
+			Line:   entity.Source.Line,
+			URL:    entity.URL,
+		}
+
+		for _, attribute := range entity.Attributes {
+			if attribute.position == nil {
+				continue
+			}
+
+			mapped.Fields = append(mapped.Fields, &SourceMapField{
+				Field:      attribute.Name,
+				Index:      attribute.position.Index,
+				MixedIn:    attribute.position.MixedIn,
+				MixinIndex: attribute.position.MixinIndex,
+			})
+		}
+
+		sourceMap.Entities = append(sourceMap.Entities, mapped)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(sourceMap)
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateDiagramSourceMap(t *testing.T) {
+	sourceMapPath := filepath.Join(t.TempDir(), "erd.map.json")
+
+	if err := GenerateDiagram("../examples/start/schema", "", Markdown, "", "", WithOutput(FormatSourceMap, sourceMapPath)); err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	content, err := os.ReadFile(sourceMapPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sourceMap SourceMap
+	if err := json.Unmarshal(content, &sourceMap); err != nil {
+		t.Fatalf("Failed to decode the source map: %v", err)
+	}
+
+	if len(sourceMap.Entities) != 3 {
+		t.Fatalf("Expected the 3 schema types without the join table, got %d", len(sourceMap.Entities))
+	}
+
+	user := sourceMap.Entities[2]
+	if user.Entity != "User" || user.File != "../examples/start/schema/user.go" || user.Line != 14 {
+		t.Errorf("Got %+v, expected User declared at ../examples/start/schema/user.go:14", user)
+	}
+
+	if len(user.Fields) != 4 || user.Fields[0].Field != "age" || user.Fields[3].Field != "json" || user.Fields[3].Index != 3 {
+		t.Errorf("Expected the 4 declared fields from age to json in order, got %d", len(user.Fields))
+	}
+}
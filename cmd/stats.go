@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+)
+
+// mermaidMaxTextSize is the default maxTextSize of Mermaid, larger diagrams are refused instead of rendered.
+const mermaidMaxTextSize = 50000
+
+// DiagramStats summarizes the size of the generated diagram.
+type DiagramStats struct {
+	Entities      int
+	JoinTables    int
+	Relationships int
+	Attributes    int
+	// Bytes is the size of the rendered Mermaid diagram, Characters its length as Mermaid measures it.
+	Bytes      int
+	Characters int
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Print the number of entities and relationships in the schema and the size of the generated diagram",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWithOptions(cmd, func(ctx context.Context, opts []Option) error {
+			stats, err := generateStats(ctx, schemaPaths[0], outputType, newOptions(opts))
+			if err != nil {
+				return err
+			}
+
+			return writeStats(os.Stdout, stats)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+}
+
+// modelStats counts the entities, join tables, relationships and attributes of the model.
+func modelStats(model *Model) *DiagramStats {
+	stats := &DiagramStats{Relationships: len(model.Relationships)}
+
+	for _, entity := range model.Entities {
+		if entity.JoinTable {
+			stats.JoinTables++
+		} else {
+			stats.Entities++
+		}
+
+		stats.Attributes += len(entity.Attributes)
+	}
+
+	return stats
+}
+
+// generateStats loads the schema and renders the diagram just to measure it.
+func generateStats(ctx context.Context, schemaPath string, outputType OutputType, o *options) (*DiagramStats, error) {
+	model, err := loadModel(ctx, schemaPath, o)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := modelStats(model)
+
+	var builder strings.Builder
+	if err := renderMermaid(ctx, &builder, model, o); err != nil {
+		return nil, err
+	}
+
+	diagram, err := fenceMermaid(builder.String(), outputType, o)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.Bytes = len(diagram)
+	stats.Characters = utf8.RuneCountInString(diagram)
+
+	return stats, nil
+}
+
+func writeStats(w io.Writer, stats *DiagramStats) error {
+	_, err := fmt.Fprintf(w, "Entities:      %d\nJoin tables:   %d\nRelationships: %d\nAttributes:    %d\nDiagram size:  %d bytes\n",
+		stats.Entities, stats.JoinTables, stats.Relationships, stats.Attributes, stats.Bytes)
+	if err != nil {
+		return err
+	}
+
+	if stats.Characters > mermaidMaxTextSize {
+		// Round up to leave some headroom for the schema to grow.
+		suggested := (stats.Characters/mermaidMaxTextSize + 1) * mermaidMaxTextSize
+		_, err = fmt.Fprintf(w, "\nwarning: the diagram is %d characters, larger than the default Mermaid maxTextSize of %d, and won't render.\n"+
+			"Raise the limit in the configuration of the site or renderer, as maxTextSize is a secure key an init directive "+
+			"can't override:\n\n  mermaid.initialize({maxTextSize: %d})\n\n"+
+			"Or split it into several diagrams with --page-size, or narrow it down with --entity or %s, where the limit "+
+			"can't be raised (e.g. on GitHub).\n",
+			stats.Characters, mermaidMaxTextSize, suggested, IgnoreFileName)
+	}
+
+	return err
+}
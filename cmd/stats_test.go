@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGenerateStats(t *testing.T) {
+	stats, err := generateStats(context.Background(), "../examples/start/schema", Markdown, newOptions(nil))
+	if err != nil {
+		t.Fatalf("Failed to generate stats: %v", err)
+	}
+
+	if stats.Entities != 3 || stats.JoinTables != 1 || stats.Relationships != 3 || stats.Attributes != 13 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	if stats.Bytes == 0 || stats.Characters != stats.Bytes {
+		t.Errorf("Expected the ASCII diagram size to be measured, got %+v", stats)
+	}
+}
+
+func TestWriteStatsMaxTextSize(t *testing.T) {
+	var small bytes.Buffer
+	if err := writeStats(&small, &DiagramStats{Characters: mermaidMaxTextSize}); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(small.String(), "warning") {
+		t.Errorf("Didn't expect a warning for a diagram at the limit:\n%s", small.String())
+	}
+
+	var large bytes.Buffer
+	if err := writeStats(&large, &DiagramStats{Characters: mermaidMaxTextSize + 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(large.String(), `mermaid.initialize({maxTextSize: 100000})`) || strings.Contains(large.String(), "%%{init") {
+		t.Errorf("Expected the limit to be raised in the configuration of the renderer:\n%s", large.String())
+	}
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thediveo/enumflag/v2"
+)
+
+// SummaryPosition is where the one-line summary of the diagram is placed in the target.
+type SummaryPosition enumflag.Flag
+
+const (
+	SummaryNone SummaryPosition = iota
+	SummaryAbove
+	SummaryBelow
+)
+
+var SummaryPositionIds = map[SummaryPosition][]string{
+	SummaryNone:  {"none"},
+	SummaryAbove: {"above"},
+	SummaryBelow: {"below"},
+}
+
+// WithSummary places a one-line summary of the scope of the diagram, e.g. "34 entities, 51 relationships, generated
+// from the ent schema by entmaid v1.2.0", above or below the diagram in the target.
+func WithSummary(position SummaryPosition) Option {
+	return func(o *options) {
+		o.summary = position
+	}
+}
+
+// addSummary places the summary of the model around the diagram, as an emphasized paragraph in Markdown documents.
+// Plain documents only hold the diagram, so it's a Mermaid comment below it, as the front matter has to come first.
+func addSummary(diagram string, model *Model, outputType OutputType, o *options) string {
+	if o.summary == SummaryNone {
+		return diagram
+	}
+
+	if !outputType.isMarkdown() {
+		return diagram + "\n%% " + diagramSummary(model, o.reproducible)
+	}
+
+	summary := "_" + diagramSummary(model, o.reproducible) + "_"
+	if o.summary == SummaryAbove {
+		return summary + "\n\n" + diagram
+	}
+
+	return diagram + "\n\n" + summary
+}
+
+// diagramSummary describes the scope of the diagram in a sentence, naming the entmaid version unless it's reproducible.
+func diagramSummary(model *Model, reproducible bool) string {
+	stats := modelStats(model)
+
+	counts := []string{pluralize(stats.Entities, "entity", "entities")}
+	if stats.JoinTables > 0 {
+		counts = append(counts, pluralize(stats.JoinTables, "join table", "join tables"))
+	}
+	counts = append(counts, pluralize(stats.Relationships, "relationship", "relationships"))
+
+	summary := strings.Join(counts, ", ") + ", generated from the ent schema by entmaid"
+	if !reproducible {
+		summary += " " + version()
+	}
+
+	return summary
+}
+
+func pluralize(count int, singular string, plural string) string {
+	if count == 1 {
+		return "1 " + singular
+	}
+
+	return fmt.Sprintf("%d %s", count, plural)
+}
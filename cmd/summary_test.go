@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDiagramSummary(t *testing.T) {
+	model := &Model{
+		Entities:      []*Entity{{Name: "User"}, {Name: "Group"}, {Name: "group_users", JoinTable: true}},
+		Relationships: []*Relationship{{From: "Group", To: "group_users"}, {From: "User", To: "group_users"}},
+	}
+
+	expected := "2 entities, 1 join table, 2 relationships, generated from the ent schema by entmaid " + version()
+	if got := diagramSummary(model, false); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	if got := diagramSummary(model, true); got != "2 entities, 1 join table, 2 relationships, generated from the ent schema by entmaid" {
+		t.Errorf("Expected the reproducible summary to be without the version, got %q", got)
+	}
+
+	if got := diagramSummary(&Model{Entities: []*Entity{{Name: "User"}}}, false); !strings.HasPrefix(got, "1 entity, 0 relationships,") {
+		t.Errorf("Expected a single entity without join tables, got %q", got)
+	}
+}
+
+func TestGenerateDiagramSummary(t *testing.T) {
+	document := defaultStartPattern + "\n" + defaultEndPattern + "\n"
+
+	for _, tc := range []struct {
+		position   SummaryPosition
+		outputType OutputType
+		expected   string
+	}{
+		{SummaryAbove, Markdown, defaultStartPattern + "\n_3 entities, 1 join table, 3 relationships, generated from the ent schema by entmaid " + version() + "_\n\n```mermaid\n"},
+		{SummaryBelow, Markdown, "```\n\n_3 entities, 1 join table, 3 relationships, generated from the ent schema by entmaid " + version() + "_\n" + defaultEndPattern},
+		{SummaryAbove, Plain, "\n%% 3 entities, 1 join table, 3 relationships, generated from the ent schema by entmaid " + version() + "\n" + defaultEndPattern},
+	} {
+		var stdout bytes.Buffer
+		err := GenerateDiagram("../examples/start/schema", StdioTarget, tc.outputType, defaultStartPattern, defaultEndPattern,
+			WithSummary(tc.position), WithStdio(strings.NewReader(document), &stdout))
+		if err != nil {
+			t.Fatalf("Failed to generate diagram: %v", err)
+		}
+
+		if !strings.Contains(stdout.String(), tc.expected) {
+			t.Errorf("Expected the summary %s the diagram, got:\n%s", SummaryPositionIds[tc.position][0], stdout.String())
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import "fmt"
+
+// WithMaxFields lists at most n attributes of every entity in the Mermaid diagram, followed by a row counting the
+// ones left out. The keys are always listed, so the relationships can still be traced. Tables with dozens of columns
+// otherwise stretch the whole diagram.
+func WithMaxFields(n int) Option {
+	return func(o *options) {
+		o.maxFields = n
+	}
+}
+
+// truncateAttributes returns the first attributes of the entity up to the maximum of WithMaxFields, or the lower
+// one of the entity, along with every key, and the number of attributes left out.
+func truncateAttributes(entity *Entity, attributes []*Attribute, o *options) ([]*Attribute, int) {
+	maxFields := o.maxFields
+	if entity.maxFields > 0 && (maxFields <= 0 || entity.maxFields < maxFields) {
+		maxFields = entity.maxFields
+	}
+
+	if maxFields <= 0 || len(attributes) <= maxFields {
+		return attributes, 0
+	}
+
+	shown := make([]*Attribute, 0, maxFields)
+	for _, attribute := range attributes {
+		if len(shown) < maxFields || len(attribute.Keys) > 0 {
+			shown = append(shown, attribute)
+		}
+	}
+
+	return shown, len(attributes) - len(shown)
+}
+
+// moreAttributes describes the attributes left out of an entity by WithMaxFields.
+func moreAttributes(n int) string {
+	return fmt.Sprintf("... %d more", n)
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestMaxFields(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		opts     []Option
+		expected string
+	}{
+		{"crowsfoot", nil, " Car {\n  int id PK\n  string model\n  int user_cars FK\n  __ __ \"... 1 more\"\n }\n"},
+		{"uml", []Option{WithNotation(NotationUML)}, "  int id PK\n  string model\n  int user_cars FK\n  ... 1 more\n }\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			o := newOptions(append(tc.opts, WithMaxFields(2)))
+
+			model, err := loadModel(context.Background(), "../examples/start/schema", o)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var builder strings.Builder
+			if err := renderMermaid(context.Background(), &builder, model, o); err != nil {
+				t.Fatal(err)
+			}
+
+			diagram := builder.String()
+			if !strings.Contains(diagram, tc.expected) {
+				t.Errorf("The diagram is missing %q:\n%s", tc.expected, diagram)
+			}
+
+			// The entities with at most two attributes are listed whole.
+			if !strings.Contains(diagram, "  int id PK\n  string name\n }\n") {
+				t.Errorf("Expected the Group to be listed whole:\n%s", diagram)
+			}
+		})
+	}
+}
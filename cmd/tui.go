@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Interactively pick the entities to draw and write the filtered diagram to the target",
+	Long: `Interactively pick the entities to draw and write the filtered diagram to the target.
+
+The entities are listed with a number, type / and a search to fuzzy filter the list and toggle entities by their
+number or whole database schemas at once. Writing the diagram prints the --entity flags drawing the same selection,
+to reuse in scripts and hooks. Type ? for the list of commands.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWithOptions(cmd, func(ctx context.Context, opts []Option) error {
+			o := newOptions(append(opts, WithQuiet()))
+
+			model, err := loadModel(ctx, schemaPaths[0], o)
+			if err != nil {
+				return err
+			}
+
+			start, end := startPattern, endPattern
+			defaultStart, defaultEnd := DefaultPatterns(targetPath)
+			if start == "" {
+				start = defaultStart
+			}
+			if end == "" {
+				end = defaultEnd
+			}
+
+			return runTUI(ctx, os.Stdin, os.Stdout, model, targetPath, func(ctx context.Context, filtered *Model) error {
+				return writeTarget(ctx, filtered, targetPath, targetOutputType(cmd), start, end, o)
+			})
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+}
+
+const tuiHelp = `Commands:
+  /<search>    fuzzy filter the entities, an empty line lists them all
+  t <n> ...    toggle the listed entities by number
+  a / n        select / deselect every listed entity
+  g <schema>   toggle every entity of the database schema (or domain)
+  w            write the diagram of the selected entities to the target
+  q            quit
+`
+
+// tuiState is the selection and the filtered list of the entities shown to the user.
+type tuiState struct {
+	entities []*Entity
+	selected map[string]bool
+	listed   []*Entity
+}
+
+// runTUI reads the commands from in until it's closed or the user quits, writing the diagram of the selected
+// entities to the target with write.
+func runTUI(ctx context.Context, in io.Reader, out io.Writer, model *Model, targetPath string, write func(context.Context, *Model) error) error {
+	state := &tuiState{selected: make(map[string]bool)}
+	for _, entity := range model.Entities {
+		// Join tables follow the entities on their sides.
+		if !entity.JoinTable {
+			state.entities = append(state.entities, entity)
+			state.selected[entity.Name] = true
+		}
+	}
+
+	state.filter("")
+	state.print(out)
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return scanner.Err()
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		command, argument, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		argument = strings.TrimSpace(argument)
+
+		switch command {
+		case "q":
+			return nil
+		case "?":
+			fmt.Fprint(out, tuiHelp)
+			continue
+		case "t":
+			if err := state.toggle(strings.Fields(argument)); err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+		case "a", "n":
+			for _, entity := range state.listed {
+				state.selected[entity.Name] = command == "a"
+			}
+		case "g":
+			state.toggleSchema(argument)
+		case "w":
+			names := state.selection()
+			if err := write(ctx, filterEntities(model, names)); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "Wrote %d entities to %s, draw them again with:\n  %s\n", len(names), targetPath, entityFlags(names))
+			continue
+		case "":
+			state.filter("")
+		default:
+			if !strings.HasPrefix(command, "/") {
+				fmt.Fprint(out, tuiHelp)
+				continue
+			}
+
+			state.filter(strings.TrimPrefix(command+" "+argument, "/"))
+		}
+
+		state.print(out)
+	}
+}
+
+// filter lists the entities matching the fuzzy search.
+func (s *tuiState) filter(search string) {
+	s.listed = s.listed[:0]
+	for _, entity := range s.entities {
+		if fuzzyMatch(search, entity.Name) {
+			s.listed = append(s.listed, entity)
+		}
+	}
+}
+
+func (s *tuiState) toggle(numbers []string) error {
+	for _, number := range numbers {
+		n, err := strconv.Atoi(number)
+		if err != nil || n < 1 || n > len(s.listed) {
+			return fmt.Errorf("%s is not the number of a listed entity", number)
+		}
+
+		name := s.listed[n-1].Name
+		s.selected[name] = !s.selected[name]
+	}
+
+	return nil
+}
+
+// toggleSchema selects every entity of the database schema or domain, unless they're all already selected.
+func (s *tuiState) toggleSchema(schema string) {
+	all := true
+	for _, entity := range s.entities {
+		if entity.group() == schema && !s.selected[entity.Name] {
+			all = false
+		}
+	}
+
+	for _, entity := range s.entities {
+		if entity.group() == schema {
+			s.selected[entity.Name] = !all
+		}
+	}
+}
+
+func (s *tuiState) selection() []string {
+	var names []string
+	for _, entity := range s.entities {
+		if s.selected[entity.Name] {
+			names = append(names, entity.Name)
+		}
+	}
+
+	return names
+}
+
+func (s *tuiState) print(out io.Writer) {
+	for i, entity := range s.listed {
+		mark := " "
+		if s.selected[entity.Name] {
+			mark = "x"
+		}
+
+		fmt.Fprintf(out, "%3d [%s] %s\n", i+1, mark, entity.Name)
+	}
+
+	fmt.Fprintf(out, "%d of %d entities selected, ? for help\n", len(s.selection()), len(s.entities))
+}
+
+// fuzzyMatch reports whether the letters of the search appear in the name in order, ignoring case.
+func fuzzyMatch(search string, name string) bool {
+	name = strings.ToLower(name)
+	for _, r := range strings.ToLower(search) {
+		if unicode.IsSpace(r) {
+			continue
+		}
+
+		i := strings.IndexRune(name, r)
+		if i == -1 {
+			return false
+		}
+		name = name[i+len(string(r)):]
+	}
+
+	return true
+}
+
+// entityFlags returns the flags drawing the entities.
+func entityFlags(names []string) string {
+	flags := make([]string, len(names))
+	for i, name := range names {
+		flags[i] = "--entity " + name
+	}
+
+	return strings.Join(flags, " ")
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	for search, expected := range map[string]bool{"": true, "usr": true, "USER": true, "gu": true, "ug": false, "car": false} {
+		if got := fuzzyMatch(search, "group_users"); got != expected {
+			t.Errorf("fuzzyMatch(%q) = %v, expected %v", search, got, expected)
+		}
+	}
+}
+
+func TestRunTUI(t *testing.T) {
+	model, err := loadModel(context.Background(), "../examples/start/schema", newOptions(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var written *Model
+	var out strings.Builder
+
+	in := strings.NewReader("/gro\nt 1\n\nt 9\nw\nq\n")
+	err = runTUI(context.Background(), in, &out, model, "erd.md", func(_ context.Context, filtered *Model) error {
+		written = filtered
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to run the TUI: %v", err)
+	}
+
+	var names []string
+	for _, entity := range written.Entities {
+		names = append(names, entity.Name)
+	}
+
+	if strings.Join(names, ",") != "Car,User" || len(written.Relationships) != 1 {
+		t.Errorf("Expected the Group and its join table to be left out, got %v and %d relationships", names, len(written.Relationships))
+	}
+
+	for _, expected := range []string{"  1 [x] Group\n", "9 is not the number of a listed entity", "--entity Car --entity User"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("TUI output is missing %q:\n%s", expected, out.String())
+		}
+	}
+}
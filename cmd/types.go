@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/entc/gen"
+	"entgo.io/ent/schema/field"
+	"github.com/thediveo/enumflag/v2"
+)
+
+// otherTypeAlias is the type of field.Other fields without a SchemaType, unless it's aliased.
+const otherTypeAlias = "other"
+
+// WithTypeAlias shows the fields of the Go type (as written in the schema package, e.g. "pgtype.Range") as alias.
+// Aliasing "other" sets the type of the field.Other fields that don't declare a SchemaType.
+func WithTypeAlias(goType string, alias string) Option {
+	return func(o *options) {
+		if o.typeAliases == nil {
+			o.typeAliases = make(map[string]string)
+		}
+		o.typeAliases[goType] = alias
+	}
+}
+
+// WithDetailedTypes shows the size and precision of the columns for DBA reviews: the SchemaType declared by any field
+// (e.g. numeric(10,2)) and the MaxLen of the string and bytes fields (e.g. varchar(255)). The check constraints of
+// the entsql annotations are listed below their entity.
+func WithDetailedTypes() Option {
+	return func(o *options) {
+		o.detailedTypes = true
+	}
+}
+
+// WithNullability appends NOT NULL or NULL to the comments of the attributes in the Mermaid diagram, so that along
+// with WithDetailedTypes or WithDialect it doubles as a reference of the column constraints when reviewing migrations.
+func WithNullability() Option {
+	return func(o *options) {
+		o.nullability = true
+	}
+}
+
+// columnNullability returns the SQL constraint of the column: only the columns of the optional fields are nullable, as in
+// the migrations of ent.
+func columnNullability(attribute *Attribute) string {
+	if attribute.optional {
+		return "NULL"
+	}
+
+	return "NOT NULL"
+}
+
+// TypeStyle is how the types of the attributes are written, for the conventions of the diagrams of an organization.
+type TypeStyle enumflag.Flag
+
+const (
+	// TypeStyleLower writes the lowercase SQL-like types, e.g. varchar(255) or timestamp, the default.
+	TypeStyleLower TypeStyle = iota
+	// TypeStyleUpper writes the uppercase SQL types, e.g. VARCHAR(255) or TIMESTAMP.
+	TypeStyleUpper
+	// TypeStyleGo writes the Go types of the fields, e.g. time.Time or *schema.Period.
+	TypeStyleGo
+)
+
+var TypeStyleIds = map[TypeStyle][]string{
+	TypeStyleLower: {"lower"},
+	TypeStyleUpper: {"upper"},
+	TypeStyleGo:    {"go"},
+}
+
+// WithTypeStyle writes the types of the attributes in the style, the aliases of WithTypeAlias are kept as they are.
+func WithTypeStyle(style TypeStyle) Option {
+	return func(o *options) {
+		o.typeStyle = style
+	}
+}
+
+// WithoutTypePackages drops the package qualifiers of the custom Go types, e.g. Period for schema.Period.
+func WithoutTypePackages() Option {
+	return func(o *options) {
+		o.stripTypePackages = true
+	}
+}
+
+// typePackageRegex matches the package qualifiers of a Go type, along with the import path of the package.
+var typePackageRegex = regexp.MustCompile(`(?:[\w.-]+/)*\w+\.`)
+
+// unqualifiedType drops the package qualifiers of the Go type, e.g. []*Period for []*schema.Period.
+func unqualifiedType(goType string) string {
+	return typePackageRegex.ReplaceAllString(goType, "")
+}
+
+// dialectPreference is the order the SchemaType of the dialects is looked up in, when a field declares several.
+var dialectPreference = []string{dialect.Postgres, dialect.MySQL, dialect.SQLite}
+
+// attributeType returns the diagram type of the field in the WithTypeStyle style.
+func attributeType(f *gen.Field, o *options) string {
+	goType := f.Type.String()
+	if alias, ok := o.typeAliases[goType]; ok {
+		return alias
+	}
+
+	switch o.typeStyle {
+	case TypeStyleGo:
+		if o.stripTypePackages {
+			return unqualifiedType(goType)
+		}
+		return goType
+	case TypeStyleUpper:
+		return strings.ToUpper(diagramType(f, o))
+	}
+
+	return diagramType(f, o)
+}
+
+// diagramType returns the lowercase diagram type of the field. Custom Go types are shown by their declared SchemaType
+// or the ent type they're based on, rather than their mangled Go type.
+func diagramType(f *gen.Field, o *options) string {
+	goType := f.Type.String()
+
+	if o.detailedTypes {
+		if t, ok := detailedType(f, o); ok {
+			return t
+		}
+	}
+
+	if o.dialect != DialectAny {
+		if t, ok := dialectType(f, o.dialect); ok {
+			return t
+		}
+	}
+
+	if f.Type.Type == field.TypeTime {
+		if t, ok := timeType(declaredSchemaType(f, o)); ok {
+			return t
+		}
+	}
+
+	if t, ok := knownType(goType); ok {
+		return t
+	}
+
+	switch {
+	case f.Type.Type == field.TypeOther:
+		if schemaType := declaredSchemaType(f, o); schemaType != "" {
+			return schemaType
+		}
+
+		if alias, ok := o.typeAliases[otherTypeAlias]; ok {
+			return alias
+		}
+
+		return otherTypeAlias
+
+	case f.HasGoType() && (f.Type.Numeric() || f.Type.Type == field.TypeString || f.Type.Type == field.TypeBool):
+		if schemaType := declaredSchemaType(f, o); schemaType != "" {
+			return schemaType
+		}
+
+		return f.Type.Type.String()
+	}
+
+	if o.stripTypePackages {
+		goType = unqualifiedType(goType)
+	}
+
+	return formatType(goType)
+}
+
+// detailedType returns the declared SchemaType of the field, or the sized type of the string and bytes fields with a
+// MaxLen. Text fields are unbounded and keep their type.
+func detailedType(f *gen.Field, o *options) (string, bool) {
+	if schemaType := declaredSchemaType(f, o); schemaType != "" {
+		return schemaType, true
+	}
+
+	size := f.Column().Size
+	if size <= 0 || size >= math.MaxInt32 {
+		return "", false
+	}
+
+	switch f.Type.Type {
+	case field.TypeString:
+		return fmt.Sprintf("varchar(%d)", size), true
+	case field.TypeBytes:
+		return fmt.Sprintf("varbinary(%d)", size), true
+	}
+
+	return "", false
+}
+
+// declaredSchemaType returns the column type declared by the field for the WithDialect or preferred dialect, or for
+// the first dialect by name when none of the preferred ones are declared.
+func declaredSchemaType(f *gen.Field, o *options) string {
+	schemaTypes := f.Column().SchemaType
+	if len(schemaTypes) == 0 {
+		return ""
+	}
+
+	preference := dialectPreference
+	if o.dialect != DialectAny {
+		preference = append([]string{DialectIds[o.dialect][0]}, preference...)
+	}
+
+	for _, name := range preference {
+		if schemaType, ok := schemaTypes[name]; ok {
+			return schemaType
+		}
+	}
+
+	names := make([]string, 0, len(schemaTypes))
+	for name := range schemaTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return schemaTypes[names[0]]
+}
+
+// timeTypeRegex matches the date and time column types of the dialects, capturing their base type and time zone.
+var timeTypeRegex = regexp.MustCompile(`^(date|time|timetz|timestamp|timestamptz|datetime)(?:\s*\(\d+\))?(?:\s+(with|without)\s+time\s+zone)?$`)
+
+// timeType returns the date, time, timetz, timestamp or timestamptz type of the SchemaType of a time field, so the
+// date-only and time-only columns aren't shown as timestamps. The precision is left to the detailed types.
+func timeType(schemaType string) (string, bool) {
+	match := timeTypeRegex.FindStringSubmatch(strings.ToLower(strings.TrimSpace(schemaType)))
+	if match == nil {
+		return "", false
+	}
+
+	switch base, zone := match[1], match[2]; {
+	case base == "date":
+		return "date", true
+	case base == "datetime":
+		return "timestamp", true
+	case zone == "with":
+		return strings.TrimSuffix(base, "tz") + "tz", true
+	case zone == "without":
+		return strings.TrimSuffix(base, "tz"), true
+	default:
+		return base, true
+	}
+}
+
+// goTypes maps lowercased Go types, without any pointer, to the idiomatic type shown in the diagram.
+var goTypes = map[string]string{
+	"time.time":               "timestamp",
+	"time.duration":           "duration",
+	"map[string]interface {}": "jsonb",
+	"map[string]interface{}":  "jsonb",
+	"map[string]any":          "jsonb",
+	"json.rawmessage":         "json",
+	"[]byte":                  "bytes",
+	"[]uint8":                 "bytes",
+	"uuid.uuid":               "uuid",
+	"decimal.decimal":         "decimal",
+	"big.int":                 "numeric",
+	"big.float":               "numeric",
+	"net.ip":                  "inet",
+	"netip.addr":              "inet",
+	"net.ipnet":               "cidr",
+	"netip.prefix":            "cidr",
+	"net.hardwareaddr":        "macaddr",
+	"url.url":                 "url",
+	"pq.stringarray":          "text[]",
+	"pq.int64array":           "int64[]",
+	"pq.int32array":           "int32[]",
+	"pq.float64array":         "float64[]",
+	"pq.float32array":         "float32[]",
+	"pq.boolarray":            "bool[]",
+	"pq.byteaarray":           "bytes[]",
+}
+
+// arrayRegex matches the slice and array Go types, capturing their element type.
+var arrayRegex = regexp.MustCompile(`^\[\d*\](.+)$`)
+
+// knownType returns the diagram type of the Go type when it's one of the goTypes.
+func knownType(goType string) (string, bool) {
+	t, ok := goTypes[strings.ToLower(strings.TrimLeft(goType, "*"))]
+	return t, ok
+}
+
+func formatType(s string) string {
+	if t, ok := knownType(s); ok {
+		return t
+	}
+
+	if match := arrayRegex.FindStringSubmatch(strings.TrimLeft(s, "*")); match != nil {
+		return arrayType(match[1])
+	}
+
+	if strings.Contains(strings.ToLower(s), "inet") {
+		return "inet"
+	}
+
+	return strings.ReplaceAll(s, ".", "-")
+}
+
+// arrayType returns the diagram type of the slices and arrays of the element type, byte arrays (e.g. [16]byte) being
+// bytes.
+func arrayType(element string) string {
+	switch strings.ToLower(strings.TrimLeft(element, "*")) {
+	case "byte", "uint8":
+		return "bytes"
+	case "string":
+		return "text[]"
+	}
+
+	return formatType(element) + "[]"
+}
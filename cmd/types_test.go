@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"entgo.io/ent/entc/gen"
+	"entgo.io/ent/schema/field"
+)
+
+func TestGenerateDiagramTypeAlias(t *testing.T) {
+	mermaidPath := filepath.Join(t.TempDir(), "erd.mmd")
+
+	err := GenerateDiagram("../examples/customtypes/schema", "", Plain, "", "",
+		WithTypeAlias("*schema.Period", "period"), WithTypeAlias("schema.Status", "booking_status"), WithOutput(FormatMermaid, mermaidPath))
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	content, err := os.ReadFile(mermaidPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"  period period\n", "  booking_status status\n", "  int64 price\n"} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Diagram is missing %q:\n%s", expected, content)
+		}
+	}
+
+	if strings.Contains(string(content), "Booking check") {
+		t.Errorf("The check constraints should only be drawn with the detailed types:\n%s", content)
+	}
+}
+
+func TestGenerateDiagramDetailedTypes(t *testing.T) {
+	mermaidPath := filepath.Join(t.TempDir(), "erd.mmd")
+
+	if err := GenerateDiagram("../examples/customtypes/schema", "", Plain, "", "", WithDetailedTypes(), WithOutput(FormatMermaid, mermaidPath)); err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	content, err := os.ReadFile(mermaidPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"  tstzrange period\n", "  varchar(32) reference\n", `  numeric(10_2) total "type: numeric(10,2)"` + "\n", "  int64 price\n",
+		" %% Booking check (price >= 0)\n %% Booking check paid_total (NOT paid OR total > 0)\n",
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Diagram is missing %q:\n%s", expected, content)
+		}
+	}
+}
+
+func TestGenerateDiagramNullability(t *testing.T) {
+	mermaidPath := filepath.Join(t.TempDir(), "erd.mmd")
+
+	if err := GenerateDiagram("../examples/start/schema", "", Plain, "", "", WithNullability(), WithOutput(FormatMermaid, mermaidPath)); err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	content, err := os.ReadFile(mermaidPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"  int id PK \"NOT NULL\"\n", "  int user_cars FK \"NULL\"\n", "  int group_id PK,FK \"NOT NULL\"\n", `  string name "default: unknown, NOT NULL"` + "\n"} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Diagram is missing %q:\n%s", expected, content)
+		}
+	}
+}
+
+func TestFormatType(t *testing.T) {
+	testCases := map[string]string{
+		"int":                     "int",
+		"string":                  "string",
+		"time.Time":               "timestamp",
+		"*time.Time":              "timestamp",
+		"time.Duration":           "duration",
+		"map[string]interface {}": "jsonb",
+		"map[string]any":          "jsonb",
+		"json.RawMessage":         "json",
+		"[]byte":                  "bytes",
+		"uuid.UUID":               "uuid",
+		"decimal.Decimal":         "decimal",
+		"*big.Int":                "numeric",
+		"net.IP":                  "inet",
+		"netip.Prefix":            "cidr",
+		"pgtype.Inet":             "inet",
+		"*url.URL":                "url",
+		"[]string":                "text[]",
+		"[]int":                   "int[]",
+		"[]float64":               "float64[]",
+		"[16]byte":                "bytes",
+		"[16]uint8":               "bytes",
+		"[]uuid.UUID":             "uuid[]",
+		"[][]string":              "text[][]",
+		"[4]int":                  "int[]",
+		"pq.StringArray":          "text[]",
+		"pq.Int64Array":           "int64[]",
+		"schema.Address":          "schema-Address",
+	}
+
+	for goType, expected := range testCases {
+		if got := formatType(goType); got != expected {
+			t.Errorf("formatType(%q) = %q, expected %q", goType, got, expected)
+		}
+	}
+}
+
+func TestDialectType(t *testing.T) {
+	testCases := []struct {
+		info     *field.TypeInfo
+		dialect  Dialect
+		expected string
+	}{
+		{&field.TypeInfo{Type: field.TypeJSON, Ident: "[]string"}, DialectPostgres, "jsonb"},
+		{&field.TypeInfo{Type: field.TypeJSON, Ident: "[]int"}, DialectMySQL, "json"},
+		{&field.TypeInfo{Type: field.TypeOther, Ident: "pq.Int64Array"}, DialectPostgres, "bigint[]"},
+		{&field.TypeInfo{Type: field.TypeOther, Ident: "pq.Int64Array"}, DialectSQLite, ""},
+		{&field.TypeInfo{Type: field.TypeJSON, Ident: "map[string]interface {}"}, DialectPostgres, ""},
+	}
+
+	for _, testCase := range testCases {
+		got, _ := dialectType(&gen.Field{Type: testCase.info}, testCase.dialect)
+		if got != testCase.expected {
+			t.Errorf("dialectType(%s, %s) = %q, expected %q", testCase.info.Ident, DialectIds[testCase.dialect][0], got, testCase.expected)
+		}
+	}
+}
+
+func TestGenerateDiagramTypeStyles(t *testing.T) {
+	for name, test := range map[string]struct {
+		opts     []Option
+		expected []string
+	}{
+		"upper":    {[]Option{WithTypeStyle(TypeStyleUpper), WithDetailedTypes()}, []string{"  TSTZRANGE period\n", "  VARCHAR(32) reference\n", "  INT64 price\n"}},
+		"go":       {[]Option{WithTypeStyle(TypeStyleGo), WithTypeAlias("schema.Status", "booking_status")}, []string{`  _schema_Period period "type: *schema.Period"` + "\n", "  booking_status status\n"}},
+		"packages": {[]Option{WithTypeStyle(TypeStyleGo), WithoutTypePackages()}, []string{"  *Period period\n", "  Cents price\n"}},
+		"lower":    {[]Option{WithoutTypePackages()}, []string{"  tstzrange period\n", "  *Address address\n"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mermaidPath := filepath.Join(t.TempDir(), "erd.mmd")
+
+			if err := GenerateDiagram("../examples/customtypes/schema", "", Plain, "", "", append(test.opts, WithOutput(FormatMermaid, mermaidPath))...); err != nil {
+				t.Fatalf("Failed to generate diagram: %v", err)
+			}
+
+			content, err := os.ReadFile(mermaidPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, expected := range test.expected {
+				if !strings.Contains(string(content), expected) {
+					t.Errorf("Diagram is missing %q:\n%s", expected, content)
+				}
+			}
+		})
+	}
+}
+
+func TestUnqualifiedType(t *testing.T) {
+	for goType, expected := range map[string]string{
+		"*schema.Period":                 "*Period",
+		"[]pgtype.Range":                 "[]Range",
+		"map[string]*github.com/a/b.Rec": "map[string]*Rec",
+		"string":                         "string",
+	} {
+		if unqualified := unqualifiedType(goType); unqualified != expected {
+			t.Errorf("unqualifiedType(%q) = %q, expected %q", goType, unqualified, expected)
+		}
+	}
+}
+
+func TestTimeType(t *testing.T) {
+	for schemaType, expected := range map[string]string{
+		"date":                        "date",
+		"DATE":                        "date",
+		"time(3)":                     "time",
+		"time with time zone":         "timetz",
+		"timestamp":                   "timestamp",
+		"timestamp(6) with time zone": "timestamptz",
+		"timestamptz":                 "timestamptz",
+		"timestamp without time zone": "timestamp",
+		"datetime(6)":                 "timestamp",
+		"":                            "",
+		"year":                        "",
+	} {
+		if got, _ := timeType(schemaType); got != expected {
+			t.Errorf("timeType(%q) = %q, expected %q", schemaType, got, expected)
+		}
+	}
+}
+
+func TestGenerateDiagramTimeTypes(t *testing.T) {
+	for _, test := range []struct {
+		dialect  Dialect
+		expected []string
+	}{
+		{DialectAny, []string{"  date check_in\n", "  timestamptz booked_at\n"}},
+		// The datetime(6) declared for MySQL is a timestamp without time zone.
+		{DialectMySQL, []string{"  date check_in\n", "  timestamp booked_at\n"}},
+	} {
+		t.Run(DialectIds[test.dialect][0], func(t *testing.T) {
+			mermaidPath := filepath.Join(t.TempDir(), "erd.mmd")
+
+			if err := GenerateDiagram("../examples/customtypes/schema", "", Plain, "", "", WithDialect(test.dialect), WithOutput(FormatMermaid, mermaidPath)); err != nil {
+				t.Fatalf("Failed to generate diagram: %v", err)
+			}
+
+			content, err := os.ReadFile(mermaidPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, expected := range test.expected {
+				if !strings.Contains(string(content), expected) {
+					t.Errorf("Diagram is missing %q:\n%s", expected, content)
+				}
+			}
+		})
+	}
+}
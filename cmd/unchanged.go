@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// WithSkipUnchanged skips the whole run, loading the schema included, when neither the schema directories nor the
+// files written by the last successful run with the same settings changed since, as recorded in a state file of the
+// cache directory (see WithCache). The settings identify the other options of the run, like for WithIncremental.
+// It keeps the pre-commit hooks fast for the commits not touching the schema. Unlike WithCache, the changes of the
+// packages imported by the schema aren't detected, nor the ones of the other files read (e.g. by WithLocale).
+func WithSkipUnchanged(settings string) Option {
+	return func(o *options) {
+		o.skipUnchanged = true
+		o.skipUnchangedKey = settings
+	}
+}
+
+// runState records the inputs of the last successful run writing a target, and the hashes of the files it left.
+type runState struct {
+	Inputs string            `json:"inputs"`
+	Files  map[string]string `json:"files"`
+
+	path  string
+	files []string
+}
+
+// newRunState returns the state the run writing the target and the outputs would be recorded with, or nil when the
+// run can't be skipped, e.g. when it writes to stdout.
+func newRunState(schemaPaths []string, targetPath string, o *options) *runState {
+	if targetPath == "" || o.changedSince != "" {
+		o.logger.Debug("not skipping the unchanged run, it doesn't only depend on the schema and the target")
+		return nil
+	}
+
+	files := []string{targetPath}
+	for _, out := range o.outputs {
+		files = append(files, out.path)
+	}
+
+	cacheDir, err := cacheDirectory(o.cacheDir)
+	if err != nil {
+		o.logger.Warn("not skipping the unchanged run, the cache directory is unknown", "error", err)
+		return nil
+	}
+
+	inputs := sha256.New()
+	fmt.Fprintf(inputs, "%s\x00%s\x00", version(), o.skipUnchangedKey)
+
+	var keys []string
+	for _, path := range files {
+		if path == StdioTarget {
+			o.logger.Debug("not skipping the unchanged run, it writes to stdout")
+			return nil
+		}
+
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			o.logger.Warn("not skipping the unchanged run, the file is unknown", "path", path, "error", err)
+			return nil
+		}
+		keys = append(keys, abs)
+	}
+
+	if o.graphSnapshot != "" {
+		fmt.Fprintf(inputs, "%s\x00%s\x00", o.graphSnapshot, fileHash(o.graphSnapshot))
+	} else if o.entSnapshot != "" {
+		fmt.Fprintf(inputs, "%s\x00%s\x00", o.entSnapshot, fileHash(o.entSnapshot))
+	} else {
+		for _, schemaPath := range schemaPaths {
+			// The import paths of the schemas are only resolved when loading them.
+			if info, err := os.Stat(schemaPath); err != nil || !info.IsDir() {
+				o.logger.Debug("not skipping the unchanged run, the schema isn't a directory", "schema", schemaPath)
+				return nil
+			}
+
+			schemaHash, err := hashSchema(schemaPath)
+			if err != nil {
+				return nil
+			}
+			fmt.Fprintf(inputs, "%s\x00%s\x00", schemaPath, schemaHash)
+		}
+	}
+
+	key, err := json.Marshal(keys)
+	if err != nil {
+		return nil
+	}
+	sum := sha256.Sum256(key)
+
+	return &runState{
+		Inputs: hex.EncodeToString(inputs.Sum(nil)),
+		Files:  make(map[string]string, len(files)),
+		path:   filepath.Join(cacheDir, "run-"+hex.EncodeToString(sum[:])+".json"),
+		files:  files,
+	}
+}
+
+// unchanged tells whether the last successful run had the same inputs, and left the files as they still are.
+func (s *runState) unchanged(o *options) bool {
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			o.logger.Warn("ignoring unreadable run state", "state", s.path, "error", err)
+		}
+		return false
+	}
+
+	var last runState
+	if err := json.Unmarshal(content, &last); err != nil {
+		o.logger.Warn("ignoring unreadable run state", "state", s.path, "error", err)
+		return false
+	}
+
+	if last.Inputs != s.Inputs || len(last.Files) == 0 {
+		return false
+	}
+
+	for path, hash := range last.Files {
+		if fileHash(path) != hash {
+			o.logger.Debug("not skipping the run, a file changed since the last one", "path", path)
+			return false
+		}
+	}
+
+	return true
+}
+
+// record writes the state of the successful run, with the hashes of the files as it left them.
+func (s *runState) record(o *options) {
+	for _, path := range s.files {
+		s.Files[path] = fileHash(path)
+	}
+
+	if err := writeCacheFile(s.path, s); err != nil {
+		o.logger.Warn("failed to record the run", "state", s.path, "error", err)
+	}
+}
+
+// fileHash returns the hash of the content of the file, or "" when it can't be read.
+func fileHash(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateDiagramSkipUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	targetPath, cacheDir := filepath.Join(dir, "readme.md"), filepath.Join(dir, "cache")
+
+	content, err := os.ReadFile("../examples/start/readme.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(targetPath, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	generate := func(settings string, opts ...Option) string {
+		t.Helper()
+
+		opts = append(opts, WithQuiet(), WithCache(cacheDir), WithSkipUnchanged(settings))
+		if err := GenerateDiagram("../examples/start/schema", targetPath, Markdown, "", "", opts...); err != nil {
+			t.Fatalf("Failed to generate diagram: %v", err)
+		}
+
+		content, err := os.ReadFile(targetPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(content)
+	}
+
+	generated := generate("")
+
+	// The settings identify the options, the title isn't drawn by a run with the same ones as nothing changed.
+	if got := generate("", WithTitle("Start")); got != generated {
+		t.Errorf("Expected the unchanged run to be skipped, got:\n%s", got)
+	}
+
+	if got := generate("--title=Start", WithTitle("Start")); !strings.Contains(got, "title: \"Start\"") {
+		t.Errorf("Expected the run with other settings to draw the title, got:\n%s", got)
+	}
+
+	// A target edited since the last run is generated again.
+	if err := os.WriteFile(targetPath, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := generate("--title=Start", WithTitle("Start")); !strings.Contains(got, "title: \"Start\"") {
+		t.Errorf("Expected the edited target to be generated again, got:\n%s", got)
+	}
+
+	if state := newRunState([]string{"../examples/start/schema"}, StdioTarget, newOptions(nil)); state != nil {
+		t.Errorf("Expected the run writing to stdout to never be skipped, got %+v", state)
+	}
+}
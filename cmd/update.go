@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/semver"
+)
+
+// latestReleaseURL is the GitHub API resource of the latest entmaid release.
+const latestReleaseURL = "https://api.github.com/repos/lespea/entmaid/releases/latest"
+
+// checksumsAsset is the release asset listing the SHA-256 of the binaries, as written by sha256sum.
+const checksumsAsset = "checksums.txt"
+
+// maxReleaseAssetSize bounds the downloaded assets so a broken release can't fill the disk.
+const maxReleaseAssetSize = 256 << 20
+
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Replace the entmaid binary by the latest GitHub release, verifying its checksum",
+	Long: `Replace the entmaid binary by the latest GitHub release when it's newer than this one, after verifying the
+downloaded binary against the checksums of the release. With --check, only report whether an update is available.
+
+Binaries installed with 'go install' can be updated this way too, or again with
+'go install github.com/lespea/entmaid@latest'.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		executable, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrSelfUpdate, err)
+		}
+
+		if executable, err = filepath.EvalSymlinks(executable); err != nil {
+			return fmt.Errorf("%w: %w", ErrSelfUpdate, err)
+		}
+
+		latest, err := selfUpdate(ctx, http.DefaultClient, latestReleaseURL, executable, version(), check)
+		switch {
+		case err != nil:
+			return err
+		case quiet:
+		case latest == "":
+			fmt.Printf("entmaid %s is the latest release.\n", version())
+		case check:
+			fmt.Printf("entmaid %s is available (this is %s), run 'entmaid self-update' to install it.\n", latest, version())
+		default:
+			fmt.Printf("Updated %s to entmaid %s.\n", executable, latest)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(selfUpdateCmd)
+}
+
+// selfUpdate replaces the executable by the binary of the latest release when it's newer than the current version,
+// returning the version of the release, or an empty string when there's nothing to update. Development builds
+// without a semantic version are always updated. In check mode, nothing is downloaded nor replaced.
+func selfUpdate(ctx context.Context, client *http.Client, releaseURL string, executable string, current string, check bool) (string, error) {
+	body, err := download(ctx, client, releaseURL)
+	if err != nil {
+		return "", err
+	}
+
+	var release githubRelease
+	if err := json.Unmarshal(body, &release); err != nil {
+		return "", fmt.Errorf("%w: invalid release: %w", ErrSelfUpdate, err)
+	}
+
+	if !semver.IsValid(release.TagName) {
+		return "", fmt.Errorf("%w: the latest release %q isn't a semantic version", ErrSelfUpdate, release.TagName)
+	}
+
+	if semver.IsValid(current) && semver.Compare(release.TagName, current) <= 0 {
+		return "", nil
+	}
+
+	if check {
+		return release.TagName, nil
+	}
+
+	name := releaseAssetName(runtime.GOOS, runtime.GOARCH)
+	assets := make(map[string]string, len(release.Assets))
+	for _, asset := range release.Assets {
+		assets[asset.Name] = asset.URL
+	}
+
+	if assets[name] == "" || assets[checksumsAsset] == "" {
+		return "", fmt.Errorf("%w: release %s has no %s binary with checksums, install it with 'go install github.com/lespea/entmaid@%s'",
+			ErrSelfUpdate, release.TagName, name, release.TagName)
+	}
+
+	checksums, err := download(ctx, client, assets[checksumsAsset])
+	if err != nil {
+		return "", err
+	}
+
+	expected, err := assetChecksum(checksums, name)
+	if err != nil {
+		return "", err
+	}
+
+	binary, err := download(ctx, client, assets[name])
+	if err != nil {
+		return "", err
+	}
+
+	if sum := sha256.Sum256(binary); hex.EncodeToString(sum[:]) != expected {
+		return "", fmt.Errorf("%w: the checksum of %s doesn't match the one of %s", ErrSelfUpdate, name, checksumsAsset)
+	}
+
+	if err := replaceExecutable(executable, binary); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrSelfUpdate, err)
+	}
+
+	return release.TagName, nil
+}
+
+// releaseAssetName is the name of the binary built for the platform by 'make dist'.
+func releaseAssetName(goos string, goarch string) string {
+	name := fmt.Sprintf("entmaid_%s_%s", goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+
+	return name
+}
+
+// assetChecksum finds the SHA-256 of the asset in the checksums written by sha256sum.
+func assetChecksum(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// sha256sum marks the files read in binary mode with a '*'.
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s has no checksum for %s", ErrSelfUpdate, checksumsAsset, name)
+}
+
+func download(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSelfUpdate, err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSelfUpdate, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: GET %s: %s", ErrSelfUpdate, url, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxReleaseAssetSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSelfUpdate, err)
+	}
+
+	if len(body) > maxReleaseAssetSize {
+		return nil, fmt.Errorf("%w: GET %s: larger than %d bytes", ErrSelfUpdate, url, maxReleaseAssetSize)
+	}
+
+	return body, nil
+}
+
+// replaceExecutable writes the binary next to the executable and renames it over, so the executable is never left
+// half written. Windows can't replace a running executable, it's moved aside first.
+func replaceExecutable(executable string, binary []byte) error {
+	info, err := os.Stat(executable)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(executable), ".entmaid-update-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	if runtime.GOOS == "windows" {
+		old := executable + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(executable, old); err != nil {
+			return err
+		}
+	}
+
+	return os.Rename(tmp.Name(), executable)
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestSelfUpdate(t *testing.T) {
+	binary := []byte("#!/bin/sh\necho new\n")
+	sum := sha256.Sum256(binary)
+	checksum := hex.EncodeToString(sum[:])
+	name := releaseAssetName(runtime.GOOS, runtime.GOARCH)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest":
+			fmt.Fprintf(w, `{"tag_name": "v1.2.0", "assets": [{"name": %q, "browser_download_url": %q}, {"name": %q, "browser_download_url": %q}]}`,
+				name, server.URL+"/binary", checksumsAsset, server.URL+"/checksums")
+		case "/binary":
+			_, _ = w.Write(binary)
+		case "/checksums":
+			fmt.Fprintf(w, "%s  entmaid_plan9_386\n%s *%s\n", checksum, checksum, name)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	executable := filepath.Join(t.TempDir(), "entmaid")
+	if err := os.WriteFile(executable, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := selfUpdate(context.Background(), server.Client(), server.URL+"/latest", executable, "v1.2.0", false)
+	if err != nil || latest != "" {
+		t.Fatalf("Expected the latest release to be left alone, got %q: %v", latest, err)
+	}
+
+	latest, err = selfUpdate(context.Background(), server.Client(), server.URL+"/latest", executable, "v1.1.0", true)
+	if err != nil || latest != "v1.2.0" {
+		t.Fatalf("Expected v1.2.0 to be available, got %q: %v", latest, err)
+	}
+
+	if content, _ := os.ReadFile(executable); string(content) != "old" {
+		t.Errorf("Expected the executable to be left alone in check mode, got %q", content)
+	}
+
+	latest, err = selfUpdate(context.Background(), server.Client(), server.URL+"/latest", executable, "v1.1.0", false)
+	if err != nil || latest != "v1.2.0" {
+		t.Fatalf("Expected the executable to be updated to v1.2.0, got %q: %v", latest, err)
+	}
+
+	if content, _ := os.ReadFile(executable); string(content) != string(binary) {
+		t.Errorf("Expected the executable to be replaced, got %q", content)
+	}
+
+	if info, _ := os.Stat(executable); runtime.GOOS != "windows" && info.Mode().Perm() != 0o755 {
+		t.Errorf("Expected the executable to keep its mode, got %v", info.Mode())
+	}
+}
+
+func TestSelfUpdateChecksumMismatch(t *testing.T) {
+	name := releaseAssetName(runtime.GOOS, runtime.GOARCH)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest":
+			fmt.Fprintf(w, `{"tag_name": "v1.2.0", "assets": [{"name": %q, "browser_download_url": %q}, {"name": %q, "browser_download_url": %q}]}`,
+				name, server.URL+"/binary", checksumsAsset, server.URL+"/checksums")
+		case "/binary":
+			_, _ = w.Write([]byte("tampered"))
+		case "/checksums":
+			fmt.Fprintf(w, "%064x  %s\n", 0, name)
+		}
+	}))
+	defer server.Close()
+
+	executable := filepath.Join(t.TempDir(), "entmaid")
+	if err := os.WriteFile(executable, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := selfUpdate(context.Background(), server.Client(), server.URL+"/latest", executable, "(devel)", false); !errors.Is(err, ErrSelfUpdate) {
+		t.Errorf("Expected the checksum mismatch to fail the update, got %v", err)
+	}
+
+	if content, _ := os.ReadFile(executable); string(content) != "old" {
+		t.Errorf("Expected the executable to be left alone, got %q", content)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(executable))
+	if len(entries) != 1 {
+		t.Errorf("Expected no leftover temporary file, got %d entries", len(entries))
+	}
+}
+
+func TestSelfUpdateWithoutBinary(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"tag_name": "v1.2.0", "assets": []}`)
+	}))
+	defer server.Close()
+
+	if _, err := selfUpdate(context.Background(), server.Client(), server.URL, "entmaid", "v1.0.0", false); !errors.Is(err, ErrSelfUpdate) {
+		t.Errorf("Expected a release without binaries to fail the update, got %v", err)
+	}
+}
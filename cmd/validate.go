@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// WithValidate checks the Mermaid diagram against the erDiagram grammar before anything is written, failing with
+// ErrInvalidDiagram and the offending line instead of leaving a diagram GitHub can't render in the target.
+func WithValidate() Option {
+	return func(o *options) {
+		o.validate = true
+	}
+}
+
+const (
+	mermaidNamePattern  = `(?:[A-Za-z_][A-Za-z0-9_-]*|"[^"]*")`
+	mermaidWordPattern  = `[*A-Za-z_][A-Za-z0-9_\-\[\]()]*`
+	mermaidKeyPattern   = `(?:PK|FK|UK)`
+	mermaidQuotePattern = `"[^"]*"`
+)
+
+var (
+	mermaidEntityLineRegex = regexp.MustCompile(`^(` + mermaidNamePattern + `)(?:\[` + mermaidQuotePattern + `\])?\s*\{$`)
+	mermaidAttributeRegex  = regexp.MustCompile(`^` + mermaidWordPattern + `\s+` + mermaidWordPattern +
+		`(?:\s+` + mermaidKeyPattern + `(?:\s*,\s*` + mermaidKeyPattern + `)*)?(?:\s+` + mermaidQuotePattern + `)?$`)
+	mermaidStyleRegex        = regexp.MustCompile(`^style\s+` + mermaidNamePattern + `\s+\S+$`)
+	mermaidRelationshipRegex = regexp.MustCompile(`^(` + mermaidNamePattern + `)\s+(?:\|o|\|\||\}o|\}\|)(?:--|\.\.)(?:o\||\|\||o\{|\|\{)\s+(` +
+		mermaidNamePattern + `)\s*:\s*(?:[A-Za-z_][A-Za-z0-9_-]*|` + mermaidQuotePattern + `)$`)
+)
+
+// validateMermaid checks every line of the diagram is an entity, attribute, relationship, style or comment as the
+// erDiagram grammar expects them, returning the first line that isn't.
+func validateMermaid(diagram string) error {
+	lines := strings.Split(diagram, "\n")
+
+	inEntity := false
+	typed := false
+	frontmatter := false
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		invalid := func(reason string) error {
+			return fmt.Errorf("%w: line %d %s: %s", ErrInvalidDiagram, i+1, reason, trimmed)
+		}
+
+		switch {
+		case frontmatter:
+			frontmatter = trimmed != "---"
+			continue
+		case trimmed == "---" && !typed:
+			frontmatter = true
+			continue
+		case trimmed == "" || strings.HasPrefix(trimmed, "%%"):
+			continue
+		case !typed:
+			if trimmed != "erDiagram" {
+				return invalid("should declare the erDiagram type")
+			}
+			typed = true
+			continue
+		}
+
+		if inEntity {
+			switch {
+			case trimmed == "}":
+				inEntity = false
+			case !mermaidAttributeRegex.MatchString(trimmed):
+				return invalid("is not a valid attribute")
+			}
+			continue
+		}
+
+		if match := mermaidEntityLineRegex.FindStringSubmatch(trimmed); match != nil {
+			if mermaidKeywords[strings.ToLower(match[1])] {
+				return invalid("uses a keyword as an unquoted entity name")
+			}
+
+			inEntity = true
+			continue
+		}
+
+		if match := mermaidRelationshipRegex.FindStringSubmatch(trimmed); match != nil {
+			for _, name := range match[1:3] {
+				if mermaidKeywords[strings.ToLower(name)] {
+					return invalid("uses a keyword as an unquoted entity name")
+				}
+			}
+			continue
+		}
+
+		if mermaidStyleRegex.MatchString(trimmed) {
+			continue
+		}
+
+		return invalid("is not an entity, relationship, style or comment")
+	}
+
+	switch {
+	case !typed:
+		return fmt.Errorf("%w: the erDiagram type is missing", ErrInvalidDiagram)
+	case inEntity:
+		return fmt.Errorf("%w: line %d: the last entity is never closed", ErrInvalidDiagram, len(lines))
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateMermaid(t *testing.T) {
+	valid := "---\ntitle: Schema\n---\nerDiagram\n %% comment\n User {\n  int id PK\n  string name \"default: unknown\"\n }\n\n" +
+		" ActiveUser[\"ActiveUser (view)\"] {\n  int id\n }\n\n \"Order Item\" {\n  int user_id FK\n }\n\n" +
+		" User |o--o{ \"Order Item\" : items-user\n User ||..|| User : \"has parent\"\n style User fill:#f96,stroke:#c00\n"
+	if err := validateMermaid(valid); err != nil {
+		t.Errorf("Expected the diagram to be valid, got %v", err)
+	}
+
+	invalid := map[string]string{
+		"erDiagram\n User {\n  int id PK PK2\n }\n":    "line 3 is not a valid attribute",
+		"erDiagram\n class {\n }\n":                    "line 2 uses a keyword",
+		"erDiagram\n User |o--o{ Car owns\n":           "line 2 is not an entity, relationship, style or comment",
+		"erDiagram\n User {\n  int id PK\n":            "the last entity is never closed",
+		"graph TD\n A --> B\n":                         "line 1 should declare the erDiagram type",
+		"erDiagram\n User |o--o{ Order Item : items\n": "line 2 is not an entity",
+	}
+
+	for diagram, expected := range invalid {
+		err := validateMermaid(diagram)
+		if !errors.Is(err, ErrInvalidDiagram) || !strings.Contains(err.Error(), expected) {
+			t.Errorf("validateMermaid(%q) = %v, expected %q", diagram, err, expected)
+		}
+	}
+}
+
+func TestGenerateDiagramValidate(t *testing.T) {
+	for _, example := range []string{"start", "m2m2types", "edgefield", "edgeschema", "customtypes", "multischema", "views", "storagekey", "annotations"} {
+		mermaidPath := filepath.Join(t.TempDir(), "erd.mmd")
+
+		err := GenerateDiagram("../examples/"+example+"/schema", "", Plain, "", "", WithValidate(), WithMaxTypeLength(4), WithOutput(FormatMermaid, mermaidPath))
+		if err != nil {
+			t.Errorf("Expected the %s diagram to be valid, got %v", example, err)
+		}
+	}
+}
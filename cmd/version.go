@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Version, Commit and Date describe the entmaid build, set at build time with
+// -ldflags "-X github.com/lespea/entmaid/cmd.Version=... -X github.com/lespea/entmaid/cmd.Commit=...".
+// When unset, the module version and the VCS settings of the build info are used instead.
+var (
+	Version = ""
+	Commit  = ""
+	Date    = ""
+)
+
+const entModule = "entgo.io/ent"
+
+// buildMetadata is what entmaid was built from, the ent version in particular changes how the schemas are loaded
+// and so the generated diagrams.
+type buildMetadata struct {
+	Version string
+	Commit  string
+	Date    string
+	Ent     string
+	Go      string
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the entmaid version, commit and build date and the ent version it was built with",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return writeBuildMetadata(os.Stdout, newBuildMetadata(readBuildInfo()))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+}
+
+func version() string {
+	return newBuildMetadata(readBuildInfo()).Version
+}
+
+func readBuildInfo() *debug.BuildInfo {
+	info, _ := debug.ReadBuildInfo()
+	return info
+}
+
+// newBuildMetadata fills what wasn't set at build time from the build info, which is nil when unavailable.
+func newBuildMetadata(info *debug.BuildInfo) buildMetadata {
+	metadata := buildMetadata{Version: Version, Commit: Commit, Date: Date, Go: runtime.Version()}
+
+	if info != nil {
+		if metadata.Version == "" && info.Main.Version != "" {
+			metadata.Version = info.Main.Version
+		}
+
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && metadata.Commit == "":
+				metadata.Commit = setting.Value
+			case setting.Key == "vcs.time" && metadata.Date == "":
+				metadata.Date = setting.Value
+			}
+		}
+
+		for _, dep := range info.Deps {
+			if dep.Path != entModule {
+				continue
+			}
+
+			metadata.Ent = dep.Version
+			if replace := dep.Replace; replace != nil {
+				metadata.Ent = strings.TrimSpace(fmt.Sprintf("%s (replaced by %s %s", dep.Version, replace.Path, replace.Version)) + ")"
+			}
+		}
+	}
+
+	if metadata.Version == "" {
+		metadata.Version = "(devel)"
+	}
+
+	return metadata
+}
+
+func writeBuildMetadata(w io.Writer, metadata buildMetadata) error {
+	unknown := func(value string) string {
+		if value == "" {
+			return "unknown"
+		}
+		return value
+	}
+
+	_, err := fmt.Fprintf(w, "entmaid %s\ncommit: %s\nbuilt:  %s\nent:    %s\ngo:     %s\n",
+		metadata.Version, unknown(metadata.Commit), unknown(metadata.Date), unknown(metadata.Ent), metadata.Go)
+	return err
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestNewBuildMetadata(t *testing.T) {
+	info := &debug.BuildInfo{
+		Main: debug.Module{Path: "github.com/lespea/entmaid", Version: "v1.2.3"},
+		Deps: []*debug.Module{
+			{Path: "github.com/spf13/cobra", Version: "v1.8.1"},
+			{Path: entModule, Version: "v0.14.5"},
+		},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "abc123"},
+			{Key: "vcs.time", Value: "2024-01-02T03:04:05Z"},
+		},
+	}
+
+	metadata := newBuildMetadata(info)
+	expected := buildMetadata{Version: "v1.2.3", Commit: "abc123", Date: "2024-01-02T03:04:05Z", Ent: "v0.14.5", Go: runtime.Version()}
+	if metadata != expected {
+		t.Errorf("Expected %+v, got %+v", expected, metadata)
+	}
+
+	info.Deps[1].Replace = &debug.Module{Path: "../ent", Version: ""}
+	if got := newBuildMetadata(info).Ent; got != "v0.14.5 (replaced by ../ent)" {
+		t.Errorf("Expected the replaced ent version, got %q", got)
+	}
+
+	Commit = "def456"
+	defer func() { Commit = "" }()
+
+	if got := newBuildMetadata(info).Commit; got != "def456" {
+		t.Errorf("Expected the commit set at build time to win, got %q", got)
+	}
+}
+
+func TestWriteBuildMetadata(t *testing.T) {
+	var builder strings.Builder
+	if err := writeBuildMetadata(&builder, newBuildMetadata(nil)); err != nil {
+		t.Fatalf("Failed to write the build metadata: %v", err)
+	}
+
+	expected := "entmaid (devel)\ncommit: unknown\nbuilt:  unknown\nent:    unknown\ngo:     " + runtime.Version() + "\n"
+	if builder.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, builder.String())
+	}
+}
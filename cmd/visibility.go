@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"github.com/thediveo/enumflag/v2"
+
+	"github.com/lespea/entmaid/annotation"
+)
+
+// Visibility is the audience of the diagram, it draws the entities and fields visible to it.
+type Visibility enumflag.Flag
+
+const (
+	// VisibilityPrivate draws everything, whatever its visibility.
+	VisibilityPrivate Visibility = iota
+	// VisibilityInternal draws the public and internal entities and fields.
+	VisibilityInternal
+	// VisibilityPublic only draws the public entities and fields.
+	VisibilityPublic
+)
+
+var VisibilityIds = map[Visibility][]string{
+	VisibilityPrivate:  {string(annotation.VisibilityPrivate)},
+	VisibilityInternal: {string(annotation.VisibilityInternal)},
+	VisibilityPublic:   {string(annotation.VisibilityPublic)},
+}
+
+// WithVisibility only draws the entities and fields visible to the audience, from their annotation.Visible
+// annotations, so the diagram of the public documentation and the full internal one come from the same schema.
+// The relationships and join tables of the entities left out are left out as well.
+func WithVisibility(visibility Visibility) Option {
+	return func(o *options) {
+		o.visibility = visibility
+	}
+}
+
+// schemaVisibility returns the visibility of the annotation, public when it has none. An unknown visibility is
+// private, so a typo never publishes what was meant to be hidden.
+func schemaVisibility(visibility annotation.Visibility, entity string, element string, o *options) Visibility {
+	switch visibility {
+	case "", annotation.VisibilityPublic:
+		return VisibilityPublic
+	case annotation.VisibilityInternal:
+		return VisibilityInternal
+	case annotation.VisibilityPrivate:
+		return VisibilityPrivate
+	default:
+		o.warn(entity, element, "unknown visibility %q, drawn as private", visibility)
+		return VisibilityPrivate
+	}
+}
+
+// visibleModel returns the model without the entities and fields hidden from the audience of the diagram, along with
+// the relationships and join tables of the hidden entities.
+func visibleModel(model *Model, o *options) *Model {
+	var names []string
+	for _, entity := range model.Entities {
+		switch {
+		case entity.JoinTable:
+		case schemaVisibility(entity.visibility, entity.Name, "", o) < o.visibility:
+			o.skipEntity(entity.Name, "not visible with --visibility "+VisibilityIds[o.visibility][0])
+		default:
+			names = append(names, entity.Name)
+		}
+	}
+
+	filtered := filterEntities(model, names)
+	for _, entity := range filtered.Entities {
+		attributes := make([]*Attribute, 0, len(entity.Attributes))
+		for _, attribute := range entity.Attributes {
+			if schemaVisibility(attribute.visibility, entity.Name, attribute.Name, o) >= o.visibility {
+				attributes = append(attributes, attribute)
+			}
+		}
+		entity.Attributes = attributes
+	}
+
+	return filtered
+}
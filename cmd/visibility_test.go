@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestVisibleModel(t *testing.T) {
+	for _, tc := range []struct {
+		visibility Visibility
+		entities   []string
+		password   bool
+	}{
+		{VisibilityPrivate, []string{"Customer", "Order", "OrderLine"}, true},
+		{VisibilityInternal, []string{"Customer", "Order", "OrderLine"}, false},
+		{VisibilityPublic, []string{"Customer", "Order"}, false},
+	} {
+		t.Run(VisibilityIds[tc.visibility][0], func(t *testing.T) {
+			o := newOptions([]Option{WithQuiet(), WithVisibility(tc.visibility)})
+
+			model, err := loadModel(context.Background(), "../examples/annotations/schema", o)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if names := entityNames(model.Entities); !slices.Equal(names, tc.entities) {
+				t.Errorf("Expected the entities %v, got %v", tc.entities, names)
+			}
+			if password := model.Entity("Customer").Attribute("password_hash") != nil; password != tc.password {
+				t.Errorf("Expected the password hash to be drawn: %t", tc.password)
+			}
+			for _, rel := range model.Relationships {
+				if model.Entity(rel.From) == nil || model.Entity(rel.To) == nil {
+					t.Errorf("The relationship %s-%s is to a hidden entity", rel.From, rel.To)
+				}
+			}
+		})
+	}
+}
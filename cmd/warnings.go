@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Warning describes a schema construct that was skipped or couldn't be fully represented in the diagram, or an issue
+// of the whole diagram when it has no entity.
+type Warning struct {
+	Entity  string `json:"entity"`
+	Element string `json:"element,omitempty"`
+	Message string `json:"message"`
+	// Rule is the lint rule of the findings of WithLint.
+	Rule LintRule `json:"rule,omitempty"`
+}
+
+func (w Warning) String() string {
+	message := w.Message
+	if w.Rule != "" {
+		message += fmt.Sprintf(" (%s)", w.Rule)
+	}
+
+	if w.Entity == "" {
+		return message
+	}
+	if w.Element == "" {
+		return fmt.Sprintf("%s: %s", w.Entity, message)
+	}
+
+	return fmt.Sprintf("%s.%s: %s", w.Entity, w.Element, message)
+}
+
+// warn records the warning and passes it to the configured handler.
+func (o *options) warn(entity string, element string, format string, args ...any) {
+	w := Warning{Entity: entity, Element: element, Message: fmt.Sprintf(format, args...)}
+
+	o.logger.Debug("skipped schema construct", "entity", w.Entity, "element", w.Element, "reason", w.Message)
+
+	o.addWarning(w)
+}
+
+// addWarning records the warning and passes it to the configured handler.
+func (o *options) addWarning(w Warning) {
+	o.warnings = append(o.warnings, w)
+	if o.warningHandler != nil {
+		o.warningHandler(w)
+	}
+}
+
+// writeWarnings prints the warnings one per line, or as a JSON array when asJSON is set.
+func writeWarnings(w io.Writer, warnings []Warning, asJSON bool) error {
+	if asJSON {
+		if warnings == nil {
+			warnings = []Warning{}
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(warnings)
+	}
+
+	for _, warning := range warnings {
+		if _, err := fmt.Fprintf(w, "warning: %s\n", warning); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestGenerateDiagramWarnings(t *testing.T) {
+	var warnings []Warning
+	handler := WithWarningHandler(func(w Warning) {
+		warnings = append(warnings, w)
+	})
+
+	err := GenerateDiagram("../examples/views/schema", "../examples/views/readme-expected.md", Markdown, defaultStartPattern, defaultEndPattern, WithCheck(), WithAnalyze(), handler)
+	if err != nil {
+		t.Fatalf("Failed to generate diagram: %v", err)
+	}
+
+	expected := Warning{Entity: "User", Message: "has no relationships"}
+	if len(warnings) != 1 || warnings[0] != expected {
+		t.Errorf("Got warnings %v, expected only %v", warnings, expected)
+	}
+
+	err = GenerateDiagram("../examples/views/schema", "../examples/views/readme-expected.md", Markdown, defaultStartPattern, defaultEndPattern, WithCheck(), WithAnalyze(), WithStrict())
+	if !errors.Is(err, ErrWarnings) {
+		t.Errorf("Expected strict mode to fail with ErrWarnings, got %v", err)
+	}
+}
+
+func TestWriteWarnings(t *testing.T) {
+	warnings := []Warning{{Entity: "Post", Element: "author_id", Message: "skipped"}, {Entity: "Group", Message: "skipped"}}
+
+	var text bytes.Buffer
+	if err := writeWarnings(&text, warnings, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "warning: Post.author_id: skipped\nwarning: Group: skipped\n"; text.String() != expected {
+		t.Errorf("Got %q, expected %q", text.String(), expected)
+	}
+
+	var empty bytes.Buffer
+	if err := writeWarnings(&empty, nil, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "[]\n"; empty.String() != expected {
+		t.Errorf("Got %q, expected %q", empty.String(), expected)
+	}
+}
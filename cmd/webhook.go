@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// WithWebhook posts a summary of the entities and relationships added to or removed from the diagram to the webhook
+// URL, when the check of the target finds its diagram out of date. The payload has the text of a Slack incoming
+// webhook along with the changes themselves.
+func WithWebhook(url string) Option {
+	return func(o *options) {
+		o.webhookURL = url
+	}
+}
+
+// ShapeChanges are the entities and relationships added to or removed from the diagram. The relationships are
+// written as "From to To (label)".
+type ShapeChanges struct {
+	AddedEntities        []string `json:"addedEntities,omitempty"`
+	RemovedEntities      []string `json:"removedEntities,omitempty"`
+	AddedRelationships   []string `json:"addedRelationships,omitempty"`
+	RemovedRelationships []string `json:"removedRelationships,omitempty"`
+}
+
+// empty reports whether the shape of the diagram is unchanged, e.g. when only attributes changed.
+func (c *ShapeChanges) empty() bool {
+	return len(c.AddedEntities) == 0 && len(c.RemovedEntities) == 0 && len(c.AddedRelationships) == 0 && len(c.RemovedRelationships) == 0
+}
+
+// webhookPayload is the body posted to the webhook.
+type webhookPayload struct {
+	Text    string        `json:"text"`
+	Target  string        `json:"target"`
+	Changes *ShapeChanges `json:"changes"`
+}
+
+// diagramShape returns the sorted entities and relationships of the erDiagrams in the content.
+func diagramShape(content string) (entities []string, relationships []string) {
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if match := mermaidEntityLineRegex.FindStringSubmatch(trimmed); match != nil {
+			entities = append(entities, strings.Trim(match[1], `"`))
+		} else if match := mermaidRelationshipRegex.FindStringSubmatch(trimmed); match != nil {
+			label := strings.TrimSpace(trimmed[strings.LastIndex(trimmed, ":")+1:])
+			relationships = append(relationships, fmt.Sprintf("%s to %s (%s)", strings.Trim(match[1], `"`), strings.Trim(match[2], `"`), strings.Trim(label, `"`)))
+		}
+	}
+
+	slices.Sort(entities)
+	slices.Sort(relationships)
+
+	// The stubs of paginated diagrams repeat the entities drawn on other pages.
+	return slices.Compact(entities), slices.Compact(relationships)
+}
+
+// shapeChanges compares the shape of the previous diagram to the one of the current diagram.
+func shapeChanges(previous string, current string) *ShapeChanges {
+	previousEntities, previousRelationships := diagramShape(previous)
+	currentEntities, currentRelationships := diagramShape(current)
+
+	return &ShapeChanges{
+		AddedEntities:        missingFrom(currentEntities, previousEntities),
+		RemovedEntities:      missingFrom(previousEntities, currentEntities),
+		AddedRelationships:   missingFrom(currentRelationships, previousRelationships),
+		RemovedRelationships: missingFrom(previousRelationships, currentRelationships),
+	}
+}
+
+// missingFrom returns the values that aren't in the other values.
+func missingFrom(values []string, other []string) []string {
+	var missing []string
+	for _, value := range values {
+		if !slices.Contains(other, value) {
+			missing = append(missing, value)
+		}
+	}
+
+	return missing
+}
+
+// shapeSummary returns the text summing the changes up, a line per kind of change.
+func shapeSummary(targetPath string, changes *ShapeChanges) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("The schema diagram in %s changed shape:", targetPath))
+
+	for _, kind := range []struct {
+		name   string
+		values []string
+	}{
+		{"Added entities", changes.AddedEntities},
+		{"Removed entities", changes.RemovedEntities},
+		{"Added relationships", changes.AddedRelationships},
+		{"Removed relationships", changes.RemovedRelationships},
+	} {
+		if len(kind.values) > 0 {
+			builder.WriteString(fmt.Sprintf("\n%s: %s", kind.name, strings.Join(kind.values, ", ")))
+		}
+	}
+
+	return builder.String()
+}
+
+// notifyShapeChanges posts the changes of shape between the diagrams in the target and the generated one to the
+// webhook, unless only the attributes or the styles changed.
+func notifyShapeChanges(ctx context.Context, targetPath string, mermaidCode string, startPattern string, endPattern string, o *options) error {
+	content, err := readTargetFile(targetPath, o)
+	if err != nil {
+		return inFile(targetPath, fmt.Errorf("%w: %w", ErrTarget, err))
+	}
+
+	regions, err := findMarkerRegions(string(content), startPattern, endPattern)
+	if err != nil {
+		return inFile(targetPath, err)
+	}
+
+	var previous strings.Builder
+	for _, region := range regions {
+		previous.WriteString(string(content[region.startIndex+len(startPattern) : region.endIndex]))
+	}
+
+	changes := shapeChanges(previous.String(), mermaidCode)
+	if changes.empty() {
+		o.logger.Info("diagram changed without changing shape, skipping webhook", "target", targetPath)
+		return nil
+	}
+
+	body, err := json.Marshal(webhookPayload{Text: shapeSummary(targetPath, changes), Target: targetPath, Changes: changes})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWebhook, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWebhook, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWebhook, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%w: POST returned %s: %s", ErrWebhook, resp.Status, bytes.TrimSpace(message))
+	}
+
+	o.logger.Info("notified webhook of the shape changes", "target", targetPath)
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestShapeChanges(t *testing.T) {
+	previous := "erDiagram\n User {\n  int id PK\n }\n Car {\n }\n Pet {\n }\n User |o--o{ Car : cars-owner\n User |o--o{ Pet : pets-owner\n"
+	current := "erDiagram\n User {\n  int id PK\n  string name\n }\n Car {\n }\n \"Order Item\"[\"Order Item (view)\"] {\n }\n User |o--o{ Car : cars-owner\n User ||--o{ \"Order Item\" : items\n"
+
+	expected := &ShapeChanges{
+		AddedEntities:        []string{"Order Item"},
+		RemovedEntities:      []string{"Pet"},
+		AddedRelationships:   []string{"User to Order Item (items)"},
+		RemovedRelationships: []string{"User to Pet (pets-owner)"},
+	}
+	if changes := shapeChanges(previous, current); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Got the changes %+v, expected %+v", changes, expected)
+	}
+
+	if changes := shapeChanges(previous, strings.ReplaceAll(previous, "int id PK", "bigint id PK")); !changes.empty() {
+		t.Errorf("Expected no change of shape for a changed attribute, got %+v", changes)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var payloads []webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload webhookPayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		payloads = append(payloads, payload)
+	}))
+	defer server.Close()
+
+	target := filepath.Join(t.TempDir(), "erd.md")
+	content := "<!-- #start:entmaid -->\n```mermaid\nerDiagram\n User {\n }\n Pet {\n }\n```\n<!-- #end:entmaid -->\n"
+	if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := GenerateDiagram("../examples/start/schema", target, Markdown, "", "", WithCheck(), WithWebhook(server.URL))
+	if !errors.Is(err, ErrStaleDiagram) || ExitCode(err) != ExitStale {
+		t.Errorf("Expected the stale diagram error, got %v", err)
+	}
+
+	if len(payloads) != 1 {
+		t.Fatalf("Expected a single notification, got %d", len(payloads))
+	}
+	changes := payloads[0].Changes
+	if !reflect.DeepEqual(changes.AddedEntities, []string{"Car", "Group", "group_users"}) || !reflect.DeepEqual(changes.RemovedEntities, []string{"Pet"}) || !slices.Contains(changes.AddedRelationships, "User to Car (cars-owner)") {
+		t.Errorf("Unexpected changes posted: %+v", changes)
+	}
+	if !strings.Contains(payloads[0].Text, "Removed entities: Pet") {
+		t.Errorf("Unexpected summary posted: %q", payloads[0].Text)
+	}
+
+	err = GenerateDiagram("../examples/start/schema", target, Markdown, "", "", WithCheck(), WithWebhook(server.URL+"/missing\x7f"))
+	if !errors.Is(err, ErrWebhook) || !errors.Is(err, ErrStaleDiagram) {
+		t.Errorf("Expected the webhook error along with the stale diagram, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"github.com/thediveo/enumflag/v2"
+)
+
+// RelationshipWeight is what the width of the relationships is derived from, for the formats drawing it (draw.io
+// and GraphML). The relationships annotated with annotation.Weighted keep their weight.
+type RelationshipWeight enumflag.Flag
+
+const (
+	// WeightNone draws the relationships that aren't annotated with the default width, the default.
+	WeightNone RelationshipWeight = iota
+	// WeightEdges weighs the relationships by the number of relationships between the same two entities, so the
+	// entities joined in several ways stand out.
+	WeightEdges
+	// WeightMultiplicity weighs the relationships by one more than their number of many sides: 1 for O2O, 2 for O2M
+	// (as are the relationships to the join tables of M2M edges) and 3 when both sides are many.
+	WeightMultiplicity
+)
+
+var RelationshipWeightIds = map[RelationshipWeight][]string{
+	WeightNone:         {"none"},
+	WeightEdges:        {"edges"},
+	WeightMultiplicity: {"multiplicity"},
+}
+
+// WithWeightBy derives the weight of the relationships that aren't annotated with one.
+func WithWeightBy(weight RelationshipWeight) Option {
+	return func(o *options) {
+		o.weightBy = weight
+	}
+}
+
+// weighRelationships sets the weight of the relationships of the model that don't have one.
+func weighRelationships(model *Model, weight RelationshipWeight) {
+	pairs := make(map[[2]string]int)
+	for _, rel := range model.Relationships {
+		pairs[relationshipPair(rel)]++
+	}
+
+	for _, rel := range model.Relationships {
+		if rel.Weight != 0 {
+			continue
+		}
+
+		switch weight {
+		case WeightEdges:
+			rel.Weight = pairs[relationshipPair(rel)]
+		case WeightMultiplicity:
+			rel.Weight = 1
+			for _, cardinality := range []Cardinality{rel.FromCardinality, rel.ToCardinality} {
+				if !isSingle(cardinality) {
+					rel.Weight++
+				}
+			}
+		}
+	}
+}
+
+// relationshipPair returns the entities of the relationship, whichever side it's drawn from.
+func relationshipPair(rel *Relationship) [2]string {
+	if rel.From > rel.To {
+		return [2]string{rel.To, rel.From}
+	}
+
+	return [2]string{rel.From, rel.To}
+}
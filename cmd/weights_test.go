@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"entgo.io/ent/entc/gen"
+
+	"github.com/lespea/entmaid/annotation"
+)
+
+func TestWeightedRelationship(t *testing.T) {
+	user, car := &gen.Type{Name: "User"}, &gen.Type{Name: "Car"}
+	cars := &gen.Edge{Name: "cars", Type: car, Rel: gen.Relation{Type: gen.O2M}}
+	owner := &gen.Edge{Name: "owner", Type: user, Ref: cars, Inverse: "cars", Rel: gen.Relation{Type: gen.O2M}, Annotations: gen.Annotations{annotation.Name: annotation.Weighted(3)}}
+	cars.Ref = owner
+
+	o := newOptions([]Option{WithQuiet()})
+
+	// The weight of the back-reference applies to the relationship of the edge.
+	rel := &Relationship{}
+	overrideRelationship(rel, user, cars, o)
+	if rel.Weight != 3 {
+		t.Errorf("Expected the weight of the back-reference, got %+v", rel)
+	}
+
+	cars.Annotations = gen.Annotations{annotation.Name: annotation.Weighted(-1)}
+	overrideRelationship(&Relationship{}, user, cars, o)
+	if len(o.warnings) != 1 {
+		t.Errorf("Expected a warning about the negative weight, got %v", o.warnings)
+	}
+}
+
+func TestWeighRelationships(t *testing.T) {
+	newModel := func() *Model {
+		return &Model{Relationships: []*Relationship{
+			{From: "User", To: "Message", FromCardinality: ExactlyOne, ToCardinality: ZeroOrMore, Label: "sent"},
+			{From: "Message", To: "User", FromCardinality: ZeroOrMore, ToCardinality: ExactlyOne, Label: "recipient"},
+			{From: "User", To: "Profile", FromCardinality: ZeroOrOne, ToCardinality: ZeroOrOne, Label: "profile"},
+			{From: "User", To: "Car", FromCardinality: OneOrMore, ToCardinality: ZeroOrMore, Label: "cars", Weight: 5},
+		}}
+	}
+
+	for _, tt := range []struct {
+		weight   RelationshipWeight
+		expected []int
+	}{
+		{WeightEdges, []int{2, 2, 1, 5}},
+		{WeightMultiplicity, []int{2, 2, 1, 5}},
+	} {
+		t.Run(RelationshipWeightIds[tt.weight][0], func(t *testing.T) {
+			model := newModel()
+			weighRelationships(model, tt.weight)
+
+			for i, rel := range model.Relationships {
+				if rel.Weight != tt.expected[i] {
+					t.Errorf("Expected the %s relationship to weigh %d, got %d", rel.Label, tt.expected[i], rel.Weight)
+				}
+			}
+		})
+	}
+
+	model := newModel()
+	model.Relationships[3].Weight = 0
+	weighRelationships(model, WeightMultiplicity)
+	if weight := model.Relationships[3].Weight; weight != 3 {
+		t.Errorf("Expected the M2M relationship to weigh 3, got %d", weight)
+	}
+
+	var buf bytes.Buffer
+	if err := renderDrawIO(context.Background(), &buf, model, newOptions(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "endArrow=ERzeroToMany;strokeWidth=3;") {
+		t.Errorf("Expected the M2M connector to be 3 thick, got\n%s", buf.String())
+	}
+}
@@ -0,0 +1,59 @@
+// Package entmaidtest checks from a unit test that the diagram entmaid generates from the ent schema is up to date,
+// instead of running entmaid --check in CI, e.g.:
+//
+//	func TestDiagram(t *testing.T) {
+//		entmaidtest.AssertDiagramUpToDate(t, "./schema", "../README.md", entmaidtest.Markers{})
+//	}
+//
+// Run the tests with ENTMAID_UPDATE=1 to write the regenerated diagram to the target instead of failing.
+package entmaidtest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lespea/entmaid/cmd"
+)
+
+// UpdateEnv is the environment variable which, when set, makes the assertions update the stale diagrams.
+const UpdateEnv = "ENTMAID_UPDATE"
+
+// Markers are the lines the diagram is placed between in the target, the default ones for the extension of the
+// target when empty (e.g. <!-- #start:entmaid --> and <!-- #end:entmaid --> for Markdown), and how it's fenced.
+type Markers struct {
+	Start string
+	End   string
+	// OutputType fences the diagram, Markdown by default or GoDoc for a .go target, like the entmaid command.
+	OutputType cmd.OutputType
+}
+
+// AssertDiagramUpToDate fails the test when the diagram between the markers of the target doesn't match the one
+// generated from the schema with the options, the same ones as for cmd.GenerateDiagram.
+func AssertDiagramUpToDate(t testing.TB, schemaPath string, targetPath string, markers Markers, opts ...cmd.Option) {
+	t.Helper()
+
+	update := os.Getenv(UpdateEnv) != ""
+
+	opts = append(opts[:len(opts):len(opts)], cmd.WithQuiet())
+	if !update {
+		opts = append(opts, cmd.WithCheck())
+	}
+
+	outputType := markers.OutputType
+	if outputType == cmd.Markdown && strings.EqualFold(filepath.Ext(targetPath), ".go") {
+		outputType = cmd.GoDoc
+	}
+
+	err := cmd.GenerateDiagram(schemaPath, targetPath, outputType, markers.Start, markers.End, opts...)
+	switch {
+	case err == nil:
+	case errors.Is(err, cmd.ErrStaleDiagram):
+		t.Errorf("The diagram in %s is out of date with the schema %s, regenerate it with entmaid or run the tests with %s=1",
+			targetPath, schemaPath, UpdateEnv)
+	default:
+		t.Errorf("Failed to check the diagram in %s: %v", targetPath, err)
+	}
+}
@@ -0,0 +1,93 @@
+package entmaidtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recorder records the failures of the assertions, which should fail the test they're given.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertDiagramUpToDate(t *testing.T) {
+	r := &recorder{TB: t}
+	AssertDiagramUpToDate(r, "../examples/start/schema", "../examples/start/readme.md", Markers{})
+
+	if len(r.failures) != 0 {
+		t.Errorf("Expected the example diagram to be up to date, got %v", r.failures)
+	}
+}
+
+func TestAssertDiagramUpToDateStale(t *testing.T) {
+	content, err := os.ReadFile("../examples/start/readme.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := filepath.Join(t.TempDir(), "readme.md")
+	stale := strings.Replace(string(content), "erDiagram", "erDiagram\n Stale {\n }", 1)
+	if err := os.WriteFile(target, []byte(stale), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &recorder{TB: t}
+	AssertDiagramUpToDate(r, "../examples/start/schema", target, Markers{})
+
+	if len(r.failures) != 1 || !strings.Contains(r.failures[0], "is out of date") {
+		t.Fatalf("Expected the stale diagram to fail the test, got %v", r.failures)
+	}
+
+	t.Setenv(UpdateEnv, "1")
+
+	r = &recorder{TB: t}
+	AssertDiagramUpToDate(r, "../examples/start/schema", target, Markers{})
+
+	if updated, _ := os.ReadFile(target); len(r.failures) != 0 || string(updated) != string(content) {
+		t.Errorf("Expected the stale diagram to be updated, got %v", r.failures)
+	}
+}
+
+func TestAssertDiagramUpToDateGoTarget(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "doc.go")
+	if err := os.WriteFile(target, []byte("// #start:entmaid\n// #end:entmaid\npackage ent\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(UpdateEnv, "1")
+
+	r := &recorder{TB: t}
+	AssertDiagramUpToDate(r, "../examples/start/schema", target, Markers{})
+
+	if updated, _ := os.ReadFile(target); len(r.failures) != 0 || !strings.Contains(string(updated), "//\terDiagram") {
+		t.Fatalf("Expected the diagram to be written as a doc comment, got %v:\n%s", r.failures, updated)
+	}
+
+	t.Setenv(UpdateEnv, "")
+
+	r = &recorder{TB: t}
+	AssertDiagramUpToDate(r, "../examples/start/schema", target, Markers{})
+
+	if len(r.failures) != 0 {
+		t.Errorf("Expected the Go target to be up to date, got %v", r.failures)
+	}
+}
+
+func TestAssertDiagramUpToDateMissingMarkers(t *testing.T) {
+	r := &recorder{TB: t}
+	AssertDiagramUpToDate(r, "../examples/start/schema", "../examples/start/readme.md", Markers{Start: "<!-- nope -->", End: "<!-- nope end -->"})
+
+	if len(r.failures) != 1 || !strings.Contains(r.failures[0], "Failed to check") {
+		t.Errorf("Expected the missing markers to fail the test, got %v", r.failures)
+	}
+}
@@ -0,0 +1,50 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+
+	"github.com/lespea/entmaid/annotation"
+)
+
+// Customer holds the schema definition for the Customer entity.
+type Customer struct {
+	ent.Schema
+}
+
+// Annotations of the Customer.
+func (Customer) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		// Customers hold PII, so they stand out in the diagram and the compliance report.
+		annotation.Styled(annotation.Style{Fill: "#fdd", Stroke: "#c00"}),
+		// Any one schema type describes the whole diagram.
+		annotation.Described("Orders", "The customers and their orders.\nThe order lines are internal."),
+		// The diagrams link the customers to their data catalog entry.
+		annotation.Linked("https://catalog.example.com/tables/customers"),
+	}
+}
+
+// Fields of the Customer.
+func (Customer) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("name"),
+		field.String("email").
+			Annotations(annotation.Classified(annotation.PII)),
+		field.String("password_hash").
+			Sensitive().
+			Annotations(
+				annotation.Classified(annotation.Secret),
+				// Never drawn in the diagrams of the documentation.
+				annotation.Visible(annotation.VisibilityPrivate),
+			),
+	}
+}
+
+// Edges of the Customer.
+func (Customer) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.To("orders", Order.Type),
+	}
+}
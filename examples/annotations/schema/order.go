@@ -0,0 +1,37 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+
+	"github.com/lespea/entmaid/annotation"
+)
+
+// Order holds the schema definition for the Order entity.
+type Order struct {
+	ent.Schema
+}
+
+// Fields of the Order.
+func (Order) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int("total"),
+	}
+}
+
+// Edges of the Order.
+func (Order) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("customer", Customer.Type).
+			Ref("orders").
+			Unique(),
+		edge.To("lines", OrderLine.Type),
+		// Orders are archived to another database, so the link to the order they replace isn't a foreign key.
+		edge.To("replacement", Order.Type).
+			Unique().
+			From("replaces").
+			Unique().
+			Annotations(annotation.Logical()),
+	}
+}
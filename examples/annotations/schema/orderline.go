@@ -0,0 +1,43 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+
+	"github.com/lespea/entmaid/annotation"
+)
+
+// OrderLine holds the schema definition for the OrderLine entity.
+type OrderLine struct {
+	ent.Schema
+}
+
+// Annotations of the OrderLine.
+func (OrderLine) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		// The lines are an implementation detail of the orders for the public API.
+		annotation.Visible(annotation.VisibilityInternal),
+	}
+}
+
+// Fields of the OrderLine.
+func (OrderLine) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("sku"),
+		field.Int("quantity"),
+	}
+}
+
+// Edges of the OrderLine.
+func (OrderLine) Edges() []ent.Edge {
+	return []ent.Edge{
+		// Every order has at least one line, which ent can't enforce.
+		edge.From("order", Order.Type).
+			Ref("lines").
+			Unique().
+			Required().
+			Annotations(annotation.Cardinalities(annotation.OneOrMore, annotation.ExactlyOne)),
+	}
+}
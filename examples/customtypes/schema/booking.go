@@ -0,0 +1,98 @@
+package schema
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/entsql"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/field"
+)
+
+// Booking holds the schema definition for the Booking entity.
+type Booking struct {
+	ent.Schema
+}
+
+// Fields of the Booking.
+func (Booking) Fields() []ent.Field {
+	return []ent.Field{
+		field.Other("period", &Period{}).
+			SchemaType(map[string]string{
+				dialect.Postgres: "tstzrange",
+				dialect.MySQL:    "varchar(64)",
+			}),
+		field.String("status").
+			GoType(Status("")),
+		field.Int64("price").
+			GoType(Cents(0)),
+		field.Bool("paid").
+			GoType(Flag(false)),
+		field.String("reference").
+			MaxLen(32),
+		field.Float("total").
+			SchemaType(map[string]string{
+				dialect.Postgres: "numeric(10,2)",
+			}),
+		field.Time("check_in").
+			SchemaType(map[string]string{
+				dialect.Postgres: "date",
+			}),
+		field.Time("booked_at").
+			SchemaType(map[string]string{
+				dialect.Postgres: "timestamp with time zone",
+				dialect.MySQL:    "datetime(6)",
+			}),
+		field.JSON("address", &Address{}).
+			Optional(),
+	}
+}
+
+// Annotations of the Booking.
+func (Booking) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		&entsql.Annotation{
+			Check: "price >= 0",
+			Checks: map[string]string{
+				"paid_total": "NOT paid OR total > 0",
+			},
+		},
+	}
+}
+
+// Period is the time range of a booking, stored as a range in Postgres.
+type Period struct {
+	Start, End string
+}
+
+// Scan implements the sql.Scanner interface.
+func (p *Period) Scan(value any) error {
+	_, err := fmt.Sscanf(fmt.Sprint(value), "[%s,%s)", &p.Start, &p.End)
+	return err
+}
+
+// Value implements the driver.Valuer interface.
+func (p *Period) Value() (driver.Value, error) {
+	return fmt.Sprintf("[%s,%s)", p.Start, p.End), nil
+}
+
+// Address is where the booking is billed to, stored as a JSON document.
+type Address struct {
+	Street     string     `json:"street"`
+	City       string     `json:"city"`
+	Zip        string     `json:"zip,omitempty"`
+	VerifiedAt *time.Time `json:"verified_at"`
+	Notes      []string   `json:"-"`
+}
+
+// Status is the state of a booking.
+type Status string
+
+// Cents is an amount of money in cents.
+type Cents int64
+
+// Flag is a boolean stored as is.
+type Flag bool
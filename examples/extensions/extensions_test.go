@@ -0,0 +1,84 @@
+package extensions_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"entgo.io/contrib/entgql"
+	"entgo.io/contrib/entoas"
+	"entgo.io/ent/entc"
+
+	"github.com/lespea/entmaid/cmd"
+)
+
+// extensionOptions loads the schema with the extensions the project generates its code with.
+func extensionOptions(t *testing.T) cmd.Option {
+	t.Helper()
+
+	gqlExtension, err := entgql.NewExtension(entgql.WithSchemaPath("ent.graphql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oasExtension, err := entoas.NewExtension()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cmd.WithEntcOptions(entc.Extensions(gqlExtension, oasExtension))
+}
+
+func TestGenerateDiagram(t *testing.T) {
+	readme, err := os.ReadFile("readme.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	targetPath := filepath.Join(t.TempDir(), "readme.md")
+	if err := os.WriteFile(targetPath, readme, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cmd.GenerateDiagram("./schema", targetPath, cmd.Markdown, "", "", cmd.WithQuiet(), extensionOptions(t)); err != nil {
+		t.Fatalf("Failed to generate the diagram of the schema annotated for the extensions: %v", err)
+	}
+
+	generated, err := os.ReadFile(targetPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := os.ReadFile("readme-expected.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(generated) != string(expected) {
+		t.Errorf("Generated file does not match the expected output:\n%s", generated)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	cacheDir := t.TempDir()
+
+	// The second load reads the annotations of the extensions back from the cache.
+	for _, cached := range []bool{false, true} {
+		var warnings []cmd.Warning
+		model, err := cmd.Inspect("./schema", cmd.WithQuiet(), cmd.WithCache(cacheDir), extensionOptions(t),
+			cmd.WithWarningHandler(func(w cmd.Warning) {
+				warnings = append(warnings, w)
+			}))
+		if err != nil {
+			t.Fatalf("Failed to load the schema annotated for the extensions (cached: %v): %v", cached, err)
+		}
+
+		todo := model.Entity("Todo")
+		if todo == nil || todo.Style == nil || todo.Attribute("priority").Classification != "public" {
+			t.Errorf("Expected the entmaid annotations declared along with the extensions to be read (cached: %v)", cached)
+		}
+		if len(warnings) != 0 {
+			t.Errorf("Expected the annotations of the extensions to be ignored, got %v (cached: %v)", warnings, cached)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/lespea/entmaid/examples/extensions
+
+go 1.23
+
+require (
+	entgo.io/contrib v0.6.0
+	entgo.io/ent v0.14.5
+	github.com/lespea/entmaid v0.0.0
+)
+
+// The example tests the entmaid of this checkout.
+replace github.com/lespea/entmaid => ../..
@@ -0,0 +1,71 @@
+package schema
+
+import (
+	"entgo.io/contrib/entgql"
+	"entgo.io/contrib/entoas"
+	"entgo.io/ent"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+
+	"github.com/lespea/entmaid/annotation"
+)
+
+// Todo holds the schema definition for the Todo entity.
+type Todo struct {
+	ent.Schema
+}
+
+// Fields of the Todo.
+func (Todo) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("text").
+			Annotations(
+				entgql.OrderField("TEXT"),
+				entoas.Example("Buy milk"),
+			),
+		field.Enum("status").
+			Values("in_progress", "completed").
+			Annotations(
+				entgql.OrderField("STATUS"),
+				entgql.Skip(entgql.SkipWhereInput),
+			),
+		field.Time("created_at").
+			Immutable().
+			Annotations(
+				entgql.OrderField("CREATED_AT"),
+				entoas.ReadOnly(true),
+			),
+		field.Int("priority").
+			Default(0).
+			Annotations(
+				entgql.Directives(entgql.Deprecated("use the status")),
+				annotation.Classified(annotation.Public),
+			),
+	}
+}
+
+// Edges of the Todo.
+func (Todo) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.To("children", Todo.Type).
+			Annotations(entgql.RelayConnection(), entoas.Groups("tree")).
+			From("parent").
+			Unique(),
+		edge.From("owner", User.Type).
+			Ref("todos").
+			Unique().
+			Annotations(entoas.Groups("owner")),
+	}
+}
+
+// Annotations of the Todo.
+func (Todo) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		entgql.QueryField(),
+		entgql.RelayConnection(),
+		entgql.Mutations(),
+		entoas.DeleteOperation(entoas.OperationPolicy(entoas.PolicyExclude)),
+		annotation.Styled(annotation.Style{Fill: "#dfd"}),
+	}
+}
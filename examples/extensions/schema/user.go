@@ -0,0 +1,43 @@
+package schema
+
+import (
+	"entgo.io/contrib/entgql"
+	"entgo.io/contrib/entoas"
+	"entgo.io/ent"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+)
+
+// User holds the schema definition for the User entity.
+type User struct {
+	ent.Schema
+}
+
+// Fields of the User.
+func (User) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("name").
+			Annotations(entgql.OrderField("NAME"), entoas.Example("Ada")),
+		field.String("password").
+			Sensitive().
+			Annotations(entgql.Skip(), entoas.Skip(true)),
+	}
+}
+
+// Edges of the User.
+func (User) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.To("todos", Todo.Type).
+			Annotations(entgql.RelayConnection()),
+	}
+}
+
+// Annotations of the User.
+func (User) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		entgql.QueryField("users"),
+		entgql.Directives(entgql.Deprecated("use the accounts")),
+		entoas.Groups("public"),
+	}
+}
@@ -0,0 +1,50 @@
+package schema
+
+import (
+	"context"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+)
+
+// Account holds the schema definition for the Account entity.
+type Account struct {
+	ent.Schema
+}
+
+// Fields of the Account.
+func (Account) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("owner"),
+		field.Int("balance"),
+	}
+}
+
+// Edges of the Account.
+func (Account) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.To("transfers", Transfer.Type),
+	}
+}
+
+// Policy of the Account.
+func (Account) Policy() ent.Policy {
+	return ownerPolicy{}
+}
+
+// Hooks of the Account.
+func (Account) Hooks() []ent.Hook {
+	return []ent.Hook{
+		func(next ent.Mutator) ent.Mutator {
+			return next
+		},
+	}
+}
+
+// ownerPolicy only lets the owners of the accounts read and update them.
+type ownerPolicy struct{}
+
+func (ownerPolicy) EvalMutation(context.Context, ent.Mutation) error { return nil }
+
+func (ownerPolicy) EvalQuery(context.Context, ent.Query) error { return nil }
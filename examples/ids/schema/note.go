@@ -0,0 +1,29 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+)
+
+// Note holds the schema definition for the Note entity.
+type Note struct {
+	ent.Schema
+}
+
+// Fields of the Note.
+func (Note) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int64("id"),
+		field.Text("body"),
+	}
+}
+
+// Edges of the Note.
+func (Note) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("account", Account.Type).
+			Ref("notes").
+			Unique(),
+	}
+}
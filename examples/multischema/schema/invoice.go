@@ -0,0 +1,37 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/entsql"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+)
+
+// Invoice holds the schema definition for the Invoice entity, stored in the billing database schema.
+type Invoice struct {
+	ent.Schema
+}
+
+// Annotations of the Invoice.
+func (Invoice) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		entsql.Schema("billing"),
+	}
+}
+
+// Fields of the Invoice.
+func (Invoice) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int64("total"),
+	}
+}
+
+// Edges of the Invoice.
+func (Invoice) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("user", User.Type).
+			Ref("invoices").
+			Unique(),
+	}
+}
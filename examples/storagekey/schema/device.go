@@ -0,0 +1,28 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+)
+
+// Device holds the schema definition for the Device entity.
+type Device struct {
+	ent.Schema
+}
+
+// Fields of the Device.
+func (Device) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("serial"),
+	}
+}
+
+// Edges of the Device.
+func (Device) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("owner", User.Type).
+			Ref("devices").
+			Unique(),
+	}
+}
@@ -0,0 +1,28 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/entsql"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/field"
+)
+
+// ActiveUser holds the schema definition for the view of the active users.
+type ActiveUser struct {
+	ent.View
+}
+
+// Annotations of the ActiveUser.
+func (ActiveUser) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		entsql.View("SELECT id, name FROM users WHERE active"),
+	}
+}
+
+// Fields of the ActiveUser.
+func (ActiveUser) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int("id"),
+		field.String("name"),
+	}
+}
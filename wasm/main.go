@@ -0,0 +1,90 @@
+//go:build js && wasm
+
+// Command wasm is the browser build of the entmaid renderers, for a playground drawing the diagram of a schema
+// snapshot (the JSON model written by --output json) without a Go toolchain. Build it with:
+//
+//	GOOS=js GOARCH=wasm go build -o entmaid.wasm ./wasm
+//
+// and load it with the wasm_exec.js of the Go distribution. It defines the entmaidRender(model, format, options)
+// JavaScript function, returning {diagram} or {error}. The options are an object holding the notation and the
+// mermaidVersion, as given to the flags of the same names.
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"syscall/js"
+
+	"github.com/lespea/entmaid/cmd"
+)
+
+func main() {
+	js.Global().Set("entmaidRender", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		diagram, err := render(args)
+		if err != nil {
+			return map[string]any{"error": err.Error()}
+		}
+
+		return map[string]any{"diagram": diagram}
+	}))
+
+	// Keep the function callable for the lifetime of the page.
+	select {}
+}
+
+// render renders the model of the first argument in the format of the second one, Mermaid by default.
+func render(args []js.Value) (string, error) {
+	if len(args) == 0 || args[0].Type() != js.TypeString {
+		return "", fmt.Errorf("expected the JSON model as the first argument")
+	}
+
+	format := cmd.FormatMermaid
+	if len(args) > 1 && args[1].Type() == js.TypeString && args[1].String() != "" {
+		format = cmd.Format(args[1].String())
+	}
+
+	var opts []cmd.Option
+	if len(args) > 2 && args[2].Type() == js.TypeObject {
+		if notation := args[2].Get("notation"); notation.Type() == js.TypeString {
+			id, ok := lookupID(cmd.NotationIds, notation.String())
+			if !ok {
+				return "", fmt.Errorf("unknown notation %q", notation.String())
+			}
+			opts = append(opts, cmd.WithNotation(id))
+		}
+		if version := args[2].Get("mermaidVersion"); version.Type() == js.TypeString {
+			id, ok := lookupID(cmd.MermaidVersionIds, version.String())
+			if !ok {
+				return "", fmt.Errorf("unknown Mermaid version %q", version.String())
+			}
+			opts = append(opts, cmd.WithMermaidVersion(id))
+		}
+	}
+
+	model, err := cmd.ReadModel(strings.NewReader(args[0].String()))
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	if err := cmd.Render(context.Background(), &builder, model, format, opts...); err != nil {
+		return "", err
+	}
+
+	return builder.String(), nil
+}
+
+// lookupID returns the value of the enum flag named name.
+func lookupID[T comparable](ids map[T][]string, name string) (T, bool) {
+	for id, names := range ids {
+		for _, n := range names {
+			if n == name {
+				return id, true
+			}
+		}
+	}
+
+	var zero T
+	return zero, false
+}